@@ -0,0 +1,112 @@
+// Command gitlab-release drives the GitLab provider without semantic-release, e.g. for manual hotfix releases or to
+// debug the provider. The next version is computed from the conventional commits since the latest release. The
+// maintenance branches matching gitlab_maintenance_branches are released after the branch, even if it has nothing to
+// release.
+//
+// Options of the provider are set with -set key=value or read from the environment like in a semantic-release run:
+//
+//	gitlab-release -set gitlab_projectid=group/project -set gitlab_branch=main -asset 'dist/*'
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	gitlabProvider "github.com/go-semantic-release/provider-gitlab/pkg/provider"
+)
+
+// listFlag collects the values of a flag given multiple times
+type listFlag []string
+
+func (l *listFlag) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *listFlag) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "gitlab-release: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("gitlab-release", flag.ContinueOnError)
+	var options, assets listFlag
+	flags.Var(&options, "set", "set a provider option, e.g. gitlab_branch=main (repeatable)")
+	flags.Var(&assets, "asset", "upload the files matching the pattern to the release (repeatable)")
+	version := flags.String("version", "", "release this version instead of the one computed from the commits")
+	dryRun := flags.Bool("dry-run", false, "only log what would be released")
+	printOnly := flags.Bool("print", false, "only print the next version")
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+	if flags.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments: %s", strings.Join(flags.Args(), " "))
+	}
+
+	config := make(map[string]string)
+	for _, option := range options {
+		key, value, ok := strings.Cut(option, "=")
+		if !ok {
+			return fmt.Errorf("invalid option %q, expected key=value", option)
+		}
+		config[key] = value
+	}
+	if len(assets) > 0 {
+		config["gitlab_assets"] = strings.Join(assets, ",")
+	}
+	if *dryRun {
+		config["gitlab_dry_run"] = "true"
+	}
+
+	repo := &gitlabProvider.GitLabRepository{}
+	if err := repo.Init(config); err != nil {
+		return err
+	}
+
+	release, err := repo.NextRelease()
+	if err != nil {
+		return err
+	}
+	if *version != "" {
+		// the version is released from the head of the branch even without changes, e.g. to retry a failed release
+		release.NewVersion = strings.TrimPrefix(*version, "v")
+	}
+	if release.NewVersion == "" {
+		fmt.Fprintln(stdout, "no changes to release")
+		if *printOnly {
+			return nil
+		}
+		return repo.ReleaseMaintenanceBranches()
+	}
+
+	if *printOnly {
+		fmt.Fprintln(stdout, release.NewVersion)
+		return nil
+	}
+	if err := repo.CreateRelease(release); err != nil {
+		return err
+	}
+	if mergeRequest := repo.PendingReleaseMergeRequest(); mergeRequest != "" {
+		// the release is published by the run after the merge of the release merge request
+		fmt.Fprintf(stdout, "waiting for release merge request %s to be merged\n", mergeRequest)
+		return nil
+	}
+	if !*dryRun {
+		fmt.Fprintf(stdout, "released %s\n", release.NewVersion)
+	}
+	// the maintenance branches are released last, their failures do not affect the published release
+	return repo.ReleaseMaintenanceBranches()
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-semantic-release/provider-gitlab/pkg/gitlabtest"
+	"github.com/stretchr/testify/require"
+	"github.com/xanzy/go-gitlab"
+)
+
+// newTestProject serves a project whose last release is 1.0.0 followed by a fix
+func newTestProject(t *testing.T) (*gitlabtest.Server, *gitlabtest.Project) {
+	s := gitlabtest.NewServer()
+	t.Cleanup(s.Close)
+	p := s.AddProject(gitlab.Project{PathWithNamespace: "group/project"})
+	s.AddCommit(p, "1111111111", "feat: first")
+	s.AddTag(p, "v1.0.0", "1111111111")
+	s.AddCommit(p, "2222222222", "fix: handle timeouts")
+	return s, p
+}
+
+func TestRun(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		args     []string
+		err      string
+		stdout   string
+		released string
+	}{
+		{name: "release", stdout: "released 1.0.1\n", released: "v1.0.1"},
+		{name: "version", args: []string{"-version", "v1.1.0"}, stdout: "released 1.1.0\n", released: "v1.1.0"},
+		{name: "print", args: []string{"-print"}, stdout: "1.0.1\n"},
+		{name: "print version", args: []string{"-print", "-version", "2.0.0"}, stdout: "2.0.0\n"},
+		{name: "dry run", args: []string{"-dry-run"}},
+		{name: "help", args: []string{"-h"}},
+		{name: "unknown flag", args: []string{"-force"}, err: "flag provided but not defined: -force"},
+		{name: "arguments", args: []string{"1.0.1"}, err: "unexpected arguments: 1.0.1"},
+		{name: "invalid option", args: []string{"-set", "gitlab_branch"}, err: `invalid option "gitlab_branch", expected key=value`},
+		{name: "unknown option", args: []string{"-set", "gitlab_brnch=main"}, err: "unknown option gitlab_brnch, did you mean gitlab_branch?"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s, p := newTestProject(t)
+			args := append([]string{
+				"-set", "gitlab_baseurl=" + s.URL,
+				"-set", "token=token",
+				"-set", "gitlab_projectid=1",
+				"-set", "gitlab_branch=main",
+			}, tc.args...)
+
+			var stdout bytes.Buffer
+			err := run(args, &stdout)
+			if tc.err != "" {
+				require.EqualError(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.stdout, stdout.String())
+			if tc.released == "" {
+				require.Empty(t, p.Releases)
+				return
+			}
+			release := s.Release(p, tc.released)
+			require.NotNil(t, release)
+			require.Equal(t, "2222222222", release.Commit.ID)
+		})
+	}
+}
+
+func TestRunReleasesMaintenanceBranchesWithoutChanges(t *testing.T) {
+	s := gitlabtest.NewServer()
+	defer s.Close()
+	p := s.AddProject(gitlab.Project{PathWithNamespace: "group/project"})
+	s.AddCommit(p, "1111111111", "feat: first")
+	s.AddTag(p, "v1.0.0", "1111111111")
+	backport := s.AddCommit(p, "2222222222", "fix: backported fix")
+	s.AddCommit(p, "3333333333", "feat!: breaking change")
+	s.AddTag(p, "v2.0.0", "3333333333")
+
+	// the fake server only knows the default branch, release/1.x points to the backported fix
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/api/v4/projects/1/repository/branches":
+			//nolint:errcheck
+			json.NewEncoder(w).Encode([]*gitlab.Branch{
+				{Name: "main", Commit: p.Commits[0]},
+				{Name: "release/1.x", Commit: backport},
+			})
+		case r.Method == "GET" && strings.HasPrefix(r.URL.Path, "/api/v4/projects/1/repository/commits/") && strings.HasSuffix(r.URL.Path, "/refs"):
+			//nolint:errcheck
+			json.NewEncoder(w).Encode([]*gitlab.CommitRef{{Type: "branch", Name: "release/1.x"}})
+		default:
+			s.Config.Handler.ServeHTTP(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	var stdout bytes.Buffer
+	err := run([]string{
+		"-set", "gitlab_baseurl=" + ts.URL,
+		"-set", "token=token",
+		"-set", "gitlab_projectid=1",
+		"-set", "gitlab_branch=main",
+		"-set", "gitlab_maintenance_branches=release/*",
+	}, &stdout)
+	require.NoError(t, err)
+	require.Equal(t, "no changes to release\n", stdout.String())
+	release := s.Release(p, "v1.0.1")
+	require.NotNil(t, release)
+	require.Equal(t, "2222222222", release.Commit.ID)
+	require.Nil(t, s.Release(p, "v2.0.1"))
+}
@@ -0,0 +1,625 @@
+// Package gitlabtest provides a fake GitLab API for tests of the provider and of tools built around semantic-release.
+// It serves projects, commits, tags and releases from memory, paginates them like GitLab and can simulate rate
+// limiting and failing requests.
+package gitlabtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+const defaultPerPage = 20
+
+// Project is a project served by the fake server, its fields must only be changed through the server methods while
+// the server is in use.
+type Project struct {
+	gitlab.Project
+
+	// Commits of the default branch, the newest commit first
+	Commits  []*gitlab.Commit
+	Tags     []*gitlab.Tag
+	Releases []*gitlab.Release
+
+	// Signatures of the commits by SHA
+	Signatures map[string]*gitlab.GPGSignature
+}
+
+// Server is a fake GitLab instance, use URL as gitlab_baseurl.
+type Server struct {
+	*httptest.Server
+
+	// Token is the token requests have to authenticate with, any token is accepted if it is empty
+	Token string
+
+	// Version is the GitLab version of the instance, the version endpoint answers 404 Not Found if it is empty
+	Version string
+
+	mu          sync.Mutex
+	projects    []*Project
+	rateLimited int
+	failures    map[string]*failure
+	handlers    map[string]http.HandlerFunc
+	requests    []string
+	queries     []url.Values
+}
+
+type failure struct {
+	status int
+	n      int
+}
+
+// NewServer starts a fake GitLab instance without projects, it has to be closed by the caller.
+func NewServer() *Server {
+	s := &Server{}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// AddProject adds a project, the default branch defaults to main.
+func (s *Server) AddProject(project gitlab.Project) *Project {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if project.DefaultBranch == "" {
+		project.DefaultBranch = "main"
+	}
+	if project.ID == 0 {
+		project.ID = len(s.projects) + 1
+	}
+	if project.PathWithNamespace == "" {
+		project.PathWithNamespace = fmt.Sprintf("group/project-%d", project.ID)
+	}
+	project.WebURL = s.URL + "/" + project.PathWithNamespace
+
+	p := &Project{Project: project}
+	s.projects = append(s.projects, p)
+	return p
+}
+
+// AddCommit adds a commit on top of the default branch of the project.
+func (s *Server) AddCommit(p *Project, sha, message string) *gitlab.Commit {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	commit := &gitlab.Commit{ID: sha, ShortID: shortID(sha), Title: strings.SplitN(message, "\n", 2)[0], Message: message, CommittedDate: &now}
+	p.Commits = append([]*gitlab.Commit{commit}, p.Commits...)
+	return commit
+}
+
+// PushCommits adds the commits on top of the default branch of the project in the given order, the last one becomes
+// the head. Unlike AddCommit the commits are kept as they are, e.g. with their authors, dates and parents.
+func (s *Server) PushCommits(p *Project, commits ...*gitlab.Commit) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, commit := range commits {
+		if commit.ShortID == "" {
+			commit.ShortID = shortID(commit.ID)
+		}
+		if commit.Title == "" {
+			commit.Title = strings.SplitN(commit.Message, "\n", 2)[0]
+		}
+		p.Commits = append([]*gitlab.Commit{commit}, p.Commits...)
+	}
+}
+
+// SignCommit sets the signature of a commit of the project, commits without a signature are unsigned.
+func (s *Server) SignCommit(p *Project, sha string, signature *gitlab.GPGSignature) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if p.Signatures == nil {
+		p.Signatures = make(map[string]*gitlab.GPGSignature)
+	}
+	p.Signatures[sha] = signature
+}
+
+// AddTag tags a commit of the project, the commit does not have to be part of the default branch.
+func (s *Server) AddTag(p *Project, name, sha string) *gitlab.Tag {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	commit := findCommit(p, sha)
+	if commit == nil {
+		commit = &gitlab.Commit{ID: sha, ShortID: shortID(sha)}
+	}
+	tag := &gitlab.Tag{Name: name, Commit: commit}
+	p.Tags = append(p.Tags, tag)
+	return tag
+}
+
+// RateLimit answers the next n requests with 429 Too Many Requests.
+func (s *Server) RateLimit(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rateLimited = n
+}
+
+// Fail answers the next n requests with the method and path of request, e.g. "GET /api/v4/projects/1/repository/tags",
+// with the status.
+func (s *Server) Fail(request string, status, n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.failures == nil {
+		s.failures = make(map[string]*failure)
+	}
+	s.failures[request] = &failure{status: status, n: n}
+}
+
+// HandleFunc answers the requests with the method and path of request with fn instead of the fake, e.g. to simulate
+// endpoints the server does not serve. Requests handled by fn are not authenticated.
+func (s *Server) HandleFunc(request string, fn http.HandlerFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.handlers == nil {
+		s.handlers = make(map[string]http.HandlerFunc)
+	}
+	s.handlers[request] = fn
+}
+
+// Requests returns the method and path of all API requests served so far, e.g. "GET /api/v4/projects/1".
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.requests...)
+}
+
+// Queries returns the query parameters of the requests served so far with the method and path of request.
+func (s *Server) Queries(request string) []url.Values {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var queries []url.Values
+	for i, served := range s.requests {
+		if served == request {
+			queries = append(queries, s.queries[i])
+		}
+	}
+	return queries
+}
+
+// Release returns the release of the tag or nil.
+func (s *Server) Release(p *Project, tag string) *gitlab.Release {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return findRelease(p, tag)
+}
+
+func shortID(sha string) string {
+	if len(sha) > 8 {
+		return sha[:8]
+	}
+	return sha
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	// go-gitlab probes the rate limit of the instance when the client is created
+	if r.Method == http.MethodGet && r.URL.Path == "/api/v4/" {
+		writeJSON(w, http.StatusOK, struct{}{})
+		return
+	}
+
+	if s.intercept(w, r) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token := r.Header.Get("PRIVATE-TOKEN")
+	if token == "" {
+		token = r.Header.Get("JOB-TOKEN")
+	}
+	if token == "" {
+		token = strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	}
+	if token == "" || s.Token != "" && token != s.Token {
+		writeError(w, http.StatusUnauthorized, "401 Unauthorized")
+		return
+	}
+
+	if r.Method == http.MethodGet && r.URL.Path == "/api/v4/version" {
+		if s.Version == "" {
+			writeError(w, http.StatusNotFound, "404 Not Found")
+			return
+		}
+		writeJSON(w, http.StatusOK, &gitlab.Version{Version: s.Version})
+		return
+	}
+
+	segments, ok := pathSegments(r)
+	if !ok || len(segments) < 2 || segments[0] != "projects" {
+		writeError(w, http.StatusNotFound, "404 Not Found")
+		return
+	}
+	p := s.findProject(segments[1])
+	if p == nil {
+		writeError(w, http.StatusNotFound, "404 Project Not Found")
+		return
+	}
+	s.serveProject(w, r, p, strings.Join(segments[2:], "/"), segments[2:])
+}
+
+// intercept records the request and answers it if it is rate limited, has to fail or has its own handler
+func (s *Server) intercept(w http.ResponseWriter, r *http.Request) bool {
+	request := r.Method + " " + r.URL.Path
+
+	s.mu.Lock()
+	s.requests = append(s.requests, request)
+	s.queries = append(s.queries, r.URL.Query())
+	if s.rateLimited > 0 {
+		s.rateLimited--
+		s.mu.Unlock()
+		w.Header().Set("RateLimit-Remaining", "0")
+		w.Header().Set("Retry-After", "1")
+		writeError(w, http.StatusTooManyRequests, "Retry later")
+		return true
+	}
+	if f := s.failures[request]; f != nil && f.n > 0 {
+		f.n--
+		s.mu.Unlock()
+		writeError(w, f.status, fmt.Sprintf("%d %s", f.status, http.StatusText(f.status)))
+		return true
+	}
+	fn := s.handlers[request]
+	s.mu.Unlock()
+
+	if fn == nil {
+		return false
+	}
+	fn(w, r)
+	return true
+}
+
+// pathSegments returns the unescaped segments of the path below /api/v4/, escaped slashes stay in their segment
+func pathSegments(r *http.Request) ([]string, bool) {
+	path := r.URL.EscapedPath()
+	if !strings.HasPrefix(path, "/api/v4/") {
+		return nil, false
+	}
+	segments := strings.Split(strings.TrimPrefix(path, "/api/v4/"), "/")
+	for i, segment := range segments {
+		unescaped, err := url.PathUnescape(segment)
+		if err != nil {
+			return nil, false
+		}
+		segments[i] = unescaped
+	}
+	return segments, true
+}
+
+func (s *Server) findProject(pid string) *Project {
+	for _, p := range s.projects {
+		if strconv.Itoa(p.ID) == pid || p.PathWithNamespace == pid {
+			return p
+		}
+	}
+	return nil
+}
+
+//nolint:gocyclo
+func (s *Server) serveProject(w http.ResponseWriter, r *http.Request, p *Project, route string, segments []string) {
+	switch {
+	case r.Method == http.MethodGet && route == "":
+		writeJSON(w, http.StatusOK, p.Project)
+
+	case r.Method == http.MethodGet && route == "repository/commits":
+		commits := listCommits(p, r.URL.Query().Get("ref_name"))
+		if since := r.URL.Query().Get("since"); since != "" {
+			date, err := time.Parse(time.RFC3339, since)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, "since is invalid")
+				return
+			}
+			commits = commitsSince(commits, date)
+		}
+		start, end := paginate(w, r, len(commits))
+		writeJSON(w, http.StatusOK, commits[start:end])
+
+	case r.Method == http.MethodGet && route == "repository/compare":
+		query := r.URL.Query()
+		commits := listCommits(p, query.Get("from")+"..."+query.Get("to"))
+		// the compare API lists the oldest commit first
+		compare := &gitlab.Compare{Commits: make([]*gitlab.Commit, len(commits))}
+		for i, commit := range commits {
+			compare.Commits[len(commits)-1-i] = commit
+		}
+		if len(commits) > 0 {
+			compare.Commit = commits[0]
+		}
+		writeJSON(w, http.StatusOK, compare)
+
+	case r.Method == http.MethodGet && len(segments) == 4 && segments[0] == "repository" && segments[1] == "commits" && segments[3] == "signature":
+		if signature := p.Signatures[segments[2]]; signature != nil {
+			writeJSON(w, http.StatusOK, signature)
+			return
+		}
+		writeError(w, http.StatusNotFound, "404 Signature Not Found")
+
+	case r.Method == http.MethodGet && len(segments) == 3 && segments[0] == "repository" && segments[1] == "commits":
+		if commit := findCommit(p, segments[2]); commit != nil {
+			writeJSON(w, http.StatusOK, commit)
+			return
+		}
+		writeError(w, http.StatusNotFound, "404 Commit Not Found")
+
+	case r.Method == http.MethodGet && len(segments) == 3 && segments[0] == "repository" && segments[1] == "branches":
+		if segments[2] != p.DefaultBranch || len(p.Commits) == 0 {
+			writeError(w, http.StatusNotFound, "404 Branch Not Found")
+			return
+		}
+		writeJSON(w, http.StatusOK, &gitlab.Branch{Name: p.DefaultBranch, Commit: p.Commits[0], Default: true})
+
+	case r.Method == http.MethodGet && route == "repository/tags":
+		start, end := paginate(w, r, len(p.Tags))
+		writeJSON(w, http.StatusOK, p.Tags[start:end])
+
+	case r.Method == http.MethodPost && route == "repository/tags":
+		var opts gitlab.CreateTagOptions
+		if err := json.NewDecoder(r.Body).Decode(&opts); err != nil || opts.TagName == nil || opts.Ref == nil {
+			writeError(w, http.StatusBadRequest, "tag_name and ref are required")
+			return
+		}
+		tag, status, message := createTag(p, *opts.TagName, *opts.Ref)
+		if tag == nil {
+			writeError(w, status, message)
+			return
+		}
+		if opts.Message != nil {
+			tag.Message = *opts.Message
+		}
+		writeJSON(w, http.StatusCreated, tag)
+
+	case len(segments) == 3 && segments[0] == "repository" && segments[1] == "tags":
+		i := findTag(p, segments[2])
+		switch {
+		case i < 0:
+			writeError(w, http.StatusNotFound, "404 Tag Not Found")
+		case r.Method == http.MethodGet:
+			writeJSON(w, http.StatusOK, p.Tags[i])
+		case r.Method == http.MethodDelete:
+			p.Tags = append(p.Tags[:i], p.Tags[i+1:]...)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			writeError(w, http.StatusMethodNotAllowed, "405 Method Not Allowed")
+		}
+
+	case r.Method == http.MethodGet && route == "releases":
+		start, end := paginate(w, r, len(p.Releases))
+		writeJSON(w, http.StatusOK, p.Releases[start:end])
+
+	case r.Method == http.MethodPost && route == "releases":
+		var opts gitlab.CreateReleaseOptions
+		if err := json.NewDecoder(r.Body).Decode(&opts); err != nil || opts.TagName == nil {
+			writeError(w, http.StatusBadRequest, "tag_name is required")
+			return
+		}
+		s.createRelease(w, p, &opts)
+
+	case len(segments) == 2 && segments[0] == "releases":
+		release := findRelease(p, segments[1])
+		switch {
+		case release == nil:
+			writeError(w, http.StatusNotFound, "404 Not Found")
+		case r.Method == http.MethodGet:
+			writeJSON(w, http.StatusOK, release)
+		case r.Method == http.MethodPut:
+			var opts gitlab.UpdateReleaseOptions
+			if err := json.NewDecoder(r.Body).Decode(&opts); err != nil {
+				writeError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			if opts.Name != nil {
+				release.Name = *opts.Name
+			}
+			if opts.Description != nil {
+				release.Description = *opts.Description
+			}
+			writeJSON(w, http.StatusOK, release)
+		case r.Method == http.MethodDelete:
+			for i := range p.Releases {
+				if p.Releases[i] == release {
+					p.Releases = append(p.Releases[:i], p.Releases[i+1:]...)
+					break
+				}
+			}
+			writeJSON(w, http.StatusOK, release)
+		default:
+			writeError(w, http.StatusMethodNotAllowed, "405 Method Not Allowed")
+		}
+
+	default:
+		writeError(w, http.StatusNotFound, "404 Not Found")
+	}
+}
+
+func (s *Server) createRelease(w http.ResponseWriter, p *Project, opts *gitlab.CreateReleaseOptions) {
+	tagName := *opts.TagName
+	if findRelease(p, tagName) != nil {
+		writeError(w, http.StatusConflict, "Release already exists")
+		return
+	}
+
+	i := findTag(p, tagName)
+	if i < 0 {
+		// like GitLab, the tag is created from the ref if it does not exist
+		if opts.Ref == nil {
+			writeError(w, http.StatusUnprocessableEntity, "Ref is not specified")
+			return
+		}
+		tag, status, message := createTag(p, tagName, *opts.Ref)
+		if tag == nil {
+			writeError(w, status, message)
+			return
+		}
+		i = len(p.Tags) - 1
+	}
+
+	now := time.Now()
+	release := &gitlab.Release{TagName: tagName, Name: tagName, CreatedAt: &now, ReleasedAt: &now, Commit: *p.Tags[i].Commit}
+	if opts.Name != nil {
+		release.Name = *opts.Name
+	}
+	if opts.Description != nil {
+		release.Description = *opts.Description
+	}
+	if opts.ReleasedAt != nil {
+		release.ReleasedAt = opts.ReleasedAt
+	}
+	if opts.Assets != nil {
+		for i, link := range opts.Assets.Links {
+			releaseLink := &gitlab.ReleaseLink{ID: i + 1}
+			if link.Name != nil {
+				releaseLink.Name = *link.Name
+			}
+			if link.URL != nil {
+				releaseLink.URL = *link.URL
+				releaseLink.DirectAssetURL = *link.URL
+			}
+			if link.LinkType != nil {
+				releaseLink.LinkType = *link.LinkType
+			}
+			release.Assets.Links = append(release.Assets.Links, releaseLink)
+		}
+		release.Assets.Count = len(release.Assets.Links)
+	}
+	p.Releases = append(p.Releases, release)
+	writeJSON(w, http.StatusCreated, release)
+}
+
+// listCommits resolves "from...to" ranges of the history of the default branch, other refs list the history up to
+// the ref. Like on a lagging Geo secondary, unknown commits have no history.
+func listCommits(p *Project, ref string) []*gitlab.Commit {
+	from, to, isRange := strings.Cut(ref, "...")
+	if !isRange {
+		from, to = "", ref
+	}
+
+	start, end := 0, len(p.Commits)
+	if to != "" && to != p.DefaultBranch {
+		start = -1
+	}
+	for i, commit := range p.Commits {
+		if start < 0 && commit.ID == to {
+			start = i
+		}
+		if from != "" && commit.ID == from {
+			end = i
+		}
+	}
+	if start < 0 || start > end {
+		return []*gitlab.Commit{}
+	}
+	return p.Commits[start:end]
+}
+
+// commitsSince returns the commits committed at or after the date
+func commitsSince(commits []*gitlab.Commit, date time.Time) []*gitlab.Commit {
+	since := make([]*gitlab.Commit, 0, len(commits))
+	for _, commit := range commits {
+		if commit.CommittedDate != nil && !commit.CommittedDate.Before(date) {
+			since = append(since, commit)
+		}
+	}
+	return since
+}
+
+func createTag(p *Project, name, ref string) (*gitlab.Tag, int, string) {
+	if findTag(p, name) >= 0 {
+		return nil, http.StatusBadRequest, fmt.Sprintf("Tag %s already exists", name)
+	}
+	commit := findCommit(p, ref)
+	if commit == nil {
+		return nil, http.StatusBadRequest, "Target " + ref + " is invalid"
+	}
+	tag := &gitlab.Tag{Name: name, Commit: commit}
+	p.Tags = append(p.Tags, tag)
+	return tag, 0, ""
+}
+
+func findCommit(p *Project, ref string) *gitlab.Commit {
+	if ref == p.DefaultBranch && len(p.Commits) > 0 {
+		return p.Commits[0]
+	}
+	for _, commit := range p.Commits {
+		if commit.ID == ref || commit.ShortID == ref {
+			return commit
+		}
+	}
+	return nil
+}
+
+func findTag(p *Project, name string) int {
+	for i, tag := range p.Tags {
+		if tag.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func findRelease(p *Project, tag string) *gitlab.Release {
+	for _, release := range p.Releases {
+		if release.TagName == tag {
+			return release
+		}
+	}
+	return nil
+}
+
+// paginate returns the bounds of the requested page of total items and sets the pagination headers of GitLab
+func paginate(w http.ResponseWriter, r *http.Request, total int) (int, int) {
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	perPage, _ := strconv.Atoi(r.URL.Query().Get("per_page"))
+	if perPage < 1 {
+		perPage = defaultPerPage
+	}
+	totalPages := (total + perPage - 1) / perPage
+	if totalPages == 0 {
+		totalPages = 1
+	}
+
+	start := (page - 1) * perPage
+	if start > total {
+		start = total
+	}
+	end := start + perPage
+	if end > total {
+		end = total
+	}
+
+	w.Header().Set("X-Page", strconv.Itoa(page))
+	w.Header().Set("X-Per-Page", strconv.Itoa(perPage))
+	w.Header().Set("X-Total", strconv.Itoa(total))
+	w.Header().Set("X-Total-Pages", strconv.Itoa(totalPages))
+	if page < totalPages {
+		w.Header().Set("X-Next-Page", strconv.Itoa(page+1))
+	}
+	if page > 1 {
+		w.Header().Set("X-Prev-Page", strconv.Itoa(page-1))
+	}
+	return start, end
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	//nolint:errcheck
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"message": message})
+}
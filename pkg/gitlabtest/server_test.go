@@ -0,0 +1,168 @@
+package gitlabtest
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xanzy/go-gitlab"
+)
+
+func newTestClient(t *testing.T, s *Server) *gitlab.Client {
+	client, err := gitlab.NewClient("token", gitlab.WithBaseURL(s.URL))
+	require.NoError(t, err)
+	return client
+}
+
+func TestPagination(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	p := s.AddProject(gitlab.Project{PathWithNamespace: "group/project"})
+	for i := 0; i < 5; i++ {
+		s.AddCommit(p, fmt.Sprintf("commit%d", i), fmt.Sprintf("fix: %d", i))
+	}
+
+	client := newTestClient(t, s)
+	commits, resp, err := client.Commits.ListCommits("group/project", &gitlab.ListCommitsOptions{ListOptions: gitlab.ListOptions{Page: 2, PerPage: 2}})
+	require.NoError(t, err)
+	require.Equal(t, 5, resp.TotalItems)
+	require.Equal(t, 3, resp.TotalPages)
+	require.Equal(t, 3, resp.NextPage)
+	require.Equal(t, "commit2", commits[0].ID)
+	require.Equal(t, "commit1", commits[1].ID)
+
+	commits, _, err = client.Commits.ListCommits(p.ID, &gitlab.ListCommitsOptions{RefName: gitlab.String("commit1...commit3")})
+	require.NoError(t, err)
+	require.Len(t, commits, 2)
+	require.Equal(t, "commit3", commits[0].ID)
+	require.Equal(t, "commit2", commits[1].ID)
+}
+
+func TestReleases(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	p := s.AddProject(gitlab.Project{})
+	s.AddCommit(p, "abcdef123456", "feat: first")
+
+	client := newTestClient(t, s)
+	_, _, err := client.Releases.CreateRelease(p.ID, &gitlab.CreateReleaseOptions{TagName: gitlab.String("v1.0.0"), Ref: gitlab.String("unknown")})
+	require.EqualError(t, err, fmt.Sprintf("POST %s/api/v4/projects/1/releases: 400 {message: Target unknown is invalid}", s.URL))
+
+	release, _, err := client.Releases.CreateRelease(p.ID, &gitlab.CreateReleaseOptions{TagName: gitlab.String("v1.0.0"), Ref: gitlab.String("main"), Description: gitlab.String("notes")})
+	require.NoError(t, err)
+	require.Equal(t, "abcdef123456", release.Commit.ID)
+
+	tag, _, err := client.Tags.GetTag(p.ID, "v1.0.0")
+	require.NoError(t, err)
+	require.Equal(t, "abcdef123456", tag.Commit.ID)
+	require.Equal(t, "notes", s.Release(p, "v1.0.0").Description)
+
+	_, resp, err := client.Releases.CreateRelease(p.ID, &gitlab.CreateReleaseOptions{TagName: gitlab.String("v1.0.0")})
+	require.Error(t, err)
+	require.Equal(t, 409, resp.StatusCode)
+}
+
+func TestAuthentication(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	s.Token = "secret"
+	s.AddProject(gitlab.Project{})
+
+	_, resp, err := newTestClient(t, s).Projects.GetProject(1, nil)
+	require.Error(t, err)
+	require.Equal(t, 401, resp.StatusCode)
+}
+
+func TestRateLimit(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	s.AddProject(gitlab.Project{})
+	s.RateLimit(1)
+
+	// the client retries rate limited requests
+	_, _, err := newTestClient(t, s).Projects.GetProject(1, nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{"GET /api/v4/projects/1", "GET /api/v4/projects/1"}, s.Requests())
+}
+
+func TestCommitHistory(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	p := s.AddProject(gitlab.Project{})
+	released := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+	s.PushCommits(p,
+		&gitlab.Commit{ID: "old", Message: "feat: old", CommittedDate: gitlab.Time(released.Add(-time.Hour))},
+		&gitlab.Commit{ID: "release", Message: "chore: release", CommittedDate: &released},
+		&gitlab.Commit{ID: "new", Message: "fix: new\n\nbody", CommittedDate: gitlab.Time(released.Add(time.Hour))},
+	)
+
+	client := newTestClient(t, s)
+	commits, _, err := client.Commits.ListCommits(p.ID, &gitlab.ListCommitsOptions{RefName: gitlab.String("release")})
+	require.NoError(t, err)
+	require.Len(t, commits, 2)
+	require.Equal(t, "fix: new", p.Commits[0].Title)
+
+	commits, _, err = client.Commits.ListCommits(p.ID, &gitlab.ListCommitsOptions{RefName: gitlab.String("main"), Since: &released})
+	require.NoError(t, err)
+	require.Len(t, commits, 2)
+	require.Equal(t, "new", commits[0].ID)
+
+	// unknown commits have no history
+	commits, _, err = client.Commits.ListCommits(p.ID, &gitlab.ListCommitsOptions{RefName: gitlab.String("old...unknown")})
+	require.NoError(t, err)
+	require.Empty(t, commits)
+
+	compare, _, err := client.Repositories.Compare(p.ID, &gitlab.CompareOptions{From: gitlab.String("old"), To: gitlab.String("new")})
+	require.NoError(t, err)
+	require.Equal(t, "release", compare.Commits[0].ID)
+	require.Equal(t, "new", compare.Commits[1].ID)
+	require.Equal(t, "new", compare.Commit.ID)
+
+	s.SignCommit(p, "new", &gitlab.GPGSignature{VerificationStatus: "verified"})
+	signature, _, err := client.Commits.GetGPGSiganature(p.ID, "new")
+	require.NoError(t, err)
+	require.Equal(t, "verified", signature.VerificationStatus)
+	_, resp, err := client.Commits.GetGPGSiganature(p.ID, "old")
+	require.Error(t, err)
+	require.Equal(t, 404, resp.StatusCode)
+}
+
+func TestFailAndHandleFunc(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	s.AddProject(gitlab.Project{})
+	s.Fail("GET /api/v4/projects/1", 500, 1)
+	s.HandleFunc("GET /api/v4/projects/1/repository/branches/main", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"name": "main", "protected": true}`)) //nolint:errcheck
+	})
+
+	client, err := gitlab.NewClient("token", gitlab.WithBaseURL(s.URL), gitlab.WithoutRetries())
+	require.NoError(t, err)
+	_, resp, err := client.Projects.GetProject(1, nil)
+	require.Error(t, err)
+	require.Equal(t, 500, resp.StatusCode)
+	_, _, err = client.Projects.GetProject(1, nil)
+	require.NoError(t, err)
+
+	branch, _, err := client.Branches.GetBranch(1, "main")
+	require.NoError(t, err)
+	require.True(t, branch.Protected)
+	require.Len(t, s.Queries("GET /api/v4/projects/1"), 2)
+}
+
+func TestVersion(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	client := newTestClient(t, s)
+	_, resp, err := client.Version.GetVersion()
+	require.Error(t, err)
+	require.Equal(t, 404, resp.StatusCode)
+
+	s.Version = "15.0.0"
+	version, _, err := client.Version.GetVersion()
+	require.NoError(t, err)
+	require.Equal(t, "15.0.0", version.Version)
+}
@@ -0,0 +1,103 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+// getTokenInfo returns the metadata of the personal, project or group access token in use
+func (repo *GitLabRepository) getTokenInfo() (*gitlab.PersonalAccessToken, *gitlab.Response, error) {
+	req, err := repo.api.NewRequest(http.MethodGet, "personal_access_tokens/self", nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	token := new(gitlab.PersonalAccessToken)
+	resp, err := repo.api.Do(req, token)
+	if err != nil {
+		return nil, resp, err
+	}
+	return token, resp, nil
+}
+
+// VerifyAccess checks that the base URL is reachable and that the token is allowed to create tags and releases
+// in the project. All detected problems are returned as a single error.
+func (repo *GitLabRepository) VerifyAccess() error {
+	user, resp, err := repo.api.CurrentUser()
+	if resp != nil && resp.StatusCode == http.StatusUnauthorized {
+		return errors.New("access verification failed: the token is invalid, expired or revoked")
+	}
+	if err != nil {
+		return fmt.Errorf("access verification failed: could not reach %s: %w", repo.api.BaseURL(), err)
+	}
+
+	var problems []string
+
+	// older instances do not know this endpoint and non-token credentials have no scopes
+	token, _, err := repo.getTokenInfo()
+	if err == nil && !containsString(token.Scopes, "api") {
+		problems = append(problems, fmt.Sprintf("the token has the scopes [%s] but the api scope is required to create tags and releases", strings.Join(token.Scopes, ", ")))
+	}
+
+	_, resp, err = repo.api.GetProject(repo.projectID, nil)
+	switch {
+	case resp != nil && resp.StatusCode == http.StatusNotFound:
+		problems = append(problems, fmt.Sprintf("project %s does not exist or is not visible to user %s", repo.projectID, user.Username))
+	case err != nil:
+		problems = append(problems, fmt.Sprintf("failed to get project %s: %s", repo.projectID, err))
+	case !user.IsAdmin:
+		problems = append(problems, repo.verifyMembership(user)...)
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("access verification failed:\n  - %s", strings.Join(problems, "\n  - "))
+	}
+	return nil
+}
+
+func (repo *GitLabRepository) verifyMembership(user *gitlab.User) []string {
+	member, resp, err := repo.api.GetInheritedProjectMember(repo.projectID, user.ID)
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return []string{fmt.Sprintf("user %s is not a member of project %s", user.Username, repo.projectID)}
+	}
+	if err != nil {
+		return []string{fmt.Sprintf("failed to get the membership of user %s: %s", user.Username, err)}
+	}
+
+	if member.AccessLevel < gitlab.DeveloperPermissions {
+		return []string{fmt.Sprintf("user %s has the %s role in project %s but at least developer is required to create tags and releases",
+			user.Username, accessLevelName(member.AccessLevel), repo.projectID)}
+	}
+	return nil
+}
+
+func accessLevelName(level gitlab.AccessLevelValue) string {
+	switch {
+	case level >= gitlab.OwnerPermissions:
+		return "owner"
+	case level >= gitlab.MaintainerPermissions:
+		return "maintainer"
+	case level >= gitlab.DeveloperPermissions:
+		return "developer"
+	case level >= gitlab.ReporterPermissions:
+		return "reporter"
+	case level >= gitlab.GuestPermissions:
+		return "guest"
+	case level >= gitlab.MinimalAccessPermissions:
+		return "minimal access"
+	}
+	return "no access"
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,51 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xanzy/go-gitlab"
+)
+
+func TestGitlabVerifyAccess(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(GitlabHandler))
+	defer ts.Close()
+
+	config := map[string]string{
+		"gitlab_baseurl":       ts.URL,
+		"token":                "gitlab-examples-ci",
+		"gitlab_projectid":     strconv.Itoa(GITLAB_PROJECT_ID),
+		"gitlab_verify_access": "true",
+	}
+	repo := &GitLabRepository{}
+	require.NoError(t, repo.Init(config))
+
+	//nolint:errcheck
+	ts.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v4/personal_access_tokens/self":
+			json.NewEncoder(w).Encode(gitlab.PersonalAccessToken{Scopes: []string{"read_api"}})
+		case fmt.Sprintf("/api/v4/projects/%d/members/all/%d", GITLAB_PROJECT_ID, GITLAB_USER.ID):
+			json.NewEncoder(w).Encode(gitlab.ProjectMember{AccessLevel: gitlab.ReporterPermissions})
+		default:
+			GitlabHandler(w, r)
+		}
+	})
+	repo = &GitLabRepository{}
+	err := repo.Init(config)
+	require.EqualError(t, err, fmt.Sprintf("access verification failed:\n"+
+		"  - the token has the scopes [read_api] but the api scope is required to create tags and releases\n"+
+		"  - user release-bot has the reporter role in project %d but at least developer is required to create tags and releases", GITLAB_PROJECT_ID))
+
+	ts.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"message":"401 Unauthorized"}`, http.StatusUnauthorized)
+	})
+	repo = &GitLabRepository{}
+	err = repo.Init(config)
+	require.EqualError(t, err, "access verification failed: the token is invalid, expired or revoked")
+}
@@ -0,0 +1,66 @@
+package provider
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+const (
+	defaultAnnouncementComment = "📦 {{if .ReleaseURL}}[{{.Tag}}]({{.ReleaseURL}}){{else}}{{.Tag}}{{end}} has been released" +
+		"{{if .Summary}}: {{.Summary}}{{end}}"
+	announcementDescription = "Release announcements of this project, subscribe to this issue to be notified about new releases."
+)
+
+// announcementIssueIID returns the IID of the issue given by gitlab_announcement_issue. A number refers to an existing
+// issue, otherwise the open issue with the title is used and created if it does not exist yet.
+func (repo *GitLabRepository) announcementIssueIID() (int, error) {
+	if iid, err := strconv.Atoi(repo.announcementIssue); err == nil {
+		return iid, nil
+	}
+
+	issues, _, err := repo.api.ListProjectIssues(repo.projectID, &gitlab.ListProjectIssuesOptions{
+		State:  gitlab.String("opened"),
+		Search: gitlab.String(repo.announcementIssue),
+		In:     gitlab.String("title"),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to search for the announcement issue: %w", err)
+	}
+	for _, issue := range issues {
+		if issue.Title == repo.announcementIssue {
+			return issue.IID, nil
+		}
+	}
+
+	issue, _, err := repo.api.CreateIssue(repo.projectID, &gitlab.CreateIssueOptions{
+		Title:       gitlab.String(repo.announcementIssue),
+		Description: gitlab.String(announcementDescription),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to create the announcement issue: %w", err)
+	}
+	repo.logger.Printf("created the announcement issue #%d, pin it to keep it on top of the issue list", issue.IID)
+	return issue.IID, nil
+}
+
+// announceRelease comments on the announcement issue so its subscribers are notified, failures are only logged
+func (repo *GitLabRepository) announceRelease(data *templateData) {
+	comment, err := renderTemplate(repo.announcementComment, data)
+	if err != nil {
+		repo.logger.Printf("WARNING: %s", err)
+		return
+	}
+
+	iid, err := repo.announcementIssueIID()
+	if err != nil {
+		repo.logger.Printf("WARNING: %s", err)
+		return
+	}
+	if _, _, err := repo.api.CreateIssueNote(repo.projectID, iid, &gitlab.CreateIssueNoteOptions{Body: &comment}); err != nil {
+		repo.logger.Printf("WARNING: failed to announce %s in issue #%d: %s", data.Tag, iid, err)
+		return
+	}
+	repo.logger.Printf("announced %s in issue #%d", data.Tag, iid)
+}
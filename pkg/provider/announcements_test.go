@@ -0,0 +1,76 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/go-semantic-release/semantic-release/v2/pkg/provider"
+	"github.com/stretchr/testify/require"
+	"github.com/xanzy/go-gitlab"
+)
+
+func TestGitlabAnnounceRelease(t *testing.T) {
+	var openIssues []*gitlab.Issue
+	var created, commented map[string]string
+	commentedIssue := ""
+	//nolint:errcheck
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, fmt.Sprintf("/api/v4/projects/%d/", GITLAB_PROJECT_ID))
+		switch {
+		case r.Method == "GET" && path == "issues":
+			require.Equal(t, "Release announcements", r.URL.Query().Get("search"))
+			require.Equal(t, "title", r.URL.Query().Get("in"))
+			json.NewEncoder(w).Encode(openIssues)
+		case r.Method == "POST" && path == "issues":
+			json.NewDecoder(r.Body).Decode(&created)
+			fmt.Fprint(w, `{"id":1,"iid":7}`)
+		case r.Method == "POST" && strings.HasPrefix(path, "issues/") && strings.HasSuffix(path, "/notes"):
+			commentedIssue = strings.TrimSuffix(strings.TrimPrefix(path, "issues/"), "/notes")
+			json.NewDecoder(r.Body).Decode(&commented)
+			fmt.Fprint(w, "{}")
+		default:
+			GitlabHandler(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	newRepo := func(issue string) *GitLabRepository {
+		repo := &GitLabRepository{}
+		err := repo.Init(map[string]string{
+			"gitlab_baseurl":            ts.URL,
+			"token":                     "gitlab-examples-ci",
+			"gitlab_projectid":          strconv.Itoa(GITLAB_PROJECT_ID),
+			"gitlab_announcement_issue": issue,
+		})
+		require.NoError(t, err)
+		return repo
+	}
+	changelog := "#### Feature\n\n* **app:** new feature (abcd)\n"
+
+	repo := newRepo("Release announcements")
+	err := repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "2.0.0", SHA: "deadbeef", Changelog: changelog})
+	require.NoError(t, err)
+	require.Equal(t, "Release announcements", created["title"])
+	require.Equal(t, announcementDescription, created["description"])
+	require.Equal(t, "7", commentedIssue)
+	require.Equal(t, "📦 v2.0.0 has been released: Feature (1)", commented["body"])
+
+	created = nil
+	openIssues = []*gitlab.Issue{{IID: 3, Title: "Release announcements (old)"}, {IID: 4, Title: "Release announcements"}}
+	err = repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "2.0.0", SHA: "deadbeef"})
+	require.NoError(t, err)
+	require.Nil(t, created)
+	require.Equal(t, "4", commentedIssue)
+
+	// a number refers to the issue without searching for it
+	openIssues = nil
+	err = newRepo("12").CreateRelease(&provider.CreateReleaseConfig{NewVersion: "2.0.0", SHA: "deadbeef"})
+	require.NoError(t, err)
+	require.Nil(t, created)
+	require.Equal(t, "12", commentedIssue)
+}
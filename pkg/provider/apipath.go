@@ -0,0 +1,80 @@
+package provider
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const defaultAPIPath = "api/v4"
+
+// apiPathTransport sends the requests for the REST API of the instance to a non-standard API path, go-gitlab always
+// appends api/v4 to the base URL
+type apiPathTransport struct {
+	next http.RoundTripper
+	host string
+	from string
+	to   string
+}
+
+// newAPIPathTransport returns nil if the instance serves the API at the standard path below its base URL
+func newAPIPathTransport(next http.RoundTripper, baseURL, apiPath string) (*apiPathTransport, error) {
+	apiPath = strings.Trim(apiPath, "/")
+	if apiPath == "" || apiPath == defaultAPIPath {
+		return nil, nil
+	}
+	if baseURL == "" {
+		baseURL = "https://gitlab.com"
+	}
+	u, err := url.Parse(baseURL)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("failed to set property gitlab_api_path: invalid base URL %q", baseURL)
+	}
+
+	// the base URL may already point at the standard API path like it does for go-gitlab
+	root := strings.TrimSuffix(strings.TrimSuffix(u.Path, "/"), "/"+defaultAPIPath)
+	return &apiPathTransport{
+		next: next,
+		host: u.Host,
+		from: root + "/" + defaultAPIPath + "/",
+		to:   root + "/" + apiPath + "/",
+	}, nil
+}
+
+func (t *apiPathTransport) rewrite(u *url.URL) *url.URL {
+	if u.Host != t.host || !strings.HasPrefix(u.Path, t.from) {
+		return u
+	}
+	rewritten := *u
+	rewritten.Path = t.to + strings.TrimPrefix(u.Path, t.from)
+	if u.RawPath != "" {
+		rewritten.RawPath = t.to + strings.TrimPrefix(u.RawPath, t.from)
+	}
+	return &rewritten
+}
+
+func (t *apiPathTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	u := t.rewrite(req.URL)
+	if u == req.URL {
+		return t.next.RoundTrip(req)
+	}
+	// a round tripper must not modify the request of the caller
+	req = req.Clone(req.Context())
+	req.URL = u
+	return t.next.RoundTrip(req)
+}
+
+// apiURL returns the URL users reach the API path at, e.g. to link packages from a release
+func (repo *GitLabRepository) apiURL(path string) string {
+	// the path is already escaped
+	link := repo.api.BaseURL().String() + path
+	if repo.apiPath == nil {
+		return link
+	}
+	u, err := url.Parse(link)
+	if err != nil {
+		return link
+	}
+	return repo.apiPath.rewrite(u).String()
+}
@@ -0,0 +1,87 @@
+package provider
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/go-semantic-release/semantic-release/v2/pkg/provider"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBaseURLWithPathPrefix(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.Handle("/gitlab/", http.StripPrefix("/gitlab", http.HandlerFunc(GitlabHandler)))
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	repo := &GitLabRepository{}
+	err := repo.Init(map[string]string{
+		"gitlab_baseurl":   ts.URL + "/gitlab/",
+		"token":            "token",
+		"gitlab_projectid": strconv.Itoa(GITLAB_PROJECT_ID),
+	})
+	require.NoError(t, err)
+
+	_, err = repo.GetInfo()
+	require.NoError(t, err)
+	_, err = repo.GetCommits("", "")
+	require.NoError(t, err)
+	_, err = repo.GetReleases("")
+	require.NoError(t, err)
+	err = repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "2.0.0", SHA: "deadbeef"})
+	require.NoError(t, err)
+
+	require.Contains(t, repo.Metrics().Endpoints, "GET projects/:id/repository/tags")
+	require.Equal(t, ts.URL+"/gitlab/api/v4/projects/1/packages", repo.apiURL("projects/1/packages"))
+}
+
+func TestAPIPath(t *testing.T) {
+	var paths []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		if !strings.HasPrefix(r.URL.Path, "/gitlab/rest/") {
+			http.NotFound(w, r)
+			return
+		}
+		r.URL.Path = "/api/v4/" + strings.TrimPrefix(r.URL.Path, "/gitlab/rest/")
+		GitlabHandler(w, r)
+	}))
+	defer ts.Close()
+
+	repo := &GitLabRepository{}
+	err := repo.Init(map[string]string{
+		"gitlab_baseurl":   ts.URL + "/gitlab",
+		"gitlab_api_path":  "/rest/",
+		"token":            "token",
+		"gitlab_projectid": strconv.Itoa(GITLAB_PROJECT_ID),
+	})
+	require.NoError(t, err)
+
+	_, err = repo.GetInfo()
+	require.NoError(t, err)
+	require.Contains(t, paths, "/gitlab/rest/projects/12324322")
+	require.Equal(t, ts.URL+"/gitlab/rest/projects/group%2Fapp/packages", repo.apiURL("projects/group%2Fapp/packages"))
+	require.Contains(t, repo.Metrics().Endpoints, "GET projects/:id")
+}
+
+func TestAPIPathTransportRewrite(t *testing.T) {
+	transport, err := newAPIPathTransport(http.DefaultTransport, "https://example.com/gitlab/api/v4", "proxy/gitlab-api")
+	require.NoError(t, err)
+
+	u, _ := url.Parse("https://example.com/gitlab/api/v4/projects/group%2Fapp")
+	require.Equal(t, "https://example.com/gitlab/proxy/gitlab-api/projects/group%2Fapp", transport.rewrite(u).String())
+
+	// other instances, e.g. mirrors, and the GraphQL API are not rewritten
+	u, _ = url.Parse("https://mirror.example.com/gitlab/api/v4/projects/1")
+	require.Same(t, u, transport.rewrite(u))
+	u, _ = url.Parse("https://example.com/gitlab/api/graphql")
+	require.Same(t, u, transport.rewrite(u))
+
+	transport, err = newAPIPathTransport(http.DefaultTransport, "", "api/v4/")
+	require.NoError(t, err)
+	require.Nil(t, transport)
+}
@@ -0,0 +1,169 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-semantic-release/semantic-release/v2/pkg/provider"
+	"github.com/xanzy/go-gitlab"
+)
+
+const releaseMergeRequestBranchPrefix = "semantic-release/"
+
+// errReleasePendingApproval is wrapped by the error of awaitReleaseMergeRequest if the release merge request was not
+// merged yet, the release is published by a later run once it has been merged
+var errReleasePendingApproval = errors.New("release pending approval")
+
+// awaitReleaseMergeRequest opens a merge request containing the version bump and returns the commit to release
+// once it has been merged, until then it returns an error wrapping errReleasePendingApproval. An open merge request
+// of an older head of the branch is closed and opened again at the current head.
+func (repo *GitLabRepository) awaitReleaseMergeRequest(tag string, release *provider.CreateReleaseConfig) (string, error) {
+	target := repo.branch
+	if target == "" {
+		target = release.Branch
+	}
+	if target == "" {
+		return "", errors.New("a branch is required to open a release merge request")
+	}
+	source := releaseMergeRequestBranchPrefix + tag
+	description := releaseMergeRequestDescription(tag, release.Changelog, repo.changelogMode)
+
+	mergeRequests, _, err := repo.api.ListProjectMergeRequests(repo.projectID, &gitlab.ListProjectMergeRequestsOptions{
+		SourceBranch: &source,
+		TargetBranch: &target,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to list release merge requests: %w", err)
+	}
+
+	for _, mr := range mergeRequests {
+		switch mr.State {
+		case "merged":
+			repo.logger.Printf("release merge request !%d was merged", mr.IID)
+			return mergedSHA(mr), nil
+		case "opened":
+			current, err := repo.isCurrentReleaseMergeRequest(mr, release.SHA)
+			if err != nil {
+				return "", err
+			}
+			if !current {
+				if err := repo.closeReleaseMergeRequest(mr); err != nil {
+					return "", err
+				}
+				continue
+			}
+			// keep the changelog up to date
+			_, _, err = repo.api.UpdateMergeRequest(repo.projectID, mr.IID, &gitlab.UpdateMergeRequestOptions{
+				Description: &description,
+			})
+			if err != nil {
+				return "", fmt.Errorf("failed to update release merge request !%d: %w", mr.IID, err)
+			}
+			repo.pendingMergeRequest = mr.WebURL
+			return "", fmt.Errorf("%w: waiting for release merge request %s to be merged", errReleasePendingApproval, mr.WebURL)
+		}
+	}
+
+	if err := repo.prepareReleaseMergeRequestBranch(source, tag, release); err != nil {
+		return "", err
+	}
+
+	mr, _, err := repo.api.CreateMergeRequest(repo.projectID, &gitlab.CreateMergeRequestOptions{
+		Title:              gitlab.String("Release " + tag),
+		Description:        &description,
+		SourceBranch:       &source,
+		TargetBranch:       &target,
+		RemoveSourceBranch: gitlab.Bool(true),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to open release merge request: %w", err)
+	}
+	repo.pendingMergeRequest = mr.WebURL
+	return "", fmt.Errorf("%w: opened release merge request %s, the release is published once it is merged", errReleasePendingApproval, mr.WebURL)
+}
+
+// isCurrentReleaseMergeRequest reports whether the source branch of the merge request starts at the released commit,
+// either directly or with the commit of the version files on top
+func (repo *GitLabRepository) isCurrentReleaseMergeRequest(mr *gitlab.MergeRequest, sha string) (bool, error) {
+	if mr.SHA == sha {
+		return true, nil
+	}
+	if len(repo.versionFiles) == 0 || mr.SHA == "" {
+		return false, nil
+	}
+	commit, _, err := repo.api.GetCommit(repo.projectID, mr.SHA)
+	if err != nil {
+		return false, fmt.Errorf("failed to get the head of release merge request !%d: %w", mr.IID, err)
+	}
+	return len(commit.ParentIDs) == 1 && commit.ParentIDs[0] == sha, nil
+}
+
+// closeReleaseMergeRequest closes a release merge request of an older head of the branch, its source branch is
+// recreated for the new one
+func (repo *GitLabRepository) closeReleaseMergeRequest(mr *gitlab.MergeRequest) error {
+	_, _, err := repo.api.UpdateMergeRequest(repo.projectID, mr.IID, &gitlab.UpdateMergeRequestOptions{
+		StateEvent: gitlab.String("close"),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to close outdated release merge request !%d: %w", mr.IID, err)
+	}
+	repo.logger.Printf("closed release merge request !%d, the branch moved on from %s", mr.IID, mr.SHA)
+	return nil
+}
+
+// PendingReleaseMergeRequest returns the web URL of the release merge request the last CreateRelease call waits for,
+// or an empty string if the release was published
+func (repo *GitLabRepository) PendingReleaseMergeRequest() string {
+	return repo.pendingMergeRequest
+}
+
+// prepareReleaseMergeRequestBranch (re)creates the source branch at the released commit and commits the version files
+func (repo *GitLabRepository) prepareReleaseMergeRequestBranch(branch, tag string, release *provider.CreateReleaseConfig) error {
+	// the branch of a closed merge request may still exist and point at an outdated commit
+	resp, err := repo.api.DeleteBranch(repo.projectID, branch)
+	if err != nil && (resp == nil || resp.StatusCode != http.StatusNotFound) {
+		return fmt.Errorf("failed to delete outdated branch %s: %w", branch, err)
+	}
+
+	_, _, err = repo.api.CreateBranch(repo.projectID, &gitlab.CreateBranchOptions{
+		Branch: &branch,
+		Ref:    &release.SHA,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create branch %s: %w", branch, err)
+	}
+
+	if len(repo.versionFiles) == 0 {
+		return nil
+	}
+	actions, err := repo.versionFileActions(release)
+	if err != nil || len(actions) == 0 {
+		return err
+	}
+	message, err := renderTemplate(repo.versionFilesMessage, newTemplateData(tag, release))
+	if err != nil {
+		return err
+	}
+	if _, err := repo.commitFiles(branch, repo.commitMessage(commitKindVersionFiles, message), actions); err != nil {
+		return fmt.Errorf("failed to update version files: %w", err)
+	}
+	return nil
+}
+
+func releaseMergeRequestDescription(tag, changelog, changelogMode string) string {
+	return fmt.Sprintf("Merging this merge request publishes the release %s.\n\n%s", tag, formatChangelog(changelog, changelogMode))
+}
+
+// mergedSHA returns the commit which ended up on the target branch depending on the merge method of the project
+func mergedSHA(mr *gitlab.MergeRequest) string {
+	if mr.MergeCommitSHA != "" {
+		return mr.MergeCommitSHA
+	}
+	// squash merges without a merge commit
+	if mr.SquashCommitSHA != "" {
+		return mr.SquashCommitSHA
+	}
+	// fast-forward merges put the head of the source branch on the target branch
+	return mr.SHA
+}
@@ -0,0 +1,165 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/go-semantic-release/semantic-release/v2/pkg/provider"
+	"github.com/stretchr/testify/require"
+	"github.com/xanzy/go-gitlab"
+)
+
+func TestGitlabReleaseMergeRequest(t *testing.T) {
+	var mergeRequests []*gitlab.MergeRequest
+	var branch *gitlab.CreateBranchOptions
+	var commit map[string]interface{}
+	var updated, releaseRef, branchRef, commitID string
+	var closed []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mergeRequestsPath := fmt.Sprintf("/api/v4/projects/%d/merge_requests", GITLAB_PROJECT_ID)
+		switch {
+		case r.Method == "GET" && r.URL.Path == mergeRequestsPath:
+			require.Equal(t, "semantic-release/v2.0.0", r.URL.Query().Get("source_branch"))
+			require.Equal(t, "main", r.URL.Query().Get("target_branch"))
+			json.NewEncoder(w).Encode(mergeRequests) //nolint:errcheck
+		case r.Method == "POST" && r.URL.Path == mergeRequestsPath:
+			var opts gitlab.CreateMergeRequestOptions
+			json.NewDecoder(r.Body).Decode(&opts) //nolint:errcheck
+			iid := len(mergeRequests) + 7
+			mergeRequests = append(mergeRequests, &gitlab.MergeRequest{IID: iid, State: "opened", SHA: commitID, WebURL: fmt.Sprintf("https://gitlab.example.com/group/project/-/merge_requests/%d", iid), Title: *opts.Title, Description: *opts.Description})
+			json.NewEncoder(w).Encode(mergeRequests[len(mergeRequests)-1]) //nolint:errcheck
+		case r.Method == "PUT" && strings.HasPrefix(r.URL.Path, mergeRequestsPath+"/"):
+			var opts gitlab.UpdateMergeRequestOptions
+			json.NewDecoder(r.Body).Decode(&opts) //nolint:errcheck
+			if opts.StateEvent != nil {
+				closed = append(closed, strings.TrimPrefix(r.URL.Path, mergeRequestsPath+"/"))
+				mergeRequests[0].State = "closed"
+			} else {
+				updated = *opts.Description
+			}
+			fmt.Fprint(w, "{}")
+		case r.Method == "GET" && strings.HasPrefix(r.URL.Path, fmt.Sprintf("/api/v4/projects/%d/repository/commits/", GITLAB_PROJECT_ID)):
+			// the version files commit on top of the released commit
+			json.NewEncoder(w).Encode(&gitlab.Commit{ID: commitID, ParentIDs: []string{branchRef}}) //nolint:errcheck
+		case r.Method == "DELETE" && r.URL.Path == fmt.Sprintf("/api/v4/projects/%d/repository/branches/semantic-release/v2.0.0", GITLAB_PROJECT_ID):
+			http.Error(w, `{"message":"404 Branch Not Found"}`, http.StatusNotFound)
+		case r.Method == "POST" && r.URL.Path == fmt.Sprintf("/api/v4/projects/%d/repository/branches", GITLAB_PROJECT_ID):
+			json.NewDecoder(r.Body).Decode(&branch) //nolint:errcheck
+			branchRef = *branch.Ref
+			fmt.Fprint(w, "{}")
+		case r.Method == "GET" && r.URL.Path == fmt.Sprintf("/api/v4/projects/%d/repository/files/VERSION/raw", GITLAB_PROJECT_ID):
+			fmt.Fprint(w, "1.0.0\n")
+		case r.Method == "POST" && r.URL.Path == fmt.Sprintf("/api/v4/projects/%d/repository/commits", GITLAB_PROJECT_ID):
+			json.NewDecoder(r.Body).Decode(&commit) //nolint:errcheck
+			commitID = "abcd" + branchRef
+			fmt.Fprintf(w, `{"id": %q}`, commitID)
+		case r.Method == "POST" && r.URL.Path == fmt.Sprintf("/api/v4/projects/%d/releases", GITLAB_PROJECT_ID):
+			var opts gitlab.CreateReleaseOptions
+			json.NewDecoder(r.Body).Decode(&opts) //nolint:errcheck
+			releaseRef = *opts.Ref
+			fmt.Fprint(w, "{}")
+		default:
+			GitlabHandler(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	repo := &GitLabRepository{}
+	err := repo.Init(map[string]string{
+		"gitlab_baseurl":               ts.URL,
+		"token":                        "gitlab-examples-ci",
+		"gitlab_branch":                "main",
+		"gitlab_projectid":             strconv.Itoa(GITLAB_PROJECT_ID),
+		"gitlab_release_merge_request": "true",
+		"gitlab_version_files":         "VERSION",
+	})
+	require.NoError(t, err)
+
+	// the first run opens the merge request, waiting for it does not fail the run
+	err = repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "2.0.0", SHA: "deadbeef", Changelog: "* feat"})
+	require.NoError(t, err)
+	require.Equal(t, "https://gitlab.example.com/group/project/-/merge_requests/7", repo.PendingReleaseMergeRequest())
+	require.Len(t, mergeRequests, 1)
+	require.Equal(t, "Release v2.0.0", mergeRequests[0].Title)
+	require.Equal(t, "Merging this merge request publishes the release v2.0.0.\n\n* feat", mergeRequests[0].Description)
+	require.Equal(t, "semantic-release/v2.0.0", *branch.Branch)
+	require.Equal(t, "deadbeef", *branch.Ref)
+	require.Equal(t, "semantic-release/v2.0.0", commit["branch"])
+	require.Empty(t, releaseRef)
+
+	// the changelog of the open merge request is updated
+	err = repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "2.0.0", SHA: "deadbeef", Changelog: "* feat\n* fix"})
+	require.NoError(t, err)
+	require.Equal(t, "https://gitlab.example.com/group/project/-/merge_requests/7", repo.PendingReleaseMergeRequest())
+	require.Equal(t, "Merging this merge request publishes the release v2.0.0.\n\n* feat\n* fix", updated)
+	require.Empty(t, closed)
+	require.Empty(t, releaseRef)
+
+	// the merge request of an older head is replaced by one at the new head
+	err = repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "2.0.0", SHA: "f00dcafe", Changelog: "* feat\n* fix"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"7"}, closed)
+	require.Equal(t, "f00dcafe", *branch.Ref)
+	require.Len(t, mergeRequests, 2)
+	require.Equal(t, "https://gitlab.example.com/group/project/-/merge_requests/8", repo.PendingReleaseMergeRequest())
+
+	// the release is created at the merge commit
+	mergeRequests = mergeRequests[1:]
+	mergeRequests[0].State = "merged"
+	mergeRequests[0].MergeCommitSHA = "cafebabe"
+	err = repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "2.0.0", SHA: "f00dcafe"})
+	require.NoError(t, err)
+	require.Empty(t, repo.PendingReleaseMergeRequest())
+	require.Equal(t, "cafebabe", releaseRef)
+}
+
+func TestMergedSHA(t *testing.T) {
+	require.Equal(t, "merge", mergedSHA(&gitlab.MergeRequest{SHA: "head", SquashCommitSHA: "squash", MergeCommitSHA: "merge"}))
+	require.Equal(t, "squash", mergedSHA(&gitlab.MergeRequest{SHA: "head", SquashCommitSHA: "squash"}))
+	require.Equal(t, "head", mergedSHA(&gitlab.MergeRequest{SHA: "head"}))
+}
+
+func TestGitlabReleaseMergeRequestPendingIsNoFailure(t *testing.T) {
+	var deleted []string
+	var issuesRequested bool
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		projectPath := fmt.Sprintf("/api/v4/projects/%d", GITLAB_PROJECT_ID)
+		switch {
+		case r.Method == "DELETE":
+			deleted = append(deleted, r.URL.Path)
+			fmt.Fprint(w, "{}")
+		case r.Method == "GET" && r.URL.Path == projectPath+"/merge_requests":
+			fmt.Fprint(w, `[{"iid": 7, "state": "opened", "sha": "deadbeef", "web_url": "https://gitlab.example.com/group/project/-/merge_requests/7"}]`)
+		case r.Method == "PUT" && r.URL.Path == projectPath+"/merge_requests/7":
+			fmt.Fprint(w, "{}")
+		case strings.HasPrefix(r.URL.Path, projectPath+"/issues"):
+			issuesRequested = true
+			fmt.Fprint(w, "[]")
+		default:
+			GitlabHandler(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	repo := &GitLabRepository{}
+	err := repo.Init(map[string]string{
+		"gitlab_baseurl":               ts.URL,
+		"token":                        "gitlab-examples-ci",
+		"gitlab_branch":                "main",
+		"gitlab_projectid":             strconv.Itoa(GITLAB_PROJECT_ID),
+		"gitlab_release_merge_request": "true",
+		"gitlab_rollback_on_failure":   "true",
+		"gitlab_failure_issue":         "true",
+	})
+	require.NoError(t, err)
+
+	err = repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "2.0.0", SHA: "deadbeef", Changelog: "* feat"})
+	require.NoError(t, err)
+	require.Empty(t, deleted)
+	require.False(t, issuesRequested)
+}
@@ -0,0 +1,60 @@
+package provider
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/go-semantic-release/semantic-release/v2/pkg/provider"
+	"github.com/xanzy/go-gitlab"
+)
+
+// assetLink is a release link configured by gitlab_asset_links, the name and the URL are templates
+type assetLink struct {
+	name *template.Template
+	url  *template.Template
+}
+
+// parseAssetLinksConfig parses a comma separated list of name=url pairs, e.g.
+// Binary={{.ProjectURL}}/-/packages/generic/app/{{.Version}}/app. Commas within a URL are written as \, or the
+// links are given as a YAML list or map in the config file.
+func parseAssetLinksConfig(config map[string]string, key string) ([]*assetLink, error) {
+	links := make([]*assetLink, 0)
+	for _, pair := range parseListConfig(config, key) {
+		name, rawURL, found := strings.Cut(pair, "=")
+		name, rawURL = strings.TrimSpace(name), strings.TrimSpace(rawURL)
+		if !found || name == "" || rawURL == "" {
+			return nil, fmt.Errorf("failed to set property %s: invalid link %q, expected name=url", key, pair)
+		}
+		nameTmpl, err := template.New(key).Funcs(templateFuncs).Parse(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to set property %s: %w", key, err)
+		}
+		urlTmpl, err := template.New(key).Funcs(templateFuncs).Parse(rawURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to set property %s: %w", key, err)
+		}
+		links = append(links, &assetLink{name: nameTmpl, url: urlTmpl})
+	}
+	return links, nil
+}
+
+// addAssetLinks renders the links of gitlab_asset_links and attaches them to the release
+func (repo *GitLabRepository) addAssetLinks(tag string, release *provider.CreateReleaseConfig) error {
+	data := repo.releaseTemplateData(tag, release)
+	for _, link := range repo.assetLinks {
+		name, err := renderTemplate(link.name, data)
+		if err != nil {
+			return err
+		}
+		linkURL, err := renderTemplate(link.url, data)
+		if err != nil {
+			return err
+		}
+		repo.releaseLinks = append(repo.releaseLinks, &gitlab.ReleaseAssetLinkOptions{
+			Name: gitlab.String(name),
+			URL:  gitlab.String(linkURL),
+		})
+	}
+	return nil
+}
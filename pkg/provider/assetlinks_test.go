@@ -0,0 +1,98 @@
+package provider
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/go-semantic-release/semantic-release/v2/pkg/provider"
+	"github.com/stretchr/testify/require"
+	"github.com/xanzy/go-gitlab"
+)
+
+func TestParseAssetLinksConfig(t *testing.T) {
+	links, err := parseAssetLinksConfig(map[string]string{"gitlab_asset_links": "Docs=https://docs.example.com/{{.Version}}, App {{.Tag}}={{.ProjectURL}}/app"}, "gitlab_asset_links")
+	require.NoError(t, err)
+	require.Len(t, links, 2)
+
+	// escaped commas stay in the URL
+	links, err = parseAssetLinksConfig(map[string]string{"gitlab_asset_links": `Map=https://maps.example.com/?ll=52.5\,13.4, Docs=https://docs.example.com`}, "gitlab_asset_links")
+	require.NoError(t, err)
+	require.Len(t, links, 2)
+	require.Equal(t, "https://maps.example.com/?ll=52.5,13.4", links[0].url.Root.String())
+
+	_, err = parseAssetLinksConfig(map[string]string{"gitlab_asset_links": "https://docs.example.com"}, "gitlab_asset_links")
+	require.EqualError(t, err, `failed to set property gitlab_asset_links: invalid link "https://docs.example.com", expected name=url`)
+
+	_, err = parseAssetLinksConfig(map[string]string{"gitlab_asset_links": "Docs={{.Version"}, "gitlab_asset_links")
+	require.ErrorContains(t, err, "failed to set property gitlab_asset_links: template: gitlab_asset_links:1: unclosed action")
+}
+
+func TestGitlabAssetLinks(t *testing.T) {
+	t.Setenv("CI_JOB_ID", "42")
+
+	var links []*gitlab.ReleaseAssetLinkOptions
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		//nolint:errcheck
+		switch {
+		case r.Method == "GET" && r.URL.Path == fmt.Sprintf("/api/v4/projects/%d", GITLAB_PROJECT_ID):
+			project := GITLAB_PROJECT
+			project.PathWithNamespace, project.WebURL = "group/app", "https://gitlab.example.com/group/app"
+			json.NewEncoder(w).Encode(project)
+		case r.Method == "POST" && r.URL.Path == fmt.Sprintf("/api/v4/projects/%d/releases", GITLAB_PROJECT_ID):
+			var opts gitlab.CreateReleaseOptions
+			json.NewDecoder(r.Body).Decode(&opts)
+			links = opts.Assets.Links
+			fmt.Fprint(w, "{}")
+		default:
+			GitlabHandler(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	var logs bytes.Buffer
+	repo := &GitLabRepository{logger: log.New(&logs, "", 0)}
+	err := repo.Init(map[string]string{
+		"gitlab_baseurl":     ts.URL,
+		"token":              "token",
+		"gitlab_projectid":   strconv.Itoa(GITLAB_PROJECT_ID),
+		"gitlab_asset_links": `Package {{.Tag}}={{.ProjectURL}}/-/packages/generic/app/{{.Version}}, Build log=https://ci.example.com/{{.ProjectPath}}/jobs/{{env "CI_JOB_ID"}}`,
+	})
+	require.NoError(t, err)
+
+	err = repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "2.0.0", SHA: "deadbeef"})
+	require.NoError(t, err)
+	require.Equal(t, []*gitlab.ReleaseAssetLinkOptions{
+		{Name: gitlab.String("Package v2.0.0"), URL: gitlab.String("https://gitlab.example.com/group/app/-/packages/generic/app/2.0.0")},
+		{Name: gitlab.String("Build log"), URL: gitlab.String("https://ci.example.com/group/app/jobs/42")},
+	}, links)
+
+	repo.dryRun = true
+	err = repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "2.0.0", SHA: "deadbeef"})
+	require.NoError(t, err)
+	require.Contains(t, logs.String(), "dry run: would link Build log to https://ci.example.com/group/app/jobs/42")
+}
+
+func TestTemplateEnv(t *testing.T) {
+	t.Setenv("CI_JOB_ID", "42")
+	t.Setenv("CI_JOB_TOKEN", "job-token")
+	t.Setenv("GITLAB_TOKEN", "token")
+
+	value, err := templateEnv("CI_JOB_ID")
+	require.NoError(t, err)
+	require.Equal(t, "42", value)
+	for _, name := range []string{"CI_JOB_TOKEN", "CI_REGISTRY_PASSWORD", "CI_JOB_JWT_V2", "CI_REPOSITORY_URL", "GITLAB_TOKEN", "HOME"} {
+		_, err := templateEnv(name)
+		require.EqualError(t, err, fmt.Sprintf("variable %s is not available in templates, only CI_ variables without credentials are", name))
+	}
+
+	links, err := parseAssetLinksConfig(map[string]string{"gitlab_asset_links": `Token={{env "GITLAB_TOKEN"}}`}, "gitlab_asset_links")
+	require.NoError(t, err)
+	_, err = renderTemplate(links[0].url, &templateData{})
+	require.ErrorContains(t, err, "variable GITLAB_TOKEN is not available in templates")
+}
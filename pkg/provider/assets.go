@@ -0,0 +1,338 @@
+package provider
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+const (
+	defaultAssetsPackage   = "release"
+	defaultUploadRetryWait = 5 * time.Second
+	assetUploadAttempts    = 3
+	// parts smaller than this are uploaded without progress lines
+	assetProgressMinSize = 1 << 20
+)
+
+var sizeUnits = []struct {
+	suffix string
+	bytes  int64
+}{
+	{"GiB", 1 << 30}, {"MiB", 1 << 20}, {"KiB", 1 << 10},
+	{"GB", 1e9}, {"MB", 1e6}, {"KB", 1e3},
+	{"B", 1},
+}
+
+// parseSizeConfig parses a size in bytes with an optional unit, e.g. 512MiB or 2GB
+func parseSizeConfig(config map[string]string, key string) (int64, error) {
+	value := strings.TrimSpace(config[key])
+	if value == "" {
+		return 0, nil
+	}
+	unit := int64(1)
+	for _, u := range sizeUnits {
+		if strings.HasSuffix(value, u.suffix) {
+			value, unit = strings.TrimSpace(strings.TrimSuffix(value, u.suffix)), u.bytes
+			break
+		}
+	}
+	size, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || size < 0 {
+		return 0, fmt.Errorf("failed to set property %s: invalid size %q", key, config[key])
+	}
+	return size * unit, nil
+}
+
+// assetPart is a file, or a chunk of a file larger than gitlab_asset_chunk_size, uploaded to the generic package
+type assetPart struct {
+	path   string
+	name   string
+	label  string
+	offset int64
+	size   int64
+}
+
+// assetPartReader streams a part from disk, every upload attempt opens the file again instead of buffering the part
+type assetPartReader struct {
+	*io.SectionReader
+	file     *os.File
+	progress func(read int64)
+	read     int64
+}
+
+func (r *assetPartReader) Read(p []byte) (int, error) {
+	n, err := r.SectionReader.Read(p)
+	r.read += int64(n)
+	if r.progress != nil && n > 0 {
+		r.progress(r.read)
+	}
+	return n, err
+}
+
+func (r *assetPartReader) Len() int {
+	return int(r.Size())
+}
+
+func (r *assetPartReader) Close() error {
+	return r.file.Close()
+}
+
+func (p assetPart) open() (*assetPartReader, error) {
+	f, err := os.Open(p.path)
+	if err != nil {
+		return nil, err
+	}
+	return &assetPartReader{SectionReader: io.NewSectionReader(f, p.offset, p.size), file: f}, nil
+}
+
+func (p assetPart) sha256() (string, error) {
+	r, err := p.open()
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// assetParts expands the patterns of gitlab_assets, files larger than the chunk size are split into numbered parts
+// which can be joined again with cat
+func (repo *GitLabRepository) assetParts() ([]assetPart, error) {
+	parts := make([]assetPart, 0)
+	for _, pattern := range repo.assets {
+		paths, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid asset pattern %q: %w", pattern, err)
+		}
+		if len(paths) == 0 {
+			return nil, fmt.Errorf("no asset matches %q", pattern)
+		}
+		for _, path := range paths {
+			info, err := os.Stat(path)
+			if err != nil {
+				return nil, err
+			}
+			if info.IsDir() {
+				continue
+			}
+
+			name := filepath.Base(path)
+			size := info.Size()
+			if repo.assetChunkSize == 0 || size <= repo.assetChunkSize {
+				parts = append(parts, assetPart{path: path, name: name, label: name, size: size})
+				continue
+			}
+			count := (size + repo.assetChunkSize - 1) / repo.assetChunkSize
+			for i := int64(0); i < count; i++ {
+				offset := i * repo.assetChunkSize
+				partSize := repo.assetChunkSize
+				if offset+partSize > size {
+					partSize = size - offset
+				}
+				parts = append(parts, assetPart{
+					path:   path,
+					name:   fmt.Sprintf("%s.part%03d", name, i+1),
+					label:  fmt.Sprintf("%s (part %d of %d)", name, i+1, count),
+					offset: offset,
+					size:   partSize,
+				})
+			}
+		}
+	}
+	return parts, nil
+}
+
+// uploadedAssets returns the SHA-256 checksums of the files already uploaded to the package version, e.g. by a
+// previous attempt of the release which failed during the upload
+func (repo *GitLabRepository) uploadedAssets(version string) (map[string]string, error) {
+	uploaded := make(map[string]string)
+	opts := &gitlab.ListProjectPackagesOptions{
+		ListOptions: repo.listOptions(),
+		PackageType: gitlab.String("generic"),
+		PackageName: gitlab.String(repo.assetsPackage),
+	}
+	for {
+		packages, resp, err := repo.api.ListProjectPackages(repo.projectID, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, pkg := range packages {
+			if pkg.Name != repo.assetsPackage || pkg.Version != version {
+				continue
+			}
+			if err := repo.listPackageFiles(pkg.ID, uploaded); err != nil {
+				return nil, err
+			}
+		}
+		if resp.NextPage == 0 {
+			return uploaded, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// listPackageFiles adds the SHA-256 checksums of the files of the package by name, large assets have many parts
+func (repo *GitLabRepository) listPackageFiles(packageID int, uploaded map[string]string) error {
+	// go-gitlab does not decode the SHA-256 checksum of package files
+	path := fmt.Sprintf("projects/%s/packages/%d/package_files", gitlab.PathEscape(repo.projectID), packageID)
+	opts := repo.listOptions()
+	for {
+		req, err := repo.api.NewRequest(http.MethodGet, path, &opts, nil)
+		if err != nil {
+			return err
+		}
+		var files []struct {
+			FileName   string `json:"file_name"`
+			FileSHA256 string `json:"file_sha256"`
+		}
+		resp, err := repo.api.Do(req, &files)
+		if err != nil {
+			return err
+		}
+		for _, file := range files {
+			uploaded[file.FileName] = file.FileSHA256
+		}
+		if resp.NextPage == 0 {
+			return nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// publishAssets uploads the assets to the generic package registry and links them from the release. Parts are
+// streamed from disk and retried on their own, parts which were already uploaded with the same checksum are skipped.
+// Up to gitlab_concurrency parts are uploaded at the same time.
+func (repo *GitLabRepository) publishAssets(version string) error {
+	parts, err := repo.assetParts()
+	if err != nil {
+		return err
+	}
+
+	uploaded, err := repo.uploadedAssets(version)
+	if err != nil {
+		repo.logger.Printf("WARNING: failed to list the uploaded assets, uploading all of them: %s", err)
+		uploaded = map[string]string{}
+	}
+
+	start := time.Now()
+	paths := make([]string, len(parts))
+	var mu sync.Mutex
+	var sent int64
+	skipped := 0
+	err = repo.forEach(len(parts), func(i int) error {
+		part := parts[i]
+		paths[i] = fmt.Sprintf(
+			"projects/%s/packages/generic/%s/%s/%s",
+			url.PathEscape(repo.projectID),
+			url.PathEscape(repo.assetsPackage),
+			url.PathEscape(version),
+			url.PathEscape(part.name),
+		)
+		skip, err := repo.uploadAssetPart(paths[i], part, uploaded[part.name])
+		if err != nil {
+			return fmt.Errorf("failed to upload asset %s: %w", part.name, err)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if skip {
+			skipped++
+		} else {
+			sent += part.size
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	repo.logger.Printf(
+		"uploaded %d assets (%d bytes) in %s, %d were already uploaded",
+		len(parts)-skipped, sent, time.Since(start).Round(time.Millisecond), skipped,
+	)
+
+	for i, part := range parts {
+		repo.releaseLinks = append(repo.releaseLinks, &gitlab.ReleaseAssetLinkOptions{
+			Name:     gitlab.String(part.label),
+			URL:      gitlab.String(repo.apiURL(paths[i])),
+			LinkType: repo.packageLinkType(),
+		})
+	}
+	return nil
+}
+
+// uploadAssetPart uploads the part unless it was already uploaded, which is reported by skipped
+func (repo *GitLabRepository) uploadAssetPart(path string, part assetPart, uploadedSHA256 string) (skipped bool, err error) {
+	if uploadedSHA256 != "" {
+		checksum, err := part.sha256()
+		if err != nil {
+			return false, err
+		}
+		if checksum == uploadedSHA256 {
+			repo.logger.Printf("asset %s was already uploaded, skipping it", part.name)
+			return true, nil
+		}
+	}
+
+	for attempt := 1; attempt <= assetUploadAttempts; attempt++ {
+		start := time.Now()
+		if err = repo.putAssetPart(path, part); err == nil {
+			repo.logger.Printf("uploaded asset %s (%d bytes) in %s", part.name, part.size, time.Since(start).Round(time.Millisecond))
+			return false, nil
+		}
+		if attempt < assetUploadAttempts {
+			repo.logger.Printf("WARNING: failed to upload asset %s, retrying: %s", part.name, err)
+			time.Sleep(repo.uploadRetryWait)
+		}
+	}
+	return false, err
+}
+
+// assetProgress returns a callback which logs the upload progress of the part in steps of 25 percent
+func (repo *GitLabRepository) assetProgress(part assetPart) func(read int64) {
+	if part.size < assetProgressMinSize {
+		return nil
+	}
+	logged := int64(0)
+	return func(read int64) {
+		percent := read * 100 / part.size
+		if percent >= 100 || percent < logged+25 {
+			return
+		}
+		logged = percent - percent%25
+		repo.logger.Printf("uploading asset %s: %d%% (%d of %d bytes)", part.name, logged, read, part.size)
+	}
+}
+
+func (repo *GitLabRepository) putAssetPart(path string, part assetPart) error {
+	req, err := repo.api.NewRequest(http.MethodPut, path, nil, nil)
+	if err != nil {
+		return err
+	}
+	err = req.SetBody(func() (io.Reader, error) {
+		r, err := part.open()
+		if err != nil {
+			return nil, err
+		}
+		r.progress = repo.assetProgress(part)
+		return r, nil
+	})
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	_, err = repo.api.Do(req, nil)
+	return err
+}
@@ -0,0 +1,140 @@
+package provider
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-semantic-release/semantic-release/v2/pkg/provider"
+	"github.com/stretchr/testify/require"
+	"github.com/xanzy/go-gitlab"
+)
+
+func TestParseSizeConfig(t *testing.T) {
+	for value, expected := range map[string]int64{"": 0, "1024": 1024, "512MiB": 512 << 20, "2GB": 2e9, "1 KiB": 1024} {
+		size, err := parseSizeConfig(map[string]string{"size": value}, "size")
+		require.NoError(t, err)
+		require.Equal(t, expected, size, value)
+	}
+
+	_, err := parseSizeConfig(map[string]string{"size": "1TB"}, "size")
+	require.EqualError(t, err, `failed to set property size: invalid size "1TB"`)
+}
+
+func TestGitlabChunkedAssets(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "app.bin"), []byte("0123456789"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "checksums.txt"), []byte("abc"), 0o644))
+
+	checksum := func(content string) string {
+		sum := sha256.Sum256([]byte(content))
+		return hex.EncodeToString(sum[:])
+	}
+
+	uploads := make(map[string]string)
+	attempts := make(map[string]int)
+	var mu sync.Mutex
+	var releaseLinks []*gitlab.ReleaseAssetLinkOptions
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		prefix := fmt.Sprintf("/api/v4/projects/%d/", GITLAB_PROJECT_ID)
+		path := strings.TrimPrefix(r.URL.Path, prefix)
+		switch {
+		case r.Method == "GET" && path == "packages":
+			require.Equal(t, "generic", r.URL.Query().Get("package_type"))
+			fmt.Fprint(w, `[{"id": 5, "name": "dist", "version": "2.0.0"}, {"id": 6, "name": "dist", "version": "1.0.0"}]`)
+		case r.Method == "GET" && path == "packages/5/package_files":
+			// a previous attempt uploaded the first part and a corrupt second part, listed on two pages
+			if r.URL.Query().Get("page") != "2" {
+				w.Header().Set("X-Next-Page", "2")
+				fmt.Fprintf(w, `[{"file_name": "app.bin.part001", "file_sha256": %q}]`, checksum("0123"))
+				return
+			}
+			fmt.Fprint(w, `[{"file_name": "app.bin.part002", "file_sha256": "corrupt"}]`)
+		case r.Method == "PUT" && strings.HasPrefix(path, "packages/generic/dist/2.0.0/"):
+			name := strings.TrimPrefix(path, "packages/generic/dist/2.0.0/")
+			mu.Lock()
+			defer mu.Unlock()
+			attempts[name]++
+			if name == "app.bin.part003" && attempts[name] == 1 {
+				// the connection drops in the middle of the upload
+				conn, _, _ := w.(http.Hijacker).Hijack()
+				conn.Close()
+				return
+			}
+			require.Equal(t, "application/octet-stream", r.Header.Get("Content-Type"))
+			body, _ := io.ReadAll(r.Body)
+			require.Equal(t, strconv.Itoa(len(body)), r.Header.Get("Content-Length"))
+			uploads[name] = string(body)
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{}`)
+		case r.Method == "POST" && path == "releases":
+			var opts gitlab.CreateReleaseOptions
+			json.NewDecoder(r.Body).Decode(&opts) //nolint:errcheck
+			releaseLinks = opts.Assets.Links
+			fmt.Fprint(w, `{}`)
+		default:
+			GitlabHandler(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	repo := &GitLabRepository{uploadRetryWait: time.Millisecond}
+	err := repo.Init(map[string]string{
+		"gitlab_baseurl":          ts.URL,
+		"token":                   "token",
+		"gitlab_projectid":        strconv.Itoa(GITLAB_PROJECT_ID),
+		"gitlab_assets":           filepath.Join(dir, "*.bin") + "," + filepath.Join(dir, "checksums.txt"),
+		"gitlab_assets_package":   "dist",
+		"gitlab_asset_chunk_size": "4",
+	})
+	require.NoError(t, err)
+
+	err = repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "2.0.0", SHA: "deadbeef"})
+	require.NoError(t, err)
+
+	require.Equal(t, map[string]string{"app.bin.part002": "4567", "app.bin.part003": "89", "checksums.txt": "abc"}, uploads)
+	require.Equal(t, 2, attempts["app.bin.part003"])
+
+	names := make([]string, 0, len(releaseLinks))
+	for _, link := range releaseLinks {
+		names = append(names, *link.Name)
+	}
+	require.Equal(t, []string{"app.bin (part 1 of 3)", "app.bin (part 2 of 3)", "app.bin (part 3 of 3)", "checksums.txt"}, names)
+	require.Equal(t, fmt.Sprintf("%s/api/v4/projects/%d/packages/generic/dist/2.0.0/app.bin.part001", ts.URL, GITLAB_PROJECT_ID), *releaseLinks[0].URL)
+}
+
+func TestGitlabAssetsMissing(t *testing.T) {
+	repo, ts := getNewGitlabTestRepo(t)
+	defer ts.Close()
+	repo.assets = []string{filepath.Join(t.TempDir(), "*.zip")}
+
+	err := repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "2.0.0", SHA: "deadbeef"})
+	require.ErrorContains(t, err, "no asset matches")
+}
+
+func TestGitlabAssetProgress(t *testing.T) {
+	var logs bytes.Buffer
+	repo := &GitLabRepository{logger: log.New(&logs, "", 0)}
+	require.Nil(t, repo.assetProgress(assetPart{name: "small.txt", size: 100}))
+
+	progress := repo.assetProgress(assetPart{name: "big.bin", size: 4 << 20})
+	for read := int64(0); read <= 4<<20; read += 512 << 10 {
+		progress(read)
+	}
+	require.Equal(t, "uploading asset big.bin: 25% (1048576 of 4194304 bytes)\n"+
+		"uploading asset big.bin: 50% (2097152 of 4194304 bytes)\n"+
+		"uploading asset big.bin: 75% (3145728 of 4194304 bytes)\n", logs.String())
+}
@@ -0,0 +1,128 @@
+package provider
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	auditEventType       = "semantic_release_published"
+	auditStreamingHeader = "X-Gitlab-Event-Streaming-Token"
+)
+
+// auditEvent follows the payload of GitLab's streaming audit events, so audit pipelines consuming a streaming
+// destination can process releases with their existing rules
+type auditEvent struct {
+	ID            string            `json:"id"`
+	AuthorID      int               `json:"author_id"`
+	EntityID      int               `json:"entity_id"`
+	EntityType    string            `json:"entity_type"`
+	EntityPath    string            `json:"entity_path,omitempty"`
+	TargetType    string            `json:"target_type"`
+	TargetDetails string            `json:"target_details"`
+	EventType     string            `json:"event_type"`
+	CreatedAt     time.Time         `json:"created_at"`
+	Details       auditEventDetails `json:"details"`
+}
+
+type auditEventDetails struct {
+	CustomMessage string `json:"custom_message"`
+	AuthorName    string `json:"author_name"`
+	TargetDetails string `json:"target_details"`
+	Version       string `json:"version"`
+	SHA           string `json:"sha"`
+	Prerelease    bool   `json:"prerelease"`
+	ReleaseURL    string `json:"release_url,omitempty"`
+	PipelineID    int    `json:"pipeline_id,omitempty"`
+	PipelineURL   string `json:"pipeline_url,omitempty"`
+}
+
+// newAuditEvent describes the release, the actor is the owner of the token or the user who started the pipeline
+func (repo *GitLabRepository) newAuditEvent(data *templateData) (*auditEvent, error) {
+	project, err := repo.getProject()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project: %w", err)
+	}
+
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+
+	authorID, _ := strconv.Atoi(os.Getenv("GITLAB_USER_ID"))
+	authorName := os.Getenv("GITLAB_USER_LOGIN")
+	// job tokens cannot read the current user
+	if user, _, err := repo.api.CurrentUser(); err == nil {
+		authorID, authorName = user.ID, user.Username
+	}
+	if authorName == "" {
+		authorName = "unknown"
+	}
+
+	pipelineID, _ := strconv.Atoi(os.Getenv("CI_PIPELINE_ID"))
+	return &auditEvent{
+		ID:            hex.EncodeToString(id),
+		AuthorID:      authorID,
+		EntityID:      project.ID,
+		EntityType:    "Project",
+		EntityPath:    project.PathWithNamespace,
+		TargetType:    "Release",
+		TargetDetails: data.Tag,
+		EventType:     auditEventType,
+		CreatedAt:     time.Now().UTC(),
+		Details: auditEventDetails{
+			CustomMessage: fmt.Sprintf("Released %s at %s", data.Tag, data.SHA),
+			AuthorName:    authorName,
+			TargetDetails: data.Tag,
+			Version:       data.Version,
+			SHA:           data.SHA,
+			Prerelease:    data.Prerelease,
+			ReleaseURL:    data.ReleaseURL,
+			PipelineID:    pipelineID,
+			PipelineURL:   os.Getenv("CI_PIPELINE_URL"),
+		},
+	}, nil
+}
+
+// sendAuditEvent streams the release to the audit destination. GitLab has no API to record custom audit events,
+// the event is therefore sent like GitLab streams its own events, verified by the destination token.
+func (repo *GitLabRepository) sendAuditEvent(data *templateData) error {
+	event, err := repo.newAuditEvent(data)
+	if err != nil {
+		return fmt.Errorf("failed to create the audit event: %w", err)
+	}
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode the audit event: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, repo.auditStreamURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create the audit event: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Gitlab-Audit-Event-Type", auditEventType)
+	if repo.auditStreamToken != "" {
+		req.Header.Set(auditStreamingHeader, repo.auditStreamToken)
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send the audit event: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("the audit event was rejected with status %s", resp.Status)
+	}
+
+	repo.logger.Printf("sent audit event %s for %s", event.ID, data.Tag)
+	return nil
+}
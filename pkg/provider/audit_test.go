@@ -0,0 +1,89 @@
+package provider
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/go-semantic-release/semantic-release/v2/pkg/provider"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGitlabAuditEvent(t *testing.T) {
+	t.Setenv("CI_PIPELINE_ID", "77")
+	t.Setenv("CI_PIPELINE_URL", "https://gitlab.com/group/project/-/pipelines/77")
+
+	var event auditEvent
+	var token string
+	audit := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token = r.Header.Get(auditStreamingHeader)
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&event))
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer audit.Close()
+	ts := httptest.NewServer(http.HandlerFunc(GitlabHandler))
+	defer ts.Close()
+
+	repo := &GitLabRepository{}
+	err := repo.Init(map[string]string{
+		"gitlab_baseurl":            ts.URL,
+		"token":                     "token",
+		"gitlab_projectid":          strconv.Itoa(GITLAB_PROJECT_ID),
+		"gitlab_audit_stream_url":   audit.URL,
+		"gitlab_audit_stream_token": "verification",
+	})
+	require.NoError(t, err)
+
+	err = repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "2.0.0", SHA: "deadbeef"})
+	require.NoError(t, err)
+
+	require.Equal(t, "verification", token)
+	require.Len(t, event.ID, 32)
+	require.Equal(t, auditEventType, event.EventType)
+	require.Equal(t, GITLAB_USER.ID, event.AuthorID)
+	require.Equal(t, GITLAB_PROJECT_ID, event.EntityID)
+	require.Equal(t, "v2.0.0", event.TargetDetails)
+	require.Equal(t, auditEventDetails{
+		CustomMessage: "Released v2.0.0 at deadbeef",
+		AuthorName:    GITLAB_USER.Username,
+		TargetDetails: "v2.0.0",
+		Version:       "2.0.0",
+		SHA:           "deadbeef",
+		PipelineID:    77,
+		PipelineURL:   "https://gitlab.com/group/project/-/pipelines/77",
+	}, event.Details)
+}
+
+func TestGitlabAuditEventRejected(t *testing.T) {
+	audit := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer audit.Close()
+	ts := httptest.NewServer(http.HandlerFunc(GitlabHandler))
+	defer ts.Close()
+
+	for _, required := range []bool{false, true} {
+		var logs bytes.Buffer
+		repo := &GitLabRepository{logger: log.New(&logs, "", 0)}
+		err := repo.Init(map[string]string{
+			"gitlab_baseurl":          ts.URL,
+			"token":                   "token",
+			"gitlab_projectid":        strconv.Itoa(GITLAB_PROJECT_ID),
+			"gitlab_audit_stream_url": audit.URL,
+			"gitlab_audit_required":   strconv.FormatBool(required),
+		})
+		require.NoError(t, err)
+
+		err = repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "2.0.0", SHA: "deadbeef"})
+		if required {
+			require.EqualError(t, err, "the audit event was rejected with status 403 Forbidden")
+		} else {
+			require.NoError(t, err)
+			require.Contains(t, logs.String(), "WARNING: the audit event was rejected with status 403 Forbidden")
+		}
+	}
+}
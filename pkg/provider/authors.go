@@ -0,0 +1,44 @@
+package provider
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+// parseAuthorPatternsConfig parses a comma separated list of author patterns, the patterns use the syntax of
+// path.Match and are matched case-insensitively against the name and the email of the author
+func parseAuthorPatternsConfig(config map[string]string, key string) ([]string, error) {
+	patterns := make([]string, 0)
+	for _, pattern := range parseListConfig(config, key) {
+		pattern = strings.ToLower(pattern)
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("failed to set property %s: invalid author pattern %q: %w", key, pattern, err)
+		}
+		patterns = append(patterns, pattern)
+	}
+	return patterns, nil
+}
+
+// isIgnoredAuthor reports whether the commit was authored by someone matching gitlab_ignore_authors, e.g. a bot
+// updating dependencies
+func (repo *GitLabRepository) isIgnoredAuthor(commit *gitlab.Commit) bool {
+	name, email := strings.ToLower(commit.AuthorName), strings.ToLower(commit.AuthorEmail)
+	for _, pattern := range repo.ignoreAuthors {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+		if matched, _ := path.Match(pattern, email); matched {
+			return true
+		}
+	}
+	return false
+}
+
+func (repo *GitLabRepository) logIgnoredAuthors(ignored int) {
+	if ignored > 0 {
+		repo.logger.Printf("ignored %d commits of authors matching gitlab_ignore_authors", ignored)
+	}
+}
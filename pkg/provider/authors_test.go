@@ -0,0 +1,64 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/go-semantic-release/provider-gitlab/pkg/gitlabtest"
+	"github.com/stretchr/testify/require"
+	"github.com/xanzy/go-gitlab"
+)
+
+func TestParseAuthorPatternsConfig(t *testing.T) {
+	patterns, err := parseAuthorPatternsConfig(map[string]string{"authors": "Renovate*, *-bot@company.com"}, "authors")
+	require.NoError(t, err)
+	require.Equal(t, []string{"renovate*", "*-bot@company.com"}, patterns)
+
+	_, err = parseAuthorPatternsConfig(map[string]string{"authors": "[bot"}, "authors")
+	require.EqualError(t, err, `failed to set property authors: invalid author pattern "[bot": syntax error in pattern`)
+}
+
+var botCommits = []*gitlab.Commit{
+	{ID: "abcd", Message: "chore(deps): update module foo to v2", AuthorName: "renovate[bot]", AuthorEmail: "bot@renovateapp.com"},
+	{ID: "dcba", Message: "feat: add bar", AuthorName: "Jane Doe", AuthorEmail: "jane@company.com"},
+	{ID: "cdba", Message: "fix(deps): bump baz", AuthorName: "Release", AuthorEmail: "release-bot@company.com"},
+	{ID: "efcd", Message: "fix: handle errors", AuthorName: "John Doe", AuthorEmail: "john@company.com"},
+}
+
+// newBotCommitsServer serves botCommits, abcd is the head of the default branch
+func newBotCommitsServer(t *testing.T) *gitlabtest.Server {
+	server, project := newFakeServer(t)
+	for i := len(botCommits) - 1; i >= 0; i-- {
+		commit := *botCommits[i]
+		server.PushCommits(project, &commit)
+	}
+	return server
+}
+
+func TestGitlabIgnoreAuthors(t *testing.T) {
+	server := newBotCommitsServer(t)
+	repo := initFakeRepo(t, &GitLabRepository{}, server, map[string]string{
+		"gitlab_ignore_authors": "renovate*,*-bot@company.com",
+	})
+
+	commits, err := repo.GetCommits("", "abcd")
+	require.NoError(t, err)
+	require.Len(t, commits, 2)
+	require.Equal(t, "dcba", commits[0].SHA)
+	require.Equal(t, "efcd", commits[1].SHA)
+}
+
+func TestGitlabIgnoreAuthorsReadReplica(t *testing.T) {
+	primary, secondary := newBotCommitsServer(t), newBotCommitsServer(t)
+	repo := initFakeRepo(t, &GitLabRepository{}, primary, map[string]string{
+		"gitlab_read_baseurl":   secondary.URL,
+		"gitlab_ignore_authors": "renovate*",
+	})
+
+	// the released commit was made by an ignored author, the replica has replicated it nonetheless
+	commits, err := repo.GetCommits("", "abcd")
+	require.NoError(t, err)
+	require.Len(t, commits, 3)
+	commitsRequest := "GET /api/v4/projects/group/app/repository/commits"
+	require.Len(t, secondary.Queries(commitsRequest), 1)
+	require.Empty(t, primary.Queries(commitsRequest))
+}
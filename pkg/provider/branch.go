@@ -0,0 +1,46 @@
+package provider
+
+import (
+	"fmt"
+)
+
+func (repo *GitLabRepository) getBranchHead(branch string) (string, error) {
+	b, _, err := repo.api.GetBranch(repo.projectID, branch)
+	if err != nil {
+		return "", fmt.Errorf("failed to get branch %s: %w", branch, err)
+	}
+	if b.Commit == nil {
+		return "", fmt.Errorf("branch %s has no commit", branch)
+	}
+	return b.Commit.ID, nil
+}
+
+// verifyBranchHead makes sure no new commits landed on the branch since the commits were analyzed
+func (repo *GitLabRepository) verifyBranchHead(branch, sha string) error {
+	if branch == "" {
+		return nil
+	}
+
+	expected := repo.branchHead
+	if expected == "" {
+		expected = sha
+	}
+
+	head, err := repo.getBranchHead(branch)
+	if err != nil {
+		return err
+	}
+	if head != expected {
+		return fmt.Errorf("branch %s moved from %s to %s while the release was prepared, rerun the release to include the new commits", branch, expected, head)
+	}
+	return nil
+}
+
+// resolveRef returns the commit of the branch, tag or SHA configured by gitlab_ref
+func (repo *GitLabRepository) resolveRef(ref string) (string, error) {
+	commit, _, err := repo.api.GetCommit(repo.projectID, ref)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve ref %s: %w", ref, err)
+	}
+	return commit.ID, nil
+}
@@ -0,0 +1,87 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/go-semantic-release/semantic-release/v2/pkg/provider"
+	"github.com/stretchr/testify/require"
+	"github.com/xanzy/go-gitlab"
+)
+
+func TestGitlabStrictHeadCheck(t *testing.T) {
+	head := "abcd"
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == fmt.Sprintf("/api/v4/projects/%d/repository/branches/master", GITLAB_PROJECT_ID) {
+			json.NewEncoder(w).Encode(gitlab.Branch{Name: "master", Commit: &gitlab.Commit{ID: head}}) //nolint:errcheck
+			return
+		}
+		GitlabHandler(w, r)
+	}))
+	defer ts.Close()
+
+	repo := &GitLabRepository{}
+	err := repo.Init(map[string]string{
+		"gitlab_baseurl":           ts.URL,
+		"token":                    "gitlab-examples-ci",
+		"gitlab_branch":            "master",
+		"gitlab_projectid":         strconv.Itoa(GITLAB_PROJECT_ID),
+		"gitlab_strict_head_check": "true",
+	})
+	require.NoError(t, err)
+
+	_, err = repo.GetCommits("", "abcd")
+	require.NoError(t, err)
+
+	head = "ffff"
+	err = repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "2.0.0", SHA: "abcd"})
+	require.EqualError(t, err, "branch master moved from abcd to ffff while the release was prepared, rerun the release to include the new commits")
+
+	head = "abcd"
+	err = repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "2.0.0", SHA: "abcd"})
+	require.NoError(t, err)
+}
+
+func TestGitlabReleaseFromRef(t *testing.T) {
+	var releaseRef string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == fmt.Sprintf("/api/v4/projects/%d/repository/commits/release-candidate", GITLAB_PROJECT_ID):
+			json.NewEncoder(w).Encode(gitlab.Commit{ID: "cafebabe"}) //nolint:errcheck
+			return
+		case r.URL.Path == fmt.Sprintf("/api/v4/projects/%d/repository/commits/missing", GITLAB_PROJECT_ID):
+			http.Error(w, `{"message": "404 Commit Not Found"}`, http.StatusNotFound)
+			return
+		case r.Method == "POST" && r.URL.Path == fmt.Sprintf("/api/v4/projects/%d/releases", GITLAB_PROJECT_ID):
+			var data map[string]string
+			json.NewDecoder(r.Body).Decode(&data) //nolint:errcheck
+			releaseRef = data["ref"]
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(gitlab.Release{TagName: data["tag_name"]}) //nolint:errcheck
+			return
+		}
+		GitlabHandler(w, r)
+	}))
+	defer ts.Close()
+
+	repo := &GitLabRepository{}
+	err := repo.Init(map[string]string{
+		"gitlab_baseurl":   ts.URL,
+		"token":            "gitlab-examples-ci",
+		"gitlab_projectid": strconv.Itoa(GITLAB_PROJECT_ID),
+		"gitlab_ref":       "release-candidate",
+	})
+	require.NoError(t, err)
+
+	err = repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "2.0.0", SHA: "deadbeef"})
+	require.NoError(t, err)
+	require.Equal(t, "cafebabe", releaseRef)
+
+	repo.ref = "missing"
+	err = repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "2.0.0", SHA: "deadbeef"})
+	require.ErrorContains(t, err, "failed to resolve ref missing: ")
+}
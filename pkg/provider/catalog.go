@@ -0,0 +1,38 @@
+package provider
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+// verifyCatalogResource checks the requirements of the CI/CD catalog, otherwise the release would not be published as
+// a new component version
+func (repo *GitLabRepository) verifyCatalogResource(sha string) error {
+	project, err := repo.getProject()
+	if err != nil {
+		return fmt.Errorf("failed to get project %s: %w", repo.projectID, err)
+	}
+
+	var problems []string
+	if project.Description == "" {
+		problems = append(problems, "the project has no description")
+	}
+	if project.ReadmeURL == "" {
+		problems = append(problems, "the project has no README")
+	}
+
+	tree, _, err := repo.api.ListTree(repo.projectID, &gitlab.ListTreeOptions{
+		Path: gitlab.String("templates"),
+		Ref:  &sha,
+	})
+	if err != nil || len(tree) == 0 {
+		problems = append(problems, fmt.Sprintf("the templates directory is missing or empty at %s", sha))
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("project %s is not a valid CI/CD catalog resource:\n  - %s", repo.projectID, strings.Join(problems, "\n  - "))
+	}
+	return nil
+}
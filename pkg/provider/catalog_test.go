@@ -0,0 +1,64 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/go-semantic-release/semantic-release/v2/pkg/provider"
+	"github.com/stretchr/testify/require"
+	"github.com/xanzy/go-gitlab"
+)
+
+func TestGitlabCICatalog(t *testing.T) {
+	project := GITLAB_PROJECT
+	var tree []*gitlab.TreeNode
+	var releasedTag string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == fmt.Sprintf("/api/v4/projects/%d", GITLAB_PROJECT_ID):
+			json.NewEncoder(w).Encode(project) //nolint:errcheck
+		case r.Method == "GET" && r.URL.Path == fmt.Sprintf("/api/v4/projects/%d/repository/tree", GITLAB_PROJECT_ID):
+			require.Equal(t, "templates", r.URL.Query().Get("path"))
+			require.Equal(t, "deadbeef", r.URL.Query().Get("ref"))
+			json.NewEncoder(w).Encode(tree) //nolint:errcheck
+		case r.Method == "POST" && r.URL.Path == fmt.Sprintf("/api/v4/projects/%d/releases", GITLAB_PROJECT_ID):
+			var opts gitlab.CreateReleaseOptions
+			json.NewDecoder(r.Body).Decode(&opts) //nolint:errcheck
+			releasedTag = *opts.TagName
+			fmt.Fprint(w, "{}")
+		default:
+			GitlabHandler(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	config := map[string]string{
+		"gitlab_baseurl":    ts.URL,
+		"token":             "gitlab-examples-ci",
+		"gitlab_projectid":  strconv.Itoa(GITLAB_PROJECT_ID),
+		"gitlab_ci_catalog": "true",
+	}
+	repo := &GitLabRepository{}
+	require.NoError(t, repo.Init(config))
+
+	err := repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "2.0.0", SHA: "deadbeef"})
+	require.EqualError(t, err, fmt.Sprintf("project %d is not a valid CI/CD catalog resource:\n  - the project has no description\n  - the project has no README\n  - the templates directory is missing or empty at deadbeef", GITLAB_PROJECT_ID))
+
+	project.Description = "Reusable jobs"
+	project.ReadmeURL = "https://gitlab.com/project/-/blob/main/README.md"
+	tree = []*gitlab.TreeNode{{Name: "build.yml", Type: "blob", Path: "templates/build.yml"}}
+	repo = &GitLabRepository{}
+	require.NoError(t, repo.Init(config))
+
+	err = repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "2.0.0", SHA: "deadbeef"})
+	require.NoError(t, err)
+	require.Equal(t, "2.0.0", releasedTag)
+
+	repo = &GitLabRepository{}
+	config["gitlab_tag_only"] = "true"
+	require.EqualError(t, repo.Init(config), "gitlab_tag_only and gitlab_ci_catalog cannot be used together, the catalog requires a release")
+}
@@ -0,0 +1,153 @@
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+const (
+	changelogModeRaw    = "raw"
+	changelogModeFenced = "fenced"
+	changelogModeEscape = "escape"
+)
+
+const (
+	// GitLab rejects release descriptions with more characters
+	maxDescriptionLength = 1000000
+	// length of the changelog kept in the description if the full text is moved to a snippet
+	changelogSummaryLength = 10000
+)
+
+// matches user/group mentions (@user) and issue, merge request and epic references (#1, !1, &1, group/project#1)
+var gitlabReferenceRe = regexp.MustCompile("(^|[\\s(\\[,;:])(@[\\w][\\w.-]*[\\w]|@[\\w]|(?:[\\w.-]+/)*[\\w.-]*[#!&]\\d+)")
+
+func isValidChangelogMode(mode string) bool {
+	switch mode {
+	case "", changelogModeRaw, changelogModeFenced, changelogModeEscape:
+		return true
+	}
+	return false
+}
+
+func formatChangelog(changelog, mode string) string {
+	switch mode {
+	case changelogModeFenced:
+		return fenceMarkdown(changelog)
+	case changelogModeEscape:
+		return escapeGitLabReferences(changelog)
+	}
+	return changelog
+}
+
+// fenceMarkdown wraps the text in a code block whose fence is longer than any backtick run inside the text
+func fenceMarkdown(text string) string {
+	longest, current := 0, 0
+	for _, c := range text {
+		if c != '`' {
+			current = 0
+			continue
+		}
+		current++
+		if current > longest {
+			longest = current
+		}
+	}
+	fenceLen := 3
+	if longest >= fenceLen {
+		fenceLen = longest + 1
+	}
+	fence := strings.Repeat("`", fenceLen)
+	return fence + "\n" + strings.TrimRight(text, "\n") + "\n" + fence
+}
+
+// escapeGitLabReferences wraps mentions and references in inline code so GitLab does not link them or send notifications
+func escapeGitLabReferences(text string) string {
+	lines := strings.Split(text, "\n")
+	inFence := false
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		lines[i] = escapeLine(line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func escapeLine(line string) string {
+	// do not touch references which are already part of inline code
+	parts := strings.Split(line, "`")
+	for i := 0; i < len(parts); i += 2 {
+		parts[i] = gitlabReferenceRe.ReplaceAllString(parts[i], "$1`$2`")
+	}
+	return strings.Join(parts, "`")
+}
+
+// releaseDescription formats the changelog as the release description. Changelogs exceeding the description limit
+// of GitLab are stored in a project snippet if gitlab_changelog_snippet is set, the description then starts with the
+// first lines of the changelog and links the snippet. The description is kept for the tag, so retries and the
+// releases in mirrors and other projects do not create another snippet.
+func (repo *GitLabRepository) releaseDescription(tag, changelog string) (string, error) {
+	if repo.descriptionTag == tag {
+		return repo.description, nil
+	}
+	description, err := repo.formatReleaseDescription(tag, changelog)
+	if err != nil {
+		return "", err
+	}
+	repo.descriptionTag, repo.description = tag, description
+	return description, nil
+}
+
+func (repo *GitLabRepository) formatReleaseDescription(tag, changelog string) (string, error) {
+	description := formatChangelog(changelog, repo.changelogMode)
+	limit := repo.descriptionLimit
+	if limit == 0 {
+		limit = maxDescriptionLength
+	}
+	if !repo.changelogSnippet || utf8.RuneCountInString(description) <= limit {
+		return description, nil
+	}
+
+	visibility := gitlab.PrivateVisibility
+	if project, err := repo.getProject(); err == nil && project.Visibility != "" {
+		visibility = project.Visibility
+	}
+	snippet, _, err := repo.api.CreateProjectSnippet(repo.projectID, &gitlab.CreateProjectSnippetOptions{
+		Title:      gitlab.String(fmt.Sprintf("Changelog of %s", tag)),
+		FileName:   gitlab.String(fmt.Sprintf("CHANGELOG-%s.md", tag)),
+		Content:    &changelog,
+		Visibility: gitlab.Visibility(visibility),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to create the changelog snippet: %w", err)
+	}
+	repo.logger.Printf("the changelog of %s exceeds %d characters, the full text is in snippet %s", tag, limit, snippet.WebURL)
+
+	footer := fmt.Sprintf("\n\n_The changelog is too long for the release description, see the [full changelog](%s)._", snippet.WebURL)
+	summaryLength := changelogSummaryLength
+	if summaryLength > limit/2 {
+		summaryLength = limit / 2
+	}
+	return formatChangelog(summarizeChangelog(changelog, summaryLength), repo.changelogMode) + footer, nil
+}
+
+// summarizeChangelog returns the complete lines of the changelog which fit into length characters
+func summarizeChangelog(changelog string, length int) string {
+	runes := []rune(changelog)
+	if len(runes) <= length {
+		return changelog
+	}
+	summary := string(runes[:length])
+	if i := strings.LastIndex(summary, "\n"); i > 0 {
+		summary = summary[:i]
+	}
+	return strings.TrimRight(summary, "\n") + "\n..."
+}
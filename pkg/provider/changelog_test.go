@@ -0,0 +1,115 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/go-semantic-release/semantic-release/v2/pkg/provider"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatChangelog(t *testing.T) {
+	testCases := []struct {
+		mode      string
+		changelog string
+		expected  string
+	}{
+		{"", "* fix @user #12", "* fix @user #12"},
+		{"raw", "* fix @user #12", "* fix @user #12"},
+		{"fenced", "* fix\n", "```\n* fix\n```"},
+		{"fenced", "* use ```go blocks", "````\n* use ```go blocks\n````"},
+		{"escape", "#### Bug Fixes\n* fix (@user, #12)", "#### Bug Fixes\n* fix (`@user`, `#12`)"},
+		{"escape", "* see group/project!3 and &4", "* see `group/project!3` and `&4`"},
+		{"escape", "* mail me@example.com", "* mail me@example.com"},
+		{"escape", "* keep `@user` as is", "* keep `@user` as is"},
+		{"escape", "```\n@user #1\n```", "```\n@user #1\n```"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.mode+": "+tc.changelog, func(t *testing.T) {
+			require.Equal(t, tc.expected, formatChangelog(tc.changelog, tc.mode))
+		})
+	}
+}
+
+func TestChangelogSnippet(t *testing.T) {
+	repo, ts := getNewGitlabTestRepo(t)
+	defer ts.Close()
+	repo.changelogSnippet = true
+	repo.descriptionLimit = 40
+
+	var snippet, description map[string]string
+	ts.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "POST" && r.URL.Path == fmt.Sprintf("/api/v4/projects/%d/snippets", GITLAB_PROJECT_ID):
+			json.NewDecoder(r.Body).Decode(&snippet) //nolint:errcheck
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"id": 7, "web_url": "https://gitlab.com/group/project/-/snippets/7"}`)
+		case r.Method == "POST" && r.URL.Path == fmt.Sprintf("/api/v4/projects/%d/releases", GITLAB_PROJECT_ID):
+			json.NewDecoder(r.Body).Decode(&description) //nolint:errcheck
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"tag_name": "v2.0.0"}`)
+		default:
+			GitlabHandler(w, r)
+		}
+	})
+
+	changelog := "* feat: first\n* fix: second\n* fix: third\n* fix: fourth\n"
+	err := repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "2.0.0", SHA: "deadbeef", Changelog: changelog})
+	require.NoError(t, err)
+	require.Equal(t, changelog, snippet["content"])
+	require.Equal(t, "CHANGELOG-v2.0.0.md", snippet["file_name"])
+	require.Equal(t, "private", snippet["visibility"])
+	require.Equal(t, "* feat: first\n...\n\n_The changelog is too long for the release description, see the [full changelog](https://gitlab.com/group/project/-/snippets/7)._", description["description"])
+
+	// short changelogs stay in the description
+	snippet = nil
+	err = repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "2.0.0", SHA: "deadbeef", Changelog: "* feat: first"})
+	require.NoError(t, err)
+	require.Nil(t, snippet)
+	require.Equal(t, "* feat: first", description["description"])
+}
+
+func TestChangelogSnippetFanOut(t *testing.T) {
+	repo, ts := getNewGitlabTestRepo(t)
+	defer ts.Close()
+	repo.changelogSnippet = true
+	repo.descriptionLimit = 40
+	repo.fanOutProjects = []*GitLabRepository{repo.newTargetRepository("4242", "main")}
+
+	snippets := 0
+	descriptions := make(map[string]string)
+	ts.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "POST" && r.URL.Path == fmt.Sprintf("/api/v4/projects/%d/snippets", GITLAB_PROJECT_ID):
+			snippets++
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"id": 7, "web_url": "https://gitlab.com/group/project/-/snippets/7"}`)
+		case r.Method == "POST" && strings.HasSuffix(r.URL.Path, "/releases"):
+			var data map[string]string
+			json.NewDecoder(r.Body).Decode(&data) //nolint:errcheck
+			descriptions[r.URL.Path] = data["description"]
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"tag_name": "v2.0.0"}`)
+		case r.Method == "GET" && r.URL.Path == "/api/v4/projects/4242/repository/branches/main":
+			fmt.Fprint(w, `{"name": "main", "commit": {"id": "cafebabe"}}`)
+		case r.Method == "POST" && r.URL.Path == "/api/v4/projects/4242/repository/tags":
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"name": "v2.0.0", "commit": {"id": "cafebabe"}}`)
+		default:
+			GitlabHandler(w, r)
+		}
+	})
+
+	changelog := "* feat: first\n* fix: second\n* fix: third\n* fix: fourth\n"
+	err := repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "2.0.0", SHA: "deadbeef", Changelog: changelog})
+	require.NoError(t, err)
+	// the other project links the snippet of the release instead of getting the full changelog
+	require.Equal(t, 1, snippets)
+	require.Len(t, descriptions, 2)
+	require.Equal(t, descriptions[fmt.Sprintf("/api/v4/projects/%d/releases", GITLAB_PROJECT_ID)], descriptions["/api/v4/projects/4242/releases"])
+	require.Contains(t, descriptions["/api/v4/projects/4242/releases"], "https://gitlab.com/group/project/-/snippets/7")
+}
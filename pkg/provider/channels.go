@@ -0,0 +1,67 @@
+package provider
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// the channel of the default release line, releases of other channels do not become the latest release
+const channelLatest = "latest"
+
+// maintenance branches are named after the versions they release, e.g. 1.x, 1.2.x or release/1.x
+var maintenanceBranchPattern = regexp.MustCompile(`(?:^|/)v?(\d+(?:\.\d+)?\.x)$`)
+
+type branchChannel struct {
+	pattern string
+	channel string
+}
+
+// parseBranchChannelsConfig parses a comma separated list of branch-pattern=channel pairs, the patterns use the
+// syntax of path.Match and the first matching pattern wins
+func parseBranchChannelsConfig(config map[string]string, key string) ([]branchChannel, error) {
+	channels := make([]branchChannel, 0)
+	for _, pair := range parseListConfig(config, key) {
+		pattern, channel, _ := strings.Cut(pair, "=")
+		pattern, channel = strings.TrimSpace(pattern), strings.TrimSpace(channel)
+		if pattern == "" || channel == "" {
+			return nil, fmt.Errorf("failed to set property %s: invalid channel %q, expected branch-pattern=channel", key, pair)
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("failed to set property %s: invalid branch pattern %q: %w", key, pattern, err)
+		}
+		channels = append(channels, branchChannel{pattern: pattern, channel: channel})
+	}
+	return channels, nil
+}
+
+// branchChannelFor returns the channel of the branch or an empty string if no pattern matches
+func branchChannelFor(channels []branchChannel, branch string) string {
+	for _, c := range channels {
+		if matched, _ := path.Match(c.pattern, branch); matched {
+			return c.channel
+		}
+	}
+	return ""
+}
+
+// maintenanceRange returns the versions a maintenance branch releases or nil for other branches
+func maintenanceRange(branch string) *semver.Constraints {
+	m := maintenanceBranchPattern.FindStringSubmatch(branch)
+	if m == nil {
+		return nil
+	}
+	constraints, err := semver.NewConstraint(m[1])
+	if err != nil {
+		return nil
+	}
+	return constraints
+}
+
+// isMaintenanceChannel reports whether the release is published to a channel other than the latest one
+func (repo *GitLabRepository) isMaintenanceChannel() bool {
+	return repo.channel != "" && repo.channel != channelLatest
+}
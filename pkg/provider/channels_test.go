@@ -0,0 +1,79 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/go-semantic-release/semantic-release/v2/pkg/provider"
+	"github.com/go-semantic-release/semantic-release/v2/pkg/semrel"
+	"github.com/stretchr/testify/require"
+	"github.com/xanzy/go-gitlab"
+)
+
+func TestBranchChannels(t *testing.T) {
+	channels, err := parseBranchChannelsConfig(map[string]string{"channels": "*.x=maintenance, main=latest"}, "channels")
+	require.NoError(t, err)
+	require.Equal(t, "maintenance", branchChannelFor(channels, "1.x"))
+	require.Equal(t, "latest", branchChannelFor(channels, "main"))
+	require.Equal(t, "", branchChannelFor(channels, "feature/x"))
+
+	_, err = parseBranchChannelsConfig(map[string]string{"channels": "[=maintenance"}, "channels")
+	require.EqualError(t, err, `failed to set property channels: invalid branch pattern "[": syntax error in pattern`)
+}
+
+func TestMaintenanceRange(t *testing.T) {
+	require.True(t, maintenanceRange("1.x").Check(semverMust(t, "1.9.0")))
+	require.False(t, maintenanceRange("1.x").Check(semverMust(t, "2.0.0")))
+	require.True(t, maintenanceRange("release/1.2.x").Check(semverMust(t, "1.2.3")))
+	require.False(t, maintenanceRange("release/1.2.x").Check(semverMust(t, "1.3.0")))
+	require.Nil(t, maintenanceRange("main"))
+}
+
+func TestMaintenanceChannelRelease(t *testing.T) {
+	var release map[string]interface{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := fmt.Sprintf("/api/v4/projects/%d/releases", GITLAB_PROJECT_ID)
+		switch {
+		case r.Method == "GET" && r.URL.Path == path:
+			fmt.Fprint(w, `[{"tag_name": "v2.0.0", "released_at": "2022-06-01T10:00:00Z"}]`)
+		case r.Method == "POST" && r.URL.Path == path:
+			json.NewDecoder(r.Body).Decode(&release) //nolint:errcheck
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(gitlab.Release{TagName: "v1.0.1"}) //nolint:errcheck
+		default:
+			GitlabHandler(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	repo := &GitLabRepository{}
+	err := repo.Init(map[string]string{
+		"gitlab_baseurl":         ts.URL,
+		"token":                  "gitlab-examples-ci",
+		"gitlab_projectid":       strconv.Itoa(GITLAB_PROJECT_ID),
+		"gitlab_branch":          "1.x",
+		"gitlab_branch_channels": "*.x=maintenance,master=latest",
+	})
+	require.NoError(t, err)
+
+	releases, err := repo.GetReleases("")
+	require.NoError(t, err)
+	require.Equal(t, []*semrel.Release{{SHA: "deadbeef", Version: "1.0.0"}}, releases)
+
+	err = repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "1.0.1", SHA: "deadbeef"})
+	require.NoError(t, err)
+	require.Equal(t, "v1.0.1 (maintenance)", release["name"])
+	// dated before the latest release of the default branch
+	require.Equal(t, "2022-06-01T09:59:59Z", release["released_at"])
+}
+
+func semverMust(t *testing.T, version string) *semver.Version {
+	v, err := semver.NewVersion(version)
+	require.NoError(t, err)
+	return v
+}
@@ -0,0 +1,165 @@
+package provider
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/go-semantic-release/semantic-release/v2/pkg/provider"
+	"github.com/go-semantic-release/semantic-release/v2/pkg/semrel"
+	"github.com/xanzy/go-gitlab"
+)
+
+// childProject is a project whose changes are part of the release notes, e.g. a submodule of an umbrella repository
+type childProject struct {
+	projectID string
+	// path is the path of the submodule in the repository, empty for projects which are not a submodule
+	path string
+}
+
+// childChange are the commits of a child project since the previous release
+type childChange struct {
+	project  *gitlab.Project
+	from, to string
+	commits  []*semrel.RawCommit
+}
+
+// parseChildProjectsConfig parses a comma separated list of project IDs, each optionally followed by the path of its
+// submodule, e.g. group/api:services/api
+func parseChildProjectsConfig(config map[string]string, key string) ([]*childProject, error) {
+	children := make([]*childProject, 0)
+	for _, entry := range parseListConfig(config, key) {
+		projectID, modulePath, _ := strings.Cut(entry, ":")
+		if projectID == "" {
+			return nil, fmt.Errorf("failed to set property %s: missing project in %q", key, entry)
+		}
+		children = append(children, &childProject{projectID: projectID, path: strings.Trim(modulePath, "/")})
+	}
+	return children, nil
+}
+
+// submoduleSHA returns the commit referenced by the submodule at the ref or an empty string if the submodule does not
+// exist at the ref yet
+func (repo *GitLabRepository) submoduleSHA(ref, modulePath string) (string, error) {
+	dir, name := path.Split(modulePath)
+	opts := &gitlab.ListTreeOptions{ListOptions: repo.listOptions(), Ref: gitlab.String(ref)}
+	if dir != "" {
+		opts.Path = gitlab.String(strings.TrimSuffix(dir, "/"))
+	}
+	for {
+		nodes, resp, err := repo.api.ListTree(repo.projectID, opts)
+		if err != nil {
+			return "", fmt.Errorf("failed to list the tree of %s at %s: %w", dir, ref, err)
+		}
+		for _, node := range nodes {
+			if node.Name != name {
+				continue
+			}
+			if node.Type != "commit" {
+				return "", fmt.Errorf("%s is not a submodule", modulePath)
+			}
+			return node.ID, nil
+		}
+		if resp.NextPage == 0 {
+			return "", nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// childChanges returns the commits of the child project which were added since the previous release. The range of a
+// submodule is given by the commits it referenced at the previous release and at sha, other projects contribute the
+// commits of their default branch since the date of the previous release.
+func (repo *GitLabRepository) childChanges(child *childProject, sha string) (*childChange, error) {
+	project, _, err := repo.api.GetProject(child.projectID, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get child project %s: %w", child.projectID, err)
+	}
+	change := &childChange{project: project}
+	target := repo.newTargetRepository(child.projectID, "")
+
+	if child.path != "" {
+		if change.to, err = repo.submoduleSHA(sha, child.path); err != nil {
+			return nil, err
+		}
+		if change.to == "" {
+			return nil, fmt.Errorf("submodule %s does not exist at %s", child.path, sha)
+		}
+		if repo.commitsFrom != "" {
+			if change.from, err = repo.submoduleSHA(repo.commitsFrom, child.path); err != nil {
+				return nil, err
+			}
+		}
+		if change.from == change.to {
+			return change, nil
+		}
+		change.commits, _, err = target.listCommitRange(target.api, change.from, change.to)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list the commits of child project %s: %w", child.projectID, err)
+		}
+		return change, nil
+	}
+
+	opts := &gitlab.ListCommitsOptions{RefName: gitlab.String(project.DefaultBranch)}
+	if repo.commitsFrom != "" {
+		since := repo.releaseDates[repo.commitsFrom]
+		if since == nil {
+			from, _, err := repo.api.GetCommit(repo.projectID, repo.commitsFrom)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get commit %s: %w", repo.commitsFrom, err)
+			}
+			since = from.CommittedDate
+		}
+		// commits of the second of the previous release were part of it
+		opts.Since = gitlab.Time(since.Add(time.Second))
+	}
+	change.commits, change.to, err = target.listCommitPages(target.api, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the commits of child project %s: %w", child.projectID, err)
+	}
+	return change, nil
+}
+
+// notes returns a section of the release notes listing the commits of the child project
+func (c *childChange) notes() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "### %s\n\n", defaultString(c.project.PathWithNamespace, c.project.Name))
+	if c.from != "" && c.project.WebURL != "" {
+		fmt.Fprintf(&sb, "[%s...%s](%s/-/compare/%s...%s)\n\n", shortSHA(c.from), shortSHA(c.to), c.project.WebURL, c.from, c.to)
+	}
+	for _, commit := range c.commits {
+		fmt.Fprintf(&sb, "* %s (%s)\n", strings.SplitN(commit.RawMessage, "\n", 2)[0], shortSHA(commit.SHA))
+	}
+	return sb.String()
+}
+
+// childProjectsNotes returns the release notes of the child projects which changed since the previous release
+func (repo *GitLabRepository) childProjectsNotes(sha string) (string, error) {
+	sections := make([]string, 0, len(repo.childProjects))
+	for _, child := range repo.childProjects {
+		change, err := repo.childChanges(child, sha)
+		if err != nil {
+			return "", err
+		}
+		if len(change.commits) == 0 {
+			repo.debugf("child project %s has no changes", child.projectID)
+			continue
+		}
+		sections = append(sections, change.notes())
+	}
+	return strings.Join(sections, "\n"), nil
+}
+
+// withChildProjectsNotes appends the release notes of the child projects to the changelog
+func (repo *GitLabRepository) withChildProjectsNotes(release *provider.CreateReleaseConfig) (*provider.CreateReleaseConfig, error) {
+	notes, err := repo.childProjectsNotes(release.SHA)
+	if err != nil || notes == "" {
+		return release, err
+	}
+	changelog := strings.TrimRight(release.Changelog, "\n")
+	if changelog != "" {
+		changelog += "\n\n"
+	}
+	return withChangelog(release, changelog+notes), nil
+}
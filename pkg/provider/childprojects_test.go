@@ -0,0 +1,103 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/go-semantic-release/semantic-release/v2/pkg/provider"
+	"github.com/stretchr/testify/require"
+	"github.com/xanzy/go-gitlab"
+)
+
+func TestParseChildProjectsConfig(t *testing.T) {
+	children, err := parseChildProjectsConfig(map[string]string{"gitlab_child_projects": "group/api:services/api/, 2002"}, "gitlab_child_projects")
+	require.NoError(t, err)
+	require.Equal(t, []*childProject{{projectID: "group/api", path: "services/api"}, {projectID: "2002"}}, children)
+
+	_, err = parseChildProjectsConfig(map[string]string{"gitlab_child_projects": ":services/api"}, "gitlab_child_projects")
+	require.EqualError(t, err, `failed to set property gitlab_child_projects: missing project in ":services/api"`)
+}
+
+func TestGitlabChildProjectsNotes(t *testing.T) {
+	released := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	var description string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		//nolint:errcheck
+		switch {
+		case r.Method == "GET" && r.URL.Path == fmt.Sprintf("/api/v4/projects/%d/repository/tree", GITLAB_PROJECT_ID):
+			require.Equal(t, "services", r.URL.Query().Get("path"))
+			id := map[string]string{"previous": "1111111111", "deadbeef": "2222222222"}[r.URL.Query().Get("ref")]
+			json.NewEncoder(w).Encode([]*gitlab.TreeNode{
+				{Name: "README.md", Type: "blob", ID: "cafe"},
+				{Name: "api", Type: "commit", ID: id},
+			})
+		case r.Method == "GET" && r.URL.Path == "/api/v4/projects/2001":
+			json.NewEncoder(w).Encode(gitlab.Project{ID: 2001, PathWithNamespace: "group/api", WebURL: "https://gitlab.example.com/group/api"})
+		case r.Method == "GET" && r.URL.Path == "/api/v4/projects/2001/repository/commits":
+			require.Equal(t, "1111111111...2222222222", r.URL.Query().Get("ref_name"))
+			json.NewEncoder(w).Encode([]*gitlab.Commit{
+				createGitlabCommit("2222222222", "fix: handle timeouts\n\nbody"),
+				createGitlabCommit("1234567890", "feat: add search"),
+			})
+		case r.Method == "GET" && r.URL.Path == "/api/v4/projects/2002":
+			json.NewEncoder(w).Encode(gitlab.Project{ID: 2002, PathWithNamespace: "group/docs", DefaultBranch: "main"})
+		case r.Method == "GET" && r.URL.Path == "/api/v4/projects/2002/repository/commits":
+			require.Equal(t, "main", r.URL.Query().Get("ref_name"))
+			require.Equal(t, "2024-01-02T03:04:06Z", r.URL.Query().Get("since"))
+			json.NewEncoder(w).Encode([]*gitlab.Commit{createGitlabCommit("3333333333", "docs: describe search")})
+		case r.Method == "GET" && r.URL.Path == "/api/v4/projects/2003":
+			json.NewEncoder(w).Encode(gitlab.Project{ID: 2003, PathWithNamespace: "group/unchanged", DefaultBranch: "main"})
+		case r.Method == "GET" && r.URL.Path == "/api/v4/projects/2003/repository/commits":
+			fmt.Fprint(w, "[]")
+		case r.Method == "POST" && r.URL.Path == fmt.Sprintf("/api/v4/projects/%d/releases", GITLAB_PROJECT_ID):
+			var opts gitlab.CreateReleaseOptions
+			json.NewDecoder(r.Body).Decode(&opts)
+			description = *opts.Description
+			fmt.Fprint(w, "{}")
+		default:
+			GitlabHandler(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	repo := &GitLabRepository{}
+	err := repo.Init(map[string]string{
+		"gitlab_baseurl":        ts.URL,
+		"token":                 "token",
+		"gitlab_projectid":      strconv.Itoa(GITLAB_PROJECT_ID),
+		"gitlab_child_projects": "2001:services/api, 2002, 2003",
+	})
+	require.NoError(t, err)
+	repo.commitsFrom = "previous"
+	repo.releaseDates = map[string]*time.Time{"previous": &released}
+
+	err = repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "2.0.0", SHA: "deadbeef", Changelog: "#### Feature\n\n* umbrella feature\n"})
+	require.NoError(t, err)
+	require.Equal(t, `#### Feature
+
+* umbrella feature
+
+### group/api
+
+[11111111...22222222](https://gitlab.example.com/group/api/-/compare/1111111111...2222222222)
+
+* fix: handle timeouts (22222222)
+* feat: add search (12345678)
+
+### group/docs
+
+* docs: describe search (33333333)
+`, description)
+
+	// the submodule did not change since the previous release
+	repo.childProjects = repo.childProjects[:1]
+	repo.commitsFrom = "deadbeef"
+	notes, err := repo.childProjectsNotes("deadbeef")
+	require.NoError(t, err)
+	require.Empty(t, notes)
+}
@@ -0,0 +1,76 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// ErrCircuitOpen is wrapped by the errors of requests which were not sent because too many requests failed in a row
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+type circuitOpenError struct {
+	failures []string
+}
+
+func (e *circuitOpenError) Error() string {
+	return fmt.Sprintf("giving up after %d consecutive failed requests:\n  - %s", len(e.failures), strings.Join(e.failures, "\n  - "))
+}
+
+func (e *circuitOpenError) Is(target error) bool {
+	return target == ErrCircuitOpen
+}
+
+// circuitBreakerTransport fails fast once threshold requests in a row failed with an error, 429 or 5xx, so a broken
+// instance does not cost hundreds of doomed requests. Any successful request closes the circuit again.
+type circuitBreakerTransport struct {
+	next      http.RoundTripper
+	threshold int
+
+	mu       sync.Mutex
+	failures []string
+}
+
+func (t *circuitBreakerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+	if len(t.failures) >= t.threshold {
+		err := &circuitOpenError{failures: append([]string(nil), t.failures...)}
+		t.mu.Unlock()
+		return nil, err
+	}
+	t.mu.Unlock()
+
+	resp, err := t.next.RoundTrip(req)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	switch {
+	case err != nil:
+		t.failures = append(t.failures, fmt.Sprintf("%s %s: %s", req.Method, endpointName(req.URL.EscapedPath()), err))
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		t.failures = append(t.failures, fmt.Sprintf("%s %s: %s", req.Method, endpointName(req.URL.EscapedPath()), resp.Status))
+	default:
+		t.failures = nil
+	}
+	return resp, err
+}
+
+func (t *circuitBreakerTransport) reset() {
+	t.mu.Lock()
+	t.failures = nil
+	t.mu.Unlock()
+}
+
+// retryPhase runs a read-only provider call again if it failed because the circuit opened and
+// gitlab_circuit_breaker_retry is set. CreateRelease is never retried as its steps are not idempotent.
+func (repo *GitLabRepository) retryPhase(name string, phase func() error) error {
+	err := phase()
+	if err == nil || repo.circuitBreaker == nil || !repo.circuitBreakerRetry || !errors.Is(err, ErrCircuitOpen) {
+		return err
+	}
+	repo.logger.Printf("WARNING: %s failed, retrying it once: %s", name, err)
+	repo.circuitBreaker.reset()
+	return phase()
+}
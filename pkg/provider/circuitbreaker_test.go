@@ -0,0 +1,89 @@
+package provider
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCircuitBreakerTransport(t *testing.T) {
+	status := http.StatusBadGateway
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer ts.Close()
+
+	breaker := &circuitBreakerTransport{next: http.DefaultTransport, threshold: 2}
+	client := &http.Client{Transport: breaker}
+	get := func() error {
+		resp, err := client.Get(ts.URL + "/api/v4/projects/1/repository/tags")
+		if err == nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+
+	// a success closes the circuit again
+	require.NoError(t, get())
+	status = http.StatusOK
+	require.NoError(t, get())
+	status = http.StatusBadGateway
+	require.NoError(t, get())
+	require.NoError(t, get())
+
+	err := get()
+	require.True(t, errors.Is(err, ErrCircuitOpen))
+	require.Contains(t, err.Error(), "giving up after 2 consecutive failed requests:\n  - GET projects/:id/repository/tags: 502 Bad Gateway\n  - GET projects/:id/repository/tags: 502 Bad Gateway")
+
+	breaker.reset()
+	status = http.StatusOK
+	require.NoError(t, get())
+}
+
+func newCircuitBreakerTestRepo(t *testing.T, retry bool, failures int) (*GitLabRepository, *bytes.Buffer) {
+	server, project := newFakeServer(t)
+	for i := 1; i <= 6; i++ {
+		sha := fmt.Sprintf("c%d", i)
+		server.AddCommit(project, sha, fmt.Sprintf("fix: change %d", i))
+		server.AddTag(project, fmt.Sprintf("v1.0.%d", i), sha)
+	}
+	server.Fail("GET /api/v4/projects/group/app/repository/tags", http.StatusInternalServerError, failures)
+
+	var logs bytes.Buffer
+	repo := initFakeRepo(t, &GitLabRepository{logger: log.New(&logs, "", 0)}, server, map[string]string{
+		"gitlab_circuit_breaker_threshold": "1",
+		"gitlab_circuit_breaker_retry":     strconv.FormatBool(retry),
+	})
+	return repo, &logs
+}
+
+func TestCircuitBreakerFailsFast(t *testing.T) {
+	repo, _ := newCircuitBreakerTestRepo(t, false, 1)
+
+	_, err := repo.GetReleases("")
+	require.True(t, errors.Is(err, ErrCircuitOpen))
+	require.Contains(t, err.Error(), "giving up after 1 consecutive failed requests:\n  - GET projects/:id/repository/tags: 500 Internal Server Error")
+}
+
+func TestCircuitBreakerRetriesPhase(t *testing.T) {
+	repo, logs := newCircuitBreakerTestRepo(t, true, 1)
+
+	releases, err := repo.GetReleases("")
+	require.NoError(t, err)
+	require.Len(t, releases, 6)
+	require.Contains(t, logs.String(), "WARNING: GetReleases failed, retrying it once: ")
+}
+
+func TestCircuitBreakerRetriesPhaseOnce(t *testing.T) {
+	repo, _ := newCircuitBreakerTestRepo(t, true, 3)
+
+	_, err := repo.GetReleases("")
+	require.True(t, errors.Is(err, ErrCircuitOpen))
+}
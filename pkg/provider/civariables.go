@@ -0,0 +1,136 @@
+package provider
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+// ciVariableName returns the name of the CI/CD variable an option is read from, e.g. GITLAB_TAG_PREFIX, or the
+// variable of gitlab_env_mapping
+func ciVariableName(config map[string]string, key string) string {
+	if mapping, err := parseEnvMappingConfig(config, "gitlab_env_mapping"); err == nil && mapping[key] != "" {
+		return mapping[key]
+	}
+	return strings.ToUpper(key)
+}
+
+// loadCIVariables fills empty options from the CI/CD variables of the project and its groups, variables of the
+// project take precedence over the ones of its groups and those of subgroups over the ones of their parents. Only
+// variables of all environments are considered, the token needs the Maintainer role to read them, groups it cannot
+// read are skipped. Like in the
+// repository config file, only the options of the messages and the release notes are read.
+func (repo *GitLabRepository) loadCIVariables(config map[string]string) (map[string]string, error) {
+	variables, err := repo.listCIVariables()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the CI/CD variables: %w", wrapAPIError(err))
+	}
+
+	merged := make(map[string]string, len(config))
+	for key, value := range config {
+		merged[key] = value
+	}
+	for _, option := range configOptions {
+		if merged[option.key] != "" || !projectOptions[option.key] {
+			continue
+		}
+		name := ciVariableName(config, option.key)
+		if value, ok := variables[name]; ok {
+			repo.debugf("set %s from the CI/CD variable %s", option.key, name)
+			merged[option.key] = value
+		}
+	}
+	return merged, nil
+}
+
+// listCIVariables returns the values of the CI/CD variables by name. Groups whose variables cannot be read are
+// skipped with a warning, protected variables are only used when the release branch is protected like in a pipeline.
+func (repo *GitLabRepository) listCIVariables() (map[string]string, error) {
+	project, err := repo.getProject()
+	if err != nil {
+		return nil, err
+	}
+
+	var protectedRef *bool
+	isProtectedRef := func() bool {
+		if protectedRef == nil {
+			protectedRef = new(bool)
+			branch, _, err := repo.api.GetBranch(repo.projectID, defaultString(repo.branch, project.DefaultBranch))
+			if err != nil {
+				repo.logger.Printf("WARNING: ignoring the protected CI/CD variables, failed to check whether the branch is protected: %s", err)
+			} else {
+				*protectedRef = branch.Protected
+			}
+		}
+		return *protectedRef
+	}
+
+	variables := make(map[string]string)
+	set := func(key, value string, variableType gitlab.VariableTypeValue, scope string, protected bool) {
+		if variableType != gitlab.EnvVariableType || scope != "" && scope != "*" {
+			return
+		}
+		if protected && !isProtectedRef() {
+			repo.debugf("ignoring the protected CI/CD variable %s on an unprotected branch", key)
+			return
+		}
+		variables[key] = value
+	}
+
+	// the groups from the top-level group to the namespace of the project, later ones override earlier ones
+	if project.Namespace != nil && project.Namespace.Kind == "group" {
+		parts := strings.Split(project.Namespace.FullPath, "/")
+		for i := range parts {
+			group := strings.Join(parts[:i+1], "/")
+			groupVariables, resp, err := repo.listGroupVariables(group)
+			switch {
+			case resp != nil && (resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusNotFound):
+				// the token may only be a member of the project or of a subgroup
+				repo.logger.Printf("WARNING: ignoring the CI/CD variables of group %s, they cannot be read: %s", group, err)
+				continue
+			case err != nil:
+				return nil, err
+			}
+			for _, v := range groupVariables {
+				set(v.Key, v.Value, v.VariableType, v.EnvironmentScope, v.Protected)
+			}
+		}
+	}
+
+	listOptions := repo.listOptions()
+	opts := (*gitlab.ListProjectVariablesOptions)(&listOptions)
+	for {
+		page, resp, err := repo.api.ListProjectVariables(repo.projectID, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range page {
+			set(v.Key, v.Value, v.VariableType, v.EnvironmentScope, v.Protected)
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	return variables, nil
+}
+
+// listGroupVariables returns all variables of the group
+func (repo *GitLabRepository) listGroupVariables(group string) ([]*gitlab.GroupVariable, *gitlab.Response, error) {
+	variables := make([]*gitlab.GroupVariable, 0)
+	listOptions := repo.listOptions()
+	opts := (*gitlab.ListGroupVariablesOptions)(&listOptions)
+	for {
+		page, resp, err := repo.api.ListGroupVariables(group, opts)
+		if err != nil {
+			return nil, resp, err
+		}
+		variables = append(variables, page...)
+		if resp.NextPage == 0 {
+			return variables, resp, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
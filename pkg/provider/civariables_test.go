@@ -0,0 +1,143 @@
+package provider
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xanzy/go-gitlab"
+)
+
+func TestGitlabCIVariables(t *testing.T) {
+	project := GITLAB_PROJECT
+	project.Namespace = &gitlab.ProjectNamespace{Kind: "group", FullPath: "platform/services"}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case fmt.Sprintf("/api/v4/projects/%d", GITLAB_PROJECT_ID):
+			json.NewEncoder(w).Encode(project) //nolint:errcheck
+		case "/api/v4/groups/platform/variables":
+			fmt.Fprint(w, `[
+				{"key": "GITLAB_SCOPES", "value": "group", "variable_type": "env_var", "environment_scope": "*"},
+				{"key": "GITLAB_CHANGELOG_MODE", "value": "fenced", "variable_type": "env_var", "environment_scope": "*"},
+				{"key": "GITLAB_COMMIT_MESSAGE_REPLACEMENT", "value": "dist", "variable_type": "env_var", "environment_scope": "*"}
+			]`)
+		case "/api/v4/groups/platform/services/variables":
+			fmt.Fprint(w, `[
+				{"key": "GITLAB_CHANGELOG_MODE", "value": "escape", "variable_type": "env_var", "environment_scope": "*"},
+				{"key": "GITLAB_COMMIT_MESSAGE_REPLACEMENT", "value": "staging", "variable_type": "env_var", "environment_scope": "staging"},
+				{"key": "TOKEN", "value": "stolen", "variable_type": "env_var", "environment_scope": "*"},
+				{"key": "GITLAB_API_PATH", "value": "/proxy/api/v4", "variable_type": "env_var", "environment_scope": "*"},
+				{"key": "GITLAB_SUDO", "value": "root", "variable_type": "env_var", "environment_scope": "*"},
+				{"key": "GITLAB_FAN_OUT_PROJECTS", "value": "other/project", "variable_type": "env_var", "environment_scope": "*"}
+			]`)
+		case fmt.Sprintf("/api/v4/projects/%d/variables", GITLAB_PROJECT_ID):
+			fmt.Fprint(w, `[
+				{"key": "GITLAB_SCOPES", "value": "api", "variable_type": "env_var", "environment_scope": "*"},
+				{"key": "GITLAB_TAG_PREFIX", "value": "api/", "variable_type": "env_var", "environment_scope": "*"},
+				{"key": "GITLAB_RELEASE_SUMMARY_FILE", "value": "summary.json", "variable_type": "file", "environment_scope": "*"}
+			]`)
+		default:
+			GitlabHandler(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	repo := &GitLabRepository{}
+	err := repo.Init(map[string]string{
+		"gitlab_baseurl":      ts.URL,
+		"token":               "token",
+		"gitlab_projectid":    strconv.Itoa(GITLAB_PROJECT_ID),
+		"gitlab_ci_variables": "true",
+		"gitlab_allow_update": "true",
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"api"}, repo.scopes)
+	require.Equal(t, changelogModeEscape, repo.changelogMode)
+	require.Equal(t, "dist", repo.messageReplacement)
+	require.Equal(t, "token", repo.token)
+	require.Nil(t, repo.apiPath)
+	require.Empty(t, repo.sudo)
+	require.Empty(t, repo.releaseSummaryFile)
+	require.Empty(t, repo.tagPrefix)
+	require.Empty(t, repo.fanOutProjects)
+	require.True(t, repo.allowUpdate)
+}
+
+func TestGitlabCIVariablesUnreadableGroupsAndProtected(t *testing.T) {
+	project := GITLAB_PROJECT
+	project.Namespace = &gitlab.ProjectNamespace{Kind: "group", FullPath: "platform/services"}
+	protectedBranch := false
+	var perPages []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case fmt.Sprintf("/api/v4/projects/%d", GITLAB_PROJECT_ID):
+			json.NewEncoder(w).Encode(project) //nolint:errcheck
+		case fmt.Sprintf("/api/v4/projects/%d/repository/branches/%s", GITLAB_PROJECT_ID, GITLAB_DEFAULTBRANCH):
+			json.NewEncoder(w).Encode(gitlab.Branch{Name: GITLAB_DEFAULTBRANCH, Protected: protectedBranch}) //nolint:errcheck
+		case "/api/v4/groups/platform/variables":
+			http.Error(w, `{"message":"403 Forbidden"}`, http.StatusForbidden)
+		case "/api/v4/groups/platform/services/variables":
+			http.Error(w, `{"message":"404 Group Not Found"}`, http.StatusNotFound)
+		case fmt.Sprintf("/api/v4/projects/%d/variables", GITLAB_PROJECT_ID):
+			perPages = append(perPages, r.URL.Query().Get("per_page"))
+			fmt.Fprint(w, `[
+				{"key": "GITLAB_SCOPES", "value": "api", "variable_type": "env_var", "environment_scope": "*"},
+				{"key": "GITLAB_CHANGELOG_MODE", "value": "fenced", "variable_type": "env_var", "environment_scope": "*", "protected": true}
+			]`)
+		default:
+			GitlabHandler(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	config := map[string]string{
+		"gitlab_baseurl":      ts.URL,
+		"token":               "token",
+		"gitlab_projectid":    strconv.Itoa(GITLAB_PROJECT_ID),
+		"gitlab_ci_variables": "true",
+		"gitlab_per_page":     "50",
+	}
+	var logs bytes.Buffer
+	repo := &GitLabRepository{logger: log.New(&logs, "", 0)}
+	require.NoError(t, repo.Init(config))
+	require.Equal(t, []string{"api"}, repo.scopes)
+	// protected variables are not available to pipelines of unprotected branches either
+	require.NotEqual(t, changelogModeFenced, repo.changelogMode)
+	require.Contains(t, logs.String(), "WARNING: ignoring the CI/CD variables of group platform, they cannot be read")
+	require.Contains(t, logs.String(), "WARNING: ignoring the CI/CD variables of group platform/services, they cannot be read")
+	require.Equal(t, []string{"50"}, perPages)
+
+	protectedBranch = true
+	repo = &GitLabRepository{logger: log.New(io.Discard, "", 0)}
+	require.NoError(t, repo.Init(config))
+	require.Equal(t, changelogModeFenced, repo.changelogMode)
+}
+
+func TestGitlabCIVariablesForbidden(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == fmt.Sprintf("/api/v4/projects/%d/variables", GITLAB_PROJECT_ID) {
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"message": "403 Forbidden"}`)
+			return
+		}
+		GitlabHandler(w, r)
+	}))
+	defer ts.Close()
+
+	repo := &GitLabRepository{}
+	err := repo.Init(map[string]string{
+		"gitlab_baseurl":      ts.URL,
+		"token":               "token",
+		"gitlab_projectid":    strconv.Itoa(GITLAB_PROJECT_ID),
+		"gitlab_ci_variables": "true",
+	})
+	require.ErrorIs(t, err, ErrPermissionDenied)
+	require.ErrorContains(t, err, "failed to list the CI/CD variables")
+}
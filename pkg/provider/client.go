@@ -0,0 +1,422 @@
+package provider
+
+import (
+	"io"
+	"net/url"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/xanzy/go-gitlab"
+)
+
+// Client is the part of the GitLab API the provider needs to read commits and releases and to create a release. It
+// is implemented by a *gitlab.Client for the configured instance unless another implementation, e.g. a fake, is
+// passed to NewGitLabRepository. List calls have to return a *gitlab.Response with the pagination of the result.
+//
+// Optional features like merge request comments, mirrors or the package registries use the configured instance.
+type Client interface {
+	GetProject(projectID string, opt *gitlab.GetProjectOptions) (*gitlab.Project, *gitlab.Response, error)
+	GetBranch(projectID, branch string) (*gitlab.Branch, *gitlab.Response, error)
+
+	ListCommits(projectID string, opt *gitlab.ListCommitsOptions) ([]*gitlab.Commit, *gitlab.Response, error)
+	GetCommit(projectID, sha string) (*gitlab.Commit, *gitlab.Response, error)
+	GetCommitDiff(projectID, sha string, opt *gitlab.GetCommitDiffOptions) ([]*gitlab.Diff, *gitlab.Response, error)
+	GetGPGSignature(projectID, sha string) (*gitlab.GPGSignature, *gitlab.Response, error)
+	Compare(projectID string, opt *gitlab.CompareOptions) (*gitlab.Compare, *gitlab.Response, error)
+
+	ListTags(projectID string, opt *gitlab.ListTagsOptions) ([]*gitlab.Tag, *gitlab.Response, error)
+	GetTag(projectID, tag string) (*gitlab.Tag, *gitlab.Response, error)
+	CreateTag(projectID string, opt *gitlab.CreateTagOptions) (*gitlab.Tag, *gitlab.Response, error)
+	DeleteTag(projectID, tag string) (*gitlab.Response, error)
+
+	ListReleases(projectID string, opt *gitlab.ListReleasesOptions) ([]*gitlab.Release, *gitlab.Response, error)
+	GetRelease(projectID, tag string) (*gitlab.Release, *gitlab.Response, error)
+	CreateRelease(projectID string, opt *gitlab.CreateReleaseOptions) (*gitlab.Release, *gitlab.Response, error)
+	UpdateRelease(projectID, tag string, opt *gitlab.UpdateReleaseOptions) (*gitlab.Release, *gitlab.Response, error)
+	DeleteRelease(projectID, tag string) (*gitlab.Release, *gitlab.Response, error)
+
+	ListReleaseLinks(projectID, tag string, opt *gitlab.ListReleaseLinksOptions) ([]*gitlab.ReleaseLink, *gitlab.Response, error)
+	CreateReleaseLink(projectID, tag string, opt *gitlab.CreateReleaseLinkOptions) (*gitlab.ReleaseLink, *gitlab.Response, error)
+	UpdateReleaseLink(projectID, tag string, link int, opt *gitlab.UpdateReleaseLinkOptions) (*gitlab.ReleaseLink, *gitlab.Response, error)
+}
+
+// apiClient adds the calls of the optional features to the Client, all requests to the instance of the project go
+// through it. Only mirrors on other instances, the read replica of gitlab_read_baseurl and the container registry use
+// clients of their own.
+type apiClient interface {
+	Client
+
+	ListBranches(projectID string, opt *gitlab.ListBranchesOptions) ([]*gitlab.Branch, *gitlab.Response, error)
+	CreateBranch(projectID string, opt *gitlab.CreateBranchOptions) (*gitlab.Branch, *gitlab.Response, error)
+	DeleteBranch(projectID, branch string) (*gitlab.Response, error)
+
+	CreateCommit(projectID string, opt *gitlab.CreateCommitOptions) (*gitlab.Commit, *gitlab.Response, error)
+	GetCommitRefs(projectID, sha string, opt *gitlab.GetCommitRefsOptions) ([]*gitlab.CommitRef, *gitlab.Response, error)
+	ListMergeRequestsByCommit(projectID, sha string) ([]*gitlab.MergeRequest, *gitlab.Response, error)
+
+	GetRawFile(projectID, fileName string, opt *gitlab.GetRawFileOptions) ([]byte, *gitlab.Response, error)
+	GetFileMetaData(projectID, fileName string, opt *gitlab.GetFileMetaDataOptions) (*gitlab.File, *gitlab.Response, error)
+	ListTree(projectID string, opt *gitlab.ListTreeOptions) ([]*gitlab.TreeNode, *gitlab.Response, error)
+
+	ListProjectMergeRequests(projectID string, opt *gitlab.ListProjectMergeRequestsOptions) ([]*gitlab.MergeRequest, *gitlab.Response, error)
+	CreateMergeRequest(projectID string, opt *gitlab.CreateMergeRequestOptions) (*gitlab.MergeRequest, *gitlab.Response, error)
+	UpdateMergeRequest(projectID string, mergeRequest int, opt *gitlab.UpdateMergeRequestOptions) (*gitlab.MergeRequest, *gitlab.Response, error)
+	GetIssuesClosedOnMerge(projectID string, mergeRequest int, opt *gitlab.GetIssuesClosedOnMergeOptions) ([]*gitlab.Issue, *gitlab.Response, error)
+	CreateMergeRequestNote(projectID string, mergeRequest int, opt *gitlab.CreateMergeRequestNoteOptions) (*gitlab.Note, *gitlab.Response, error)
+
+	ListProjectIssues(projectID string, opt *gitlab.ListProjectIssuesOptions) ([]*gitlab.Issue, *gitlab.Response, error)
+	CreateIssue(projectID string, opt *gitlab.CreateIssueOptions) (*gitlab.Issue, *gitlab.Response, error)
+	UpdateIssue(projectID string, issue int, opt *gitlab.UpdateIssueOptions) (*gitlab.Issue, *gitlab.Response, error)
+	CreateIssueNote(projectID string, issue int, opt *gitlab.CreateIssueNoteOptions) (*gitlab.Note, *gitlab.Response, error)
+
+	ListProtectedTags(projectID string, opt *gitlab.ListProtectedTagsOptions) ([]*gitlab.ProtectedTag, *gitlab.Response, error)
+	ProtectRepositoryTags(projectID string, opt *gitlab.ProtectRepositoryTagsOptions) (*gitlab.ProtectedTag, *gitlab.Response, error)
+	GetInheritedProjectMember(projectID string, user int) (*gitlab.ProjectMember, *gitlab.Response, error)
+	ListProjectVariables(projectID string, opt *gitlab.ListProjectVariablesOptions) ([]*gitlab.ProjectVariable, *gitlab.Response, error)
+	ListProjectPipelines(projectID string, opt *gitlab.ListProjectPipelinesOptions) ([]*gitlab.PipelineInfo, *gitlab.Response, error)
+	CreateProjectDeployment(projectID string, opt *gitlab.CreateProjectDeploymentOptions) (*gitlab.Deployment, *gitlab.Response, error)
+	CreateProjectSnippet(projectID string, opt *gitlab.CreateProjectSnippetOptions) (*gitlab.Snippet, *gitlab.Response, error)
+	GetWikiPage(projectID, slug string, opt *gitlab.GetWikiPageOptions) (*gitlab.Wiki, *gitlab.Response, error)
+	CreateWikiPage(projectID string, opt *gitlab.CreateWikiPageOptions) (*gitlab.Wiki, *gitlab.Response, error)
+	EditWikiPage(projectID, slug string, opt *gitlab.EditWikiPageOptions) (*gitlab.Wiki, *gitlab.Response, error)
+
+	ListProjectPackages(projectID string, opt *gitlab.ListProjectPackagesOptions) ([]*gitlab.Package, *gitlab.Response, error)
+	DeleteProjectPackage(projectID string, pkg int) (*gitlab.Response, error)
+
+	ListGroupProjects(groupID string, opt *gitlab.ListGroupProjectsOptions) ([]*gitlab.Project, *gitlab.Response, error)
+	ListGroupVariables(groupID string, opt *gitlab.ListGroupVariablesOptions) ([]*gitlab.GroupVariable, *gitlab.Response, error)
+	CurrentUser() (*gitlab.User, *gitlab.Response, error)
+	GetVersion() (*gitlab.Version, *gitlab.Response, error)
+	RenderMarkdown(opt *gitlab.RenderOptions) (*gitlab.Markdown, *gitlab.Response, error)
+
+	// NewRequest, UploadRequest and Do send the requests of endpoints without a method, e.g. the package registries
+	NewRequest(method, path string, opt interface{}, options []gitlab.RequestOptionFunc) (*retryablehttp.Request, error)
+	UploadRequest(method, path string, content io.Reader, filename string, uploadType gitlab.UploadType, opt interface{}, options []gitlab.RequestOptionFunc) (*retryablehttp.Request, error)
+	Do(req *retryablehttp.Request, v interface{}) (*gitlab.Response, error)
+	BaseURL() *url.URL
+}
+
+// NewGitLabRepository returns a repository which uses the client instead of the instance configured by Init
+func NewGitLabRepository(client Client) *GitLabRepository {
+	return &GitLabRepository{customClient: client}
+}
+
+// customClient sends the calls of the Client passed to NewGitLabRepository to it and the calls of the optional
+// features to the configured instance
+type customClient struct {
+	apiClient
+	client Client
+}
+
+func (c *customClient) GetProject(projectID string, opt *gitlab.GetProjectOptions) (*gitlab.Project, *gitlab.Response, error) {
+	return c.client.GetProject(projectID, opt)
+}
+
+func (c *customClient) GetBranch(projectID, branch string) (*gitlab.Branch, *gitlab.Response, error) {
+	return c.client.GetBranch(projectID, branch)
+}
+
+func (c *customClient) ListCommits(projectID string, opt *gitlab.ListCommitsOptions) ([]*gitlab.Commit, *gitlab.Response, error) {
+	return c.client.ListCommits(projectID, opt)
+}
+
+func (c *customClient) GetCommit(projectID, sha string) (*gitlab.Commit, *gitlab.Response, error) {
+	return c.client.GetCommit(projectID, sha)
+}
+
+func (c *customClient) GetCommitDiff(projectID, sha string, opt *gitlab.GetCommitDiffOptions) ([]*gitlab.Diff, *gitlab.Response, error) {
+	return c.client.GetCommitDiff(projectID, sha, opt)
+}
+
+func (c *customClient) GetGPGSignature(projectID, sha string) (*gitlab.GPGSignature, *gitlab.Response, error) {
+	return c.client.GetGPGSignature(projectID, sha)
+}
+
+func (c *customClient) Compare(projectID string, opt *gitlab.CompareOptions) (*gitlab.Compare, *gitlab.Response, error) {
+	return c.client.Compare(projectID, opt)
+}
+
+func (c *customClient) ListTags(projectID string, opt *gitlab.ListTagsOptions) ([]*gitlab.Tag, *gitlab.Response, error) {
+	return c.client.ListTags(projectID, opt)
+}
+
+func (c *customClient) GetTag(projectID, tag string) (*gitlab.Tag, *gitlab.Response, error) {
+	return c.client.GetTag(projectID, tag)
+}
+
+func (c *customClient) CreateTag(projectID string, opt *gitlab.CreateTagOptions) (*gitlab.Tag, *gitlab.Response, error) {
+	return c.client.CreateTag(projectID, opt)
+}
+
+func (c *customClient) DeleteTag(projectID, tag string) (*gitlab.Response, error) {
+	return c.client.DeleteTag(projectID, tag)
+}
+
+func (c *customClient) ListReleases(projectID string, opt *gitlab.ListReleasesOptions) ([]*gitlab.Release, *gitlab.Response, error) {
+	return c.client.ListReleases(projectID, opt)
+}
+
+func (c *customClient) GetRelease(projectID, tag string) (*gitlab.Release, *gitlab.Response, error) {
+	return c.client.GetRelease(projectID, tag)
+}
+
+func (c *customClient) CreateRelease(projectID string, opt *gitlab.CreateReleaseOptions) (*gitlab.Release, *gitlab.Response, error) {
+	return c.client.CreateRelease(projectID, opt)
+}
+
+func (c *customClient) UpdateRelease(projectID, tag string, opt *gitlab.UpdateReleaseOptions) (*gitlab.Release, *gitlab.Response, error) {
+	return c.client.UpdateRelease(projectID, tag, opt)
+}
+
+func (c *customClient) DeleteRelease(projectID, tag string) (*gitlab.Release, *gitlab.Response, error) {
+	return c.client.DeleteRelease(projectID, tag)
+}
+
+func (c *customClient) ListReleaseLinks(projectID, tag string, opt *gitlab.ListReleaseLinksOptions) ([]*gitlab.ReleaseLink, *gitlab.Response, error) {
+	return c.client.ListReleaseLinks(projectID, tag, opt)
+}
+
+func (c *customClient) CreateReleaseLink(projectID, tag string, opt *gitlab.CreateReleaseLinkOptions) (*gitlab.ReleaseLink, *gitlab.Response, error) {
+	return c.client.CreateReleaseLink(projectID, tag, opt)
+}
+
+func (c *customClient) UpdateReleaseLink(projectID, tag string, link int, opt *gitlab.UpdateReleaseLinkOptions) (*gitlab.ReleaseLink, *gitlab.Response, error) {
+	return c.client.UpdateReleaseLink(projectID, tag, link, opt)
+}
+
+// gitlabClient implements apiClient with the services of a *gitlab.Client, the body of every request can be replayed
+// by the maintenanceTransport
+type gitlabClient struct {
+	client *gitlab.Client
+}
+
+func (c *gitlabClient) GetProject(projectID string, opt *gitlab.GetProjectOptions) (*gitlab.Project, *gitlab.Response, error) {
+	return c.client.Projects.GetProject(projectID, opt, replayableBody)
+}
+
+func (c *gitlabClient) GetBranch(projectID, branch string) (*gitlab.Branch, *gitlab.Response, error) {
+	return c.client.Branches.GetBranch(projectID, branch, replayableBody)
+}
+
+func (c *gitlabClient) ListCommits(projectID string, opt *gitlab.ListCommitsOptions) ([]*gitlab.Commit, *gitlab.Response, error) {
+	return c.client.Commits.ListCommits(projectID, opt, replayableBody)
+}
+
+func (c *gitlabClient) GetCommit(projectID, sha string) (*gitlab.Commit, *gitlab.Response, error) {
+	return c.client.Commits.GetCommit(projectID, sha, replayableBody)
+}
+
+func (c *gitlabClient) GetCommitDiff(projectID, sha string, opt *gitlab.GetCommitDiffOptions) ([]*gitlab.Diff, *gitlab.Response, error) {
+	return c.client.Commits.GetCommitDiff(projectID, sha, opt, replayableBody)
+}
+
+func (c *gitlabClient) GetGPGSignature(projectID, sha string) (*gitlab.GPGSignature, *gitlab.Response, error) {
+	return c.client.Commits.GetGPGSiganature(projectID, sha, replayableBody)
+}
+
+func (c *gitlabClient) Compare(projectID string, opt *gitlab.CompareOptions) (*gitlab.Compare, *gitlab.Response, error) {
+	return c.client.Repositories.Compare(projectID, opt, replayableBody)
+}
+
+func (c *gitlabClient) ListTags(projectID string, opt *gitlab.ListTagsOptions) ([]*gitlab.Tag, *gitlab.Response, error) {
+	return c.client.Tags.ListTags(projectID, opt, replayableBody)
+}
+
+func (c *gitlabClient) GetTag(projectID, tag string) (*gitlab.Tag, *gitlab.Response, error) {
+	return c.client.Tags.GetTag(projectID, tag, replayableBody)
+}
+
+func (c *gitlabClient) CreateTag(projectID string, opt *gitlab.CreateTagOptions) (*gitlab.Tag, *gitlab.Response, error) {
+	return c.client.Tags.CreateTag(projectID, opt, replayableBody)
+}
+
+func (c *gitlabClient) DeleteTag(projectID, tag string) (*gitlab.Response, error) {
+	return c.client.Tags.DeleteTag(projectID, tag, replayableBody)
+}
+
+func (c *gitlabClient) ListReleases(projectID string, opt *gitlab.ListReleasesOptions) ([]*gitlab.Release, *gitlab.Response, error) {
+	return c.client.Releases.ListReleases(projectID, opt, replayableBody)
+}
+
+func (c *gitlabClient) GetRelease(projectID, tag string) (*gitlab.Release, *gitlab.Response, error) {
+	return c.client.Releases.GetRelease(projectID, tag, replayableBody)
+}
+
+func (c *gitlabClient) CreateRelease(projectID string, opt *gitlab.CreateReleaseOptions) (*gitlab.Release, *gitlab.Response, error) {
+	return c.client.Releases.CreateRelease(projectID, opt, replayableBody)
+}
+
+func (c *gitlabClient) UpdateRelease(projectID, tag string, opt *gitlab.UpdateReleaseOptions) (*gitlab.Release, *gitlab.Response, error) {
+	return c.client.Releases.UpdateRelease(projectID, tag, opt, replayableBody)
+}
+
+func (c *gitlabClient) DeleteRelease(projectID, tag string) (*gitlab.Release, *gitlab.Response, error) {
+	return c.client.Releases.DeleteRelease(projectID, tag, replayableBody)
+}
+
+func (c *gitlabClient) ListReleaseLinks(projectID, tag string, opt *gitlab.ListReleaseLinksOptions) ([]*gitlab.ReleaseLink, *gitlab.Response, error) {
+	return c.client.ReleaseLinks.ListReleaseLinks(projectID, tag, opt, replayableBody)
+}
+
+func (c *gitlabClient) CreateReleaseLink(projectID, tag string, opt *gitlab.CreateReleaseLinkOptions) (*gitlab.ReleaseLink, *gitlab.Response, error) {
+	return c.client.ReleaseLinks.CreateReleaseLink(projectID, tag, opt, replayableBody)
+}
+
+func (c *gitlabClient) UpdateReleaseLink(projectID, tag string, link int, opt *gitlab.UpdateReleaseLinkOptions) (*gitlab.ReleaseLink, *gitlab.Response, error) {
+	return c.client.ReleaseLinks.UpdateReleaseLink(projectID, tag, link, opt, replayableBody)
+}
+
+func (c *gitlabClient) ListBranches(projectID string, opt *gitlab.ListBranchesOptions) ([]*gitlab.Branch, *gitlab.Response, error) {
+	return c.client.Branches.ListBranches(projectID, opt, replayableBody)
+}
+
+func (c *gitlabClient) CreateBranch(projectID string, opt *gitlab.CreateBranchOptions) (*gitlab.Branch, *gitlab.Response, error) {
+	return c.client.Branches.CreateBranch(projectID, opt, replayableBody)
+}
+
+func (c *gitlabClient) DeleteBranch(projectID, branch string) (*gitlab.Response, error) {
+	return c.client.Branches.DeleteBranch(projectID, branch, replayableBody)
+}
+
+func (c *gitlabClient) CreateCommit(projectID string, opt *gitlab.CreateCommitOptions) (*gitlab.Commit, *gitlab.Response, error) {
+	return c.client.Commits.CreateCommit(projectID, opt, replayableBody)
+}
+
+func (c *gitlabClient) GetCommitRefs(projectID, sha string, opt *gitlab.GetCommitRefsOptions) ([]*gitlab.CommitRef, *gitlab.Response, error) {
+	return c.client.Commits.GetCommitRefs(projectID, sha, opt, replayableBody)
+}
+
+func (c *gitlabClient) ListMergeRequestsByCommit(projectID, sha string) ([]*gitlab.MergeRequest, *gitlab.Response, error) {
+	return c.client.Commits.ListMergeRequestsByCommit(projectID, sha, replayableBody)
+}
+
+func (c *gitlabClient) GetRawFile(projectID, fileName string, opt *gitlab.GetRawFileOptions) ([]byte, *gitlab.Response, error) {
+	return c.client.RepositoryFiles.GetRawFile(projectID, fileName, opt, replayableBody)
+}
+
+func (c *gitlabClient) GetFileMetaData(projectID, fileName string, opt *gitlab.GetFileMetaDataOptions) (*gitlab.File, *gitlab.Response, error) {
+	return c.client.RepositoryFiles.GetFileMetaData(projectID, fileName, opt, replayableBody)
+}
+
+func (c *gitlabClient) ListTree(projectID string, opt *gitlab.ListTreeOptions) ([]*gitlab.TreeNode, *gitlab.Response, error) {
+	return c.client.Repositories.ListTree(projectID, opt, replayableBody)
+}
+
+func (c *gitlabClient) ListProjectMergeRequests(projectID string, opt *gitlab.ListProjectMergeRequestsOptions) ([]*gitlab.MergeRequest, *gitlab.Response, error) {
+	return c.client.MergeRequests.ListProjectMergeRequests(projectID, opt, replayableBody)
+}
+
+func (c *gitlabClient) CreateMergeRequest(projectID string, opt *gitlab.CreateMergeRequestOptions) (*gitlab.MergeRequest, *gitlab.Response, error) {
+	return c.client.MergeRequests.CreateMergeRequest(projectID, opt, replayableBody)
+}
+
+func (c *gitlabClient) UpdateMergeRequest(projectID string, mergeRequest int, opt *gitlab.UpdateMergeRequestOptions) (*gitlab.MergeRequest, *gitlab.Response, error) {
+	return c.client.MergeRequests.UpdateMergeRequest(projectID, mergeRequest, opt, replayableBody)
+}
+
+func (c *gitlabClient) GetIssuesClosedOnMerge(projectID string, mergeRequest int, opt *gitlab.GetIssuesClosedOnMergeOptions) ([]*gitlab.Issue, *gitlab.Response, error) {
+	return c.client.MergeRequests.GetIssuesClosedOnMerge(projectID, mergeRequest, opt, replayableBody)
+}
+
+func (c *gitlabClient) CreateMergeRequestNote(projectID string, mergeRequest int, opt *gitlab.CreateMergeRequestNoteOptions) (*gitlab.Note, *gitlab.Response, error) {
+	return c.client.Notes.CreateMergeRequestNote(projectID, mergeRequest, opt, replayableBody)
+}
+
+func (c *gitlabClient) ListProjectIssues(projectID string, opt *gitlab.ListProjectIssuesOptions) ([]*gitlab.Issue, *gitlab.Response, error) {
+	return c.client.Issues.ListProjectIssues(projectID, opt, replayableBody)
+}
+
+func (c *gitlabClient) CreateIssue(projectID string, opt *gitlab.CreateIssueOptions) (*gitlab.Issue, *gitlab.Response, error) {
+	return c.client.Issues.CreateIssue(projectID, opt, replayableBody)
+}
+
+func (c *gitlabClient) UpdateIssue(projectID string, issue int, opt *gitlab.UpdateIssueOptions) (*gitlab.Issue, *gitlab.Response, error) {
+	return c.client.Issues.UpdateIssue(projectID, issue, opt, replayableBody)
+}
+
+func (c *gitlabClient) CreateIssueNote(projectID string, issue int, opt *gitlab.CreateIssueNoteOptions) (*gitlab.Note, *gitlab.Response, error) {
+	return c.client.Notes.CreateIssueNote(projectID, issue, opt, replayableBody)
+}
+
+func (c *gitlabClient) ListProtectedTags(projectID string, opt *gitlab.ListProtectedTagsOptions) ([]*gitlab.ProtectedTag, *gitlab.Response, error) {
+	return c.client.ProtectedTags.ListProtectedTags(projectID, opt, replayableBody)
+}
+
+func (c *gitlabClient) ProtectRepositoryTags(projectID string, opt *gitlab.ProtectRepositoryTagsOptions) (*gitlab.ProtectedTag, *gitlab.Response, error) {
+	return c.client.ProtectedTags.ProtectRepositoryTags(projectID, opt, replayableBody)
+}
+
+func (c *gitlabClient) GetInheritedProjectMember(projectID string, user int) (*gitlab.ProjectMember, *gitlab.Response, error) {
+	return c.client.ProjectMembers.GetInheritedProjectMember(projectID, user, replayableBody)
+}
+
+func (c *gitlabClient) ListProjectVariables(projectID string, opt *gitlab.ListProjectVariablesOptions) ([]*gitlab.ProjectVariable, *gitlab.Response, error) {
+	return c.client.ProjectVariables.ListVariables(projectID, opt, replayableBody)
+}
+
+func (c *gitlabClient) ListProjectPipelines(projectID string, opt *gitlab.ListProjectPipelinesOptions) ([]*gitlab.PipelineInfo, *gitlab.Response, error) {
+	return c.client.Pipelines.ListProjectPipelines(projectID, opt, replayableBody)
+}
+
+func (c *gitlabClient) CreateProjectDeployment(projectID string, opt *gitlab.CreateProjectDeploymentOptions) (*gitlab.Deployment, *gitlab.Response, error) {
+	return c.client.Deployments.CreateProjectDeployment(projectID, opt, replayableBody)
+}
+
+func (c *gitlabClient) CreateProjectSnippet(projectID string, opt *gitlab.CreateProjectSnippetOptions) (*gitlab.Snippet, *gitlab.Response, error) {
+	return c.client.ProjectSnippets.CreateSnippet(projectID, opt, replayableBody)
+}
+
+func (c *gitlabClient) GetWikiPage(projectID, slug string, opt *gitlab.GetWikiPageOptions) (*gitlab.Wiki, *gitlab.Response, error) {
+	return c.client.Wikis.GetWikiPage(projectID, slug, opt, replayableBody)
+}
+
+func (c *gitlabClient) CreateWikiPage(projectID string, opt *gitlab.CreateWikiPageOptions) (*gitlab.Wiki, *gitlab.Response, error) {
+	return c.client.Wikis.CreateWikiPage(projectID, opt, replayableBody)
+}
+
+func (c *gitlabClient) EditWikiPage(projectID, slug string, opt *gitlab.EditWikiPageOptions) (*gitlab.Wiki, *gitlab.Response, error) {
+	return c.client.Wikis.EditWikiPage(projectID, slug, opt, replayableBody)
+}
+
+func (c *gitlabClient) ListProjectPackages(projectID string, opt *gitlab.ListProjectPackagesOptions) ([]*gitlab.Package, *gitlab.Response, error) {
+	return c.client.Packages.ListProjectPackages(projectID, opt, replayableBody)
+}
+
+func (c *gitlabClient) DeleteProjectPackage(projectID string, pkg int) (*gitlab.Response, error) {
+	return c.client.Packages.DeleteProjectPackage(projectID, pkg, replayableBody)
+}
+
+func (c *gitlabClient) ListGroupProjects(groupID string, opt *gitlab.ListGroupProjectsOptions) ([]*gitlab.Project, *gitlab.Response, error) {
+	return c.client.Groups.ListGroupProjects(groupID, opt, replayableBody)
+}
+
+func (c *gitlabClient) ListGroupVariables(groupID string, opt *gitlab.ListGroupVariablesOptions) ([]*gitlab.GroupVariable, *gitlab.Response, error) {
+	return c.client.GroupVariables.ListVariables(groupID, opt, replayableBody)
+}
+
+func (c *gitlabClient) CurrentUser() (*gitlab.User, *gitlab.Response, error) {
+	return c.client.Users.CurrentUser(replayableBody)
+}
+
+func (c *gitlabClient) GetVersion() (*gitlab.Version, *gitlab.Response, error) {
+	return c.client.Version.GetVersion(replayableBody)
+}
+
+func (c *gitlabClient) RenderMarkdown(opt *gitlab.RenderOptions) (*gitlab.Markdown, *gitlab.Response, error) {
+	return c.client.Markdown.Render(opt, replayableBody)
+}
+
+func (c *gitlabClient) NewRequest(method, path string, opt interface{}, options []gitlab.RequestOptionFunc) (*retryablehttp.Request, error) {
+	return c.client.NewRequest(method, path, opt, append(options, replayableBody))
+}
+
+func (c *gitlabClient) UploadRequest(method, path string, content io.Reader, filename string, uploadType gitlab.UploadType, opt interface{}, options []gitlab.RequestOptionFunc) (*retryablehttp.Request, error) {
+	return c.client.UploadRequest(method, path, content, filename, uploadType, opt, append(options, replayableBody))
+}
+
+func (c *gitlabClient) Do(req *retryablehttp.Request, v interface{}) (*gitlab.Response, error) {
+	return c.client.Do(req, v)
+}
+
+func (c *gitlabClient) BaseURL() *url.URL {
+	return c.client.BaseURL()
+}
@@ -0,0 +1,145 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-semantic-release/semantic-release/v2/pkg/provider"
+	"github.com/go-semantic-release/semantic-release/v2/pkg/semrel"
+	"github.com/stretchr/testify/require"
+	"github.com/xanzy/go-gitlab"
+)
+
+// fakeClient serves a project from memory, calls of methods it does not override panic
+type fakeClient struct {
+	apiClient
+	releases          []*gitlab.CreateReleaseOptions
+	protectedTagLists int
+}
+
+func (c *fakeClient) GetProject(projectID string, opt *gitlab.GetProjectOptions) (*gitlab.Project, *gitlab.Response, error) {
+	return &gitlab.Project{PathWithNamespace: projectID, DefaultBranch: "main", Visibility: gitlab.PrivateVisibility}, &gitlab.Response{}, nil
+}
+
+func (c *fakeClient) ListCommits(projectID string, opt *gitlab.ListCommitsOptions) ([]*gitlab.Commit, *gitlab.Response, error) {
+	return []*gitlab.Commit{{ID: "beef", Message: "feat: fake"}}, &gitlab.Response{}, nil
+}
+
+func (c *fakeClient) ListTags(projectID string, opt *gitlab.ListTagsOptions) ([]*gitlab.Tag, *gitlab.Response, error) {
+	return []*gitlab.Tag{{Name: "v1.0.0", Commit: &gitlab.Commit{ID: "cafe"}}}, &gitlab.Response{}, nil
+}
+
+func (c *fakeClient) ListProtectedTags(projectID string, opt *gitlab.ListProtectedTagsOptions) ([]*gitlab.ProtectedTag, *gitlab.Response, error) {
+	c.protectedTagLists++
+	return []*gitlab.ProtectedTag{}, &gitlab.Response{}, nil
+}
+
+func (c *fakeClient) CreateRelease(projectID string, opt *gitlab.CreateReleaseOptions) (*gitlab.Release, *gitlab.Response, error) {
+	c.releases = append(c.releases, opt)
+	return &gitlab.Release{TagName: *opt.TagName}, &gitlab.Response{}, nil
+}
+
+func TestNewGitLabRepositoryWithFakeClient(t *testing.T) {
+	client := &fakeClient{}
+	repo := NewGitLabRepository(client)
+	err := repo.Init(map[string]string{"token": "token", "gitlab_projectid": "group/project"})
+	require.NoError(t, err)
+
+	info, err := repo.GetInfo()
+	require.NoError(t, err)
+	require.Equal(t, "main", info.DefaultBranch)
+
+	commits, err := repo.GetCommits("cafe", "beef")
+	require.NoError(t, err)
+	require.Equal(t, []*semrel.RawCommit{{SHA: "beef", RawMessage: "feat: fake"}}, commits)
+
+	releases, err := repo.GetReleases("")
+	require.NoError(t, err)
+	require.Equal(t, []*semrel.Release{{SHA: "cafe", Version: "1.0.0"}}, releases)
+
+	err = repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "1.1.0", SHA: "beef", Changelog: "changelog"})
+	require.NoError(t, err)
+	require.Len(t, client.releases, 1)
+	require.Equal(t, "v1.1.0", *client.releases[0].TagName)
+	require.Equal(t, "beef", *client.releases[0].Ref)
+	// the protected tag rules are only checked with gitlab_verify_access
+	require.Equal(t, 0, client.protectedTagLists)
+}
+
+func TestNewGitLabRepositoryWithReleaseClient(t *testing.T) {
+	var requested []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, r.URL.Path)
+		fmt.Fprint(w, `{"username": "release-bot"}`)
+	}))
+	defer ts.Close()
+
+	// a Client outside of this package only implements the release operations
+	client := &fakeClient{}
+	repo := NewGitLabRepository(struct{ Client }{client})
+	err := repo.Init(map[string]string{"gitlab_baseurl": ts.URL, "token": "token", "gitlab_projectid": "group/project"})
+	require.NoError(t, err)
+
+	info, err := repo.GetInfo()
+	require.NoError(t, err)
+	require.Equal(t, "main", info.DefaultBranch)
+	require.Empty(t, requested)
+
+	// the optional features use the configured instance
+	user, _, err := repo.api.CurrentUser()
+	require.NoError(t, err)
+	require.Equal(t, "release-bot", user.Username)
+	require.Contains(t, requested, "/api/v4/user")
+}
+
+// updateFakeClient serves an existing release with a link, every call goes through the Client
+type updateFakeClient struct {
+	fakeClient
+	createdLinks, updatedLinks []string
+}
+
+func (c *updateFakeClient) CreateRelease(projectID string, opt *gitlab.CreateReleaseOptions) (*gitlab.Release, *gitlab.Response, error) {
+	return nil, &gitlab.Response{Response: &http.Response{StatusCode: http.StatusConflict}}, errors.New("release already exists")
+}
+
+func (c *updateFakeClient) GetRelease(projectID, tag string) (*gitlab.Release, *gitlab.Response, error) {
+	return &gitlab.Release{TagName: tag, Name: tag}, &gitlab.Response{}, nil
+}
+
+func (c *updateFakeClient) UpdateRelease(projectID, tag string, opt *gitlab.UpdateReleaseOptions) (*gitlab.Release, *gitlab.Response, error) {
+	return &gitlab.Release{TagName: tag}, &gitlab.Response{}, nil
+}
+
+func (c *updateFakeClient) ListReleaseLinks(projectID, tag string, opt *gitlab.ListReleaseLinksOptions) ([]*gitlab.ReleaseLink, *gitlab.Response, error) {
+	return []*gitlab.ReleaseLink{{ID: 1, Name: "Docs", URL: "https://docs.example.com/old"}}, &gitlab.Response{}, nil
+}
+
+func (c *updateFakeClient) CreateReleaseLink(projectID, tag string, opt *gitlab.CreateReleaseLinkOptions) (*gitlab.ReleaseLink, *gitlab.Response, error) {
+	c.createdLinks = append(c.createdLinks, *opt.Name)
+	return &gitlab.ReleaseLink{}, &gitlab.Response{}, nil
+}
+
+func (c *updateFakeClient) UpdateReleaseLink(projectID, tag string, link int, opt *gitlab.UpdateReleaseLinkOptions) (*gitlab.ReleaseLink, *gitlab.Response, error) {
+	c.updatedLinks = append(c.updatedLinks, *opt.URL)
+	return &gitlab.ReleaseLink{}, &gitlab.Response{}, nil
+}
+
+func TestNewGitLabRepositoryWithFakeClientUpdatesLinks(t *testing.T) {
+	client := &updateFakeClient{}
+	repo := NewGitLabRepository(client)
+	err := repo.Init(map[string]string{
+		"token":               "token",
+		"gitlab_projectid":    "group/project",
+		"gitlab_allow_update": "true",
+		"gitlab_asset_links":  "Docs=https://docs.example.com/{{.Version}}, Download=https://example.com/{{.Tag}}.tar.gz",
+	})
+	require.NoError(t, err)
+
+	err = repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "1.0.0", SHA: "beef"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"https://docs.example.com/1.0.0"}, client.updatedLinks)
+	require.Equal(t, []string{"Download"}, client.createdLinks)
+}
@@ -0,0 +1,32 @@
+package provider
+
+import (
+	"strings"
+
+	"github.com/go-semantic-release/semantic-release/v2/pkg/semrel"
+	"github.com/xanzy/go-gitlab"
+)
+
+// rewriteCommitMessages replaces gitlab_commit_message_pattern in the messages before they are analyzed, e.g. to map
+// a legacy "JIRA-123: fix foo" format to "fix: foo (JIRA-123)". The replacement expands $1 or ${name} like
+// regexp.Expand.
+func (repo *GitLabRepository) rewriteCommitMessages(commits []*semrel.RawCommit) {
+	for _, commit := range commits {
+		message := repo.messagePattern.ReplaceAllString(commit.RawMessage, repo.messageReplacement)
+		if message != commit.RawMessage {
+			repo.debugf("rewrote the message of commit %s to %q", commit.SHA, message)
+			commit.RawMessage = message
+		}
+	}
+}
+
+// commitTitle returns the first line of the message. The REST API always sends the full message, so with
+// gitlab_commit_titles_only only the title is kept in memory.
+func commitTitle(commit *gitlab.Commit) string {
+	if commit.Title != "" {
+		return commit.Title
+	}
+	title, _, _ := strings.Cut(commit.Message, "\n")
+	// copy the title so the message can be freed
+	return string([]byte(title))
+}
@@ -0,0 +1,72 @@
+package provider
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/go-semantic-release/semantic-release/v2/pkg/semrel"
+	"github.com/stretchr/testify/require"
+	"github.com/xanzy/go-gitlab"
+)
+
+func TestRewriteCommitMessages(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(GitlabHandler))
+	defer ts.Close()
+
+	repo := &GitLabRepository{}
+	err := repo.Init(map[string]string{
+		"gitlab_baseurl":                    ts.URL,
+		"token":                             "token",
+		"gitlab_projectid":                  strconv.Itoa(GITLAB_PROJECT_ID),
+		"gitlab_commit_message_pattern":     `^(?i)fix: (.*)`,
+		"gitlab_commit_message_replacement": "fix: $1 (JIRA-123)",
+	})
+	require.NoError(t, err)
+
+	commits, err := repo.GetCommits("", "abcd")
+	require.NoError(t, err)
+	messages := make([]string, 0, len(commits))
+	for _, commit := range commits {
+		messages = append(messages, commit.RawMessage)
+	}
+	require.Equal(t, []string{
+		"feat(app): new feature",
+		"fix: bug (JIRA-123)",
+		"Initial commit",
+		"chore: break\nBREAKING CHANGE: breaks everything",
+	}, messages)
+}
+
+func TestRewriteLegacyCommitMessages(t *testing.T) {
+	repo := &GitLabRepository{}
+	repo.messagePattern, _ = parseRegexpConfig(map[string]string{"p": `^([A-Z]+-\d+): (\w+) (.*)`}, "p")
+	repo.messageReplacement = "$2: $3 ($1)"
+
+	commits := []*semrel.RawCommit{{SHA: "abcd", RawMessage: "JIRA-123: fix foo\n\nbody"}}
+	repo.rewriteCommitMessages(commits)
+	require.Equal(t, "fix: foo (JIRA-123)\n\nbody", commits[0].RawMessage)
+}
+
+func TestGitlabCommitTitlesOnly(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(GitlabHandler))
+	defer ts.Close()
+
+	repo := &GitLabRepository{}
+	err := repo.Init(map[string]string{
+		"gitlab_baseurl":            ts.URL,
+		"token":                     "token",
+		"gitlab_projectid":          strconv.Itoa(GITLAB_PROJECT_ID),
+		"gitlab_commit_titles_only": "true",
+	})
+	require.NoError(t, err)
+
+	commits, err := repo.GetCommits("", "")
+	require.NoError(t, err)
+	require.Len(t, commits, len(GITLAB_COMMITS))
+	require.Equal(t, "feat(app): new feature", commits[0].RawMessage)
+	require.Equal(t, "chore: break", commits[3].RawMessage)
+
+	require.Equal(t, "fix: bug", commitTitle(&gitlab.Commit{Title: "fix: bug", Message: "fix: bug\n\nbody"}))
+}
@@ -0,0 +1,50 @@
+package provider
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-semantic-release/semantic-release/v2/pkg/semrel"
+	"github.com/xanzy/go-gitlab"
+)
+
+// annotateCommitStats exposes the size of the commit and the paths it changed to commit analyzers, the paths are
+// separated by newlines and include both sides of renames
+func (repo *GitLabRepository) annotateCommitStats(client Client, raw *semrel.RawCommit, commit *gitlab.Commit) error {
+	paths := make(map[string]bool)
+	opts := &gitlab.GetCommitDiffOptions{Page: 1, PerPage: maxPerPage}
+	for {
+		diffs, resp, err := client.GetCommitDiff(repo.projectID, commit.ID, opts)
+		if err != nil {
+			return fmt.Errorf("failed to get the diff of commit %s: %w", commit.ID, err)
+		}
+		for _, diff := range diffs {
+			paths[diff.OldPath] = true
+			paths[diff.NewPath] = true
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	delete(paths, "")
+
+	changedPaths := make([]string, 0, len(paths))
+	for path := range paths {
+		changedPaths = append(changedPaths, path)
+	}
+	sort.Strings(changedPaths)
+
+	if raw.Annotations == nil {
+		raw.Annotations = make(map[string]string)
+	}
+	if commit.Stats != nil {
+		raw.Annotations["gitlab_additions"] = strconv.Itoa(commit.Stats.Additions)
+		raw.Annotations["gitlab_deletions"] = strconv.Itoa(commit.Stats.Deletions)
+	}
+	raw.Annotations["gitlab_files_changed"] = strconv.Itoa(len(changedPaths))
+	raw.Annotations["gitlab_changed_paths"] = strings.Join(changedPaths, "\n")
+	return nil
+}
@@ -0,0 +1,59 @@
+package provider
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommitStats(t *testing.T) {
+	var withStats string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		prefix := fmt.Sprintf("/api/v4/projects/%d/repository/commits", GITLAB_PROJECT_ID)
+		switch {
+		case r.URL.Path == prefix:
+			withStats = r.URL.Query().Get("with_stats")
+			fmt.Fprint(w, `[
+				{"id": "abcd", "message": "feat: api", "stats": {"additions": 10, "deletions": 2, "total": 12}},
+				{"id": "dcba", "message": "docs: readme", "stats": {"additions": 1, "deletions": 0, "total": 1}}
+			]`)
+		case r.URL.Path == prefix+"/abcd/diff":
+			if r.URL.Query().Get("page") == "1" {
+				w.Header().Set("X-Next-Page", "2")
+				fmt.Fprint(w, `[{"old_path": "api/old.go", "new_path": "api/new.go", "renamed_file": true}]`)
+				return
+			}
+			fmt.Fprint(w, `[{"old_path": "api/server.go", "new_path": "api/server.go"}]`)
+		case strings.HasPrefix(r.URL.Path, prefix+"/dcba/diff"):
+			fmt.Fprint(w, `[{"old_path": "README.md", "new_path": "README.md"}]`)
+		default:
+			GitlabHandler(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	repo := &GitLabRepository{}
+	err := repo.Init(map[string]string{
+		"gitlab_baseurl":      ts.URL,
+		"token":               "token",
+		"gitlab_projectid":    strconv.Itoa(GITLAB_PROJECT_ID),
+		"gitlab_commit_stats": "true",
+	})
+	require.NoError(t, err)
+
+	commits, err := repo.GetCommits("", "abcd")
+	require.NoError(t, err)
+	require.Equal(t, "true", withStats)
+	require.Equal(t, map[string]string{
+		"gitlab_additions":     "10",
+		"gitlab_deletions":     "2",
+		"gitlab_files_changed": "3",
+		"gitlab_changed_paths": "api/new.go\napi/old.go\napi/server.go",
+	}, commits[0].Annotations)
+	require.Equal(t, "README.md", commits[1].Annotations["gitlab_changed_paths"])
+}
@@ -0,0 +1,125 @@
+package provider
+
+import (
+	"fmt"
+
+	"github.com/go-semantic-release/semantic-release/v2/pkg/semrel"
+	"github.com/xanzy/go-gitlab"
+)
+
+const (
+	commitStrategyRefName   = "refname"
+	commitStrategyCompare   = "compare"
+	commitStrategySinceDate = "since-date"
+)
+
+func isValidCommitStrategy(strategy string) bool {
+	switch strategy {
+	case "", commitStrategyRefName, commitStrategyCompare, commitStrategySinceDate:
+		return true
+	}
+	return false
+}
+
+// listCommitRange returns the commits between the last release and the released commit with the strategy of
+// gitlab_commit_strategy and the newest commit before ignored authors were removed:
+//
+//   - refname lists the commits of from...to, which some instances answer slowly for large histories
+//   - compare uses the compare API, which answers with all commits at once but without pagination
+//   - since-date lists the history of the released commit after the commit date of the last release and trims it
+//     at the last release, it falls back to refname if branches merged since then have commits from before it
+func (repo *GitLabRepository) listCommitRange(client Client, fromSha, toSha string) ([]*semrel.RawCommit, string, error) {
+	switch {
+	case repo.commitStrategy == commitStrategyCompare && fromSha != "":
+		return repo.compareCommits(client, fromSha, toSha)
+	case repo.commitStrategy == commitStrategySinceDate && fromSha != "":
+		return repo.listCommitsSince(client, fromSha, toSha)
+	}
+	return repo.listCommits(client, fmt.Sprintf("%s...%s", fromSha, toSha))
+}
+
+func (repo *GitLabRepository) compareCommits(client Client, fromSha, toSha string) ([]*semrel.RawCommit, string, error) {
+	compare, _, err := client.Compare(repo.projectID, &gitlab.CompareOptions{From: &fromSha, To: &toSha})
+	if err != nil {
+		return nil, "", err
+	}
+	if compare.CompareTimeout {
+		return nil, "", fmt.Errorf("comparing %s and %s timed out, use another gitlab_commit_strategy", fromSha, toSha)
+	}
+
+	// the compare API lists the oldest commit first
+	commits := make([]*gitlab.Commit, len(compare.Commits))
+	for i, commit := range compare.Commits {
+		commits[len(commits)-1-i] = commit
+	}
+	head := ""
+	if len(commits) > 0 {
+		head = commits[0].ID
+	}
+
+	raw, ignored, err := repo.rawCommits(client, commits)
+	if err != nil {
+		return nil, "", err
+	}
+	repo.logIgnoredAuthors(ignored)
+	return raw, head, nil
+}
+
+// listCommitsSince is the fast path for active repositories, the date of the last release is usually known from
+// GetReleases so listing only takes the pages of the new commits. Merged branches with commits from before the last
+// release would be missed, the range of the release is listed instead when a parent of a new commit is not listed.
+func (repo *GitLabRepository) listCommitsSince(client Client, fromSha, toSha string) ([]*semrel.RawCommit, string, error) {
+	since := repo.releaseDates[fromSha]
+	if since == nil {
+		from, _, err := client.GetCommit(repo.projectID, fromSha)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to get commit %s: %w", fromSha, err)
+		}
+		since = from.CommittedDate
+	}
+	commits, err := repo.listCommitHistory(client, &gitlab.ListCommitsOptions{
+		RefName: gitlab.String(toSha),
+		Since:   since,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	refName := fmt.Sprintf("%s...%s", fromSha, toSha)
+
+	// the history continues with the last release and commits made at the same time
+	for i, commit := range commits {
+		if commit.ID != fromSha {
+			continue
+		}
+		if parent := unlistedParent(commits[:i], fromSha); parent != "" {
+			repo.logger.Printf("WARNING: commit %s merged since the last release was committed before %s, listing %s instead", shortSHA(parent), since, refName)
+			return repo.listCommits(client, refName)
+		}
+		raw, ignored, err := repo.rawCommits(client, commits[:i])
+		if err != nil {
+			return nil, "", err
+		}
+		repo.logIgnoredAuthors(ignored)
+		return raw, commits[0].ID, nil
+	}
+	// e.g. the clock of the committer was wrong
+	repo.debugf("commit %s is not in the commits since %s, listing %s", fromSha, since, refName)
+	return repo.listCommits(client, refName)
+}
+
+// unlistedParent returns a parent of the commits which is neither one of them nor the last release, e.g. the head of
+// a branch merged since the last release whose commits were made before it
+func unlistedParent(commits []*gitlab.Commit, fromSha string) string {
+	listed := map[string]bool{fromSha: true}
+	for _, commit := range commits {
+		listed[commit.ID] = true
+	}
+	for _, commit := range commits {
+		for _, parent := range commit.ParentIDs {
+			if !listed[parent] {
+				return parent
+			}
+		}
+	}
+	return ""
+}
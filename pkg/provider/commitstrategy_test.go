@@ -0,0 +1,164 @@
+package provider
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/go-semantic-release/provider-gitlab/pkg/gitlabtest"
+	"github.com/stretchr/testify/require"
+	"github.com/xanzy/go-gitlab"
+)
+
+// newCommitStrategyServer serves the history cdba, v1 (the release v1.0.0), dcba, abcd with one commit per hour
+func newCommitStrategyServer(t *testing.T) (*gitlabtest.Server, *gitlabtest.Project) {
+	server, project := newFakeServer(t)
+	released := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+	server.PushCommits(project,
+		&gitlab.Commit{ID: "cdba", Message: "Initial commit", CommittedDate: gitlab.Time(released.Add(-time.Hour))},
+		&gitlab.Commit{ID: "v1", Message: "chore: release", CommittedDate: &released},
+		&gitlab.Commit{ID: "dcba", Message: "Fix: bug", CommittedDate: gitlab.Time(released.Add(time.Hour))},
+		&gitlab.Commit{ID: "abcd", Message: "feat(app): new feature", CommittedDate: gitlab.Time(released.Add(2 * time.Hour))},
+	)
+	server.AddTag(project, "v1.0.0", "v1")
+	return server, project
+}
+
+const (
+	commitsRequest = "GET /api/v4/projects/group/app/repository/commits"
+	compareRequest = "GET /api/v4/projects/group/app/repository/compare"
+)
+
+func TestGitlabCommitStrategyCompare(t *testing.T) {
+	server, _ := newCommitStrategyServer(t)
+	repo := initFakeRepo(t, &GitLabRepository{}, server, map[string]string{"gitlab_commit_strategy": "compare"})
+
+	sent := len(server.Requests())
+	commits, err := repo.GetCommits("v1", "abcd")
+	require.NoError(t, err)
+	require.Equal(t, []string{compareRequest}, server.Requests()[sent:])
+	require.Equal(t, "v1", server.Queries(compareRequest)[0].Get("from"))
+	require.Equal(t, "abcd", server.Queries(compareRequest)[0].Get("to"))
+	require.Len(t, commits, 2)
+	require.Equal(t, "abcd", commits[0].SHA)
+	require.Equal(t, "dcba", commits[1].SHA)
+}
+
+func TestGitlabCommitStrategySinceDate(t *testing.T) {
+	server, _ := newCommitStrategyServer(t)
+	repo := initFakeRepo(t, &GitLabRepository{}, server, map[string]string{"gitlab_commit_strategy": "since-date"})
+
+	sent := len(server.Requests())
+	commits, err := repo.GetCommits("v1", "abcd")
+	require.NoError(t, err)
+	require.Equal(t, []string{"GET /api/v4/projects/group/app/repository/commits/v1", commitsRequest}, server.Requests()[sent:])
+	query := server.Queries(commitsRequest)[0]
+	require.Equal(t, "abcd", query.Get("ref_name"))
+	require.Equal(t, "2022-01-01T12:00:00Z", query.Get("since"))
+	// the history is trimmed at the last release
+	require.Len(t, commits, 2)
+	require.Equal(t, "abcd", commits[0].SHA)
+	require.Equal(t, "dcba", commits[1].SHA)
+}
+
+func TestGitlabCommitStrategySinceReleaseDate(t *testing.T) {
+	server, _ := newCommitStrategyServer(t)
+	repo := initFakeRepo(t, &GitLabRepository{}, server, map[string]string{"gitlab_commit_strategy": "since-date"})
+
+	_, err := repo.GetReleases("")
+	require.NoError(t, err)
+	sent := len(server.Requests())
+	commits, err := repo.GetCommits("v1", "abcd")
+	require.NoError(t, err)
+	// the date of the release is known from its tag
+	require.Equal(t, []string{commitsRequest}, server.Requests()[sent:])
+	require.Equal(t, "2022-01-01T12:00:00Z", server.Queries(commitsRequest)[0].Get("since"))
+	require.Len(t, commits, 2)
+}
+
+func TestGitlabCommitStrategySinceDateFallback(t *testing.T) {
+	server, _ := newCommitStrategyServer(t)
+	// the last release was made from a commit which is not in the history of the branch
+	server.HandleFunc("GET /api/v4/projects/group/app/repository/commits/efcd", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(gitlab.Commit{ID: "efcd", CommittedDate: gitlab.Time(time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC))}) //nolint:errcheck
+	})
+	repo := initFakeRepo(t, &GitLabRepository{}, server, map[string]string{"gitlab_commit_strategy": "since-date"})
+
+	sent := len(server.Requests())
+	commits, err := repo.GetCommits("efcd", "abcd")
+	require.NoError(t, err)
+	require.Equal(t, []string{"GET /api/v4/projects/group/app/repository/commits/efcd", commitsRequest, commitsRequest}, server.Requests()[sent:])
+	queries := server.Queries(commitsRequest)
+	require.Equal(t, "2022-01-01T12:00:00Z", queries[0].Get("since"))
+	require.Equal(t, "efcd...abcd", queries[1].Get("ref_name"))
+	require.Empty(t, queries[1].Get("since"))
+	require.Equal(t, "abcd", commits[0].SHA)
+}
+
+func TestGitlabCommitStrategySinceDateMergedBranch(t *testing.T) {
+	released := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+	var requests []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		prefix := fmt.Sprintf("/api/v4/projects/%d/repository/", GITLAB_PROJECT_ID)
+		switch r.URL.Path {
+		case prefix + "commits/v1":
+			json.NewEncoder(w).Encode(gitlab.Commit{ID: "v1", CommittedDate: &released}) //nolint:errcheck
+		case prefix + "commits":
+			requests = append(requests, "commits "+r.URL.Query().Get("ref_name")+" "+r.URL.Query().Get("since"))
+			if r.URL.Query().Get("since") != "" {
+				// the fix of the merged branch was committed before the release and is not listed
+				json.NewEncoder(w).Encode([]*gitlab.Commit{ //nolint:errcheck
+					{ID: "merge", Message: "Merge branch 'fix'", ParentIDs: []string{"v1", "fix"}},
+					{ID: "v1", Message: "chore: release"},
+				})
+				return
+			}
+			json.NewEncoder(w).Encode([]*gitlab.Commit{ //nolint:errcheck
+				{ID: "merge", Message: "Merge branch 'fix'", ParentIDs: []string{"v1", "fix"}},
+				{ID: "fix", Message: "fix: committed before the release", ParentIDs: []string{"v0"}},
+			})
+		default:
+			GitlabHandler(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	var logs bytes.Buffer
+	repo := &GitLabRepository{logger: log.New(&logs, "", 0)}
+	err := repo.Init(map[string]string{
+		"gitlab_baseurl":         ts.URL,
+		"token":                  "token",
+		"gitlab_projectid":       strconv.Itoa(GITLAB_PROJECT_ID),
+		"gitlab_commit_strategy": "since-date",
+	})
+	require.NoError(t, err)
+
+	commits, err := repo.GetCommits("v1", "merge")
+	require.NoError(t, err)
+	require.Equal(t, []string{"commits merge 2022-01-01T12:00:00Z", "commits v1...merge "}, requests)
+	require.Len(t, commits, 2)
+	require.Equal(t, "fix", commits[1].SHA)
+	require.Contains(t, logs.String(), "WARNING: commit fix merged since the last release was committed before 2022-01-01 12:00:00 +0000 UTC, listing v1...merge instead")
+}
+
+func TestGitlabCommitStrategyFirstRelease(t *testing.T) {
+	server, _ := newCommitStrategyServer(t)
+	repo := initFakeRepo(t, &GitLabRepository{}, server, map[string]string{"gitlab_commit_strategy": "compare"})
+
+	sent := len(server.Requests())
+	_, err := repo.GetCommits("", "abcd")
+	require.NoError(t, err)
+	require.Equal(t, []string{commitsRequest}, server.Requests()[sent:])
+	require.Equal(t, "...abcd", server.Queries(commitsRequest)[0].Get("ref_name"))
+}
+
+func TestGitlabCommitStrategyInvalid(t *testing.T) {
+	err := (&GitLabRepository{}).Init(map[string]string{"token": "token", "gitlab_projectid": "1", "gitlab_commit_strategy": "graph"})
+	require.EqualError(t, err, `failed to set property gitlab_commit_strategy: unknown strategy "graph", expected refname, compare or since-date`)
+}
@@ -0,0 +1,430 @@
+package provider
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// configOption describes an option of the provider, env are the environment variables consulted in order if the option
+// is empty
+type configOption struct {
+	key      string
+	env      []string
+	required bool
+	validate func(config map[string]string, key string) error
+}
+
+func check[T any](parse func(config map[string]string, key string) (T, error)) func(map[string]string, string) error {
+	return func(config map[string]string, key string) error {
+		_, err := parse(config, key)
+		return err
+	}
+}
+
+var (
+	checkBool     = check(parseBoolConfig)
+	checkInt      = check(parseIntConfig)
+	checkRegexp   = check(parseRegexpConfig)
+	checkTemplate = check(func(config map[string]string, key string) (*template.Template, error) {
+		return parseTemplateConfig(config, key, "")
+	})
+	checkDuration = check(func(config map[string]string, key string) (time.Duration, error) {
+		return parseDurationConfig(config, key, 0)
+	})
+)
+
+var configOptions = []*configOption{
+	{key: "gitlab_config_file"},
+	{key: "gitlab_repository_config_file"},
+	{key: "gitlab_ci_variables", validate: checkBool},
+	{key: "gitlab_connectivity_check", validate: checkBool},
+	{key: "gitlab_env_mapping", validate: check(parseEnvMappingConfig)},
+	{key: "gitlab_baseurl", env: []string{"CI_SERVER_URL", "GITLAB_BASEURL", "GITLAB_URL"}},
+	{key: "gitlab_api_path"},
+	{key: "gitlab_record_file"},
+	{key: "gitlab_replay_file"},
+	{key: "token", env: []string{"GITLAB_TOKEN"}, required: true},
+	{key: "gitlab_read_baseurl"},
+	{key: "gitlab_read_token", env: []string{"GITLAB_READ_TOKEN"}},
+	{key: "gitlab_read_token_type", validate: func(config map[string]string, key string) error {
+		switch config[key] {
+		case "", readTokenTypePrivate, readTokenTypeJob:
+			return nil
+		}
+		return fmt.Errorf("failed to set property %s: unknown token type %q", key, config[key])
+	}},
+	{key: "gitlab_branch", env: []string{"CI_COMMIT_BRANCH"}},
+	{key: "gitlab_projectid", env: []string{"CI_PROJECT_ID", "CI_PROJECT_PATH"}, required: true},
+	{key: "strip_v_tag_prefix", validate: checkBool},
+	{key: "gitlab_tag_prefix"},
+	{key: "gitlab_ref"},
+	{key: "gitlab_changelog_mode", validate: func(config map[string]string, key string) error {
+		if !isValidChangelogMode(config[key]) {
+			return fmt.Errorf("failed to set property %s: unknown mode %q", key, config[key])
+		}
+		return nil
+	}},
+	{key: "gitlab_changelog_snippet", validate: checkBool},
+	{key: "gitlab_changelog_source", validate: func(config map[string]string, key string) error {
+		if !isValidChangelogSource(config[key]) {
+			return fmt.Errorf("failed to set property %s: unknown source %q, expected semrel or gitlab", key, config[key])
+		}
+		return nil
+	}},
+	{key: "gitlab_changelog_trailer"},
+	{key: "gitlab_changelog_config_file"},
+	{key: "gitlab_changelog_file"},
+	{key: "gitlab_allow_update", validate: checkBool},
+	{key: "gitlab_update_mode", validate: func(config map[string]string, key string) error {
+		if !isValidUpdateMode(config[key]) {
+			return fmt.Errorf("failed to set property %s: unknown mode %q, expected replace, append or prepend", key, config[key])
+		}
+		return nil
+	}},
+	{key: "gitlab_tag_only", validate: checkBool},
+	{key: "gitlab_use_existing_tag", validate: checkBool},
+	{key: "gitlab_tag_message", validate: checkTemplate},
+	{key: "gitlab_tag_metadata", validate: checkBool},
+	{key: "gitlab_force_retag", validate: checkBool},
+	{key: "gitlab_protected_tag"},
+	{key: "gitlab_protected_tag_access", validate: check(parseTagAccessConfig)},
+	{key: "gitlab_rollback_on_failure", validate: checkBool},
+	{key: "gitlab_dry_run", validate: checkBool},
+	{key: "gitlab_strict_head_check", validate: checkBool},
+	{key: "gitlab_verify_release_sha", validate: checkBool},
+	{key: "gitlab_commit_signatures", validate: checkBool},
+	{key: "gitlab_commit_stats", validate: checkBool},
+	{key: "gitlab_graphql", validate: checkBool},
+	{key: "gitlab_commit_message_pattern", validate: checkRegexp},
+	{key: "gitlab_commit_message_replacement"},
+	{key: "gitlab_ignore_authors", validate: check(parseAuthorPatternsConfig)},
+	{key: "gitlab_scopes", validate: check(parseScopesConfig)},
+	{key: "gitlab_require_signed_commits", validate: checkBool},
+	{key: "gitlab_tag_signing_key"},
+	{key: "gitlab_tag_signing_format", validate: func(config map[string]string, key string) error {
+		for _, format := range append([]string{""}, signedTagFormats...) {
+			if config[key] == format {
+				return nil
+			}
+		}
+		return fmt.Errorf("failed to set property %s: unknown format %q, expected %s", key, config[key], strings.Join(signedTagFormats, ", "))
+	}},
+	{key: "gitlab_wait_for_pipeline", validate: checkBool},
+	{key: "gitlab_wait_for_merge_train", validate: checkBool},
+	{key: "gitlab_pipeline_timeout", validate: checkDuration},
+	{key: "gitlab_release_evidence", validate: func(config map[string]string, key string) error {
+		switch config[key] {
+		case "", evidenceModeWarn, evidenceModeRequire:
+			return nil
+		}
+		return fmt.Errorf("failed to set property %s: unknown mode %q, expected warn or require", key, config[key])
+	}},
+	{key: "gitlab_release_evidence_timeout", validate: checkDuration},
+	{key: "gitlab_assets"},
+	{key: "gitlab_assets_package"},
+	{key: "gitlab_asset_chunk_size", validate: check(parseSizeConfig)},
+	{key: "gitlab_asset_links", validate: check(parseAssetLinksConfig)},
+	{key: "gitlab_release_manifest", validate: checkBool},
+	{key: "gitlab_release_order", validate: func(config map[string]string, key string) error {
+		switch config[key] {
+		case "", releaseOrderVersion, releaseOrderUpdated:
+			return nil
+		}
+		return fmt.Errorf("failed to set property %s: unknown order %q, expected version or updated", key, config[key])
+	}},
+	{key: "gitlab_commit_strategy", validate: func(config map[string]string, key string) error {
+		if !isValidCommitStrategy(config[key]) {
+			return fmt.Errorf("failed to set property %s: unknown strategy %q, expected refname, compare or since-date", key, config[key])
+		}
+		return nil
+	}},
+	{key: "gitlab_commit_titles_only", validate: checkBool},
+	{key: "gitlab_release_summary_file"},
+	{key: "gitlab_release_latest", validate: checkBool},
+	{key: "gitlab_branch_channels", validate: check(parseBranchChannelsConfig)},
+	{key: "gitlab_prerelease_channels", validate: check(parseBranchChannelsConfig)},
+	{key: "gitlab_maintenance_branches", validate: check(parseBranchPatternsConfig)},
+	{key: "gitlab_prerelease_upcoming", validate: checkDuration},
+	{key: "gitlab_environment"},
+	{key: "gitlab_comment_merge_requests", validate: checkBool},
+	{key: "gitlab_merge_request_comment", validate: checkTemplate},
+	{key: "gitlab_release_issues", validate: checkBool},
+	{key: "gitlab_issue_label", validate: checkTemplate},
+	{key: "gitlab_issue_comment", validate: checkTemplate},
+	{key: "gitlab_failure_issue", validate: checkBool},
+	{key: "gitlab_failure_issue_label"},
+	{key: "gitlab_notify_url"},
+	{key: "gitlab_notify_secret"},
+	{key: "gitlab_notify_headers", validate: check(parseHeadersConfig)},
+	{key: "gitlab_audit_stream_url"},
+	{key: "gitlab_audit_stream_token", env: []string{"GITLAB_AUDIT_STREAM_TOKEN"}},
+	{key: "gitlab_audit_required", validate: checkBool},
+	{key: "gitlab_verify_access", validate: checkBool},
+	{key: "gitlab_token_expiry_window", validate: checkDuration},
+	{key: "gitlab_token_expiry_fail", validate: checkBool},
+	{key: "gitlab_version_files", validate: check(parseVersionFilesConfig)},
+	{key: "gitlab_version_files_message", validate: checkTemplate},
+	{key: "gitlab_release_merge_request", validate: checkBool},
+	{key: "gitlab_ci_catalog", validate: checkBool},
+	{key: "gitlab_release_branch", validate: checkTemplate},
+	{key: "gitlab_back_merge_branch"},
+	{key: "gitlab_helm_chart"},
+	{key: "gitlab_helm_channel"},
+	{key: "gitlab_terraform_module", validate: func(config map[string]string, key string) error {
+		_, _, err := parseTerraformModuleConfig(config, key)
+		return err
+	}},
+	{key: "gitlab_terraform_module_path"},
+	{key: "gitlab_container_image"},
+	{key: "gitlab_container_source_tag", validate: checkTemplate},
+	{key: "gitlab_container_tags", validate: check(func(config map[string]string, key string) ([]*template.Template, error) {
+		return parseTemplateListConfig(config, key, "")
+	})},
+	{key: "gitlab_container_registry_user"},
+	{key: "gitlab_package_retention", validate: checkInt},
+	{key: "gitlab_package_retention_name"},
+	{key: "gitlab_prerelease_cleanup", validate: func(config map[string]string, key string) error {
+		switch config[key] {
+		case "", prereleaseCleanupReleases, prereleaseCleanupTags:
+			return nil
+		}
+		return fmt.Errorf("failed to set property %s: unknown mode %q, expected releases or tags", key, config[key])
+	}},
+	{key: "gitlab_prerelease_cleanup_keep", validate: checkInt},
+	{key: "gitlab_wiki_page", validate: checkTemplate},
+	{key: "gitlab_wiki_index"},
+	{key: "gitlab_pages_branch"},
+	{key: "gitlab_pages_path"},
+	{key: "gitlab_skip_ci", validate: check(parseSkipCIConfig)},
+	{key: "gitlab_announcement_issue"},
+	{key: "gitlab_announcement_comment", validate: checkTemplate},
+	{key: "gitlab_mirrors"},
+	{key: "gitlab_fan_out_projects"},
+	{key: "gitlab_fan_out_allow_partial", validate: checkBool},
+	{key: "gitlab_child_projects", validate: check(parseChildProjectsConfig)},
+	{key: "gitlab_group_id"},
+	{key: "gitlab_group_include", validate: checkRegexp},
+	{key: "gitlab_group_exclude", validate: checkRegexp},
+	{key: "gitlab_metrics_summary", validate: checkBool},
+	{key: "gitlab_maintenance_timeout", validate: checkDuration},
+	{key: "gitlab_per_page", validate: check(parsePerPageConfig)},
+	{key: "gitlab_max_pages", validate: checkInt},
+	{key: "gitlab_concurrency", validate: checkInt},
+	{key: "gitlab_circuit_breaker_threshold", validate: checkInt},
+	{key: "gitlab_circuit_breaker_retry", validate: checkBool},
+	{key: "gitlab_user_agent"},
+	{key: "gitlab_request_headers", validate: check(func(config map[string]string, key string) (http.Header, error) {
+		return parseRequestHeadersConfig(config, key, "")
+	})},
+	{key: "gitlab_sudo"},
+	{key: "gitlab_log_level", validate: func(config map[string]string, key string) error {
+		switch config[key] {
+		case "", logLevelInfo, logLevelDebug:
+			return nil
+		}
+		return fmt.Errorf("failed to set property %s: unknown level %q", key, config[key])
+	}},
+}
+
+// configConflicts are pairs of boolean options which cannot be enabled together
+var configConflicts = []struct {
+	first, second, reason string
+}{
+	{"gitlab_tag_only", "gitlab_use_existing_tag", ""},
+	{"gitlab_force_retag", "gitlab_use_existing_tag", ""},
+	{"gitlab_tag_only", "gitlab_ci_catalog", "the catalog requires a release"},
+}
+
+func findConfigOption(key string) *configOption {
+	for _, option := range configOptions {
+		if option.key == key {
+			return option
+		}
+	}
+	return nil
+}
+
+// parseEnvMappingConfig parses a comma separated list of option=VARIABLE pairs, which override the environment
+// variables the options fall back to
+func parseEnvMappingConfig(config map[string]string, key string) (map[string]string, error) {
+	mapping := make(map[string]string)
+	for _, pair := range parseListConfig(config, key) {
+		name, variable, _ := strings.Cut(pair, "=")
+		name, variable = strings.TrimSpace(name), strings.TrimSpace(variable)
+		if name == "" || variable == "" {
+			return nil, fmt.Errorf("failed to set property %s: invalid mapping %q, expected option=VARIABLE", key, pair)
+		}
+		mapping[name] = variable
+	}
+	return mapping, nil
+}
+
+// applyEnvMapping fills empty options from the environment variables of gitlab_env_mapping, an invalid mapping is
+// reported by validateConfig
+func applyEnvMapping(config map[string]string) map[string]string {
+	mapping, err := parseEnvMappingConfig(config, "gitlab_env_mapping")
+	if err != nil || len(mapping) == 0 {
+		return config
+	}
+	mapped := make(map[string]string, len(config))
+	for key, value := range config {
+		mapped[key] = value
+	}
+	for key, variable := range mapping {
+		if mapped[key] == "" {
+			if value := os.Getenv(variable); value != "" {
+				mapped[key] = value
+			}
+		}
+	}
+	return mapped
+}
+
+// envVariables returns the environment variables the option falls back to, a mapping replaces the default variables
+func envVariables(config map[string]string, key string) []string {
+	if mapping, err := parseEnvMappingConfig(config, "gitlab_env_mapping"); err == nil && mapping[key] != "" {
+		return []string{mapping[key]}
+	}
+	if option := findConfigOption(key); option != nil {
+		return option.env
+	}
+	return nil
+}
+
+// describeEnvVariables names the variables for error messages, e.g. "the environment variable A or B"
+func describeEnvVariables(variables []string) string {
+	if len(variables) == 1 {
+		return "the environment variable " + variables[0]
+	}
+	return "the environment variables " + strings.Join(variables[:len(variables)-1], ", ") + " or " + variables[len(variables)-1]
+}
+
+// lookupConfig returns the value of the option or of its environment variable if the option is empty
+func lookupConfig(config map[string]string, key string) string {
+	if value := config[key]; value != "" {
+		return value
+	}
+	for _, variable := range envVariables(config, key) {
+		if value := os.Getenv(variable); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// validateConfig checks the whole config at once so all problems can be fixed in a single run, a single problem is
+// returned as it is
+func validateConfig(config map[string]string) error {
+	var problems []string
+
+	keys := make([]string, 0, len(config))
+	for key := range config {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if findConfigOption(key) != nil {
+			continue
+		}
+		problem := fmt.Sprintf("unknown option %s", key)
+		if suggestion := suggestConfigOption(key); suggestion != "" {
+			problem += fmt.Sprintf(", did you mean %s?", suggestion)
+		}
+		problems = append(problems, problem)
+	}
+
+	// the mapping is parsed by its validator, the options it names are checked here like the keys of the config
+	mapping, _ := parseEnvMappingConfig(config, "gitlab_env_mapping")
+	mapped := make([]string, 0, len(mapping))
+	for key := range mapping {
+		mapped = append(mapped, key)
+	}
+	sort.Strings(mapped)
+	for _, key := range mapped {
+		if findConfigOption(key) != nil {
+			continue
+		}
+		problem := fmt.Sprintf("gitlab_env_mapping maps unknown option %s", key)
+		if suggestion := suggestConfigOption(key); suggestion != "" {
+			problem += fmt.Sprintf(", did you mean %s?", suggestion)
+		}
+		problems = append(problems, problem)
+	}
+
+	for _, option := range configOptions {
+		if option.required && lookupConfig(config, option.key) == "" {
+			problems = append(problems, fmt.Sprintf("%s is required, neither the option nor %s is set", option.key, describeEnvVariables(envVariables(config, option.key))))
+		}
+		if option.validate != nil {
+			if err := option.validate(config, option.key); err != nil {
+				problems = append(problems, err.Error())
+			}
+		}
+	}
+
+	for _, conflict := range configConflicts {
+		first, _ := parseBoolConfig(config, conflict.first)
+		second, _ := parseBoolConfig(config, conflict.second)
+		if first && second {
+			problem := fmt.Sprintf("%s and %s cannot be used together", conflict.first, conflict.second)
+			if conflict.reason != "" {
+				problem += ", " + conflict.reason
+			}
+			problems = append(problems, problem)
+		}
+	}
+
+	switch len(problems) {
+	case 0:
+		return nil
+	case 1:
+		return fmt.Errorf("%s", problems[0])
+	}
+	return fmt.Errorf("invalid configuration:\n  - %s", strings.Join(problems, "\n  - "))
+}
+
+// suggestConfigOption returns the known option closest to the unknown key if it is likely a typo
+func suggestConfigOption(key string) string {
+	best, bestDistance := "", 4
+	for _, option := range configOptions {
+		if d := levenshtein(key, option.key); d < bestDistance {
+			best, bestDistance = option.key, d
+		}
+	}
+	return best
+}
+
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = minInt(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+func minInt(values ...int) int {
+	m := values[0]
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+	return m
+}
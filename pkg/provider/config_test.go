@@ -0,0 +1,92 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateConfig(t *testing.T) {
+	t.Setenv("GITLAB_TOKEN", "")
+	t.Setenv("CI_PROJECT_ID", "")
+	t.Setenv("CI_PROJECT_PATH", "")
+
+	err := validateConfig(map[string]string{
+		"token":                   "token",
+		"gitlab_projectid":        "1",
+		"gitlab_alow_update":      "true",
+		"something_else":          "x",
+		"gitlab_dry_run":          "maybe",
+		"gitlab_tag_only":         "true",
+		"gitlab_use_existing_tag": "true",
+	})
+	require.EqualError(t, err, "invalid configuration:\n"+
+		"  - unknown option gitlab_alow_update, did you mean gitlab_allow_update?\n"+
+		"  - unknown option something_else\n"+
+		"  - failed to set property gitlab_dry_run: strconv.ParseBool: parsing \"maybe\": invalid syntax\n"+
+		"  - gitlab_tag_only and gitlab_use_existing_tag cannot be used together")
+}
+
+func TestValidateConfigSingleProblem(t *testing.T) {
+	t.Setenv("GITLAB_TOKEN", "")
+	t.Setenv("CI_PROJECT_ID", "1")
+
+	err := validateConfig(map[string]string{})
+	require.EqualError(t, err, "token is required, neither the option nor the environment variable GITLAB_TOKEN is set")
+}
+
+func TestValidateConfigEnvFallback(t *testing.T) {
+	t.Setenv("GITLAB_TOKEN", "token")
+	t.Setenv("CI_PROJECT_ID", "1")
+
+	require.NoError(t, validateConfig(map[string]string{}))
+	require.Equal(t, "1", lookupConfig(map[string]string{}, "gitlab_projectid"))
+	require.Equal(t, "2", lookupConfig(map[string]string{"gitlab_projectid": "2"}, "gitlab_projectid"))
+}
+
+func TestEnvMapping(t *testing.T) {
+	t.Setenv("GITLAB_TOKEN", "default")
+	t.Setenv("MY_ORG_GITLAB_PAT", "pat")
+	t.Setenv("MY_ORG_SUDO", "release-bot")
+
+	config := applyEnvMapping(map[string]string{
+		"gitlab_env_mapping": "token=MY_ORG_GITLAB_PAT, gitlab_sudo=MY_ORG_SUDO, gitlab_branch=MY_ORG_BRANCH",
+		"gitlab_projectid":   "1",
+	})
+	require.Equal(t, "pat", lookupConfig(config, "token"))
+	require.Equal(t, "release-bot", config["gitlab_sudo"])
+	// a mapped variable replaces the default one
+	t.Setenv("CI_COMMIT_BRANCH", "main")
+	require.Equal(t, "", lookupConfig(config, "gitlab_branch"))
+	require.NoError(t, validateConfig(config))
+}
+
+func TestEnvMappingValidation(t *testing.T) {
+	t.Setenv("GITLAB_TOKEN", "")
+	t.Setenv("MY_ORG_GITLAB_PAT", "")
+
+	err := validateConfig(map[string]string{
+		"gitlab_env_mapping": "token=MY_ORG_GITLAB_PAT,gitlab_brnach=MY_ORG_BRANCH",
+		"gitlab_projectid":   "1",
+	})
+	require.EqualError(t, err, "invalid configuration:\n"+
+		"  - gitlab_env_mapping maps unknown option gitlab_brnach, did you mean gitlab_branch?\n"+
+		"  - token is required, neither the option nor the environment variable MY_ORG_GITLAB_PAT is set")
+
+	err = validateConfig(map[string]string{"token": "token", "gitlab_projectid": "1", "gitlab_env_mapping": "token"})
+	require.EqualError(t, err, "failed to set property gitlab_env_mapping: invalid mapping \"token\", expected option=VARIABLE")
+}
+
+func TestBaseURLEnvFallback(t *testing.T) {
+	t.Setenv("CI_SERVER_URL", "")
+	t.Setenv("GITLAB_BASEURL", "")
+	t.Setenv("GITLAB_URL", "https://gitlab.example.com")
+	require.Equal(t, "https://gitlab.example.com", lookupConfig(map[string]string{}, "gitlab_baseurl"))
+
+	t.Setenv("GITLAB_BASEURL", "https://base.example.com")
+	require.Equal(t, "https://base.example.com", lookupConfig(map[string]string{}, "gitlab_baseurl"))
+
+	// CI_SERVER_URL describes the instance running the pipeline and takes precedence
+	t.Setenv("CI_SERVER_URL", "https://ci.example.com")
+	require.Equal(t, "https://ci.example.com", lookupConfig(map[string]string{}, "gitlab_baseurl"))
+}
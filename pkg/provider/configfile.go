@@ -0,0 +1,108 @@
+package provider
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// loadConfigFile merges the options of gitlab_config_file into the config, options set directly take precedence. The
+// file is YAML or JSON, lists are joined with commas and maps become Name=Value lists, commas within the items are
+// escaped, so
+//
+//	gitlab_request_headers:
+//	  X-Team: platform
+//
+// equals gitlab_request_headers=X-Team=platform.
+func loadConfigFile(config map[string]string) (map[string]string, error) {
+	path := config["gitlab_config_file"]
+	if path == "" {
+		return config, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	options, err := parseConfigFile(content, path)
+	if err != nil {
+		return nil, err
+	}
+	return mergeConfigFile(config, options), nil
+}
+
+// parseConfigFile returns the options of the file content
+func parseConfigFile(content []byte, path string) (map[string]string, error) {
+	var values map[string]interface{}
+	if err := yaml.Unmarshal(content, &values); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	options := make(map[string]string, len(values))
+	for key, value := range values {
+		var err error
+		if options[key], err = configFileValue(value); err != nil {
+			return nil, fmt.Errorf("invalid option %s in config file %s: %w", key, path, err)
+		}
+	}
+	return options, nil
+}
+
+// mergeConfigFile merges the options of a config file into the config, options set in the config take precedence
+func mergeConfigFile(config, options map[string]string) map[string]string {
+	merged := make(map[string]string, len(options)+len(config))
+	for key, value := range options {
+		merged[key] = value
+	}
+	for key, value := range config {
+		if value != "" || merged[key] == "" {
+			merged[key] = value
+		}
+	}
+	return merged
+}
+
+func configFileValue(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			s, err := configFileScalar(item)
+			if err != nil {
+				return "", err
+			}
+			values = append(values, escapeListItem(s))
+		}
+		return strings.Join(values, ","), nil
+	case map[string]interface{}:
+		pairs := make([]string, 0, len(v))
+		for name, item := range v {
+			s, err := configFileScalar(item)
+			if err != nil {
+				return "", err
+			}
+			pairs = append(pairs, escapeListItem(name+"="+s))
+		}
+		sort.Strings(pairs)
+		return strings.Join(pairs, ","), nil
+	}
+	return configFileScalar(value)
+}
+
+// escapeListItem escapes the commas of a list item for parseListConfig
+func escapeListItem(item string) string {
+	return strings.ReplaceAll(item, ",", `\,`)
+}
+
+func configFileScalar(value interface{}) (string, error) {
+	switch value.(type) {
+	case []interface{}, map[string]interface{}:
+		return "", fmt.Errorf("nested lists and maps are not supported")
+	}
+	return fmt.Sprint(value), nil
+}
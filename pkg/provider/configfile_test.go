@@ -0,0 +1,80 @@
+package provider
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeTestConfigFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoadConfigFile(t *testing.T) {
+	path := writeTestConfigFile(t, "release.yaml", `
+gitlab_allow_update: true
+gitlab_package_retention: 3
+gitlab_version_files:
+  - package.json
+  - VERSION
+gitlab_request_headers:
+  X-Team: platform
+  X-Cost-Center: 42
+gitlab_tag_message: |
+  Release {{.Version}}
+gitlab_asset_links:
+  - Docs=https://docs.example.com/?v={{.Version}},latest
+gitlab_branch: main
+`)
+	config, err := loadConfigFile(map[string]string{
+		"gitlab_config_file": path,
+		"gitlab_branch":      "release",
+		"gitlab_environment": "",
+	})
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{
+		"gitlab_config_file":       path,
+		"gitlab_allow_update":      "true",
+		"gitlab_package_retention": "3",
+		"gitlab_version_files":     "package.json,VERSION",
+		"gitlab_request_headers":   "X-Cost-Center=42,X-Team=platform",
+		"gitlab_tag_message":       "Release {{.Version}}\n",
+		"gitlab_asset_links":       `Docs=https://docs.example.com/?v={{.Version}}\,latest`,
+		"gitlab_branch":            "release",
+		"gitlab_environment":       "",
+	}, config)
+}
+
+func TestLoadConfigFileJSON(t *testing.T) {
+	path := writeTestConfigFile(t, "release.json", `{"gitlab_projectid": 12324322, "gitlab_tag_only": false}`)
+	config, err := loadConfigFile(map[string]string{"gitlab_config_file": path})
+	require.NoError(t, err)
+	require.Equal(t, "12324322", config["gitlab_projectid"])
+	require.Equal(t, "false", config["gitlab_tag_only"])
+}
+
+func TestLoadConfigFileErrors(t *testing.T) {
+	_, err := loadConfigFile(map[string]string{"gitlab_config_file": "/does/not/exist.yaml"})
+	require.EqualError(t, err, "failed to read config file: open /does/not/exist.yaml: no such file or directory")
+
+	path := writeTestConfigFile(t, "nested.yaml", "gitlab_mirrors:\n  - [a, b]\n")
+	_, err = loadConfigFile(map[string]string{"gitlab_config_file": path})
+	require.EqualError(t, err, "invalid option gitlab_mirrors in config file "+path+": nested lists and maps are not supported")
+}
+
+func TestInitWithConfigFile(t *testing.T) {
+	path := writeTestConfigFile(t, "release.yaml", "gitlab_projectid: "+strconv.Itoa(GITLAB_PROJECT_ID)+"\ngitlab_alow_update: true\n")
+	err := (&GitLabRepository{}).Init(map[string]string{"token": "token", "gitlab_config_file": path})
+	require.EqualError(t, err, "unknown option gitlab_alow_update, did you mean gitlab_allow_update?")
+
+	path = writeTestConfigFile(t, "release.yaml", "gitlab_projectid: "+strconv.Itoa(GITLAB_PROJECT_ID)+"\ngitlab_tag_only: true\n")
+	repo := &GitLabRepository{}
+	require.NoError(t, repo.Init(map[string]string{"token": "token", "gitlab_config_file": path}))
+	require.Equal(t, strconv.Itoa(GITLAB_PROJECT_ID), repo.projectID)
+	require.True(t, repo.tagOnly)
+}
@@ -0,0 +1,22 @@
+package provider
+
+import (
+	"fmt"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+// createDeployment records a successful deployment of the release in the configured environment
+func (repo *GitLabRepository) createDeployment(tag, sha string) error {
+	_, _, err := repo.api.CreateProjectDeployment(repo.projectID, &gitlab.CreateProjectDeploymentOptions{
+		Environment: &repo.environment,
+		Ref:         &tag,
+		SHA:         &sha,
+		Tag:         gitlab.Bool(true),
+		Status:      gitlab.DeploymentStatus(gitlab.DeploymentStatusSuccess),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create deployment of %s in environment %s: %w", tag, repo.environment, err)
+	}
+	return nil
+}
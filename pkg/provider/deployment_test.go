@@ -0,0 +1,45 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/go-semantic-release/semantic-release/v2/pkg/provider"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGitlabCreateDeployment(t *testing.T) {
+	var deployment map[string]interface{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "POST" && r.URL.Path == fmt.Sprintf("/api/v4/projects/%d/deployments", GITLAB_PROJECT_ID) {
+			json.NewDecoder(r.Body).Decode(&deployment) //nolint:errcheck
+			fmt.Fprint(w, "{}")
+			return
+		}
+		GitlabHandler(w, r)
+	}))
+	defer ts.Close()
+
+	repo := &GitLabRepository{}
+	err := repo.Init(map[string]string{
+		"gitlab_baseurl":     ts.URL,
+		"token":              "gitlab-examples-ci",
+		"gitlab_projectid":   strconv.Itoa(GITLAB_PROJECT_ID),
+		"gitlab_environment": "production",
+	})
+	require.NoError(t, err)
+
+	err = repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "2.0.0", SHA: "deadbeef"})
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{
+		"environment": "production",
+		"ref":         "v2.0.0",
+		"sha":         "deadbeef",
+		"tag":         true,
+		"status":      "success",
+	}, deployment)
+}
@@ -0,0 +1,73 @@
+package provider
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+// checkConnectivity gets the project with the release token during Init, misconfigurations then fail with a precise
+// diagnosis instead of a confusing error of a later API call
+func (repo *GitLabRepository) checkConnectivity(baseURL string) error {
+	_, resp, err := repo.api.GetProject(repo.projectID, nil)
+	if err == nil {
+		repo.debugf("connected to %s, project %s is accessible", baseURL, repo.projectID)
+		return nil
+	}
+	return fmt.Errorf("connectivity check failed: %s: %w", repo.diagnose(baseURL, resp, err), wrapAPIError(err))
+}
+
+// diagnose explains why the request failed, from the name resolution to the permissions of the token
+func (repo *GitLabRepository) diagnose(baseURL string, resp *gitlab.Response, err error) string {
+	host := baseURL
+	if u, parseErr := url.Parse(baseURL); parseErr == nil && u.Host != "" {
+		host = u.Hostname()
+	}
+
+	var dnsErr *net.DNSError
+	var unknownAuthority x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	var invalidCert x509.CertificateInvalidError
+	var recordHeaderErr tls.RecordHeaderError
+	var opErr *net.OpError
+	switch {
+	case errors.As(err, &dnsErr):
+		return fmt.Sprintf("the host %s of gitlab_baseurl cannot be resolved", host)
+	case errors.As(err, &unknownAuthority):
+		return fmt.Sprintf("the certificate of %s is signed by an unknown authority, add the CA to the system trust store or SSL_CERT_FILE", host)
+	case errors.As(err, &hostnameErr):
+		return fmt.Sprintf("the certificate of %s is not valid for the host name", host)
+	case errors.As(err, &invalidCert):
+		return fmt.Sprintf("the certificate of %s is invalid or expired", host)
+	case errors.As(err, &recordHeaderErr):
+		return fmt.Sprintf("%s does not speak TLS, check the scheme of gitlab_baseurl", host)
+	case errors.As(err, &opErr):
+		return fmt.Sprintf("cannot connect to %s, check gitlab_baseurl, proxies and firewalls", host)
+	case resp == nil:
+		return fmt.Sprintf("the request to %s failed", host)
+	}
+
+	if !strings.Contains(resp.Header.Get("Content-Type"), "json") {
+		return fmt.Sprintf("%s did not answer like the GitLab API, check gitlab_baseurl and gitlab_api_path", baseURL)
+	}
+	switch resp.StatusCode {
+	case http.StatusUnauthorized:
+		return "the token was rejected, check that it is valid, not revoked and not expired"
+	case http.StatusForbidden:
+		return fmt.Sprintf("the token may not access project %s, it needs the api scope and at least the developer role", repo.projectID)
+	case http.StatusNotFound:
+		// the version endpoint exists on every instance, a 404 there means the API is not at the base URL
+		if _, versionResp, versionErr := repo.api.GetVersion(); versionErr != nil && versionResp != nil && versionResp.StatusCode == http.StatusNotFound {
+			return fmt.Sprintf("%s does not serve the GitLab API, check gitlab_baseurl and gitlab_api_path", baseURL)
+		}
+		return fmt.Sprintf("project %s does not exist or the token cannot see it, check gitlab_projectid", repo.projectID)
+	}
+	return fmt.Sprintf("GitLab answered with status %d", resp.StatusCode)
+}
@@ -0,0 +1,113 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func initConnectivityCheck(baseURL string) error {
+	repo := &GitLabRepository{}
+	return repo.Init(map[string]string{
+		"gitlab_baseurl":            baseURL,
+		"token":                     "token",
+		"gitlab_projectid":          strconv.Itoa(GITLAB_PROJECT_ID),
+		"gitlab_connectivity_check": "true",
+	})
+}
+
+// jsonError answers like the GitLab API, http.Error would send a text/plain content type
+func jsonError(w http.ResponseWriter, status int, body string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	fmt.Fprint(w, body)
+}
+
+func TestGitlabConnectivityCheck(t *testing.T) {
+	projectPath := fmt.Sprintf("/api/v4/projects/%d", GITLAB_PROJECT_ID)
+	for _, tc := range []struct {
+		name      string
+		handler   http.HandlerFunc
+		diagnosis string
+		kind      error
+	}{
+		{
+			name: "unauthorized",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				jsonError(w, http.StatusUnauthorized, `{"message":"401 Unauthorized"}`)
+			},
+			diagnosis: "the token was rejected",
+			kind:      ErrAuthentication,
+		},
+		{
+			name: "forbidden",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				jsonError(w, http.StatusForbidden, `{"message":"403 Forbidden"}`)
+			},
+			diagnosis: fmt.Sprintf("the token may not access project %d", GITLAB_PROJECT_ID),
+			kind:      ErrPermissionDenied,
+		},
+		{
+			name: "project not found",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == projectPath {
+					jsonError(w, http.StatusNotFound, `{"message":"404 Project Not Found"}`)
+					return
+				}
+				GitlabHandler(w, r)
+			},
+			diagnosis: fmt.Sprintf("project %d does not exist or the token cannot see it", GITLAB_PROJECT_ID),
+			kind:      ErrProjectNotFound,
+		},
+		{
+			name: "no api",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				jsonError(w, http.StatusNotFound, `{"error":"404 Not Found"}`)
+			},
+			diagnosis: "does not serve the GitLab API",
+			kind:      ErrNotFound,
+		},
+		{
+			name: "html page",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/html")
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprint(w, "<html>not found</html>")
+			},
+			diagnosis: "did not answer like the GitLab API",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ts := httptest.NewServer(tc.handler)
+			defer ts.Close()
+
+			err := initConnectivityCheck(ts.URL)
+			require.ErrorContains(t, err, "connectivity check failed: ")
+			require.ErrorContains(t, err, tc.diagnosis)
+			if tc.kind != nil {
+				require.True(t, errors.Is(err, tc.kind), err.Error())
+			}
+		})
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(GitlabHandler))
+	defer ts.Close()
+	require.NoError(t, initConnectivityCheck(ts.URL))
+}
+
+func TestGitlabConnectivityCheckTLS(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(GitlabHandler))
+	defer ts.Close()
+	err := initConnectivityCheck(ts.URL)
+	require.ErrorContains(t, err, "is signed by an unknown authority")
+
+	closed := httptest.NewServer(http.HandlerFunc(GitlabHandler))
+	closed.Close()
+	err = initConnectivityCheck(closed.URL)
+	require.ErrorContains(t, err, "cannot connect to 127.0.0.1")
+}
@@ -0,0 +1,203 @@
+package provider
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/go-semantic-release/semantic-release/v2/pkg/provider"
+)
+
+const dryRunDescriptionLength = 300
+
+// logDryRun logs everything CreateRelease would create without calling any mutating endpoint
+func (repo *GitLabRepository) logDryRun(release *provider.CreateReleaseConfig) error {
+	tag := repo.tagName(release.NewVersion)
+	if repo.ref != "" {
+		// resolving the ref does not change the repository, the logged commit is the one a real run tags
+		sha, err := repo.resolveRef(repo.ref)
+		if err != nil {
+			return err
+		}
+		repo.logger.Printf("dry run: would release %s from %s at %s instead of %s", tag, repo.ref, sha, release.SHA)
+		release = withSHA(release, sha)
+	}
+	if repo.verifyReleaseSHA {
+		// reading the commit does not change the repository
+		if err := repo.validateReleaseSHA(defaultString(repo.branch, release.Branch), release.SHA); err != nil {
+			return err
+		}
+	}
+	if repo.releaseMergeRequest {
+		repo.logger.Printf("dry run: would open a release merge request for %s and create the tag once it is merged", tag)
+	}
+	if repo.protectedTag != "" {
+		repo.logger.Printf("dry run: would make sure tags matching %s are protected for %ss", repo.protectedTag, accessLevelName(repo.protectedTagAccess))
+	}
+	repo.logger.Printf("dry run: would create tag %s at %s in project %s", tag, release.SHA, repo.projectID)
+
+	for _, file := range repo.versionFiles {
+		repo.logger.Printf("dry run: would update the version in %s", file.path)
+	}
+
+	if repo.tagMessage != nil {
+		message, err := renderTemplate(repo.tagMessage, newTemplateData(tag, release))
+		if err != nil {
+			return err
+		}
+		repo.logger.Printf("dry run: tag message:\n%s", truncate(message, dryRunDescriptionLength))
+	}
+	if repo.tagMetadata {
+		repo.logger.Printf("dry run: tag metadata:\n%s", repo.newTagMetadata(release).trailers())
+	}
+
+	if repo.helmChart != "" {
+		repo.logger.Printf("dry run: would publish helm chart %s to channel %s", repo.helmChart, repo.helmChannel)
+	}
+
+	if repo.terraformModuleName != "" {
+		repo.logger.Printf("dry run: would publish terraform module %s/%s from %s", repo.terraformModuleName, repo.terraformModuleSystem, repo.terraformModulePath)
+	}
+
+	if len(repo.assets) > 0 {
+		repo.logger.Printf("dry run: would upload %s to the generic package %s", strings.Join(repo.assets, ", "), repo.assetsPackage)
+	}
+
+	if len(repo.assetLinks) > 0 {
+		if err := repo.addAssetLinks(tag, release); err != nil {
+			return err
+		}
+		for _, link := range repo.releaseLinks {
+			repo.logger.Printf("dry run: would link %s to %s", *link.Name, *link.URL)
+		}
+	}
+
+	if repo.releaseManifest && !repo.tagOnly {
+		repo.logger.Printf("dry run: would upload %s with %d commits to the generic package %s", releaseManifestFile, len(repo.commits), repo.assetsPackage)
+	}
+
+	if repo.containerImage != "" {
+		repo.logger.Printf("dry run: would tag container image %s with the release version", repo.containerImage)
+	}
+
+	for _, mirror := range repo.mirrors {
+		repo.logger.Printf("dry run: would create tag %s and the release in mirror %s", tag, mirror.projectID)
+	}
+
+	for _, target := range repo.fanOutProjects {
+		repo.logger.Printf("dry run: would create tag %s and the release in project %s", tag, target.projectID)
+	}
+
+	if repo.groupID != "" {
+		repo.logger.Printf("dry run: would create tag %s and the release in the projects of group %s", tag, repo.groupID)
+	}
+
+	if repo.evidenceMode != "" && !repo.tagOnly {
+		repo.logger.Printf("dry run: would verify the evidence of release %s (%s)", tag, repo.evidenceMode)
+	}
+	if repo.environment != "" {
+		repo.logger.Printf("dry run: would create a deployment of %s at %s in environment %s", tag, release.SHA, repo.environment)
+	}
+
+	if repo.releaseBranch != nil {
+		branch, err := renderTemplate(repo.releaseBranch, newTemplateData(tag, release))
+		if err != nil {
+			return err
+		}
+		repo.logger.Printf("dry run: would create release branch %s", branch)
+	}
+	if repo.backMergeBranch != "" {
+		repo.logger.Printf("dry run: would open a back-merge request into %s", repo.backMergeBranch)
+	}
+
+	data := repo.releaseTemplateData(tag, release)
+	if repo.mergeRequestComment != nil {
+		repo.logger.Printf("dry run: would comment on the merge requests released in %s", tag)
+	}
+	if repo.issueComment != nil {
+		repo.logger.Printf("dry run: would comment on the issues closed by the merge requests released in %s", tag)
+		if repo.issueLabel != nil {
+			label, err := renderTemplate(repo.issueLabel, data)
+			if err != nil {
+				return err
+			}
+			repo.logger.Printf("dry run: would label the released issues with %s", label)
+		}
+	}
+	if repo.wikiPage != nil {
+		title, err := renderTemplate(repo.wikiPage, data)
+		if err != nil {
+			return err
+		}
+		repo.logger.Printf("dry run: would write the release notes of %s to wiki page %s", tag, title)
+	}
+
+	if repo.pagesBranch != "" && !repo.tagOnly {
+		repo.logger.Printf("dry run: would publish the changelog site to %s in branch %s", repo.pagesPath, repo.pagesBranch)
+	}
+	if repo.announcementIssue != "" {
+		repo.logger.Printf("dry run: would announce %s in issue %s", tag, repo.announcementIssue)
+	}
+	if repo.changelogFile != "" {
+		repo.logger.Printf("dry run: would commit the changelog of %s to %s", release.NewVersion, repo.changelogFile)
+	}
+
+	if repo.packageRetention > 0 {
+		repo.logger.Printf("dry run: would delete the outdated prerelease packages except for the newest %d", repo.packageRetention)
+	}
+	if repo.prereleaseCleanup != "" && !release.Prerelease {
+		repo.logger.Printf("dry run: would delete the %s of the prereleases of %s", repo.prereleaseCleanup, release.NewVersion)
+	}
+	if repo.notifyURL != "" {
+		repo.logger.Printf("dry run: would send the release notification to %s", urlHost(repo.notifyURL))
+	}
+	if repo.auditStreamURL != "" {
+		repo.logger.Printf("dry run: would send the audit event of %s to %s", tag, urlHost(repo.auditStreamURL))
+	}
+	if repo.releaseSummaryFile != "" {
+		repo.logger.Printf("dry run: would write the release summary to %s", repo.releaseSummaryFile)
+	}
+
+	if len(repo.maintenanceBranches) > 0 {
+		repo.logger.Printf("dry run: would release the maintenance branches matching %s", strings.Join(repo.maintenanceBranches, ", "))
+	}
+
+	if repo.tagOnly {
+		return nil
+	}
+
+	if repo.changelogSource == changelogSourceGitLab {
+		// generating the changelog does not change the repository
+		changelog, err := repo.gitlabChangelog(release)
+		if err != nil {
+			return err
+		}
+		release = withChangelog(release, changelog)
+	}
+	if len(repo.childProjects) > 0 {
+		var err error
+		if release, err = repo.withChildProjectsNotes(release); err != nil {
+			return err
+		}
+	}
+
+	description := formatChangelog(release.Changelog, repo.changelogMode)
+	repo.logger.Printf("dry run: would create release %s with description:\n%s", tag, truncate(description, dryRunDescriptionLength))
+	return nil
+}
+
+// urlHost returns the host of the URL, webhook URLs often contain a secret in their path or query
+func urlHost(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return "the configured URL"
+	}
+	return u.Host
+}
+
+func truncate(s string, length int) string {
+	runes := []rune(s)
+	if len(runes) <= length {
+		return s
+	}
+	return string(runes[:length]) + "..."
+}
@@ -0,0 +1,78 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+// Errors returned by the provider wrap one of these sentinels if they were caused by a GitLab API response,
+// they can be checked with errors.Is.
+var (
+	ErrAuthentication   = errors.New("authentication failed")
+	ErrPermissionDenied = errors.New("permission denied")
+	ErrProjectNotFound  = errors.New("project not found")
+	ErrNotFound         = errors.New("not found")
+	ErrConflict         = errors.New("conflict")
+	ErrRateLimited      = errors.New("rate limited")
+	ErrReadOnly         = errors.New("instance is read-only")
+)
+
+// APIError is an error caused by a failed GitLab API request.
+type APIError struct {
+	// Kind is one of the sentinel errors
+	Kind       error
+	StatusCode int
+	Err        error
+}
+
+func (e *APIError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
+func (e *APIError) Is(target error) bool {
+	return target == e.Kind
+}
+
+// wrapAPIError classifies the error by the status of the failed API request, other errors are returned as they are
+func wrapAPIError(err error) error {
+	var apiErr *APIError
+	var errResp *gitlab.ErrorResponse
+	if err == nil || errors.As(err, &apiErr) || !errors.As(err, &errResp) || errResp.Response == nil {
+		return err
+	}
+
+	var kind error
+	switch errResp.Response.StatusCode {
+	case http.StatusUnauthorized:
+		kind = ErrAuthentication
+	case http.StatusForbidden:
+		kind = ErrPermissionDenied
+	case http.StatusNotFound:
+		kind = ErrNotFound
+		// project scoped endpoints answer with this message if the project is not visible
+		if strings.Contains(errResp.Message, "Project Not Found") {
+			kind = ErrProjectNotFound
+		}
+	case http.StatusConflict:
+		kind = ErrConflict
+	case http.StatusTooManyRequests:
+		kind = ErrRateLimited
+	case http.StatusServiceUnavailable:
+		if !isReadOnlyMessage(errResp.Message) {
+			return err
+		}
+		kind = ErrReadOnly
+		err = fmt.Errorf("the GitLab instance is in maintenance mode or read-only, retry once it accepts writes again or set gitlab_maintenance_timeout to wait for it: %w", err)
+	default:
+		return err
+	}
+	return &APIError{Kind: kind, StatusCode: errResp.Response.StatusCode, Err: err}
+}
@@ -0,0 +1,73 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/go-semantic-release/semantic-release/v2/pkg/provider"
+	"github.com/stretchr/testify/require"
+	"github.com/xanzy/go-gitlab"
+)
+
+func TestWrapAPIError(t *testing.T) {
+	testCases := []struct {
+		status   int
+		body     string
+		sentinel error
+	}{
+		{http.StatusUnauthorized, `{"message":"401 Unauthorized"}`, ErrAuthentication},
+		{http.StatusForbidden, `{"message":"403 Forbidden"}`, ErrPermissionDenied},
+		{http.StatusNotFound, `{"message":"404 Project Not Found"}`, ErrProjectNotFound},
+		{http.StatusNotFound, `{"message":"404 Tag Not Found"}`, ErrNotFound},
+		{http.StatusConflict, `{"message":"Release already exists"}`, ErrConflict},
+		{http.StatusServiceUnavailable, `{"message":"You cannot perform write operations on a read-only instance"}`, ErrReadOnly},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.body, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == fmt.Sprintf("/api/v4/projects/%d", GITLAB_PROJECT_ID) {
+					http.Error(w, tc.body, tc.status)
+					return
+				}
+				GitlabHandler(w, r)
+			}))
+			defer ts.Close()
+
+			repo := &GitLabRepository{}
+			err := repo.Init(map[string]string{
+				"gitlab_baseurl":   ts.URL,
+				"token":            "gitlab-examples-ci",
+				"gitlab_projectid": strconv.Itoa(GITLAB_PROJECT_ID),
+			})
+			require.NoError(t, err)
+
+			_, err = repo.GetInfo()
+			require.ErrorIs(t, err, tc.sentinel)
+
+			var apiErr *APIError
+			require.ErrorAs(t, err, &apiErr)
+			require.Equal(t, tc.status, apiErr.StatusCode)
+
+			var errResp *gitlab.ErrorResponse
+			require.ErrorAs(t, err, &errResp)
+		})
+	}
+
+	err := errors.New("plain")
+	require.Equal(t, err, wrapAPIError(err))
+	require.Nil(t, wrapAPIError(nil))
+}
+
+func TestGitlabCreateReleaseConflictError(t *testing.T) {
+	repo, ts := getNewGitlabTestRepo(t)
+	defer ts.Close()
+
+	err := repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "1.0.0", SHA: "deadbeef"})
+	require.ErrorIs(t, err, ErrConflict)
+	require.NotErrorIs(t, err, ErrNotFound)
+}
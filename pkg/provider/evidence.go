@@ -0,0 +1,75 @@
+package provider
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"time"
+)
+
+const (
+	evidenceModeWarn    = "warn"
+	evidenceModeRequire = "require"
+
+	defaultEvidenceTimeout = 5 * time.Minute
+)
+
+// the SHA of an evidence is the SHA-256 of its JSON document
+var evidenceSHAPattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+type releaseEvidence struct {
+	SHA         string     `json:"sha"`
+	Filepath    string     `json:"filepath"`
+	CollectedAt *time.Time `json:"collected_at"`
+}
+
+// verifyReleaseEvidence waits until GitLab collected the evidence of the release, which happens in the background
+// after the release was created. Depending on gitlab_release_evidence a missing or invalid evidence fails the release
+// or is only logged.
+func (repo *GitLabRepository) verifyReleaseEvidence(tag string, data *templateData) error {
+	evidence, err := repo.awaitReleaseEvidence(tag)
+	if err == nil && !evidenceSHAPattern.MatchString(evidence.SHA) {
+		err = fmt.Errorf("the release evidence of %s has an invalid SHA %q", tag, evidence.SHA)
+	}
+	if err != nil {
+		if repo.evidenceMode == evidenceModeRequire {
+			return err
+		}
+		repo.logger.Printf("WARNING: %s", err)
+		return nil
+	}
+
+	data.EvidenceSHA = evidence.SHA
+	repo.logger.Printf("collected release evidence of %s: %s", tag, evidence.SHA)
+	return nil
+}
+
+func (repo *GitLabRepository) awaitReleaseEvidence(tag string) (*releaseEvidence, error) {
+	path := fmt.Sprintf("projects/%s/releases/%s", url.PathEscape(repo.projectID), url.PathEscape(tag))
+	deadline := time.Now().Add(repo.evidenceTimeout)
+
+	for {
+		req, err := repo.api.NewRequest(http.MethodGet, path, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		var release struct {
+			Evidences []*releaseEvidence `json:"evidences"`
+		}
+		if _, err := repo.api.Do(req, &release); err != nil {
+			return nil, fmt.Errorf("failed to get release %s: %w", tag, err)
+		}
+
+		// the latest evidence comes first
+		if len(release.Evidences) > 0 {
+			return release.Evidences[0], nil
+		}
+		if time.Now().Add(repo.pipelinePollInterval).After(deadline) {
+			return nil, fmt.Errorf("timed out after %s waiting for the release evidence of %s", repo.evidenceTimeout, tag)
+		}
+
+		repo.logger.Printf("waiting for the release evidence of %s", tag)
+		time.Sleep(repo.pipelinePollInterval)
+	}
+}
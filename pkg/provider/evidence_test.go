@@ -0,0 +1,68 @@
+package provider
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-semantic-release/semantic-release/v2/pkg/provider"
+	"github.com/stretchr/testify/require"
+)
+
+const testEvidenceSHA = "760d6cdfb0879c3ffedec13af470e0f71cf52c6cde4d4d4ce2afa2e0a4da4a2e"
+
+func newEvidenceTestRepo(t *testing.T, mode string, evidences func(requests int) string) (*GitLabRepository, *bytes.Buffer) {
+	server, project := newFakeServer(t)
+	server.AddCommit(project, "deadbeef", "feat: evidence")
+	// the fake server does not collect evidence
+	var requests int
+	server.HandleFunc("GET /api/v4/projects/group/app/releases/v2.0.0", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		fmt.Fprintf(w, `{"tag_name": "v2.0.0", "evidences": [%s]}`, evidences(requests))
+	})
+
+	var logs bytes.Buffer
+	repo := initFakeRepo(t, &GitLabRepository{logger: log.New(&logs, "", 0), pipelinePollInterval: time.Millisecond}, server, map[string]string{
+		"gitlab_release_evidence":         mode,
+		"gitlab_release_evidence_timeout": "50ms",
+		"gitlab_allow_update":             "true",
+	})
+	return repo, &logs
+}
+
+func TestReleaseEvidence(t *testing.T) {
+	repo, logs := newEvidenceTestRepo(t, "require", func(requests int) string {
+		if requests < 3 {
+			return ""
+		}
+		return fmt.Sprintf(`{"sha": %q, "filepath": "https://gitlab.com/group/project/-/releases/v2.0.0/evidences/1.json", "collected_at": "2022-06-01T10:00:00Z"}`, testEvidenceSHA)
+	})
+
+	err := repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "2.0.0", SHA: "deadbeef"})
+	require.NoError(t, err)
+	require.Equal(t, 2, strings.Count(logs.String(), "waiting for the release evidence of v2.0.0"))
+	require.Contains(t, logs.String(), "collected release evidence of v2.0.0: "+testEvidenceSHA)
+}
+
+func TestReleaseEvidenceTimeout(t *testing.T) {
+	repo, logs := newEvidenceTestRepo(t, "warn", func(int) string { return "" })
+
+	err := repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "2.0.0", SHA: "deadbeef"})
+	require.NoError(t, err)
+	require.Contains(t, logs.String(), "WARNING: timed out after 50ms waiting for the release evidence of v2.0.0")
+
+	repo.evidenceMode = "require"
+	err = repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "2.0.0", SHA: "deadbeef"})
+	require.EqualError(t, err, "timed out after 50ms waiting for the release evidence of v2.0.0")
+}
+
+func TestReleaseEvidenceInvalidSHA(t *testing.T) {
+	repo, _ := newEvidenceTestRepo(t, "require", func(int) string { return `{"sha": "abc"}` })
+
+	err := repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "2.0.0", SHA: "deadbeef"})
+	require.EqualError(t, err, `the release evidence of v2.0.0 has an invalid SHA "abc"`)
+}
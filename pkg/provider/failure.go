@@ -0,0 +1,64 @@
+package provider
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/go-semantic-release/semantic-release/v2/pkg/provider"
+	"github.com/xanzy/go-gitlab"
+)
+
+const (
+	defaultFailureIssueLabel = "semantic-release"
+	failureIssueTitle        = "The automated release is failing 🚨"
+)
+
+func failureReport(release *provider.CreateReleaseConfig, tag string, releaseErr error) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "The release of **%s** (`%s`) failed:\n\n", tag, release.SHA)
+	fmt.Fprintf(&sb, "```\n%s\n```\n", releaseErr)
+	if pipelineURL := os.Getenv("CI_PIPELINE_URL"); pipelineURL != "" {
+		fmt.Fprintf(&sb, "\nPipeline: %s\n", pipelineURL)
+	}
+	return sb.String()
+}
+
+// reportFailure creates an issue describing the failed release, or comments on the open one of a previous failure
+func (repo *GitLabRepository) reportFailure(release *provider.CreateReleaseConfig, releaseErr error) {
+	report := failureReport(release, repo.tagName(release.NewVersion), releaseErr)
+	labels := gitlab.Labels{repo.failureIssueLabel}
+
+	issues, _, err := repo.api.ListProjectIssues(repo.projectID, &gitlab.ListProjectIssuesOptions{
+		State:  gitlab.String("opened"),
+		Labels: &labels,
+		Search: gitlab.String(failureIssueTitle),
+	})
+	if err != nil {
+		repo.logger.Printf("WARNING: failed to search for an open release failure issue: %s", err)
+		return
+	}
+
+	for _, issue := range issues {
+		if issue.Title != failureIssueTitle {
+			continue
+		}
+		if _, _, err := repo.api.CreateIssueNote(repo.projectID, issue.IID, &gitlab.CreateIssueNoteOptions{Body: &report}); err != nil {
+			repo.logger.Printf("WARNING: failed to comment on release failure issue #%d: %s", issue.IID, err)
+			return
+		}
+		repo.logger.Printf("reported the release failure in issue #%d", issue.IID)
+		return
+	}
+
+	issue, _, err := repo.api.CreateIssue(repo.projectID, &gitlab.CreateIssueOptions{
+		Title:       gitlab.String(failureIssueTitle),
+		Description: &report,
+		Labels:      &labels,
+	})
+	if err != nil {
+		repo.logger.Printf("WARNING: failed to create a release failure issue: %s", err)
+		return
+	}
+	repo.logger.Printf("reported the release failure in issue #%d", issue.IID)
+}
@@ -0,0 +1,67 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/go-semantic-release/semantic-release/v2/pkg/provider"
+	"github.com/stretchr/testify/require"
+	"github.com/xanzy/go-gitlab"
+)
+
+func TestGitlabReportFailure(t *testing.T) {
+	t.Setenv("CI_PIPELINE_URL", "https://gitlab.com/group/project/-/pipelines/1")
+
+	var openIssues []*gitlab.Issue
+	var created, commented map[string]string
+	//nolint:errcheck
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, fmt.Sprintf("/api/v4/projects/%d/", GITLAB_PROJECT_ID))
+		switch {
+		case r.Method == "GET" && path == "issues":
+			require.Equal(t, "semantic-release", r.URL.Query().Get("labels"))
+			json.NewEncoder(w).Encode(openIssues)
+		case r.Method == "POST" && path == "issues":
+			json.NewDecoder(r.Body).Decode(&created)
+			fmt.Fprint(w, `{"id":1,"iid":5}`)
+		case r.Method == "POST" && path == "issues/5/notes":
+			json.NewDecoder(r.Body).Decode(&commented)
+			fmt.Fprint(w, "{}")
+		case r.Method == "POST" && path == "releases":
+			http.Error(w, `{"message":"boom"}`, http.StatusBadRequest)
+		default:
+			GitlabHandler(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	repo := &GitLabRepository{}
+	err := repo.Init(map[string]string{
+		"gitlab_baseurl":       ts.URL,
+		"token":                "gitlab-examples-ci",
+		"gitlab_projectid":     strconv.Itoa(GITLAB_PROJECT_ID),
+		"gitlab_failure_issue": "true",
+	})
+	require.NoError(t, err)
+
+	err = repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "2.0.0", SHA: "deadbeef"})
+	require.Error(t, err)
+	require.Equal(t, failureIssueTitle, created["title"])
+	require.Equal(t, "semantic-release", created["labels"])
+	require.Contains(t, created["description"], "The release of **v2.0.0** (`deadbeef`) failed")
+	require.Contains(t, created["description"], "boom")
+	require.Contains(t, created["description"], "Pipeline: https://gitlab.com/group/project/-/pipelines/1")
+	require.Nil(t, commented)
+
+	created = nil
+	openIssues = []*gitlab.Issue{{IID: 5, Title: failureIssueTitle}}
+	err = repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "2.0.0", SHA: "deadbeef"})
+	require.Error(t, err)
+	require.Nil(t, created)
+	require.Contains(t, commented["body"], "The release of **v2.0.0**")
+}
@@ -0,0 +1,83 @@
+package provider
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-semantic-release/semantic-release/v2/pkg/provider"
+)
+
+// parseFanOutConfig parses a comma separated list of project[:branch] entries, the release is created at the head of
+// the branch or of the default branch of each project
+func (repo *GitLabRepository) parseFanOutConfig(config map[string]string, key string) ([]*GitLabRepository, error) {
+	targets := make([]*GitLabRepository, 0)
+	for _, entry := range parseListConfig(config, key) {
+		projectID, branch, _ := strings.Cut(entry, ":")
+		if projectID == "" {
+			return nil, fmt.Errorf("failed to set property %s: missing project in %q", key, entry)
+		}
+		targets = append(targets, repo.newTargetRepository(projectID, branch))
+	}
+	return targets, nil
+}
+
+// newTargetRepository returns a repository for another project which creates tags and releases like this one
+func (repo *GitLabRepository) newTargetRepository(projectID, branch string) *GitLabRepository {
+	return &GitLabRepository{
+		projectID:       projectID,
+		branch:          branch,
+		stripVTagPrefix: repo.stripVTagPrefix,
+		tagPrefix:       repo.tagPrefix,
+		changelogMode:   repo.changelogMode,
+		tagMessage:      repo.tagMessage,
+		perPage:         repo.perPage,
+		maxPages:        repo.maxPages,
+		concurrency:     repo.concurrency,
+		ignoreAuthors:   repo.ignoreAuthors,
+		// the tag may already exist, e.g. when a previously failed release is retried
+		allowUpdate: true,
+		readClient:  repo.readClient,
+		api:         repo.api,
+		logger:      repo.logger,
+	}
+}
+
+// fanOutSHA returns the head of the branch the release is created at in the target project
+func (repo *GitLabRepository) fanOutSHA() (string, error) {
+	branch := repo.branch
+	if branch == "" {
+		project, err := repo.getProject()
+		if err != nil {
+			return "", fmt.Errorf("failed to get project: %w", err)
+		}
+		branch = project.DefaultBranch
+	}
+	return repo.getBranchHead(branch)
+}
+
+// releaseToProjects creates the same version in all target projects, the failures of single projects are reported
+// together and only logged if partial releases are allowed
+func (repo *GitLabRepository) releaseToProjects(tag string, release *provider.CreateReleaseConfig, targets []*GitLabRepository) error {
+	var problems []string
+	for _, target := range targets {
+		sha, err := target.fanOutSHA()
+		if err == nil {
+			err = repo.copyRelease(target, tag, withSHA(release, sha))
+		}
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %s", target.projectID, err))
+			continue
+		}
+		repo.logger.Printf("released %s to project %s at %s", tag, target.projectID, sha)
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	err := fmt.Errorf("failed to release %s to %d of %d projects:\n  - %s", tag, len(problems), len(targets), strings.Join(problems, "\n  - "))
+	if repo.fanOutAllowPartial {
+		repo.logger.Printf("WARNING: %s", err)
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,63 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/go-semantic-release/semantic-release/v2/pkg/provider"
+	"github.com/stretchr/testify/require"
+	"github.com/xanzy/go-gitlab"
+)
+
+func TestGitlabReleaseToFanOutProjects(t *testing.T) {
+	heads := map[string]string{"split/a/repository/branches/main": "aaaa", "split/b/repository/branches/develop": "bbbb"}
+	created := map[string]string{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, "/api/v4/projects/")
+		if !strings.HasPrefix(path, "split/") {
+			GitlabHandler(w, r)
+			return
+		}
+		switch {
+		case r.Method == "GET" && path == "split/b":
+			json.NewEncoder(w).Encode(gitlab.Project{DefaultBranch: "develop"}) //nolint:errcheck
+		case r.Method == "GET" && heads[path] != "":
+			json.NewEncoder(w).Encode(gitlab.Branch{Commit: &gitlab.Commit{ID: heads[path]}}) //nolint:errcheck
+		case r.Method == "POST" && strings.HasSuffix(path, "/repository/tags"):
+			var opts gitlab.CreateTagOptions
+			json.NewDecoder(r.Body).Decode(&opts) //nolint:errcheck
+			created[strings.TrimSuffix(path, "/repository/tags")] = *opts.TagName + "@" + *opts.Ref
+			fmt.Fprint(w, "{}")
+		case r.Method == "POST" && strings.HasSuffix(path, "/releases"):
+			fmt.Fprint(w, "{}")
+		default:
+			http.Error(w, `{"message":"404 Not Found"}`, http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	config := map[string]string{
+		"gitlab_baseurl":          ts.URL,
+		"token":                   "gitlab-examples-ci",
+		"gitlab_projectid":        strconv.Itoa(GITLAB_PROJECT_ID),
+		"gitlab_fan_out_projects": "split/a:main,split/b,split/c",
+	}
+	repo := &GitLabRepository{}
+	require.NoError(t, repo.Init(config))
+
+	err := repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "2.0.0", SHA: "deadbeef"})
+	require.ErrorContains(t, err, "failed to release v2.0.0 to 1 of 3 projects:\n  - split/c: failed to get project: GET "+ts.URL)
+	require.Equal(t, map[string]string{"split/a": "v2.0.0@aaaa", "split/b": "v2.0.0@bbbb"}, created)
+
+	config["gitlab_fan_out_allow_partial"] = "true"
+	repo = &GitLabRepository{}
+	require.NoError(t, repo.Init(config))
+
+	err = repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "2.0.0", SHA: "deadbeef"})
+	require.NoError(t, err)
+}
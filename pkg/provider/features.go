@@ -0,0 +1,68 @@
+package provider
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/xanzy/go-gitlab"
+)
+
+// feature is an API of GitLab which is not available on older self-managed instances
+type feature struct {
+	name  string
+	since string
+}
+
+var (
+	featureReleaseLinkTypes = feature{"release link types", "13.1"}
+	featureTerraformModules = feature{"the terraform module registry", "14.0"}
+	featureHelmCharts       = feature{"the helm chart registry", "14.1"}
+)
+
+// detectServerVersion remembers the version of the instance on first use, features are assumed to be available if
+// the version cannot be read, e.g. because the token lacks the read_api scope
+func (repo *GitLabRepository) detectServerVersion() {
+	if repo.versionDetected {
+		return
+	}
+	repo.versionDetected = true
+
+	v, _, err := repo.api.GetVersion()
+	if err != nil {
+		repo.debugf("failed to detect the GitLab version, assuming all features are available: %s", err)
+		return
+	}
+	// editions are appended like a prerelease, e.g. 15.0.0-ee
+	version, err := semver.NewVersion(strings.SplitN(v.Version, "-", 2)[0])
+	if err != nil {
+		repo.debugf("unknown GitLab version %q, assuming all features are available", v.Version)
+		return
+	}
+	repo.serverVersion = version
+	repo.debugf("detected GitLab %s", version)
+}
+
+func (repo *GitLabRepository) supports(f feature) bool {
+	repo.detectServerVersion()
+	if repo.serverVersion == nil {
+		return true
+	}
+	return !repo.serverVersion.LessThan(semver.MustParse(f.since))
+}
+
+// requireFeature returns an error naming the required version if the instance is too old for the option
+func (repo *GitLabRepository) requireFeature(option string, f feature) error {
+	if repo.supports(f) {
+		return nil
+	}
+	return fmt.Errorf("%s requires %s which is available since GitLab %s, the instance runs GitLab %s", option, f.name, f.since, repo.serverVersion)
+}
+
+// packageLinkType returns the link type of release links to packages, older instances reject the attribute
+func (repo *GitLabRepository) packageLinkType() *gitlab.LinkTypeValue {
+	if !repo.supports(featureReleaseLinkTypes) {
+		return nil
+	}
+	return gitlab.LinkType(gitlab.PackageLinkType)
+}
@@ -0,0 +1,53 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/go-semantic-release/provider-gitlab/pkg/gitlabtest"
+	"github.com/stretchr/testify/require"
+	"github.com/xanzy/go-gitlab"
+)
+
+// newVersionTestServer runs the given GitLab version, the version of the instance is unknown if it is empty
+func newVersionTestServer(t *testing.T, version string) *gitlabtest.Server {
+	server, _ := newFakeServer(t)
+	server.Version = version
+	return server
+}
+
+func TestDetectServerVersion(t *testing.T) {
+	repo, ts := getNewGitlabTestRepo(t)
+	defer ts.Close()
+	require.True(t, repo.supports(featureHelmCharts))
+	require.Equal(t, "15.0.0", repo.serverVersion.String())
+	require.Equal(t, gitlab.LinkType(gitlab.PackageLinkType), repo.packageLinkType())
+}
+
+func TestUnknownServerVersion(t *testing.T) {
+	server := newVersionTestServer(t, "")
+	repo := initFakeRepo(t, &GitLabRepository{}, server, map[string]string{"gitlab_helm_chart": "chart"})
+	require.Nil(t, repo.serverVersion)
+	require.True(t, repo.supports(featureHelmCharts))
+}
+
+func TestOldServerVersion(t *testing.T) {
+	server := newVersionTestServer(t, "13.0.14")
+	config := map[string]string{
+		"gitlab_baseurl":   server.URL,
+		"token":            "token",
+		"gitlab_projectid": "group/app",
+	}
+
+	repo := &GitLabRepository{}
+	require.NoError(t, repo.Init(config))
+	require.Nil(t, repo.packageLinkType())
+
+	config["gitlab_helm_chart"] = "chart"
+	err := (&GitLabRepository{}).Init(config)
+	require.EqualError(t, err, "gitlab_helm_chart requires the helm chart registry which is available since GitLab 14.1, the instance runs GitLab 13.0.14")
+
+	delete(config, "gitlab_helm_chart")
+	config["gitlab_terraform_module"] = "vpc/aws"
+	err = (&GitLabRepository{}).Init(config)
+	require.EqualError(t, err, "gitlab_terraform_module requires the terraform module registry which is available since GitLab 14.0, the instance runs GitLab 13.0.14")
+}
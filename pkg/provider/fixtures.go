@@ -0,0 +1,268 @@
+package provider
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// the host of the instance is replaced in recorded responses so fixtures can be shared
+const fixtureHost = "gitlab.example.com"
+
+// fixtureHeaders are the response headers kept in fixtures, the client needs them for pagination
+var fixtureHeaders = []string{"Content-Type", "Link", "X-Page", "X-Next-Page", "X-Per-Page", "X-Total", "X-Total-Pages"}
+
+// fixture is a recorded request and its response, one per line of a fixture file
+type fixture struct {
+	Method  string            `json:"method"`
+	Path    string            `json:"path"`
+	Request string            `json:"request,omitempty"`
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    string            `json:"body"`
+}
+
+// fixtureKey identifies the request of a fixture, credentials in the query are redacted and the host is ignored
+func fixtureKey(u *url.URL) string {
+	query := u.Query()
+	for _, param := range redactedQueryParams {
+		if query.Has(param) {
+			query.Set(param, "REDACTED")
+		}
+	}
+	if len(query) == 0 {
+		return u.EscapedPath()
+	}
+	return u.EscapedPath() + "?" + query.Encode()
+}
+
+// redactedFixtureFields are the JSON fields whose values are redacted in fixtures, e.g. tokens and email addresses
+var redactedFixtureFields = regexp.MustCompile(`(?i)(token|secret|password|email)`)
+
+// unrecordedFixturePaths are the endpoints whose responses are never recorded, they hold credentials or the values of
+// CI/CD variables
+var unrecordedFixturePaths = regexp.MustCompile(`(/jwt/auth|/variables(/[^/]*)?)$`)
+
+// recordTransport appends every request and its response to gitlab_record_file, e.g. to reproduce a release which
+// computed the wrong version with gitlab_replay_file without access to the instance. Request headers are not recorded
+// as they contain the token, tokens and email addresses in the bodies are redacted so the fixtures can be shared.
+type recordTransport struct {
+	next    http.RoundTripper
+	path    string
+	secrets []string
+	mu      sync.Mutex
+}
+
+func newRecordTransport(next http.RoundTripper, path string) (*recordTransport, error) {
+	// every run starts a new fixture file
+	if err := os.WriteFile(path, nil, 0o600); err != nil {
+		return nil, fmt.Errorf("failed to set property gitlab_record_file: %w", err)
+	}
+	return &recordTransport{next: next, path: path}, nil
+}
+
+// maxFixtureRequestSize is the size of the largest request body recorded in fixtures
+const maxFixtureRequestSize = 1 << 20
+
+// binaryContentTypes are the content types of request bodies which are not recorded, e.g. uploaded assets
+var binaryContentTypes = regexp.MustCompile(`^(application/octet-stream|multipart/)`)
+
+// recordedRequest returns the body of the request for the fixture. Binary and large bodies, e.g. package uploads of
+// gitlab_assets, are neither read nor recorded, only their size is.
+func (t *recordTransport) recordedRequest(req *http.Request) string {
+	if req.GetBody == nil {
+		return ""
+	}
+	if binaryContentTypes.MatchString(req.Header.Get("Content-Type")) || req.ContentLength > maxFixtureRequestSize {
+		if req.ContentLength < 0 {
+			return "<binary body>"
+		}
+		return fmt.Sprintf("<body of %d bytes>", req.ContentLength)
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return ""
+	}
+	defer body.Close()
+	content, _ := io.ReadAll(io.LimitReader(body, maxFixtureRequestSize+1))
+	if len(content) > maxFixtureRequestSize {
+		return fmt.Sprintf("<body of more than %d bytes>", maxFixtureRequestSize)
+	}
+	return t.redact(content, req.URL.Host)
+}
+
+func (t *recordTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	requestBody := t.recordedRequest(req)
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil || unrecordedFixturePaths.MatchString(req.URL.Path) {
+		return resp, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	recorded := &fixture{
+		Method:  req.Method,
+		Path:    fixtureKey(req.URL),
+		Request: requestBody,
+		Status:  resp.StatusCode,
+		Headers: make(map[string]string),
+		Body:    t.redact(body, req.URL.Host),
+	}
+	for _, name := range fixtureHeaders {
+		if value := resp.Header.Get(name); value != "" {
+			recorded.Headers[name] = strings.ReplaceAll(value, req.URL.Host, fixtureHost)
+		}
+	}
+	if err := t.write(recorded); err != nil {
+		return nil, fmt.Errorf("failed to record %s %s: %w", req.Method, recorded.Path, err)
+	}
+	return resp, nil
+}
+
+// redact replaces the host of the instance, known secrets and the values of redactedFixtureFields in the body
+func (t *recordTransport) redact(body []byte, host string) string {
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err == nil {
+		if redacted, err := json.Marshal(redactFixtureValue(value)); err == nil {
+			body = redacted
+		}
+	}
+
+	s := strings.ReplaceAll(string(body), host, fixtureHost)
+	for _, secret := range t.secrets {
+		s = strings.ReplaceAll(s, secret, "REDACTED")
+	}
+	return s
+}
+
+// nonEmptyStrings returns the values which are not empty, e.g. the configured secrets
+func nonEmptyStrings(values ...string) []string {
+	nonEmpty := make([]string, 0, len(values))
+	for _, value := range values {
+		if value != "" {
+			nonEmpty = append(nonEmpty, value)
+		}
+	}
+	return nonEmpty
+}
+
+func redactFixtureValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			if _, ok := item.(string); ok && redactedFixtureFields.MatchString(key) {
+				v[key] = "REDACTED"
+				continue
+			}
+			v[key] = redactFixtureValue(item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redactFixtureValue(item)
+		}
+	}
+	return value
+}
+
+func (t *recordTransport) write(recorded *fixture) error {
+	line, err := json.Marshal(recorded)
+	if err != nil {
+		return err
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	f, err := os.OpenFile(t.path, os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// replayTransport answers the requests from the fixtures of gitlab_replay_file without contacting GitLab. Repeated
+// requests get the recorded responses in order, the last one is repeated once they are used up.
+type replayTransport struct {
+	path     string
+	mu       sync.Mutex
+	fixtures map[string][]*fixture
+}
+
+func newReplayTransport(path string) (*replayTransport, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set property gitlab_replay_file: %w", err)
+	}
+	defer f.Close()
+
+	t := &replayTransport{path: path, fixtures: make(map[string][]*fixture)}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 64<<20)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		recorded := &fixture{}
+		if err := json.Unmarshal(scanner.Bytes(), recorded); err != nil {
+			return nil, fmt.Errorf("failed to set property gitlab_replay_file: invalid fixture in line %d of %s: %w", line, path, err)
+		}
+		key := recorded.Method + " " + recorded.Path
+		t.fixtures[key] = append(t.fixtures[key], recorded)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to set property gitlab_replay_file: %w", err)
+	}
+	return t, nil
+}
+
+func (t *replayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	key := req.Method + " " + fixtureKey(req.URL)
+
+	t.mu.Lock()
+	recorded := t.fixtures[key]
+	if len(recorded) == 0 {
+		t.mu.Unlock()
+		return nil, fmt.Errorf("no recorded response for %s", key)
+	}
+	next := recorded[0]
+	if len(recorded) > 1 {
+		t.fixtures[key] = recorded[1:]
+	}
+	t.mu.Unlock()
+
+	header := make(http.Header)
+	for name, value := range next.Headers {
+		header.Set(name, value)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", next.Status, http.StatusText(next.Status)),
+		StatusCode:    next.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(next.Body)),
+		ContentLength: int64(len(next.Body)),
+		Request:       req,
+	}, nil
+}
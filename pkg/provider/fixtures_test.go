@@ -0,0 +1,139 @@
+package provider
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFixtureKey(t *testing.T) {
+	u, err := url.Parse("https://gitlab.com/api/v4/projects/group%2Fproject/repository/tags?per_page=100&private_token=secret&page=2")
+	require.NoError(t, err)
+	require.Equal(t, "/api/v4/projects/group%2Fproject/repository/tags?page=2&per_page=100&private_token=REDACTED", fixtureKey(u))
+}
+
+func TestGitlabRecordReplay(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(GitlabHandler))
+	defer ts.Close()
+	fixtures := filepath.Join(t.TempDir(), "fixtures.jsonl")
+
+	recording := &GitLabRepository{}
+	err := recording.Init(map[string]string{
+		"gitlab_baseurl":     ts.URL,
+		"token":              "secret-token",
+		"gitlab_projectid":   strconv.Itoa(GITLAB_PROJECT_ID),
+		"gitlab_record_file": fixtures,
+	})
+	require.NoError(t, err)
+	recordedCommits, err := recording.GetCommits("", "")
+	require.NoError(t, err)
+	recordedReleases, err := recording.GetReleases("")
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(fixtures)
+	require.NoError(t, err)
+	require.Contains(t, string(content), `"path":"/api/v4/projects/12324322/repository/tags?`)
+	require.NotContains(t, string(content), "secret-token")
+	require.NotContains(t, string(content), strings.TrimPrefix(ts.URL, "http://"))
+
+	// the instance is not needed to replay the run
+	ts.Close()
+	replaying := &GitLabRepository{}
+	err = replaying.Init(map[string]string{
+		"gitlab_baseurl":     ts.URL,
+		"token":              "token",
+		"gitlab_projectid":   strconv.Itoa(GITLAB_PROJECT_ID),
+		"gitlab_replay_file": fixtures,
+	})
+	require.NoError(t, err)
+	commits, err := replaying.GetCommits("", "")
+	require.NoError(t, err)
+	require.Equal(t, recordedCommits, commits)
+	releases, err := replaying.GetReleases("")
+	require.NoError(t, err)
+	require.Equal(t, recordedReleases, releases)
+
+	_, err = replaying.GetInfo()
+	require.ErrorContains(t, err, "no recorded response for GET /api/v4/projects/12324322")
+}
+
+func TestRecordTransportRedacts(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v4/user":
+			fmt.Fprint(w, `{"id":1,"username":"release-bot","email":"bot@example.com","note":"uses secret-token"}`)
+		case "/api/v4/projects/1/variables", "/jwt/auth":
+			fmt.Fprint(w, `[{"key":"DEPLOY_PASSWORD","value":"variable-value"}]`)
+		case "/api/v4/personal_access_tokens/self":
+			fmt.Fprint(w, `{"name":"semantic-release","token":"glpat-other","scopes":["api"]}`)
+		}
+	}))
+	defer ts.Close()
+	fixtures := filepath.Join(t.TempDir(), "fixtures.jsonl")
+
+	recorder, err := newRecordTransport(http.DefaultTransport, fixtures)
+	require.NoError(t, err)
+	recorder.secrets = nonEmptyStrings("secret-token", "")
+	client := &http.Client{Transport: recorder}
+	for _, path := range []string{"/api/v4/user", "/api/v4/projects/1/variables", "/jwt/auth", "/api/v4/personal_access_tokens/self"} {
+		resp, err := client.Get(ts.URL + path)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	content, err := os.ReadFile(fixtures)
+	require.NoError(t, err)
+	for _, secret := range []string{"secret-token", "bot@example.com", "variable-value", "glpat-other", "/jwt/auth", "/variables"} {
+		require.NotContains(t, string(content), secret)
+	}
+	require.Contains(t, string(content), `"body":"{\"email\":\"REDACTED\",\"id\":1,\"note\":\"uses REDACTED\",\"username\":\"release-bot\"}"`)
+}
+
+func TestRecordTransportSkipsLargeBodies(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, "{}")
+	}))
+	defer ts.Close()
+	fixtures := filepath.Join(t.TempDir(), "fixtures.jsonl")
+
+	recorder, err := newRecordTransport(http.DefaultTransport, fixtures)
+	require.NoError(t, err)
+	client := &http.Client{Transport: recorder}
+	for contentType, body := range map[string]string{
+		"application/octet-stream": "binary asset",
+		"application/json":         `{"data":"` + strings.Repeat("a", maxFixtureRequestSize) + `"}`,
+		"text/plain":               "notes",
+	} {
+		req, err := http.NewRequest(http.MethodPut, ts.URL+"/api/v4/projects/1/packages/generic/app/1.0.0/app.tar.gz", strings.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", contentType)
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	content, err := os.ReadFile(fixtures)
+	require.NoError(t, err)
+	require.NotContains(t, string(content), "binary asset")
+	require.NotContains(t, string(content), "aaaa")
+	require.Contains(t, string(content), `"request":"\u003cbody of 12 bytes\u003e"`)
+	require.Contains(t, string(content), fmt.Sprintf(`"request":"\u003cbody of %d bytes\u003e"`, maxFixtureRequestSize+11))
+	require.Contains(t, string(content), `"request":"notes"`)
+}
+
+func TestGitlabReplayInvalidFixture(t *testing.T) {
+	fixtures := filepath.Join(t.TempDir(), "fixtures.jsonl")
+	require.NoError(t, os.WriteFile(fixtures, []byte("{\"method\":\"GET\"}\nnot json\n"), 0o644))
+
+	err := (&GitLabRepository{}).Init(map[string]string{"token": "token", "gitlab_projectid": "1", "gitlab_replay_file": fixtures})
+	require.ErrorContains(t, err, "failed to set property gitlab_replay_file: invalid fixture in line 2 of")
+}
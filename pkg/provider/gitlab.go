@@ -1,86 +1,741 @@
 package provider
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"log"
+	"net/http"
 	"os"
 	"regexp"
 	"strconv"
+	"strings"
+	"text/template"
+	"time"
 
 	"github.com/Masterminds/semver/v3"
 	"github.com/go-semantic-release/semantic-release/v2/pkg/provider"
 	"github.com/go-semantic-release/semantic-release/v2/pkg/semrel"
 	"github.com/xanzy/go-gitlab"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 )
 
 var PVERSION = "dev"
 
 type GitLabRepository struct {
-	projectID       string
-	branch          string
-	stripVTagPrefix bool
-	client          *gitlab.Client
+	projectID             string
+	branch                string
+	stripVTagPrefix       bool
+	tagPrefix             string
+	ref                   string
+	channel               string
+	channelRange          *semver.Constraints
+	prereleaseChannel     string
+	prereleaseUpcoming    time.Duration
+	channelBuilds         map[string]uint64
+	maintenanceBranches   []string
+	changelogMode         string
+	changelogSnippet      bool
+	changelogSource       string
+	changelogTrailer      string
+	changelogConfigFile   string
+	changelogFile         string
+	allowUpdate           bool
+	updateMode            string
+	tagOnly               bool
+	useExistingTag        bool
+	tagMessage            *template.Template
+	tagMetadata           bool
+	forceRetag            bool
+	protectedTag          string
+	protectedTagAccess    gitlab.AccessLevelValue
+	verifyAccess          bool
+	rollbackOnFailure     bool
+	dryRun                bool
+	strictHeadCheck       bool
+	verifyReleaseSHA      bool
+	commitSignatures      bool
+	commitStats           bool
+	graphql               bool
+	messagePattern        *regexp.Regexp
+	ignoreAuthors         []string
+	scopes                []string
+	messageReplacement    string
+	tagSigningKey         string
+	tagSigningFormat      string
+	runGit                func(env []string, args ...string) ([]byte, error)
+	requireSignedCommits  bool
+	waitForPipeline       bool
+	waitForMergeTrain     bool
+	pipelineTimeout       time.Duration
+	evidenceMode          string
+	evidenceTimeout       time.Duration
+	releaseSummaryFile    string
+	releaseLatest         *bool
+	environment           string
+	mergeRequestComment   *template.Template
+	issueLabel            *template.Template
+	issueComment          *template.Template
+	failureIssueLabel     string
+	notifyURL             string
+	notifySecret          string
+	auditStreamURL        string
+	auditStreamToken      string
+	auditRequired         bool
+	tokenExpiryWindow     time.Duration
+	tokenExpiryFail       bool
+	notifyHeaders         map[string]string
+	versionFiles          []*versionFile
+	versionFilesMessage   *template.Template
+	releaseMergeRequest   bool
+	ciCatalog             bool
+	releaseBranch         *template.Template
+	backMergeBranch       string
+	helmChart             string
+	helmChannel           string
+	terraformModuleName   string
+	terraformModuleSystem string
+	terraformModulePath   string
+	containerImage        string
+	containerSourceTag    *template.Template
+	containerTags         []*template.Template
+	registryUser          string
+	packageRetention      int
+	packageRetentionName  string
+	prereleaseCleanup     string
+	prereleaseCleanupKeep int
+	wikiPage              *template.Template
+	wikiIndex             string
+	pagesBranch           string
+	pagesPath             string
+	skipCI                map[string]bool
+	announcementIssue     string
+	announcementComment   *template.Template
+	mirrors               []*GitLabRepository
+	fanOutProjects        []*GitLabRepository
+	fanOutAllowPartial    bool
+	childProjects         []*childProject
+	groupID               string
+	groupInclude          *regexp.Regexp
+	groupExclude          *regexp.Regexp
+	logLevel              string
+	tracerProvider        *sdktrace.TracerProvider
+	metricsSummary        bool
+	maintenanceTimeout    time.Duration
+	apiPath               *apiPathTransport
+	recorder              *recordTransport
+	replayer              *replayTransport
+	perPage               int
+	concurrency           int
+	releaseOrder          string
+	commitStrategy        string
+	commitTitlesOnly      bool
+	assets                []string
+	assetsPackage         string
+	assetChunkSize        int64
+	assetLinks            []*assetLink
+	releaseManifest       bool
+	circuitBreaker        *circuitBreakerTransport
+	circuitBreakerRetry   bool
+	maxPages              int
+	requestHeaders        http.Header
+	sudo                  string
+	metrics               *metricsTransport
+	serverVersion         *semver.Version
+	versionDetected       bool
+	token                 string
+	readClient            *gitlab.Client
+	api                   apiClient
+	customClient          Client
+	logger                *log.Logger
+
+	// only configurable for testing
+	pipelinePollInterval time.Duration
+	uploadRetryWait      time.Duration
+	descriptionLimit     int
+
+	// head of the branch, commits and start of the range of the last GetCommits call
+	branchHead  string
+	commits     []*semrel.RawCommit
+	commitsFrom string
+
+	// merge requests by commit fetched by the last GetCommits call with gitlab_graphql
+	commitMergeRequests map[string][]*gitlab.MergeRequest
+
+	// release tags and their commit dates by commit returned by the last GetReleases call
+	releaseTags  map[string]string
+	releaseDates map[string]*time.Time
+
+	// latest version returned by the last GetReleases call, recorded in the tag metadata
+	previousVersion string
+
+	// projects of the group, enumerated on first use
+	groupTargets []*GitLabRepository
+
+	project *gitlab.Project
+
+	// tag and release created by the last CreateRelease call
+	createdTag     string
+	createdRelease string
+
+	// release merge request the last CreateRelease call waits for
+	pendingMergeRequest string
+
+	// whether the maintenance branches were released since the last GetReleases call
+	maintenanceReleased bool
+
+	// context of the span of the current provider call
+	spanCtx context.Context
+
+	// links attached to the release by the publishing steps of the last CreateRelease call
+	releaseLinks []*gitlab.ReleaseAssetLinkOptions
+
+	// release description of the last CreateRelease call, the changelog snippet is only created once
+	descriptionTag string
+	description    string
 }
 
-func (repo *GitLabRepository) Init(config map[string]string) error {
-	gitlabBaseUrl := config["gitlab_baseurl"]
-	if gitlabBaseUrl == "" {
-		gitlabBaseUrl = os.Getenv("CI_SERVER_URL")
+func (repo *GitLabRepository) Init(config map[string]string) (err error) {
+	if repo.tracerProvider == nil {
+		if repo.tracerProvider, err = newTracerProvider(); err != nil {
+			return err
+		}
+	}
+	span := repo.startSpan("Init")
+	defer repo.endSpan(span, &err)
+
+	if config, err = loadConfigFile(config); err != nil {
+		return err
+	}
+	config = applyEnvMapping(config)
+	config = resolveProjectFromGitRemote(config)
+	if err := validateConfig(config); err != nil {
+		return err
+	}
+
+	gitlabBaseUrl := lookupConfig(config, "gitlab_baseurl")
+	token := lookupConfig(config, "token")
+	branch := lookupConfig(config, "gitlab_branch")
+	projectID := lookupConfig(config, "gitlab_projectid")
+	span.SetAttributes(attribute.String("gitlab.project_id", projectID))
+
+	if repo.stripVTagPrefix, err = parseBoolConfig(config, "strip_v_tag_prefix"); err != nil {
+		return err
+	}
+
+	repo.tagPrefix = config["gitlab_tag_prefix"]
+	repo.ref = config["gitlab_ref"]
+
+	if repo.allowUpdate, err = parseBoolConfig(config, "gitlab_allow_update"); err != nil {
+		return err
+	}
+	repo.updateMode = config["gitlab_update_mode"]
+	if !isValidUpdateMode(repo.updateMode) {
+		return fmt.Errorf("failed to set property gitlab_update_mode: unknown mode %q, expected replace, append or prepend", repo.updateMode)
+	}
+
+	if repo.tagOnly, err = parseBoolConfig(config, "gitlab_tag_only"); err != nil {
+		return err
+	}
+
+	if repo.useExistingTag, err = parseBoolConfig(config, "gitlab_use_existing_tag"); err != nil {
+		return err
+	}
+
+	if repo.forceRetag, err = parseBoolConfig(config, "gitlab_force_retag"); err != nil {
+		return err
+	}
+
+	repo.protectedTag = config["gitlab_protected_tag"]
+	if repo.protectedTagAccess, err = parseTagAccessConfig(config, "gitlab_protected_tag_access"); err != nil {
+		return err
+	}
+
+	if repo.strictHeadCheck, err = parseBoolConfig(config, "gitlab_strict_head_check"); err != nil {
+		return err
+	}
+
+	if repo.verifyReleaseSHA, err = parseBoolConfig(config, "gitlab_verify_release_sha"); err != nil {
+		return err
+	}
+
+	if repo.commitSignatures, err = parseBoolConfig(config, "gitlab_commit_signatures"); err != nil {
+		return err
+	}
+
+	if repo.commitStats, err = parseBoolConfig(config, "gitlab_commit_stats"); err != nil {
+		return err
+	}
+
+	if repo.graphql, err = parseBoolConfig(config, "gitlab_graphql"); err != nil {
+		return err
+	}
+
+	if repo.messagePattern, err = parseRegexpConfig(config, "gitlab_commit_message_pattern"); err != nil {
+		return err
+	}
+	repo.messageReplacement = config["gitlab_commit_message_replacement"]
+	if repo.ignoreAuthors, err = parseAuthorPatternsConfig(config, "gitlab_ignore_authors"); err != nil {
+		return err
+	}
+	if repo.scopes, err = parseScopesConfig(config, "gitlab_scopes"); err != nil {
+		return err
+	}
+
+	if repo.requireSignedCommits, err = parseBoolConfig(config, "gitlab_require_signed_commits"); err != nil {
+		return err
+	}
+
+	repo.tagSigningKey = config["gitlab_tag_signing_key"]
+	repo.tagSigningFormat = config["gitlab_tag_signing_format"]
+
+	if repo.waitForPipeline, err = parseBoolConfig(config, "gitlab_wait_for_pipeline"); err != nil {
+		return err
+	}
+	if repo.waitForMergeTrain, err = parseBoolConfig(config, "gitlab_wait_for_merge_train"); err != nil {
+		return err
+	}
+
+	if repo.pipelineTimeout, err = parseDurationConfig(config, "gitlab_pipeline_timeout", defaultPipelineTimeout); err != nil {
+		return err
+	}
+
+	repo.releaseSummaryFile = config["gitlab_release_summary_file"]
+
+	if repo.releaseLatest, err = parseReleaseLatestConfig(config, "gitlab_release_latest"); err != nil {
+		return err
+	}
+
+	channels, err := parseBranchChannelsConfig(config, "gitlab_branch_channels")
+	if err != nil {
+		return err
+	}
+	repo.channel = branchChannelFor(channels, branch)
+	prereleaseChannels, err := parseBranchChannelsConfig(config, "gitlab_prerelease_channels")
+	if err != nil {
+		return err
+	}
+	repo.prereleaseChannel = branchChannelFor(prereleaseChannels, branch)
+	if _, err := semver.NewVersion("0.0.0-" + repo.prereleaseChannel + ".1"); repo.prereleaseChannel != "" && err != nil {
+		return fmt.Errorf("failed to set property gitlab_prerelease_channels: channel %q is not a valid prerelease identifier", repo.prereleaseChannel)
+	}
+	if repo.prereleaseUpcoming, err = parseDurationConfig(config, "gitlab_prerelease_upcoming", defaultPrereleaseUpcoming); err != nil {
+		return err
+	}
+	if repo.maintenanceBranches, err = parseBranchPatternsConfig(config, "gitlab_maintenance_branches"); err != nil {
+		return err
+	}
+
+	if repo.isMaintenanceChannel() {
+		// only the versions of the maintenance line are considered, e.g. 1.x.y on the 1.x branch
+		repo.channelRange = maintenanceRange(branch)
+		if repo.releaseLatest == nil {
+			repo.releaseLatest = new(bool)
+		}
+	}
+
+	repo.evidenceMode = config["gitlab_release_evidence"]
+	if repo.evidenceTimeout, err = parseDurationConfig(config, "gitlab_release_evidence_timeout", defaultEvidenceTimeout); err != nil {
+		return err
+	}
+
+	if repo.pipelinePollInterval == 0 {
+		repo.pipelinePollInterval = defaultPipelinePollInterval
+	}
+
+	if repo.dryRun, err = parseBoolConfig(config, "gitlab_dry_run"); err != nil {
+		return err
+	}
+
+	if repo.rollbackOnFailure, err = parseBoolConfig(config, "gitlab_rollback_on_failure"); err != nil {
+		return err
+	}
+
+	if repo.tagMessage, err = parseTemplateConfig(config, "gitlab_tag_message", ""); err != nil {
+		return err
+	}
+
+	if repo.tagMetadata, err = parseBoolConfig(config, "gitlab_tag_metadata"); err != nil {
+		return err
+	}
+
+	repo.environment = config["gitlab_environment"]
+
+	commentMergeRequests, err := parseBoolConfig(config, "gitlab_comment_merge_requests")
+	if err != nil {
+		return err
+	}
+	if commentMergeRequests {
+		if repo.mergeRequestComment, err = parseTemplateConfig(config, "gitlab_merge_request_comment", defaultMergeRequestComment); err != nil {
+			return err
+		}
+	}
+
+	releaseIssues, err := parseBoolConfig(config, "gitlab_release_issues")
+	if err != nil {
+		return err
+	}
+	if releaseIssues {
+		if repo.issueLabel, err = parseTemplateConfig(config, "gitlab_issue_label", ""); err != nil {
+			return err
+		}
+		if repo.issueComment, err = parseTemplateConfig(config, "gitlab_issue_comment", defaultIssueComment); err != nil {
+			return err
+		}
+	}
+
+	failureIssue, err := parseBoolConfig(config, "gitlab_failure_issue")
+	if err != nil {
+		return err
+	}
+	if failureIssue {
+		repo.failureIssueLabel = config["gitlab_failure_issue_label"]
+		if repo.failureIssueLabel == "" {
+			repo.failureIssueLabel = defaultFailureIssueLabel
+		}
+	}
+
+	repo.notifyURL = config["gitlab_notify_url"]
+	repo.notifySecret = config["gitlab_notify_secret"]
+	if repo.notifyHeaders, err = parseHeadersConfig(config, "gitlab_notify_headers"); err != nil {
+		return err
 	}
 
-	token := config["token"]
-	if token == "" {
-		token = os.Getenv("GITLAB_TOKEN")
+	repo.auditStreamURL = config["gitlab_audit_stream_url"]
+	repo.auditStreamToken = lookupConfig(config, "gitlab_audit_stream_token")
+	if repo.auditRequired, err = parseBoolConfig(config, "gitlab_audit_required"); err != nil {
+		return err
 	}
-	if token == "" {
-		return errors.New("gitlab token missing")
+
+	if repo.versionFiles, err = parseVersionFilesConfig(config, "gitlab_version_files"); err != nil {
+		return err
+	}
+	if len(repo.versionFiles) > 0 {
+		if repo.versionFilesMessage, err = parseTemplateConfig(config, "gitlab_version_files_message", defaultVersionFilesMessage); err != nil {
+			return err
+		}
 	}
 
-	branch := config["gitlab_branch"]
-	if branch == "" {
-		branch = os.Getenv("CI_COMMIT_BRANCH")
+	if repo.ciCatalog, err = parseBoolConfig(config, "gitlab_ci_catalog"); err != nil {
+		return err
+	}
+	if repo.ciCatalog {
+		// the catalog only accepts component versions without a prefix
+		if repo.tagPrefix != "" {
+			return errors.New("gitlab_tag_prefix cannot be used with gitlab_ci_catalog, the catalog only accepts versions without a prefix")
+		}
+		repo.stripVTagPrefix = true
 	}
 
-	projectID := config["gitlab_projectid"]
-	if projectID == "" {
-		projectID = os.Getenv("CI_PROJECT_ID")
+	if repo.releaseMergeRequest, err = parseBoolConfig(config, "gitlab_release_merge_request"); err != nil {
+		return err
 	}
-	if projectID == "" {
-		return fmt.Errorf("gitlab_projectid is required")
+
+	if repo.releaseBranch, err = parseTemplateConfig(config, "gitlab_release_branch", ""); err != nil {
+		return err
 	}
+	repo.backMergeBranch = config["gitlab_back_merge_branch"]
 
-	var err error
-	stripVTagPrefix := config["strip_v_tag_prefix"]
-	repo.stripVTagPrefix, err = strconv.ParseBool(stripVTagPrefix)
+	repo.helmChart = config["gitlab_helm_chart"]
+	repo.helmChannel = config["gitlab_helm_channel"]
+	if repo.helmChannel == "" {
+		repo.helmChannel = defaultHelmChannel
+	}
 
-	if stripVTagPrefix != "" && err != nil {
-		return fmt.Errorf("failed to set property strip_v_tag_prefix: %w", err)
+	if repo.terraformModuleName, repo.terraformModuleSystem, err = parseTerraformModuleConfig(config, "gitlab_terraform_module"); err != nil {
+		return err
+	}
+	repo.terraformModulePath = config["gitlab_terraform_module_path"]
+	if repo.terraformModulePath == "" {
+		repo.terraformModulePath = "."
+	}
+
+	repo.containerImage = config["gitlab_container_image"]
+	if repo.containerImage != "" {
+		if repo.containerSourceTag, err = parseTemplateConfig(config, "gitlab_container_source_tag", defaultContainerSourceTag); err != nil {
+			return err
+		}
+		if repo.containerTags, err = parseTemplateListConfig(config, "gitlab_container_tags", defaultContainerTags); err != nil {
+			return err
+		}
+		repo.registryUser = config["gitlab_container_registry_user"]
+	}
+
+	if repo.packageRetention, err = parseIntConfig(config, "gitlab_package_retention"); err != nil {
+		return err
+	}
+	repo.packageRetentionName = config["gitlab_package_retention_name"]
+	repo.prereleaseCleanup = config["gitlab_prerelease_cleanup"]
+	if repo.prereleaseCleanupKeep, err = parseIntConfig(config, "gitlab_prerelease_cleanup_keep"); err != nil {
+		return err
+	}
+
+	if repo.wikiPage, err = parseTemplateConfig(config, "gitlab_wiki_page", ""); err != nil {
+		return err
+	}
+	repo.wikiIndex = config["gitlab_wiki_index"]
+	repo.pagesBranch = config["gitlab_pages_branch"]
+	repo.pagesPath = defaultString(config["gitlab_pages_path"], defaultPagesPath)
+	if repo.skipCI, err = parseSkipCIConfig(config, "gitlab_skip_ci"); err != nil {
+		return err
+	}
+	repo.announcementIssue = config["gitlab_announcement_issue"]
+	if repo.announcementIssue != "" {
+		if repo.announcementComment, err = parseTemplateConfig(config, "gitlab_announcement_comment", defaultAnnouncementComment); err != nil {
+			return err
+		}
+	}
+
+	changelogMode := config["gitlab_changelog_mode"]
+	if !isValidChangelogMode(changelogMode) {
+		return fmt.Errorf("failed to set property gitlab_changelog_mode: unknown mode %q", changelogMode)
 	}
 
 	repo.projectID = projectID
 	repo.branch = branch
+	repo.token = token
+	repo.changelogMode = changelogMode
 
-	var client *gitlab.Client
-	if gitlabBaseUrl != "" {
-		client, err = gitlab.NewClient(token, gitlab.WithBaseURL(gitlabBaseUrl))
-	} else {
-		client, err = gitlab.NewClient(token)
+	if repo.changelogSnippet, err = parseBoolConfig(config, "gitlab_changelog_snippet"); err != nil {
+		return err
+	}
+	repo.changelogSource = config["gitlab_changelog_source"]
+	if !isValidChangelogSource(repo.changelogSource) {
+		return fmt.Errorf("failed to set property gitlab_changelog_source: unknown source %q, expected semrel or gitlab", repo.changelogSource)
 	}
+	repo.changelogTrailer = config["gitlab_changelog_trailer"]
+	repo.changelogConfigFile = config["gitlab_changelog_config_file"]
+	repo.changelogFile = config["gitlab_changelog_file"]
 
+	repo.logLevel = defaultString(config["gitlab_log_level"], logLevelInfo)
+	if repo.metricsSummary, err = parseBoolConfig(config, "gitlab_metrics_summary"); err != nil {
+		return err
+	}
+	if repo.requestHeaders, err = parseRequestHeadersConfig(config, "gitlab_request_headers", "gitlab_user_agent"); err != nil {
+		return err
+	}
+	if repo.maintenanceTimeout, err = parseDurationConfig(config, "gitlab_maintenance_timeout", 0); err != nil {
+		return err
+	}
+
+	if repo.perPage, err = parsePerPageConfig(config, "gitlab_per_page"); err != nil {
+		return err
+	}
+
+	if repo.maxPages, err = parseIntConfig(config, "gitlab_max_pages"); err != nil {
+		return err
+	}
+	repo.releaseOrder = config["gitlab_release_order"]
+	repo.commitStrategy = config["gitlab_commit_strategy"]
+	if !isValidCommitStrategy(repo.commitStrategy) {
+		return fmt.Errorf("failed to set property gitlab_commit_strategy: unknown strategy %q, expected refname, compare or since-date", repo.commitStrategy)
+	}
+	// footers like BREAKING CHANGE are part of the body, so only analyzers which read the title can use this
+	if repo.commitTitlesOnly, err = parseBoolConfig(config, "gitlab_commit_titles_only"); err != nil {
+		return err
+	}
+
+	repo.assets = parseListConfig(config, "gitlab_assets")
+	repo.assetsPackage = defaultString(config["gitlab_assets_package"], defaultAssetsPackage)
+	if repo.assetChunkSize, err = parseSizeConfig(config, "gitlab_asset_chunk_size"); err != nil {
+		return err
+	}
+	if repo.assetLinks, err = parseAssetLinksConfig(config, "gitlab_asset_links"); err != nil {
+		return err
+	}
+	if repo.releaseManifest, err = parseBoolConfig(config, "gitlab_release_manifest"); err != nil {
+		return err
+	}
+	if repo.uploadRetryWait == 0 {
+		repo.uploadRetryWait = defaultUploadRetryWait
+	}
+
+	if repo.concurrency, err = parseIntConfig(config, "gitlab_concurrency"); err != nil {
+		return err
+	}
+
+	threshold, err := parseIntConfig(config, "gitlab_circuit_breaker_threshold")
+	if err != nil {
+		return err
+	}
+	repo.circuitBreaker = nil
+	if threshold > 0 {
+		repo.circuitBreaker = &circuitBreakerTransport{threshold: threshold}
+	}
+	if repo.circuitBreakerRetry, err = parseBoolConfig(config, "gitlab_circuit_breaker_retry"); err != nil {
+		return err
+	}
+
+	if repo.logger == nil {
+		repo.logger = log.New(os.Stderr, "", 0)
+	}
+
+	base := http.DefaultTransport
+	// the fixtures are kept if Init runs again after loading options from the repository
+	if replayFile := config["gitlab_replay_file"]; replayFile != "" {
+		if repo.replayer == nil || repo.replayer.path != replayFile {
+			if repo.replayer, err = newReplayTransport(replayFile); err != nil {
+				return err
+			}
+		}
+		base = repo.replayer
+	} else if recordFile := config["gitlab_record_file"]; recordFile != "" {
+		if repo.recorder == nil || repo.recorder.path != recordFile {
+			if repo.recorder, err = newRecordTransport(base, recordFile); err != nil {
+				return err
+			}
+		}
+		repo.recorder.secrets = nonEmptyStrings(token, lookupConfig(config, "gitlab_read_token"), os.Getenv("CI_JOB_TOKEN"))
+		base = repo.recorder
+	}
+	if repo.apiPath, err = newAPIPathTransport(base, gitlabBaseUrl, config["gitlab_api_path"]); err != nil {
+		return err
+	}
+	if repo.apiPath != nil {
+		base = repo.apiPath
+	}
+	if repo.maintenanceTimeout > 0 {
+		base = &maintenanceTransport{next: base, timeout: repo.maintenanceTimeout, logger: repo.logger, sleep: time.Sleep}
+	}
+	if repo.circuitBreaker != nil {
+		repo.circuitBreaker.next = base
+		base = repo.circuitBreaker
+	}
+	repo.metrics = newMetricsTransport(base)
+
+	// the sudo user only exists on the instance of the project
+	instanceHeaders := make(http.Header)
+	if repo.sudo = config["gitlab_sudo"]; repo.sudo != "" {
+		instanceHeaders.Set("Sudo", repo.sudo)
+	}
+	client, err := repo.newClient(gitlabBaseUrl, token, instanceHeaders)
 	if err != nil {
 		return fmt.Errorf("failed to create client: %w", err)
 	}
+	if repo.readClient, err = repo.newReadClient(config, gitlabBaseUrl, token, instanceHeaders); err != nil {
+		return fmt.Errorf("failed to create read client: %w", err)
+	}
+	repo.api = &gitlabClient{client: client}
+	if repo.customClient != nil {
+		// the custom client also serves the reads
+		repo.api = &customClient{apiClient: repo.api, client: repo.customClient}
+		if api, ok := repo.customClient.(apiClient); ok {
+			// the fakes of the tests serve the optional features as well
+			repo.api = api
+		}
+		repo.readClient = nil
+	}
+
+	connectivityCheck, err := parseBoolConfig(config, "gitlab_connectivity_check")
+	if err != nil {
+		return err
+	}
+	if connectivityCheck {
+		if err := repo.checkConnectivity(gitlabBaseUrl); err != nil {
+			return err
+		}
+	}
+
+	ciVariables, err := parseBoolConfig(config, "gitlab_ci_variables")
+	if err != nil {
+		return err
+	}
+	if config["gitlab_repository_config_file"] != "" || ciVariables {
+		merged := config
+		if merged["gitlab_repository_config_file"] != "" {
+			if merged, err = repo.loadRepositoryConfigFile(merged); err != nil {
+				return err
+			}
+		}
+		if ciVariables {
+			if merged, err = repo.loadCIVariables(merged); err != nil {
+				return err
+			}
+		}
+		// the loaded options may change everything set up so far, they are only loaded once
+		merged["gitlab_repository_config_file"] = ""
+		merged["gitlab_ci_variables"] = "false"
+		if connectivityCheck {
+			merged["gitlab_connectivity_check"] = "false"
+		}
+		return repo.Init(merged)
+	}
+
+	if repo.helmChart != "" {
+		if err := repo.requireFeature("gitlab_helm_chart", featureHelmCharts); err != nil {
+			return err
+		}
+	}
+	if repo.terraformModuleName != "" {
+		if err := repo.requireFeature("gitlab_terraform_module", featureTerraformModules); err != nil {
+			return err
+		}
+	}
+
+	if repo.mirrors, err = repo.parseMirrorsConfig(config, "gitlab_mirrors"); err != nil {
+		return err
+	}
+
+	if repo.fanOutProjects, err = repo.parseFanOutConfig(config, "gitlab_fan_out_projects"); err != nil {
+		return err
+	}
+	if repo.fanOutAllowPartial, err = parseBoolConfig(config, "gitlab_fan_out_allow_partial"); err != nil {
+		return err
+	}
+	if repo.childProjects, err = parseChildProjectsConfig(config, "gitlab_child_projects"); err != nil {
+		return err
+	}
+
+	repo.groupID = config["gitlab_group_id"]
+	if repo.groupInclude, err = parseRegexpConfig(config, "gitlab_group_include"); err != nil {
+		return err
+	}
+	if repo.groupExclude, err = parseRegexpConfig(config, "gitlab_group_exclude"); err != nil {
+		return err
+	}
 
-	repo.client = client
+	if repo.tokenExpiryWindow, err = parseDurationConfig(config, "gitlab_token_expiry_window", 0); err != nil {
+		return err
+	}
+	if repo.tokenExpiryFail, err = parseBoolConfig(config, "gitlab_token_expiry_fail"); err != nil {
+		return err
+	}
+	if repo.tokenExpiryWindow > 0 {
+		if err := repo.checkTokenExpiry(); err != nil {
+			return err
+		}
+	}
+
+	repo.verifyAccess, err = parseBoolConfig(config, "gitlab_verify_access")
+	if err != nil {
+		return err
+	}
+	if repo.verifyAccess {
+		return repo.VerifyAccess()
+	}
 	return nil
 }
 
-func (repo *GitLabRepository) GetInfo() (*provider.RepositoryInfo, error) {
-	project, _, err := repo.client.Projects.GetProject(repo.projectID, nil)
+func (repo *GitLabRepository) getProject() (*gitlab.Project, error) {
+	if repo.project != nil {
+		return repo.project, nil
+	}
 
+	project, _, err := repo.reader().GetProject(repo.projectID, nil)
 	if err != nil {
 		return nil, err
 	}
+	repo.project = project
+	return project, nil
+}
+
+func (repo *GitLabRepository) GetInfo() (*provider.RepositoryInfo, error) {
+	project, err := repo.getProject()
+
+	if err != nil {
+		return nil, wrapAPIError(err)
+	}
 	return &provider.RepositoryInfo{
 		Owner:         "",
 		Repo:          "",
@@ -89,60 +744,226 @@ func (repo *GitLabRepository) GetInfo() (*provider.RepositoryInfo, error) {
 	}, nil
 }
 
-func (repo *GitLabRepository) GetCommits(fromSha, toSha string) ([]*semrel.RawCommit, error) {
-	opts := &gitlab.ListCommitsOptions{
-		ListOptions: gitlab.ListOptions{
-			Page:    1,
-			PerPage: 100,
-		},
+func (repo *GitLabRepository) GetCommits(fromSha, toSha string) (commits []*semrel.RawCommit, err error) {
+	span := repo.startSpan("GetCommits", attribute.String("gitlab.from_sha", fromSha), attribute.String("gitlab.to_sha", toSha))
+	defer repo.endSpan(span, &err)
+
+	err = repo.retryPhase("GetCommits", func() (err error) {
+		commits, err = repo.getCommits(fromSha, toSha)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	span.SetAttributes(attribute.Int("gitlab.commits", len(commits)))
+	return commits, nil
+}
+
+func (repo *GitLabRepository) getCommits(fromSha, toSha string) ([]*semrel.RawCommit, error) {
+	if repo.strictHeadCheck && repo.branch != "" {
+		head, err := repo.getBranchHead(repo.branch)
+		if err != nil {
+			return nil, wrapAPIError(err)
+		}
+		repo.branchHead = head
+	}
+
+	allCommits, err := repo.listCommitsFromReader(fromSha, toSha)
+	if err != nil {
+		return nil, wrapAPIError(err)
+	}
+	repo.commits = allCommits
+	repo.commitsFrom = fromSha
+
+	repo.commitMergeRequests = nil
+	if repo.graphql {
+		if err := repo.fetchCommitMergeRequests(fromSha, allCommits); err != nil {
+			return nil, wrapAPIError(err)
+		}
+	}
+
+	if repo.commitSignatures || repo.requireSignedCommits {
+		if err := repo.annotateCommitSignatures(allCommits); err != nil {
+			return nil, err
+		}
+	}
+
+	if repo.groupID != "" {
+		groupCommits, err := repo.getGroupCommits(fromSha)
+		if err != nil {
+			return nil, wrapAPIError(err)
+		}
+		allCommits = append(allCommits, groupCommits...)
+	}
+
+	if repo.messagePattern != nil {
+		repo.rewriteCommitMessages(allCommits)
+	}
+
+	if len(repo.scopes) > 0 {
+		// the scope is matched against the rewritten message, merge requests and issues of other scopes are not released
+		allCommits = repo.filterScopes(allCommits)
+		repo.commits = repo.filterScopes(repo.commits)
+	}
+	return allCommits, nil
+}
+
+// listCommits returns the commits of the ref without the ones of ignored authors and the newest commit of the ref
+// before they were removed
+func (repo *GitLabRepository) listCommits(client Client, refName string) ([]*semrel.RawCommit, string, error) {
+	return repo.listCommitPages(client, &gitlab.ListCommitsOptions{
 		// No Matter the order ofr fromSha and toSha gitlab always returns commits in reverse chronological order
-		RefName: gitlab.String(fmt.Sprintf("%s...%s", fromSha, toSha)),
+		RefName: gitlab.String(refName),
+	})
+}
+
+func (repo *GitLabRepository) listCommitPages(client Client, opts *gitlab.ListCommitsOptions) ([]*semrel.RawCommit, string, error) {
+	commits, err := repo.listCommitHistory(client, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	head := ""
+	if len(commits) > 0 {
+		head = commits[0].ID
 	}
 
-	allCommits := make([]*semrel.RawCommit, 0)
+	raw, ignored, err := repo.rawCommits(client, commits)
+	if err != nil {
+		return nil, "", err
+	}
+	repo.logIgnoredAuthors(ignored)
+	return raw, head, nil
+}
 
-	for {
-		commits, resp, err := repo.client.Commits.ListCommits(repo.projectID, opts)
+// listCommitHistory lists all pages of the commits, newest first
+func (repo *GitLabRepository) listCommitHistory(client Client, opts *gitlab.ListCommitsOptions) ([]*gitlab.Commit, error) {
+	opts.ListOptions = repo.listOptions()
+	if repo.commitStats {
+		opts.WithStats = gitlab.Bool(true)
+	}
 
+	allCommits := make([]*gitlab.Commit, 0)
+	for {
+		commits, resp, err := client.ListCommits(repo.projectID, opts)
 		if err != nil {
 			return nil, err
 		}
+		allCommits = append(allCommits, commits...)
 
-		for _, commit := range commits {
-			allCommits = append(allCommits, &semrel.RawCommit{
-				SHA:        commit.ID,
-				RawMessage: commit.Message,
-			})
+		if opts.Page == 1 && repo.concurrency > 1 && resp.TotalPages > 1 {
+			return repo.listRemainingCommitPages(client, opts, resp.TotalPages, allCommits)
 		}
 
 		// We cannot always rely on the total pages header
 		// https://gitlab.com/gitlab-org/gitlab-foss/-/merge_requests/23931
 		// if resp.CurrentPage >= resp.TotalPages {
-		if resp.NextPage == 0 {
-			break
+		if resp.NextPage == 0 || repo.pageLimitReached(opts.Page, "commits") {
+			return allCommits, nil
 		}
 
 		opts.Page = resp.NextPage
 	}
+}
 
+// listRemainingCommitPages fetches the pages 2 to total with the pool once the first page announced the total
+func (repo *GitLabRepository) listRemainingCommitPages(client Client, opts *gitlab.ListCommitsOptions, total int, allCommits []*gitlab.Commit) ([]*gitlab.Commit, error) {
+	if repo.maxPages > 0 && total > repo.maxPages {
+		total = repo.maxPages
+		repo.pageLimitReached(total, "commits")
+	}
+	pages := make([][]*gitlab.Commit, total-1)
+	err := repo.forEach(len(pages), func(i int) error {
+		pageOpts := *opts
+		pageOpts.Page = i + 2
+		commits, _, err := client.ListCommits(repo.projectID, &pageOpts)
+		pages[i] = commits
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, commits := range pages {
+		allCommits = append(allCommits, commits...)
+	}
 	return allCommits, nil
 }
 
-func (repo *GitLabRepository) GetReleases(rawRe string) ([]*semrel.Release, error) {
+func (repo *GitLabRepository) rawCommits(client Client, commits []*gitlab.Commit) ([]*semrel.RawCommit, int, error) {
+	ignored := 0
+	if len(repo.ignoreAuthors) > 0 {
+		kept := make([]*gitlab.Commit, 0, len(commits))
+		for _, commit := range commits {
+			if repo.isIgnoredAuthor(commit) {
+				repo.debugf("ignoring commit %s of %s <%s>", commit.ShortID, commit.AuthorName, commit.AuthorEmail)
+				ignored++
+				continue
+			}
+			kept = append(kept, commit)
+		}
+		commits = kept
+	}
+
+	raw := make([]*semrel.RawCommit, len(commits))
+	for i, commit := range commits {
+		message := commit.Message
+		if repo.commitTitlesOnly {
+			message = commitTitle(commit)
+		}
+		raw[i] = &semrel.RawCommit{
+			SHA:        commit.ID,
+			RawMessage: message,
+		}
+		if repo.releaseManifest {
+			annotateCommitAuthor(raw[i], commit)
+		}
+	}
+	if repo.commitStats {
+		err := repo.forEach(len(commits), func(i int) error {
+			return repo.annotateCommitStats(client, raw[i], commits[i])
+		})
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+	return raw, ignored, nil
+}
+
+func (repo *GitLabRepository) GetReleases(rawRe string) (releases []*semrel.Release, err error) {
+	span := repo.startSpan("GetReleases")
+	defer repo.endSpan(span, &err)
+
+	err = repo.retryPhase("GetReleases", func() (err error) {
+		releases, err = repo.getReleases(rawRe)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	span.SetAttributes(attribute.Int("gitlab.releases", len(releases)))
+	return releases, nil
+}
+
+func (repo *GitLabRepository) getReleases(rawRe string) ([]*semrel.Release, error) {
 	re := regexp.MustCompile(rawRe)
 	allReleases := make([]*semrel.Release, 0)
+	repo.releaseTags = make(map[string]string)
+	repo.releaseDates = make(map[string]*time.Time)
+	repo.channelBuilds = make(map[string]uint64)
+	repo.previousVersion = ""
+	repo.maintenanceReleased = false
 
 	opts := &gitlab.ListTagsOptions{
-		ListOptions: gitlab.ListOptions{
-			Page:    1,
-			PerPage: 100,
-		},
+		ListOptions: repo.listOptions(),
 	}
+	listing := repo.newReleaseListing(opts)
 
 	for {
-		tags, resp, err := repo.client.Tags.ListTags(repo.projectID, opts)
+		tags, resp, err := repo.reader().ListTags(repo.projectID, opts)
 		if err != nil {
-			return nil, err
+			return nil, wrapAPIError(err)
+		}
+		if listing != nil {
+			listing.startPage()
 		}
 
 		for _, tag := range tags {
@@ -150,18 +971,40 @@ func (repo *GitLabRepository) GetReleases(rawRe string) ([]*semrel.Release, erro
 				continue
 			}
 
-			version, err := semver.NewVersion(tag.Name)
+			version, err := repo.tagVersion(tag.Name)
 			if err != nil {
 				continue
 			}
+			if repo.channelRange != nil && !repo.channelRange.Check(version) {
+				continue
+			}
+			if !repo.trackChannelRelease(version) {
+				continue
+			}
+			if repo.tagMetadata && repo.otherChannelTag(tag.Name, parseTagMetadata(tag.Message)) {
+				continue
+			}
+			repo.trackPreviousVersion(version)
 
 			allReleases = append(allReleases, &semrel.Release{
 				SHA:     tag.Commit.ID,
 				Version: version.String(),
 			})
+			repo.releaseTags[tag.Commit.ID] = tag.Name
+			if tag.Commit.CommittedDate != nil {
+				repo.releaseDates[tag.Commit.ID] = tag.Commit.CommittedDate
+			}
+			if listing != nil {
+				listing.add(version)
+			}
 		}
 
-		if resp.CurrentPage >= resp.TotalPages {
+		// like the commits, the total pages header is omitted for large result sets
+		if resp.NextPage == 0 || repo.pageLimitReached(opts.Page, "tags") {
+			break
+		}
+		if listing.done() {
+			repo.debugf("stopped listing tags after page %d, the remaining tags are older than %s", opts.Page, listing.latest)
 			break
 		}
 
@@ -171,23 +1014,248 @@ func (repo *GitLabRepository) GetReleases(rawRe string) ([]*semrel.Release, erro
 	return allReleases, nil
 }
 
-func (repo *GitLabRepository) CreateRelease(release *provider.CreateReleaseConfig) error {
-	prefix := "v"
+func (repo *GitLabRepository) CreateRelease(release *provider.CreateReleaseConfig) (err error) {
+	// reset the state of a previous run
+	repo.createdTag, repo.createdRelease = "", ""
+	repo.releaseLinks = nil
+	repo.descriptionTag, repo.description = "", ""
+	repo.pendingMergeRequest = ""
+	release = repo.withChannelVersion(release)
+
+	span := repo.startSpan("CreateRelease", attribute.String("gitlab.version", release.NewVersion), attribute.String("gitlab.sha", release.SHA))
+	defer repo.endSpan(span, &err)
+	if repo.metricsSummary {
+		// the release is the last call of a run
+		defer repo.logMetricsSummary()
+	}
+
+	if repo.dryRun {
+		return repo.logDryRun(release)
+	}
+
+	tag, published, err := repo.createRelease(release)
+	if errors.Is(err, errReleasePendingApproval) {
+		// waiting for the release merge request is not a failure, a later run publishes the release
+		repo.logger.Println(err)
+		return nil
+	}
+	if err == nil {
+		// the published release is never rolled back, mirrors and other projects may already have received it
+		err = repo.afterRelease(tag, published)
+	} else if repo.rollbackOnFailure {
+		if rollbackErr := repo.Rollback(); rollbackErr != nil {
+			err = fmt.Errorf("%w (rollback failed: %s)", err, rollbackErr)
+		}
+	}
+	if err != nil && repo.failureIssueLabel != "" {
+		repo.reportFailure(release, err)
+	}
+	return wrapAPIError(err)
+}
+
+// createRelease creates the tag and publishes the release, it returns the tag and the release as published, e.g. with
+// the commit of the version files
+func (repo *GitLabRepository) createRelease(release *provider.CreateReleaseConfig) (string, *provider.CreateReleaseConfig, error) {
+	tag := repo.tagName(release.NewVersion)
+
+	if repo.strictHeadCheck {
+		branch := repo.branch
+		if branch == "" {
+			branch = release.Branch
+		}
+		if err := repo.verifyBranchHead(branch, release.SHA); err != nil {
+			return "", nil, err
+		}
+	}
+
+	if repo.ref != "" {
+		sha, err := repo.resolveRef(repo.ref)
+		if err != nil {
+			return "", nil, err
+		}
+		repo.logger.Printf("releasing %s from %s at %s instead of %s", tag, repo.ref, sha, release.SHA)
+		release = withSHA(release, sha)
+	}
+
+	if repo.verifyReleaseSHA {
+		if err := repo.validateReleaseSHA(defaultString(repo.branch, release.Branch), release.SHA); err != nil {
+			return "", nil, err
+		}
+	}
+
+	if repo.waitForPipeline {
+		if err := repo.waitForPipelines(release.SHA); err != nil {
+			return "", nil, err
+		}
+	}
+
+	if repo.waitForMergeTrain {
+		if err := repo.awaitMergeTrain(defaultString(repo.branch, release.Branch), release.SHA); err != nil {
+			return "", nil, err
+		}
+	}
+
+	if repo.releaseMergeRequest {
+		sha, err := repo.awaitReleaseMergeRequest(tag, release)
+		if err != nil {
+			return "", nil, err
+		}
+		// the release points at the merged commit which was approved
+		release = withSHA(release, sha)
+	} else if len(repo.versionFiles) > 0 {
+		sha, err := repo.bumpVersionFiles(tag, release)
+		if err != nil {
+			return "", nil, err
+		}
+		// the release points at the commit containing the updated version files
+		release = withSHA(release, sha)
+	}
+
+	if repo.ciCatalog {
+		if err := repo.verifyCatalogResource(release.SHA); err != nil {
+			return "", nil, err
+		}
+	}
+
+	if repo.changelogSource == changelogSourceGitLab {
+		changelog, err := repo.gitlabChangelog(release)
+		if err != nil {
+			return "", nil, err
+		}
+		// mirrors and fan-out projects get the same release notes
+		release = withChangelog(release, changelog)
+	}
+
+	if len(repo.childProjects) > 0 {
+		var err error
+		if release, err = repo.withChildProjectsNotes(release); err != nil {
+			return "", nil, err
+		}
+	}
+
+	if repo.forceRetag {
+		if err := repo.removeMisplacedTag(tag, release.SHA); err != nil {
+			return "", nil, err
+		}
+	}
+
+	// the tag has to be created upfront if it should not be a lightweight tag created by the releases API
+	// or if it has to be known whether the tag was created by this run
+	createTag := !repo.useExistingTag && (repo.tagOnly || repo.tagMessage != nil || repo.tagMetadata || repo.tagSigningKey != "" || repo.rollbackOnFailure)
+
+	if repo.protectedTag != "" {
+		if err := repo.ensureTagProtection(tag); err != nil {
+			return "", nil, err
+		}
+	}
+
+	if repo.verifyAccess && !repo.useExistingTag {
+		if err := repo.checkTagProtection(tag); err != nil {
+			return "", nil, err
+		}
+	}
+
+	if createTag {
+		if err := repo.createTag(tag, release); err != nil {
+			return "", nil, err
+		}
+	}
+
+	if repo.helmChart != "" {
+		if err := repo.publishHelmChart(release.NewVersion); err != nil {
+			return "", nil, err
+		}
+	}
+
+	if repo.terraformModuleName != "" {
+		if err := repo.publishTerraformModule(release.NewVersion); err != nil {
+			return "", nil, err
+		}
+	}
+
+	if len(repo.assets) > 0 {
+		if err := repo.publishAssets(release.NewVersion); err != nil {
+			return "", nil, err
+		}
+	}
+
+	if len(repo.assetLinks) > 0 {
+		if err := repo.addAssetLinks(tag, release); err != nil {
+			return "", nil, err
+		}
+	}
+
+	if repo.releaseManifest && !repo.tagOnly {
+		if err := repo.publishReleaseManifest(tag, release); err != nil {
+			return "", nil, err
+		}
+	}
+
+	if !repo.tagOnly {
+		if err := repo.publishRelease(tag, release, createTag); err != nil {
+			return "", nil, err
+		}
+	}
+
+	return tag, release, nil
+}
+
+func (repo *GitLabRepository) tagName(version string) string {
 	if repo.stripVTagPrefix {
-		prefix = ""
+		return repo.tagPrefix + version
+	}
+	return repo.tagPrefix + "v" + version
+}
+
+// tagVersion parses the version of a tag created by tagName, semver accepts the version with and without the v so
+// histories from before strip_v_tag_prefix was changed are read the same way
+func (repo *GitLabRepository) tagVersion(tag string) (*semver.Version, error) {
+	if !strings.HasPrefix(tag, repo.tagPrefix) {
+		return nil, fmt.Errorf("tag %s does not start with %s", tag, repo.tagPrefix)
 	}
+	return semver.NewVersion(strings.TrimPrefix(tag, repo.tagPrefix))
+}
 
-	tag := prefix + release.NewVersion
+func parseBoolConfig(config map[string]string, key string) (bool, error) {
+	value := config[key]
+	if value == "" {
+		return false, nil
+	}
 
-	// Gitlab does not have any notion of pre-releases
-	_, _, err := repo.client.Releases.CreateRelease(repo.projectID, &gitlab.CreateReleaseOptions{
-		TagName: &tag,
-		Ref:     &release.SHA,
-		// TODO: this may been to be wrapped in ```
-		Description: &release.Changelog,
-	})
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("failed to set property %s: %w", key, err)
+	}
+	return b, nil
+}
+
+func parseRegexpConfig(config map[string]string, key string) (*regexp.Regexp, error) {
+	value := config[key]
+	if value == "" {
+		return nil, nil
+	}
+
+	re, err := regexp.Compile(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set property %s: %w", key, err)
+	}
+	return re, nil
+}
+
+func parseIntConfig(config map[string]string, key string) (int, error) {
+	value := config[key]
+	if value == "" {
+		return 0, nil
+	}
 
-	return err
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("failed to set property %s: %w", key, err)
+	}
+	if i < 0 {
+		return 0, fmt.Errorf("failed to set property %s: must not be negative", key)
+	}
+	return i, nil
 }
 
 func (repo *GitLabRepository) Name() string {
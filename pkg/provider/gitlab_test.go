@@ -1,12 +1,17 @@
 package provider
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
+	"log"
 	"net/http"
 	"net/http/httptest"
 	"strconv"
+	"strings"
 	"testing"
+	"text/template"
 
 	"github.com/go-semantic-release/semantic-release/v2/pkg/provider"
 	"github.com/go-semantic-release/semantic-release/v2/pkg/semrel"
@@ -19,13 +24,23 @@ var validTags = map[string]bool{
 	"2.0.0":  true,
 }
 
+var existingReleases = map[string]*gitlab.Release{
+	"v1.0.0": {TagName: "v1.0.0", Name: "First release", Description: "initial"},
+}
+
 func TestNewGitlabRepository(t *testing.T) {
 	require := require.New(t)
 
+	t.Setenv("GITLAB_TOKEN", "")
+	t.Setenv("CI_PROJECT_ID", "")
+	t.Setenv("CI_PROJECT_PATH", "")
+
 	var repo *GitLabRepository
 	repo = &GitLabRepository{}
 	err := repo.Init(map[string]string{})
-	require.EqualError(err, "gitlab token missing")
+	require.EqualError(err, "invalid configuration:\n"+
+		"  - token is required, neither the option nor the environment variable GITLAB_TOKEN is set\n"+
+		"  - gitlab_projectid is required, neither the option nor the environment variables CI_PROJECT_ID or CI_PROJECT_PATH is set")
 
 	repo = &GitLabRepository{}
 	err = repo.Init(map[string]string{
@@ -42,7 +57,15 @@ func TestNewGitlabRepository(t *testing.T) {
 		"strip_v_tag_prefix": "true",
 	})
 	require.NoError(err)
-	require.Equal("https://mygitlab.com/api/v4/", repo.client.BaseURL().String(), "invalid custom instance initialization")
+	require.Equal("https://mygitlab.com/api/v4/", repo.api.BaseURL().String(), "invalid custom instance initialization")
+
+	repo = &GitLabRepository{}
+	err = repo.Init(map[string]string{
+		"token":                 "token",
+		"gitlab_projectid":      "1",
+		"gitlab_changelog_mode": "html",
+	})
+	require.EqualError(err, "failed to set property gitlab_changelog_mode: unknown mode \"html\"")
 }
 
 func createGitlabCommit(sha, message string) *gitlab.Commit {
@@ -56,10 +79,16 @@ func createGitlabTag(name, sha string) *gitlab.Tag {
 }
 
 var (
-	GITLAB_PROJECT_ID    = 12324322
-	GITLAB_DEFAULTBRANCH = "master"
-	GITLAB_PROJECT       = gitlab.Project{DefaultBranch: GITLAB_DEFAULTBRANCH, Visibility: gitlab.PrivateVisibility, ID: GITLAB_PROJECT_ID}
-	GITLAB_COMMITS       = []*gitlab.Commit{
+	GITLAB_PROJECT_ID     = 12324322
+	GITLAB_DEFAULTBRANCH  = "master"
+	GITLAB_PROJECT        = gitlab.Project{DefaultBranch: GITLAB_DEFAULTBRANCH, Visibility: gitlab.PrivateVisibility, ID: GITLAB_PROJECT_ID, HTTPURLToRepo: "https://gitlab.com/group/project.git"}
+	GITLAB_USER           = gitlab.User{ID: 42, Username: "release-bot"}
+	GITLAB_VERSION        = "15.0.0-ee"
+	GITLAB_PROTECTED_TAGS = []*gitlab.ProtectedTag{
+		{Name: "v*", CreateAccessLevels: []*gitlab.TagAccessDescription{{AccessLevel: gitlab.MaintainerPermissions, AccessLevelDescription: "Maintainers"}}},
+		{Name: "stable-*", CreateAccessLevels: []*gitlab.TagAccessDescription{{AccessLevel: gitlab.NoPermissions, AccessLevelDescription: "No one"}}},
+	}
+	GITLAB_COMMITS = []*gitlab.Commit{
 		createGitlabCommit("abcd", "feat(app): new feature"),
 		createGitlabCommit("dcba", "Fix: bug"),
 		createGitlabCommit("cdba", "Initial commit"),
@@ -89,6 +118,31 @@ func GitlabHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if r.Method == "GET" && r.URL.Path == "/api/v4/version" {
+		json.NewEncoder(w).Encode(gitlab.Version{Version: GITLAB_VERSION, Revision: "cafebabe"})
+		return
+	}
+
+	if r.Method == "GET" && r.URL.Path == "/api/v4/user" {
+		json.NewEncoder(w).Encode(GITLAB_USER)
+		return
+	}
+
+	if r.Method == "GET" && r.URL.Path == "/api/v4/personal_access_tokens/self" {
+		json.NewEncoder(w).Encode(gitlab.PersonalAccessToken{Name: "semantic-release", Scopes: []string{"api"}, Active: true})
+		return
+	}
+
+	if r.Method == "GET" && r.URL.Path == fmt.Sprintf("/api/v4/projects/%d/members/all/%d", GITLAB_PROJECT_ID, GITLAB_USER.ID) {
+		json.NewEncoder(w).Encode(gitlab.ProjectMember{ID: GITLAB_USER.ID, Username: GITLAB_USER.Username, AccessLevel: gitlab.MaintainerPermissions})
+		return
+	}
+
+	if r.Method == "GET" && r.URL.Path == fmt.Sprintf("/api/v4/projects/%d/protected_tags", GITLAB_PROJECT_ID) {
+		json.NewEncoder(w).Encode(GITLAB_PROTECTED_TAGS)
+		return
+	}
+
 	if r.Method == "GET" && r.URL.Path == fmt.Sprintf("/api/v4/projects/%d", GITLAB_PROJECT_ID) {
 		json.NewEncoder(w).Encode(GITLAB_PROJECT)
 		return
@@ -104,11 +158,30 @@ func GitlabHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if r.Method == "POST" && r.URL.Path == fmt.Sprintf("/api/v4/projects/%d/repository/tags", GITLAB_PROJECT_ID) {
+		var data map[string]string
+		json.NewDecoder(r.Body).Decode(&data)
+		r.Body.Close()
+
+		if _, ok := validTags[data["tag_name"]]; !ok {
+			http.Error(w, "invalid tag name", http.StatusBadRequest)
+			return
+		}
+
+		json.NewEncoder(w).Encode(createGitlabTag(data["tag_name"], data["ref"]))
+		return
+	}
+
 	if r.Method == "POST" && r.URL.Path == fmt.Sprintf("/api/v4/projects/%d/releases", GITLAB_PROJECT_ID) {
 		var data map[string]string
 		json.NewDecoder(r.Body).Decode(&data)
 		r.Body.Close()
 
+		if _, ok := existingReleases[data["tag_name"]]; ok {
+			http.Error(w, `{"message":"Release already exists"}`, http.StatusConflict)
+			return
+		}
+
 		if _, ok := validTags[data["tag_name"]]; !ok {
 			http.Error(w, "invalid tag name", http.StatusBadRequest)
 			return
@@ -118,6 +191,42 @@ func GitlabHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	for _, tag := range GITLAB_TAGS {
+		if r.Method == "GET" && r.URL.Path == fmt.Sprintf("/api/v4/projects/%d/repository/tags/%s", GITLAB_PROJECT_ID, tag.Name) {
+			json.NewEncoder(w).Encode(tag)
+			return
+		}
+	}
+
+	if r.Method == "GET" && strings.HasPrefix(r.URL.Path, fmt.Sprintf("/api/v4/projects/%d/repository/tags/", GITLAB_PROJECT_ID)) {
+		http.Error(w, `{"message":"404 Tag Not Found"}`, http.StatusNotFound)
+		return
+	}
+
+	for tag, release := range existingReleases {
+		if r.URL.Path != fmt.Sprintf("/api/v4/projects/%d/releases/%s", GITLAB_PROJECT_ID, tag) {
+			continue
+		}
+
+		switch r.Method {
+		case "GET":
+			json.NewEncoder(w).Encode(release)
+		case "PUT":
+			var data map[string]string
+			json.NewDecoder(r.Body).Decode(&data)
+			r.Body.Close()
+
+			if data["name"] != release.Name {
+				http.Error(w, "name changed", http.StatusBadRequest)
+				return
+			}
+			json.NewEncoder(w).Encode(gitlab.Release{TagName: tag, Name: data["name"], Description: data["description"]})
+		default:
+			http.Error(w, "invalid method", http.StatusMethodNotAllowed)
+		}
+		return
+	}
+
 	http.Error(w, "invalid route", http.StatusNotImplemented)
 }
 
@@ -211,3 +320,350 @@ func TestGitlabStripVTagRelease(t *testing.T) {
 	err = repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "2.0.0", SHA: "deadbeef"})
 	require.NoError(t, err)
 }
+
+func TestGitlabTagPrefix(t *testing.T) {
+	var releaseTag string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == fmt.Sprintf("/api/v4/projects/%d/repository/tags", GITLAB_PROJECT_ID):
+			json.NewEncoder(w).Encode([]*gitlab.Tag{ //nolint:errcheck
+				createGitlabTag("app-v1.0.0", "beefdead"),
+				createGitlabTag("app-1.1.0", "deadbeef"),
+				createGitlabTag("v3.0.0", "cafebabe"),
+				createGitlabTag("lib-4.0.0", "babecafe"),
+			})
+		case r.Method == "POST" && r.URL.Path == fmt.Sprintf("/api/v4/projects/%d/releases", GITLAB_PROJECT_ID):
+			var data map[string]string
+			json.NewDecoder(r.Body).Decode(&data) //nolint:errcheck
+			releaseTag = data["tag_name"]
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(gitlab.Release{TagName: releaseTag}) //nolint:errcheck
+		default:
+			GitlabHandler(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	repo := &GitLabRepository{}
+	err := repo.Init(map[string]string{
+		"gitlab_baseurl":     ts.URL,
+		"token":              "gitlab-examples-ci",
+		"gitlab_projectid":   strconv.Itoa(GITLAB_PROJECT_ID),
+		"gitlab_tag_prefix":  "app-",
+		"strip_v_tag_prefix": "true",
+	})
+	require.NoError(t, err)
+
+	releases, err := repo.GetReleases("")
+	require.NoError(t, err)
+	require.Equal(t, []*semrel.Release{{SHA: "beefdead", Version: "1.0.0"}, {SHA: "deadbeef", Version: "1.1.0"}}, releases)
+
+	err = repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "1.2.0", SHA: "deadbeef"})
+	require.NoError(t, err)
+	require.Equal(t, "app-1.2.0", releaseTag)
+}
+
+func TestGitlabCreateReleaseAllowUpdate(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(GitlabHandler))
+	defer ts.Close()
+
+	config := map[string]string{
+		"gitlab_baseurl":   ts.URL,
+		"token":            "gitlab-examples-ci",
+		"gitlab_projectid": strconv.Itoa(GITLAB_PROJECT_ID),
+	}
+
+	repo := &GitLabRepository{}
+	require.NoError(t, repo.Init(config))
+	err := repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "1.0.0", SHA: "deadbeef"})
+	require.Error(t, err)
+
+	config["gitlab_allow_update"] = "true"
+	repo = &GitLabRepository{}
+	require.NoError(t, repo.Init(config))
+	err = repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "1.0.0", SHA: "deadbeef", Changelog: "updated"})
+	require.NoError(t, err)
+}
+
+func TestGitlabCreateReleaseUpdateMode(t *testing.T) {
+	for mode, expected := range map[string]string{
+		"replace": "* feat: hotfix",
+		"append":  "initial\n\n---\n\n* feat: hotfix",
+		"prepend": "* feat: hotfix\n\n---\n\ninitial",
+	} {
+		t.Run(mode, func(t *testing.T) {
+			var description string
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == "PUT" {
+					var data map[string]string
+					json.NewDecoder(r.Body).Decode(&data) //nolint:errcheck
+					description = data["description"]
+					fmt.Fprint(w, "{}")
+					return
+				}
+				GitlabHandler(w, r)
+			}))
+			defer ts.Close()
+
+			repo := &GitLabRepository{}
+			require.NoError(t, repo.Init(map[string]string{
+				"gitlab_baseurl":      ts.URL,
+				"token":               "gitlab-examples-ci",
+				"gitlab_projectid":    strconv.Itoa(GITLAB_PROJECT_ID),
+				"gitlab_allow_update": "true",
+				"gitlab_update_mode":  mode,
+			}))
+			err := repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "1.0.0", SHA: "deadbeef", Changelog: "* feat: hotfix"})
+			require.NoError(t, err)
+			require.Equal(t, expected, description)
+		})
+	}
+
+	// the changelog of a retried job is not added twice
+	require.Equal(t, "initial\n\n---\n\n* feat: x", updatedDescription("initial\n\n---\n\n* feat: x", "* feat: x", updateModeAppend))
+}
+
+func TestGitlabCreateReleaseUpdateLinks(t *testing.T) {
+	linksPath := fmt.Sprintf("/api/v4/projects/%d/releases/v1.0.0/assets/links", GITLAB_PROJECT_ID)
+	var created, updated []map[string]string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var data map[string]string
+		//nolint:errcheck
+		switch {
+		case r.Method == "GET" && r.URL.Path == linksPath:
+			json.NewEncoder(w).Encode([]*gitlab.ReleaseLink{
+				{ID: 1, Name: "Docs", URL: "https://docs.example.com/0.9.0"},
+				{ID: 2, Name: "Manual", URL: "https://example.com/manual"},
+			})
+		case r.Method == "POST" && r.URL.Path == linksPath:
+			json.NewDecoder(r.Body).Decode(&data)
+			created = append(created, data)
+			fmt.Fprint(w, "{}")
+		case r.Method == "PUT" && r.URL.Path == linksPath+"/1":
+			json.NewDecoder(r.Body).Decode(&data)
+			updated = append(updated, data)
+			fmt.Fprint(w, "{}")
+		default:
+			GitlabHandler(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	repo := &GitLabRepository{}
+	require.NoError(t, repo.Init(map[string]string{
+		"gitlab_baseurl":      ts.URL,
+		"token":               "gitlab-examples-ci",
+		"gitlab_projectid":    strconv.Itoa(GITLAB_PROJECT_ID),
+		"gitlab_allow_update": "true",
+		"gitlab_asset_links":  "Docs=https://docs.example.com/{{.Version}}, Download=https://example.com/{{.Tag}}.tar.gz",
+	}))
+	err := repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "1.0.0", SHA: "deadbeef"})
+	require.NoError(t, err)
+	// the outdated link is updated, the missing one is added and the manual one is kept
+	require.Equal(t, []map[string]string{{"url": "https://docs.example.com/1.0.0"}}, updated)
+	require.Equal(t, []map[string]string{{"name": "Download", "url": "https://example.com/v1.0.0.tar.gz"}}, created)
+}
+
+func TestGitlabCreateReleaseTagOnly(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(GitlabHandler))
+	defer ts.Close()
+
+	var createdRelease bool
+	ts.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "POST" && r.URL.Path == fmt.Sprintf("/api/v4/projects/%d/releases", GITLAB_PROJECT_ID) {
+			createdRelease = true
+		}
+		GitlabHandler(w, r)
+	})
+
+	repo := &GitLabRepository{}
+	err := repo.Init(map[string]string{
+		"gitlab_baseurl":   ts.URL,
+		"token":            "gitlab-examples-ci",
+		"gitlab_projectid": strconv.Itoa(GITLAB_PROJECT_ID),
+		"gitlab_tag_only":  "true",
+	})
+	require.NoError(t, err)
+
+	err = repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "2.0.0", SHA: "deadbeef"})
+	require.NoError(t, err)
+	require.False(t, createdRelease)
+}
+
+func TestGitlabCreateReleaseUseExistingTag(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(GitlabHandler))
+	defer ts.Close()
+
+	repo := &GitLabRepository{}
+	err := repo.Init(map[string]string{
+		"gitlab_baseurl":          ts.URL,
+		"token":                   "gitlab-examples-ci",
+		"gitlab_projectid":        strconv.Itoa(GITLAB_PROJECT_ID),
+		"gitlab_use_existing_tag": "true",
+	})
+	require.NoError(t, err)
+
+	err = repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "2.0.0", SHA: "deadbeef"})
+	require.NoError(t, err)
+
+	err = repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "2.0.0", SHA: "beefdead"})
+	require.EqualError(t, err, "tag v2.0.0 points at deadbeef instead of beefdead")
+
+	err = repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "5.0.0", SHA: "deadbeef"})
+	require.EqualError(t, err, "tag v5.0.0 does not exist")
+}
+
+func TestGitlabCreateReleaseTagMessage(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(GitlabHandler))
+	defer ts.Close()
+
+	var tagMessage string
+	var releaseRef *string
+	ts.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		var data map[string]*string
+		json.Unmarshal(body, &data) //nolint:errcheck
+		switch r.URL.Path {
+		case fmt.Sprintf("/api/v4/projects/%d/repository/tags", GITLAB_PROJECT_ID):
+			tagMessage = *data["message"]
+		case fmt.Sprintf("/api/v4/projects/%d/releases", GITLAB_PROJECT_ID):
+			releaseRef = data["ref"]
+		}
+		GitlabHandler(w, r)
+	})
+
+	repo := &GitLabRepository{}
+	err := repo.Init(map[string]string{
+		"gitlab_baseurl":     ts.URL,
+		"token":              "gitlab-examples-ci",
+		"gitlab_projectid":   strconv.Itoa(GITLAB_PROJECT_ID),
+		"gitlab_tag_message": "Release {{.Tag}}\n\n{{.Changelog}}",
+	})
+	require.NoError(t, err)
+
+	err = repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "2.0.0", SHA: "deadbeef", Changelog: "* feat"})
+	require.NoError(t, err)
+	require.Equal(t, "Release v2.0.0\n\n* feat", tagMessage)
+	require.Nil(t, releaseRef)
+}
+
+func TestSummarizeSections(t *testing.T) {
+	changelog := "## 2.0.0 (2022-01-01)\n\n" +
+		"#### Feature\n\n* **app:** new feature (abcd)\n* **cli:** new flag (dcba)\n  * nested detail\n\n" +
+		"#### Bug Fixes\n\n* bug (cdba)\n\n" +
+		"#### Empty\n\n"
+	require.Equal(t, "Feature (2), Bug Fixes (1)", summarizeSections(changelog))
+	require.Equal(t, "", summarizeSections("* feat"))
+
+	// the summary keeps the tag message short while the release gets the full changelog
+	message, err := renderTemplate(template.Must(template.New("gitlab_tag_message").Parse("{{.Tag}}: {{.Summary}}")), newTemplateData("v2.0.0", &provider.CreateReleaseConfig{NewVersion: "2.0.0", Changelog: changelog}))
+	require.NoError(t, err)
+	require.Equal(t, "v2.0.0: Feature (2), Bug Fixes (1)", message)
+}
+
+func TestGitlabCreateReleaseForceRetag(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(GitlabHandler))
+	defer ts.Close()
+
+	var deleted []string
+	ts.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "DELETE" {
+			deleted = append(deleted, strings.TrimPrefix(r.URL.Path, fmt.Sprintf("/api/v4/projects/%d/", GITLAB_PROJECT_ID)))
+			if strings.Contains(r.URL.Path, "/releases/") {
+				http.Error(w, `{"message":"404 Not Found"}`, http.StatusNotFound)
+			}
+			return
+		}
+		GitlabHandler(w, r)
+	})
+
+	var logs bytes.Buffer
+	repo := &GitLabRepository{logger: log.New(&logs, "", 0)}
+	err := repo.Init(map[string]string{
+		"gitlab_baseurl":     ts.URL,
+		"token":              "gitlab-examples-ci",
+		"gitlab_projectid":   strconv.Itoa(GITLAB_PROJECT_ID),
+		"gitlab_force_retag": "true",
+	})
+	require.NoError(t, err)
+
+	err = repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "2.0.0", SHA: "deadbeef"})
+	require.NoError(t, err)
+	require.Empty(t, deleted)
+
+	err = repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "2.0.0", SHA: "beefdead"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"releases/v2.0.0", "repository/tags/v2.0.0"}, deleted)
+	require.Contains(t, logs.String(), "tag v2.0.0 points at deadbeef instead of beefdead")
+}
+
+func TestGitlabCreateReleaseDryRun(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" {
+			t.Errorf("unexpected %s request to %s", r.Method, r.URL.Path)
+		}
+		if r.URL.Path == fmt.Sprintf("/api/v4/projects/%d/repository/commits/release-candidate", GITLAB_PROJECT_ID) {
+			json.NewEncoder(w).Encode(createGitlabCommit("cafebabe", "fix: candidate")) //nolint:errcheck
+			return
+		}
+		GitlabHandler(w, r)
+	}))
+	defer ts.Close()
+
+	var logs bytes.Buffer
+	repo := &GitLabRepository{logger: log.New(&logs, "", 0)}
+	err := repo.Init(map[string]string{
+		"gitlab_baseurl":   ts.URL,
+		"token":            "gitlab-examples-ci",
+		"gitlab_projectid": strconv.Itoa(GITLAB_PROJECT_ID),
+		"gitlab_dry_run":   "true",
+	})
+	require.NoError(t, err)
+
+	_, err = repo.GetReleases("")
+	require.NoError(t, err)
+
+	err = repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "4.0.0", SHA: "deadbeef", Changelog: strings.Repeat("a", 400)})
+	require.NoError(t, err)
+	require.Contains(t, logs.String(), "dry run: would create tag v4.0.0 at deadbeef")
+	require.Contains(t, logs.String(), strings.Repeat("a", 300)+"...")
+	require.NoError(t, repo.DeleteRelease("4.0.0"))
+
+	// the steps after the release log what they would do, the ref is resolved like in a real run
+	logs.Reset()
+	err = repo.Init(map[string]string{
+		"gitlab_baseurl":                ts.URL,
+		"token":                         "gitlab-examples-ci",
+		"gitlab_projectid":              strconv.Itoa(GITLAB_PROJECT_ID),
+		"gitlab_dry_run":                "true",
+		"gitlab_ref":                    "release-candidate",
+		"gitlab_environment":            "production",
+		"gitlab_comment_merge_requests": "true",
+		"gitlab_release_issues":         "true",
+		"gitlab_issue_label":            "released::{{.Version}}",
+		"gitlab_wiki_page":              "Releases/{{.Tag}}",
+		"gitlab_package_retention":      "3",
+		"gitlab_notify_url":             "https://hooks.example.com/services/secret",
+		"gitlab_audit_stream_url":       "https://audit.example.com/events?token=secret",
+	})
+	require.NoError(t, err)
+	err = repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "4.0.0", SHA: "beefdead"})
+	require.NoError(t, err)
+	for _, line := range []string{
+		"dry run: would release v4.0.0 from release-candidate at cafebabe instead of beefdead",
+		"dry run: would create tag v4.0.0 at cafebabe",
+		"dry run: would create a deployment of v4.0.0 at cafebabe in environment production",
+		"dry run: would comment on the merge requests released in v4.0.0",
+		"dry run: would comment on the issues closed by the merge requests released in v4.0.0",
+		"dry run: would label the released issues with released::4.0.0",
+		"dry run: would write the release notes of v4.0.0 to wiki page Releases/v4.0.0",
+		"dry run: would delete the outdated prerelease packages except for the newest 3",
+		"dry run: would send the release notification to hooks.example.com",
+		"dry run: would send the audit event of v4.0.0 to audit.example.com",
+	} {
+		require.Contains(t, logs.String(), line)
+	}
+	require.NotContains(t, logs.String(), "secret")
+}
@@ -0,0 +1,87 @@
+package provider
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/go-semantic-release/semantic-release/v2/pkg/provider"
+)
+
+const (
+	changelogSourceSemrel = "semrel"
+	changelogSourceGitLab = "gitlab"
+)
+
+func isValidChangelogSource(source string) bool {
+	return source == "" || source == changelogSourceSemrel || source == changelogSourceGitLab
+}
+
+// gitlabChangelogOptions are the parameters of the repository changelog API, which go-gitlab does not support yet
+type gitlabChangelogOptions struct {
+	Version    string `url:"version" json:"version"`
+	To         string `url:"to,omitempty" json:"to,omitempty"`
+	Trailer    string `url:"trailer,omitempty" json:"trailer,omitempty"`
+	ConfigFile string `url:"config_file,omitempty" json:"config_file,omitempty"`
+	Branch     string `url:"branch,omitempty" json:"branch,omitempty"`
+	File       string `url:"file,omitempty" json:"file,omitempty"`
+	Message    string `url:"message,omitempty" json:"message,omitempty"`
+}
+
+func (repo *GitLabRepository) changelogOptions(release *provider.CreateReleaseConfig) *gitlabChangelogOptions {
+	// GitLab starts the changelog at the latest tag before the version
+	return &gitlabChangelogOptions{
+		Version:    release.NewVersion,
+		To:         release.SHA,
+		Trailer:    repo.changelogTrailer,
+		ConfigFile: repo.changelogConfigFile,
+	}
+}
+
+// gitlabChangelog generates the release notes of the version from the changelog trailers of the commits
+func (repo *GitLabRepository) gitlabChangelog(release *provider.CreateReleaseConfig) (string, error) {
+	path := fmt.Sprintf("projects/%s/repository/changelog", url.PathEscape(repo.projectID))
+	req, err := repo.api.NewRequest(http.MethodGet, path, repo.changelogOptions(release), nil)
+	if err != nil {
+		return "", err
+	}
+	var changelog struct {
+		Notes string `json:"notes"`
+	}
+	if _, err := repo.api.Do(req, &changelog); err != nil {
+		return "", fmt.Errorf("failed to generate the changelog of %s: %w", release.NewVersion, err)
+	}
+	return changelog.Notes, nil
+}
+
+// commitGitLabChangelog adds the release notes of the version to gitlab_changelog_file in the release branch
+func (repo *GitLabRepository) commitGitLabChangelog(release *provider.CreateReleaseConfig) error {
+	opts := repo.changelogOptions(release)
+	opts.Branch = defaultString(repo.branch, release.Branch)
+	opts.File = repo.changelogFile
+	if repo.skipCI[commitKindChangelog] {
+		// the default message of GitLab
+		opts.Message = repo.commitMessage(commitKindChangelog, fmt.Sprintf("Add changelog for version %s", release.NewVersion))
+	}
+
+	path := fmt.Sprintf("projects/%s/repository/changelog", url.PathEscape(repo.projectID))
+	req, err := repo.api.NewRequest(http.MethodPost, path, opts, nil)
+	if err != nil {
+		return err
+	}
+	if _, err := repo.api.Do(req, nil); err != nil {
+		return fmt.Errorf("failed to commit the changelog of %s to %s: %w", release.NewVersion, repo.changelogFile, err)
+	}
+	repo.logger.Printf("committed the changelog of %s to %s in branch %s", release.NewVersion, repo.changelogFile, opts.Branch)
+	return nil
+}
+
+func withChangelog(release *provider.CreateReleaseConfig, changelog string) *provider.CreateReleaseConfig {
+	return &provider.CreateReleaseConfig{
+		Changelog:  changelog,
+		NewVersion: release.NewVersion,
+		Prerelease: release.Prerelease,
+		Branch:     release.Branch,
+		SHA:        release.SHA,
+	}
+}
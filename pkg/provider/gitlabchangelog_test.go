@@ -0,0 +1,103 @@
+package provider
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/go-semantic-release/semantic-release/v2/pkg/provider"
+	"github.com/stretchr/testify/require"
+	"github.com/xanzy/go-gitlab"
+)
+
+func TestGitlabChangelogSource(t *testing.T) {
+	changelogPath := fmt.Sprintf("/api/v4/projects/%d/repository/changelog", GITLAB_PROJECT_ID)
+	var description string
+	var committed map[string]string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == changelogPath:
+			require.Equal(t, "2.0.0", r.URL.Query().Get("version"))
+			require.Equal(t, "deadbeef", r.URL.Query().Get("to"))
+			require.Equal(t, "Type", r.URL.Query().Get("trailer"))
+			require.Equal(t, ".gitlab/changelog.yml", r.URL.Query().Get("config_file"))
+			fmt.Fprint(w, `{"notes": "## 2.0.0 (2022-01-01)\n\n### feature (1 change)\n\n- [Add the thing](group/project@deadbeef)\n"}`)
+		case r.Method == "POST" && r.URL.Path == changelogPath:
+			json.NewDecoder(r.Body).Decode(&committed) //nolint:errcheck
+			fmt.Fprint(w, "{}")
+		case r.Method == "POST" && r.URL.Path == fmt.Sprintf("/api/v4/projects/%d/releases", GITLAB_PROJECT_ID):
+			var opts gitlab.CreateReleaseOptions
+			json.NewDecoder(r.Body).Decode(&opts) //nolint:errcheck
+			description = *opts.Description
+			fmt.Fprint(w, "{}")
+		default:
+			GitlabHandler(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	repo := &GitLabRepository{}
+	err := repo.Init(map[string]string{
+		"gitlab_baseurl":               ts.URL,
+		"token":                        "token",
+		"gitlab_projectid":             strconv.Itoa(GITLAB_PROJECT_ID),
+		"gitlab_branch":                "main",
+		"gitlab_changelog_source":      "gitlab",
+		"gitlab_changelog_trailer":     "Type",
+		"gitlab_changelog_config_file": ".gitlab/changelog.yml",
+		"gitlab_changelog_file":        "CHANGELOG.md",
+	})
+	require.NoError(t, err)
+
+	err = repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "2.0.0", SHA: "deadbeef", Changelog: "* feat: add the thing"})
+	require.NoError(t, err)
+	require.Equal(t, "## 2.0.0 (2022-01-01)\n\n### feature (1 change)\n\n- [Add the thing](group/project@deadbeef)\n", description)
+	require.Equal(t, map[string]string{
+		"version":     "2.0.0",
+		"to":          "deadbeef",
+		"trailer":     "Type",
+		"config_file": ".gitlab/changelog.yml",
+		"branch":      "main",
+		"file":        "CHANGELOG.md",
+	}, committed)
+}
+
+func TestGitlabChangelogSourceDryRun(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == fmt.Sprintf("/api/v4/projects/%d/repository/changelog", GITLAB_PROJECT_ID) {
+			require.Equal(t, "GET", r.Method)
+			fmt.Fprint(w, `{"notes": "## 2.0.0"}`)
+			return
+		}
+		GitlabHandler(w, r)
+	}))
+	defer ts.Close()
+
+	var logs bytes.Buffer
+	repo := &GitLabRepository{logger: log.New(&logs, "", 0)}
+	err := repo.Init(map[string]string{
+		"gitlab_baseurl":          ts.URL,
+		"token":                   "token",
+		"gitlab_projectid":        strconv.Itoa(GITLAB_PROJECT_ID),
+		"gitlab_changelog_source": "gitlab",
+		"gitlab_changelog_file":   "CHANGELOG.md",
+		"gitlab_dry_run":          "true",
+	})
+	require.NoError(t, err)
+
+	err = repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "2.0.0", SHA: "deadbeef", Changelog: "* feat: add the thing"})
+	require.NoError(t, err)
+	require.Contains(t, logs.String(), "dry run: would commit the changelog of 2.0.0 to CHANGELOG.md")
+	require.Contains(t, logs.String(), "dry run: would create release v2.0.0 with description:\n## 2.0.0")
+}
+
+func TestGitlabChangelogSourceInvalid(t *testing.T) {
+	repo := &GitLabRepository{}
+	err := repo.Init(map[string]string{"token": "token", "gitlab_projectid": "1", "gitlab_changelog_source": "github"})
+	require.EqualError(t, err, `failed to set property gitlab_changelog_source: unknown source "github", expected semrel or gitlab`)
+}
@@ -0,0 +1,83 @@
+package provider
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/go-semantic-release/provider-gitlab/pkg/gitlabtest"
+	"github.com/go-semantic-release/semantic-release/v2/pkg/provider"
+	"github.com/stretchr/testify/require"
+	"github.com/xanzy/go-gitlab"
+)
+
+func TestReleaseWithFakeServer(t *testing.T) {
+	server := gitlabtest.NewServer()
+	defer server.Close()
+	project := server.AddProject(gitlab.Project{PathWithNamespace: "group/app"})
+	server.AddCommit(project, "c1", "feat: initial")
+	server.AddTag(project, "v1.0.0", "c1")
+	for i := 2; i <= 5; i++ {
+		server.AddCommit(project, fmt.Sprintf("c%d", i), fmt.Sprintf("fix: change %d", i))
+	}
+
+	repo := &GitLabRepository{}
+	err := repo.Init(map[string]string{
+		"gitlab_baseurl":   server.URL,
+		"token":            "token",
+		"gitlab_projectid": "group/app",
+		"gitlab_per_page":  "2",
+	})
+	require.NoError(t, err)
+
+	releases, err := repo.GetReleases("")
+	require.NoError(t, err)
+	require.Len(t, releases, 1)
+
+	commits, err := repo.GetCommits(releases[0].SHA, "main")
+	require.NoError(t, err)
+	require.Len(t, commits, 4)
+	require.Equal(t, "c5", commits[0].SHA)
+
+	// rate limited requests are retried
+	sent := len(server.Requests())
+	server.RateLimit(1)
+	err = repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "1.0.1", SHA: "c5", Changelog: "fixes"})
+	require.NoError(t, err)
+
+	release := server.Release(project, "v1.0.1")
+	require.NotNil(t, release)
+	require.Equal(t, "c5", release.Commit.ID)
+	require.Equal(t, "fixes", release.Description)
+
+	requests := server.Requests()
+	require.Contains(t, requests, "GET /api/v4/projects/group/app/repository/commits")
+	require.Equal(t, requests[sent], requests[sent+1])
+}
+
+// newFakeServer starts a fake GitLab instance with the project group/app, it is closed at the end of the test
+func newFakeServer(t *testing.T) (*gitlabtest.Server, *gitlabtest.Project) {
+	server := gitlabtest.NewServer()
+	t.Cleanup(server.Close)
+	return server, server.AddProject(gitlab.Project{PathWithNamespace: "group/app"})
+}
+
+// addGitlabCommits adds the GITLAB_COMMITS fixture to the project, abcd becomes the head of the default branch
+func addGitlabCommits(server *gitlabtest.Server, project *gitlabtest.Project) {
+	for i := len(GITLAB_COMMITS) - 1; i >= 0; i-- {
+		server.AddCommit(project, GITLAB_COMMITS[i].ID, GITLAB_COMMITS[i].Message)
+	}
+}
+
+// initFakeRepo initializes the repository for the project group/app of the fake server with the options of config
+func initFakeRepo(t *testing.T, repo *GitLabRepository, server *gitlabtest.Server, config map[string]string) *GitLabRepository {
+	options := map[string]string{
+		"gitlab_baseurl":   server.URL,
+		"token":            "token",
+		"gitlab_projectid": "group/app",
+	}
+	for key, value := range config {
+		options[key] = value
+	}
+	require.NoError(t, repo.Init(options))
+	return repo
+}
@@ -0,0 +1,205 @@
+package provider
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-semantic-release/semantic-release/v2/pkg/semrel"
+	"github.com/xanzy/go-gitlab"
+)
+
+// merged merge requests with everything needed to associate them with commits, one page covers up to 100 merge
+// requests instead of one REST request per commit
+const mergedMergeRequestsQuery = `query($fullPath: ID!, $targetBranches: [String!], $mergedAfter: Time, $after: String) {
+  project(fullPath: $fullPath) {
+    mergeRequests(state: merged, targetBranches: $targetBranches, mergedAfter: $mergedAfter, sort: MERGED_AT_DESC, first: 100, after: $after) {
+      pageInfo { hasNextPage endCursor }
+      nodes {
+        iid
+        title
+        webUrl
+        diffHeadSha
+        mergeCommitSha
+        squashCommitSha
+        author { username }
+        labels { nodes { title } }
+        commits(first: 100) { nodes { sha } }
+      }
+    }
+  }
+}`
+
+type graphqlRequest struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables,omitempty"`
+}
+
+type graphqlResponse struct {
+	Data   interface{} `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+type graphqlMergeRequests struct {
+	Project *struct {
+		MergeRequests struct {
+			PageInfo struct {
+				HasNextPage bool   `json:"hasNextPage"`
+				EndCursor   string `json:"endCursor"`
+			} `json:"pageInfo"`
+			Nodes []struct {
+				IID             string `json:"iid"`
+				Title           string `json:"title"`
+				WebURL          string `json:"webUrl"`
+				DiffHeadSHA     string `json:"diffHeadSha"`
+				MergeCommitSHA  string `json:"mergeCommitSha"`
+				SquashCommitSHA string `json:"squashCommitSha"`
+				Author          *struct {
+					Username string `json:"username"`
+				} `json:"author"`
+				Labels struct {
+					Nodes []struct {
+						Title string `json:"title"`
+					} `json:"nodes"`
+				} `json:"labels"`
+				Commits struct {
+					Nodes []struct {
+						SHA string `json:"sha"`
+					} `json:"nodes"`
+				} `json:"commits"`
+			} `json:"nodes"`
+		} `json:"mergeRequests"`
+	} `json:"project"`
+}
+
+// queryGraphQL runs the query against the GraphQL API of the instance and decodes its data into v
+func (repo *GitLabRepository) queryGraphQL(query string, variables map[string]interface{}, v interface{}) error {
+	req, err := repo.api.NewRequest(http.MethodPost, "", &graphqlRequest{Query: query, Variables: variables}, nil)
+	if err != nil {
+		return err
+	}
+	// the GraphQL endpoint is a sibling of the REST API
+	endpoint := repo.api.BaseURL()
+	endpoint.Path = strings.TrimSuffix(strings.TrimSuffix(endpoint.Path, "/"), "/v4") + "/graphql"
+	endpoint.RawPath = ""
+	req.URL = endpoint
+	req.Host = endpoint.Host
+
+	resp := &graphqlResponse{Data: v}
+	if _, err := repo.api.Do(req, resp); err != nil {
+		return err
+	}
+	if len(resp.Errors) > 0 {
+		messages := make([]string, 0, len(resp.Errors))
+		for _, e := range resp.Errors {
+			messages = append(messages, e.Message)
+		}
+		return fmt.Errorf("GraphQL query failed: %s", strings.Join(messages, ", "))
+	}
+	return nil
+}
+
+// fetchCommitMergeRequests finds the merged merge requests of the commits with a few GraphQL requests and annotates
+// the commits with the merge request, its labels and its author, the associations are kept for the release steps
+func (repo *GitLabRepository) fetchCommitMergeRequests(fromSha string, commits []*semrel.RawCommit) error {
+	project, err := repo.getProject()
+	if err != nil {
+		return err
+	}
+
+	variables := map[string]interface{}{"fullPath": project.PathWithNamespace}
+	if repo.branch != "" {
+		variables["targetBranches"] = []string{repo.branch}
+	}
+	// the merge requests of the new commits were merged after the previous release was committed
+	if fromSha != "" {
+		previous, _, err := repo.reader().GetCommit(repo.projectID, fromSha)
+		if err != nil {
+			return fmt.Errorf("failed to get commit %s: %w", fromSha, err)
+		}
+		if previous.CommittedDate != nil {
+			variables["mergedAfter"] = previous.CommittedDate.UTC().Format(time.RFC3339)
+		}
+	}
+
+	wanted := make(map[string]bool, len(commits))
+	pending := make(map[string]bool, len(commits))
+	for _, commit := range commits {
+		wanted[commit.SHA] = true
+		pending[commit.SHA] = true
+	}
+	associations := make(map[string][]*gitlab.MergeRequest, len(commits))
+
+	for page := 1; len(pending) > 0; page++ {
+		var data graphqlMergeRequests
+		if err := repo.queryGraphQL(mergedMergeRequestsQuery, variables, &data); err != nil {
+			return err
+		}
+		if data.Project == nil {
+			return fmt.Errorf("project %s not found: %w", project.PathWithNamespace, ErrProjectNotFound)
+		}
+
+		connection := data.Project.MergeRequests
+		for _, node := range connection.Nodes {
+			var iid int
+			if _, err := fmt.Sscan(node.IID, &iid); err != nil {
+				return fmt.Errorf("invalid merge request iid %q", node.IID)
+			}
+			mr := &gitlab.MergeRequest{
+				IID:             iid,
+				Title:           node.Title,
+				WebURL:          node.WebURL,
+				State:           "merged",
+				SHA:             node.DiffHeadSHA,
+				MergeCommitSHA:  node.MergeCommitSHA,
+				SquashCommitSHA: node.SquashCommitSHA,
+				Labels:          make(gitlab.Labels, 0, len(node.Labels.Nodes)),
+			}
+			if node.Author != nil {
+				mr.Author = &gitlab.BasicUser{Username: node.Author.Username}
+			}
+			for _, label := range node.Labels.Nodes {
+				mr.Labels = append(mr.Labels, label.Title)
+			}
+
+			shas := []string{node.MergeCommitSHA, node.SquashCommitSHA}
+			for _, commit := range node.Commits.Nodes {
+				shas = append(shas, commit.SHA)
+			}
+			for _, sha := range shas {
+				if !wanted[sha] {
+					continue
+				}
+				delete(pending, sha)
+				associations[sha] = append(associations[sha], mr)
+			}
+		}
+
+		if !connection.PageInfo.HasNextPage || repo.pageLimitReached(page, "merge requests") {
+			break
+		}
+		variables["after"] = connection.PageInfo.EndCursor
+	}
+
+	for _, commit := range commits {
+		mrs := associations[commit.SHA]
+		if len(mrs) == 0 {
+			continue
+		}
+		if commit.Annotations == nil {
+			commit.Annotations = make(map[string]string)
+		}
+		mr := mrs[0]
+		commit.Annotations["gitlab_merge_request"] = fmt.Sprintf("!%d", mr.IID)
+		commit.Annotations["gitlab_merge_request_labels"] = strings.Join(mr.Labels, "\n")
+		if mr.Author != nil {
+			commit.Annotations["gitlab_merge_request_author"] = mr.Author.Username
+		}
+	}
+
+	repo.commitMergeRequests = associations
+	return nil
+}
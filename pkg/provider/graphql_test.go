@@ -0,0 +1,122 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xanzy/go-gitlab"
+)
+
+func TestGitlabGraphQLMergeRequests(t *testing.T) {
+	var queries []graphqlRequest
+	restLookups := 0
+	//nolint:errcheck
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		prefix := fmt.Sprintf("/api/v4/projects/%d/", GITLAB_PROJECT_ID)
+		path := strings.TrimPrefix(r.URL.Path, prefix)
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/api/graphql":
+			var query graphqlRequest
+			json.NewDecoder(r.Body).Decode(&query)
+			queries = append(queries, query)
+			if query.Variables["after"] == nil {
+				fmt.Fprint(w, `{"data": {"project": {"mergeRequests": {
+					"pageInfo": {"hasNextPage": true, "endCursor": "cursor1"},
+					"nodes": [{"iid": "7", "mergeCommitSha": "abcd", "author": {"username": "jane"},
+						"labels": {"nodes": [{"title": "feature"}, {"title": "ui"}]}, "commits": {"nodes": [{"sha": "dcba"}]}}]
+				}}}}`)
+				return
+			}
+			fmt.Fprint(w, `{"data": {"project": {"mergeRequests": {
+				"pageInfo": {"hasNextPage": false, "endCursor": "cursor2"},
+				"nodes": [{"iid": "5", "squashCommitSha": "cdba", "labels": {"nodes": []}, "commits": {"nodes": []}}]
+			}}}}`)
+		case r.Method == "GET" && path == "repository/commits/0000":
+			json.NewEncoder(w).Encode(&gitlab.Commit{ID: "0000", CommittedDate: timePtr(time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC))})
+		case r.Method == "GET" && strings.HasSuffix(path, "/merge_requests"):
+			restLookups++
+			fmt.Fprint(w, "[]")
+		case r.Method == "GET" && r.URL.Path == strings.TrimSuffix(prefix, "/"):
+			project := GITLAB_PROJECT
+			project.PathWithNamespace = "group/project"
+			json.NewEncoder(w).Encode(project)
+		default:
+			GitlabHandler(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	repo := &GitLabRepository{}
+	err := repo.Init(map[string]string{
+		"gitlab_baseurl":   ts.URL,
+		"token":            "token",
+		"gitlab_projectid": strconv.Itoa(GITLAB_PROJECT_ID),
+		"gitlab_branch":    "main",
+		"gitlab_graphql":   "true",
+	})
+	require.NoError(t, err)
+
+	commits, err := repo.GetCommits("0000", "efcd")
+	require.NoError(t, err)
+
+	require.Len(t, queries, 2)
+	require.Equal(t, map[string]interface{}{
+		"fullPath":       "group/project",
+		"targetBranches": []interface{}{"main"},
+		"mergedAfter":    "2022-01-02T03:04:05Z",
+	}, queries[0].Variables)
+	require.Equal(t, "cursor1", queries[1].Variables["after"])
+
+	annotations := make(map[string]map[string]string)
+	for _, commit := range commits {
+		annotations[commit.SHA] = commit.Annotations
+	}
+	require.Equal(t, map[string]string{
+		"gitlab_merge_request":        "!7",
+		"gitlab_merge_request_labels": "feature\nui",
+		"gitlab_merge_request_author": "jane",
+	}, annotations["abcd"])
+	require.Equal(t, annotations["abcd"], annotations["dcba"])
+	require.Equal(t, map[string]string{
+		"gitlab_merge_request":        "!5",
+		"gitlab_merge_request_labels": "",
+	}, annotations["cdba"])
+	require.Nil(t, annotations["efcd"])
+
+	mergeRequests, err := repo.mergeRequestsForCommits(repo.commitSHAs())
+	require.NoError(t, err)
+	require.Len(t, mergeRequests, 2)
+	require.Equal(t, 7, mergeRequests[0].IID)
+	require.Equal(t, 5, mergeRequests[1].IID)
+	require.Zero(t, restLookups)
+}
+
+func TestGitlabGraphQLErrors(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/graphql" {
+			fmt.Fprint(w, `{"errors": [{"message": "Field 'mergedAfter' doesn't exist"}]}`)
+			return
+		}
+		GitlabHandler(w, r)
+	}))
+	defer ts.Close()
+
+	repo := &GitLabRepository{}
+	err := repo.Init(map[string]string{
+		"gitlab_baseurl":   ts.URL,
+		"token":            "token",
+		"gitlab_projectid": strconv.Itoa(GITLAB_PROJECT_ID),
+		"gitlab_graphql":   "true",
+	})
+	require.NoError(t, err)
+
+	_, err = repo.GetCommits("", "efcd")
+	require.EqualError(t, err, "GraphQL query failed: Field 'mergedAfter' doesn't exist")
+}
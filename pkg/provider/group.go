@@ -0,0 +1,102 @@
+package provider
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-semantic-release/semantic-release/v2/pkg/semrel"
+	"github.com/xanzy/go-gitlab"
+)
+
+// groupProjects returns the active projects of the group and its subgroups matching the include and exclude
+// filters, the released project itself is not part of them
+func (repo *GitLabRepository) groupProjects() ([]*GitLabRepository, error) {
+	if repo.groupTargets != nil {
+		return repo.groupTargets, nil
+	}
+
+	opts := &gitlab.ListGroupProjectsOptions{
+		ListOptions:      repo.listOptions(),
+		Archived:         gitlab.Bool(false),
+		IncludeSubGroups: gitlab.Bool(true),
+	}
+
+	targets := make([]*GitLabRepository, 0)
+	for {
+		projects, resp, err := repo.api.ListGroupProjects(repo.groupID, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list projects of group %s: %w", repo.groupID, err)
+		}
+
+		for _, project := range projects {
+			path := project.PathWithNamespace
+			switch {
+			case strconv.Itoa(project.ID) == repo.projectID || path == repo.projectID:
+				continue
+			case repo.groupInclude != nil && !repo.groupInclude.MatchString(path):
+				continue
+			case repo.groupExclude != nil && repo.groupExclude.MatchString(path):
+				continue
+			}
+			targets = append(targets, repo.newTargetRepository(path, project.DefaultBranch))
+		}
+
+		if resp.NextPage == 0 || repo.pageLimitReached(opts.Page, "group projects") {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	repo.groupTargets = targets
+	return targets, nil
+}
+
+// getGroupCommits returns the commits of all group projects since the release tag of fromSha. Projects without the
+// tag, e.g. projects added to the group since, contribute the commits of their default branch since the date of the
+// release, their older history was never part of a release of the group.
+func (repo *GitLabRepository) getGroupCommits(fromSha string) ([]*semrel.RawCommit, error) {
+	targets, err := repo.groupProjects()
+	if err != nil {
+		return nil, err
+	}
+
+	tag := repo.releaseTags[fromSha]
+	allCommits := make([]*semrel.RawCommit, 0)
+	for _, target := range targets {
+		opts := &gitlab.ListCommitsOptions{RefName: gitlab.String(target.branch)}
+		if tag != "" {
+			_, resp, err := repo.api.GetTag(target.projectID, tag)
+			switch {
+			case err == nil:
+				opts.RefName = gitlab.String(fmt.Sprintf("%s...%s", tag, target.branch))
+			case resp == nil || resp.StatusCode != http.StatusNotFound:
+				return nil, fmt.Errorf("failed to get tag %s of project %s: %w", tag, target.projectID, err)
+			}
+		}
+		if fromSha != "" && *opts.RefName == target.branch {
+			since := repo.releaseDates[fromSha]
+			if since == nil {
+				from, _, err := repo.api.GetCommit(repo.projectID, fromSha)
+				if err != nil {
+					return nil, fmt.Errorf("failed to get commit %s: %w", fromSha, err)
+				}
+				since = from.CommittedDate
+			}
+			repo.debugf("project %s has no tag %s, listing its commits since %s", target.projectID, tag, since)
+			// commits of the second of the previous release were part of it
+			opts.Since = gitlab.Time(since.Add(time.Second))
+		}
+
+		commits, _, err := target.listCommitPages(target.reader(), opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list commits of project %s: %w", target.projectID, err)
+		}
+		for _, commit := range commits {
+			commit.Annotations = map[string]string{"gitlab_project": target.projectID}
+		}
+		allCommits = append(allCommits, commits...)
+	}
+	return allCommits, nil
+}
@@ -0,0 +1,134 @@
+package provider
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/go-semantic-release/semantic-release/v2/pkg/provider"
+	"github.com/stretchr/testify/require"
+	"github.com/xanzy/go-gitlab"
+)
+
+func TestGitlabGroupRelease(t *testing.T) {
+	tagged := map[string]string{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" && r.URL.Path == "/api/v4/groups/platform/projects" {
+			require.Equal(t, "true", r.URL.Query().Get("include_subgroups"))
+			json.NewEncoder(w).Encode([]*gitlab.Project{ //nolint:errcheck
+				{ID: GITLAB_PROJECT_ID, PathWithNamespace: "platform/meta", DefaultBranch: "main"},
+				{ID: 1, PathWithNamespace: "platform/svc-a", DefaultBranch: "main"},
+				{ID: 2, PathWithNamespace: "platform/svc-b", DefaultBranch: "master"},
+				{ID: 3, PathWithNamespace: "platform/legacy-c", DefaultBranch: "main"},
+			})
+			return
+		}
+
+		path := strings.TrimPrefix(r.URL.Path, "/api/v4/projects/")
+		switch {
+		case r.Method == "GET" && path == fmt.Sprintf("%d/repository/commits/deadbeef", GITLAB_PROJECT_ID):
+			fmt.Fprint(w, `{"id":"deadbeef","committed_date":"2023-01-01T00:00:00Z"}`)
+		case !strings.HasPrefix(path, "platform/"):
+			GitlabHandler(w, r)
+		case r.Method == "GET" && path == "platform/svc-a/repository/tags/v1.0.0":
+			fmt.Fprint(w, `{"name":"v1.0.0"}`)
+		case r.Method == "GET" && strings.HasSuffix(path, "/repository/commits"):
+			project := strings.TrimSuffix(path, "/repository/commits")
+			sha := project + "@" + r.URL.Query().Get("ref_name")
+			if since := r.URL.Query().Get("since"); since != "" {
+				sha += " since " + since
+			}
+			json.NewEncoder(w).Encode([]*gitlab.Commit{ //nolint:errcheck
+				createGitlabCommit(sha, "fix: bug"),
+			})
+		case r.Method == "GET" && strings.Contains(path, "/repository/branches/"):
+			json.NewEncoder(w).Encode(gitlab.Branch{Commit: &gitlab.Commit{ID: "head-of-" + path}}) //nolint:errcheck
+		case r.Method == "POST" && strings.HasSuffix(path, "/repository/tags"):
+			var opts gitlab.CreateTagOptions
+			json.NewDecoder(r.Body).Decode(&opts) //nolint:errcheck
+			tagged[strings.TrimSuffix(path, "/repository/tags")] = *opts.TagName + "@" + *opts.Ref
+			fmt.Fprint(w, "{}")
+		case r.Method == "POST" && strings.HasSuffix(path, "/releases"):
+			fmt.Fprint(w, "{}")
+		default:
+			http.Error(w, `{"message":"404 Not Found"}`, http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	repo := &GitLabRepository{}
+	err := repo.Init(map[string]string{
+		"gitlab_baseurl":       ts.URL,
+		"token":                "gitlab-examples-ci",
+		"gitlab_projectid":     strconv.Itoa(GITLAB_PROJECT_ID),
+		"gitlab_group_id":      "platform",
+		"gitlab_group_exclude": "^platform/legacy-",
+	})
+	require.NoError(t, err)
+
+	_, err = repo.GetReleases(`^v1\.0\.0$`)
+	require.NoError(t, err)
+
+	commits, err := repo.GetCommits("deadbeef", "")
+	require.NoError(t, err)
+	shas := make([]string, 0)
+	for _, commit := range commits {
+		if commit.Annotations != nil {
+			shas = append(shas, commit.Annotations["gitlab_project"]+" "+commit.SHA)
+		}
+	}
+	require.Equal(t, []string{
+		"platform/svc-a platform/svc-a@v1.0.0...main",
+		// the project has no release yet, its history before the release of the group is not part of it
+		"platform/svc-b platform/svc-b@master since 2023-01-01T00:00:01Z",
+	}, shas)
+	require.Len(t, commits, len(GITLAB_COMMITS)+2)
+
+	err = repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "2.0.0", SHA: "deadbeef"})
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{
+		"platform/svc-a": "v2.0.0@head-of-platform/svc-a/repository/branches/main",
+		"platform/svc-b": "v2.0.0@head-of-platform/svc-b/repository/branches/master",
+	}, tagged)
+}
+
+func TestGitlabGroupProjectsPageLimits(t *testing.T) {
+	var perPages []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v4/groups/platform/projects" {
+			GitlabHandler(w, r)
+			return
+		}
+		// a group with endless projects
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		perPages = append(perPages, r.URL.Query().Get("per_page"))
+		w.Header().Set("X-Page", strconv.Itoa(page))
+		w.Header().Set("X-Next-Page", strconv.Itoa(page+1))
+		fmt.Fprintf(w, `[{"id": %d, "path_with_namespace": "platform/svc-%d"}]`, 1000+page, page)
+	}))
+	defer ts.Close()
+
+	var logs bytes.Buffer
+	repo := &GitLabRepository{logger: log.New(&logs, "", 0)}
+	err := repo.Init(map[string]string{
+		"gitlab_baseurl":   ts.URL,
+		"token":            "token",
+		"gitlab_projectid": strconv.Itoa(GITLAB_PROJECT_ID),
+		"gitlab_group_id":  "platform",
+		"gitlab_per_page":  "20",
+		"gitlab_max_pages": "2",
+	})
+	require.NoError(t, err)
+
+	targets, err := repo.groupProjects()
+	require.NoError(t, err)
+	require.Len(t, targets, 2)
+	require.Equal(t, []string{"20", "20"}, perPages)
+	require.Contains(t, logs.String(), "WARNING: stopped listing group projects after 2 pages")
+}
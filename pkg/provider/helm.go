@@ -0,0 +1,88 @@
+package provider
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+const defaultHelmChannel = "stable"
+
+var helmChartNameRe = regexp.MustCompile(`(?m)^name:[ \t]*["']?([^"'\s#]+)`)
+
+// packageHelmChart returns the file name and content of the configured chart archive, a chart directory is packaged
+// with the new version like helm package does
+func packageHelmChart(chartPath, version string) (string, []byte, error) {
+	if strings.HasSuffix(chartPath, ".tgz") {
+		archive, err := os.ReadFile(chartPath)
+		if err != nil {
+			return "", nil, err
+		}
+		return filepath.Base(chartPath), archive, nil
+	}
+
+	chartYAML, err := os.ReadFile(filepath.Join(chartPath, "Chart.yaml"))
+	if err != nil {
+		return "", nil, err
+	}
+	m := helmChartNameRe.FindSubmatch(chartYAML)
+	if m == nil {
+		return "", nil, fmt.Errorf("no chart name found in %s", filepath.Join(chartPath, "Chart.yaml"))
+	}
+	name := string(m[1])
+
+	chartFile, err := newVersionFile("Chart.yaml", "")
+	if err != nil {
+		return "", nil, err
+	}
+	if chartYAML, err = chartFile.bump(chartYAML, version); err != nil {
+		return "", nil, err
+	}
+
+	// hidden files like .helmignore are not part of the chart
+	archive, err := tarGzDir(chartPath, name+"/", map[string][]byte{"Chart.yaml": chartYAML})
+	if err != nil {
+		return "", nil, err
+	}
+	return fmt.Sprintf("%s-%s.tgz", name, version), archive, nil
+}
+
+// publishHelmChart pushes the chart to the Helm channel of the project and links it from the release
+func (repo *GitLabRepository) publishHelmChart(version string) error {
+	fileName, archive, err := packageHelmChart(repo.helmChart, version)
+	if err != nil {
+		return fmt.Errorf("failed to package helm chart %s: %w", repo.helmChart, err)
+	}
+
+	channel := url.PathEscape(repo.helmChannel)
+	req, err := repo.api.UploadRequest(
+		http.MethodPost,
+		fmt.Sprintf("projects/%s/packages/helm/api/%s/charts", url.PathEscape(repo.projectID), channel),
+		bytes.NewReader(archive),
+		fileName,
+		gitlab.UploadType("chart"),
+		nil,
+		nil,
+	)
+	if err != nil {
+		return err
+	}
+	if _, err := repo.api.Do(req, nil); err != nil {
+		return fmt.Errorf("failed to publish helm chart %s: %w", fileName, err)
+	}
+	repo.logger.Printf("published helm chart %s to channel %s", fileName, repo.helmChannel)
+
+	repo.releaseLinks = append(repo.releaseLinks, &gitlab.ReleaseAssetLinkOptions{
+		Name:     gitlab.String("Helm chart " + fileName),
+		URL:      gitlab.String(repo.apiURL(fmt.Sprintf("projects/%s/packages/helm/%s/charts/%s", url.PathEscape(repo.projectID), channel, fileName))),
+		LinkType: repo.packageLinkType(),
+	})
+	return nil
+}
@@ -0,0 +1,107 @@
+package provider
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/go-semantic-release/semantic-release/v2/pkg/provider"
+	"github.com/stretchr/testify/require"
+	"github.com/xanzy/go-gitlab"
+)
+
+func writeTestChart(t *testing.T) string {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "templates"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "Chart.yaml"), []byte("apiVersion: v2\nname: app\nversion: 0.0.0\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "templates", "deployment.yaml"), []byte("kind: Deployment\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".helmignore"), []byte("*.bak\n"), 0o644))
+	return dir
+}
+
+func readTestArchive(t *testing.T, archive []byte) map[string]string {
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	require.NoError(t, err)
+	tr := tar.NewReader(gz)
+	files := make(map[string]string)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return files
+		}
+		require.NoError(t, err)
+		content, err := io.ReadAll(tr)
+		require.NoError(t, err)
+		files[header.Name] = string(content)
+	}
+}
+
+func TestPackageHelmChart(t *testing.T) {
+	fileName, archive, err := packageHelmChart(writeTestChart(t), "2.0.0")
+	require.NoError(t, err)
+	require.Equal(t, "app-2.0.0.tgz", fileName)
+	require.Equal(t, map[string]string{
+		"app/Chart.yaml":                "apiVersion: v2\nname: app\nversion: 2.0.0\n",
+		"app/templates/deployment.yaml": "kind: Deployment\n",
+	}, readTestArchive(t, archive))
+
+	packaged := filepath.Join(t.TempDir(), "app-1.0.0.tgz")
+	require.NoError(t, os.WriteFile(packaged, archive, 0o644))
+	fileName, prepackaged, err := packageHelmChart(packaged, "2.0.0")
+	require.NoError(t, err)
+	require.Equal(t, "app-1.0.0.tgz", fileName)
+	require.Equal(t, archive, prepackaged)
+}
+
+func TestGitlabPublishHelmChart(t *testing.T) {
+	var uploaded map[string]string
+	var links []*gitlab.ReleaseAssetLinkOptions
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "POST" && r.URL.Path == fmt.Sprintf("/api/v4/projects/%d/packages/helm/api/devel/charts", GITLAB_PROJECT_ID):
+			file, header, err := r.FormFile("chart")
+			require.NoError(t, err)
+			require.Equal(t, "app-2.0.0.tgz", header.Filename)
+			content, err := io.ReadAll(file)
+			require.NoError(t, err)
+			uploaded = readTestArchive(t, content)
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"message":"201 Created"}`)
+		case r.Method == "POST" && r.URL.Path == fmt.Sprintf("/api/v4/projects/%d/releases", GITLAB_PROJECT_ID):
+			var opts gitlab.CreateReleaseOptions
+			json.NewDecoder(r.Body).Decode(&opts) //nolint:errcheck
+			links = opts.Assets.Links
+			fmt.Fprint(w, "{}")
+		default:
+			GitlabHandler(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	repo := &GitLabRepository{}
+	err := repo.Init(map[string]string{
+		"gitlab_baseurl":      ts.URL,
+		"token":               "gitlab-examples-ci",
+		"gitlab_projectid":    strconv.Itoa(GITLAB_PROJECT_ID),
+		"gitlab_helm_chart":   writeTestChart(t),
+		"gitlab_helm_channel": "devel",
+	})
+	require.NoError(t, err)
+
+	err = repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "2.0.0", SHA: "deadbeef"})
+	require.NoError(t, err)
+	require.Equal(t, "apiVersion: v2\nname: app\nversion: 2.0.0\n", uploaded["app/Chart.yaml"])
+	require.Len(t, links, 1)
+	require.Equal(t, "Helm chart app-2.0.0.tgz", *links[0].Name)
+	require.Equal(t, fmt.Sprintf("%s/api/v4/projects/%d/packages/helm/devel/charts/app-2.0.0.tgz", ts.URL, GITLAB_PROJECT_ID), *links[0].URL)
+	require.Equal(t, gitlab.PackageLinkType, *links[0].LinkType)
+}
@@ -0,0 +1,116 @@
+package provider
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+const defaultIssueComment = "🎉 This issue has been resolved in {{if .ReleaseURL}}[{{.Tag}}]({{.ReleaseURL}}){{else}}{{.Tag}}{{end}}"
+
+var (
+	// simplified version of GitLab's default issue closing pattern, only references to issues of the same project are supported
+	issueClosingRe = regexp.MustCompile(`(?i)\b(?:clos(?:e[sd]?|ing)|fix(?:e[sd]|ing)?|resolv(?:e[sd]?|ing)|implement(?:s|ed|ing)?):? +(?:issues? +)?((?:#\d+(?: *,? +and +| *, *| +)?)+)`)
+	issueRefRe     = regexp.MustCompile(`#(\d+)`)
+)
+
+// closedIssueRefs returns the issue IIDs referenced by closing patterns in the commit messages
+func closedIssueRefs(messages []string) []int {
+	seen := make(map[int]bool)
+	for _, message := range messages {
+		for _, match := range issueClosingRe.FindAllStringSubmatch(message, -1) {
+			for _, ref := range issueRefRe.FindAllStringSubmatch(match[1], -1) {
+				iid, _ := strconv.Atoi(ref[1])
+				seen[iid] = true
+			}
+		}
+	}
+
+	iids := make([]int, 0, len(seen))
+	for iid := range seen {
+		iids = append(iids, iid)
+	}
+	sort.Ints(iids)
+	return iids
+}
+
+// releasedIssues returns the IIDs of all issues closed by the released commits or merge requests
+func (repo *GitLabRepository) releasedIssues() ([]int, error) {
+	messages := make([]string, 0, len(repo.commits))
+	for _, commit := range repo.commits {
+		messages = append(messages, commit.RawMessage)
+	}
+	iids := closedIssueRefs(messages)
+
+	seen := make(map[int]bool)
+	for _, iid := range iids {
+		seen[iid] = true
+	}
+
+	project, err := repo.getProject()
+	if err != nil {
+		return nil, err
+	}
+
+	mergeRequests, err := repo.mergeRequestsForCommits(repo.commitSHAs())
+	if err != nil {
+		return nil, err
+	}
+	for _, mr := range mergeRequests {
+		issues, _, err := repo.api.GetIssuesClosedOnMerge(repo.projectID, mr.IID, nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, issue := range issues {
+			// issues of other projects cannot be addressed by their IID
+			if issue.ProjectID == project.ID && !seen[issue.IID] {
+				seen[issue.IID] = true
+				iids = append(iids, issue.IID)
+			}
+		}
+	}
+
+	return iids, nil
+}
+
+// updateReleasedIssues labels and comments all released issues, failures are only logged
+func (repo *GitLabRepository) updateReleasedIssues(data *templateData) error {
+	var label, comment string
+	var err error
+	if repo.issueLabel != nil {
+		if label, err = renderTemplate(repo.issueLabel, data); err != nil {
+			return err
+		}
+	}
+	if comment, err = renderTemplate(repo.issueComment, data); err != nil {
+		return err
+	}
+
+	iids, err := repo.releasedIssues()
+	if err != nil {
+		repo.logger.Printf("WARNING: failed to get the released issues: %s", err)
+		return nil
+	}
+
+	return repo.forEach(len(iids), func(i int) error {
+		iid := iids[i]
+		if label != "" {
+			_, _, err := repo.api.UpdateIssue(repo.projectID, iid, &gitlab.UpdateIssueOptions{
+				AddLabels: &gitlab.Labels{label},
+			})
+			if err != nil {
+				repo.logger.Printf("WARNING: failed to label issue #%d: %s", iid, err)
+			}
+		}
+
+		_, _, err := repo.api.CreateIssueNote(repo.projectID, iid, &gitlab.CreateIssueNoteOptions{
+			Body: &comment,
+		})
+		if err != nil {
+			repo.logger.Printf("WARNING: failed to comment on issue #%d: %s", iid, err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,69 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/go-semantic-release/semantic-release/v2/pkg/provider"
+	"github.com/stretchr/testify/require"
+	"github.com/xanzy/go-gitlab"
+)
+
+func TestClosedIssueRefs(t *testing.T) {
+	require.Equal(t, []int{1, 2, 3, 4, 7}, closedIssueRefs([]string{
+		"fix: crash\n\nCloses #1",
+		"feat: new feature\n\nfixes #2, #3 and #4",
+		"chore: mention #5 without closing it",
+		"Resolves: issue #7",
+	}))
+}
+
+func TestGitlabUpdateReleasedIssues(t *testing.T) {
+	updates := make(map[string]string)
+	//nolint:errcheck
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, fmt.Sprintf("/api/v4/projects/%d/", GITLAB_PROJECT_ID))
+		switch {
+		case strings.HasSuffix(path, "/merge_requests"):
+			json.NewEncoder(w).Encode([]*gitlab.MergeRequest{{IID: 1, State: "merged"}})
+		case path == "merge_requests/1/closes_issues":
+			json.NewEncoder(w).Encode([]*gitlab.Issue{{IID: 10, ProjectID: GITLAB_PROJECT_ID}, {IID: 11, ProjectID: 1}})
+		case (r.Method == "PUT" || r.Method == "POST") && strings.HasPrefix(path, "issues/"):
+			var data map[string]string
+			json.NewDecoder(r.Body).Decode(&data)
+			updates[r.Method+" "+path] = data["add_labels"] + data["body"]
+			fmt.Fprint(w, `{"id":1}`)
+		default:
+			GitlabHandler(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	repo := &GitLabRepository{}
+	err := repo.Init(map[string]string{
+		"gitlab_baseurl":        ts.URL,
+		"token":                 "gitlab-examples-ci",
+		"gitlab_projectid":      strconv.Itoa(GITLAB_PROJECT_ID),
+		"gitlab_release_issues": "true",
+		"gitlab_issue_label":    "released::{{.Tag}}",
+	})
+	require.NoError(t, err)
+
+	_, err = repo.GetCommits("", "")
+	require.NoError(t, err)
+	repo.commits[0].RawMessage += "\n\nCloses #12"
+
+	err = repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "2.0.0", SHA: "deadbeef"})
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{
+		"PUT issues/12":        "released::v2.0.0",
+		"POST issues/12/notes": "🎉 This issue has been resolved in v2.0.0",
+		"PUT issues/10":        "released::v2.0.0",
+		"POST issues/10/notes": "🎉 This issue has been resolved in v2.0.0",
+	}, updates)
+}
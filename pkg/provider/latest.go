@@ -0,0 +1,64 @@
+package provider
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+// parseReleaseLatestConfig returns nil if the option is not set, GitLab then decides by the release date as usual
+func parseReleaseLatestConfig(config map[string]string, key string) (*bool, error) {
+	if config[key] == "" {
+		return nil, nil
+	}
+	latest, err := parseBoolConfig(config, key)
+	if err != nil {
+		return nil, err
+	}
+	return &latest, nil
+}
+
+// releasedAt returns the release date which keeps the current latest release. GitLab has no latest flag,
+// /releases/permalink/latest resolves to the release with the most recent release date. A release dated in the past
+// is shown as a historical release. Releases are never dated in the future, GitLab would show them as upcoming.
+func (repo *GitLabRepository) releasedAt(tag string) (*time.Time, error) {
+	if repo.releaseLatest == nil || *repo.releaseLatest {
+		return nil, nil
+	}
+
+	latest, err := repo.latestPastRelease()
+	if err != nil {
+		return nil, err
+	}
+	if latest == nil || latest.TagName == tag {
+		return nil, nil
+	}
+
+	releasedAt := latest.ReleasedAt.Add(-time.Second)
+	repo.logger.Printf("dating release %s at %s so %s stays the latest release", tag, releasedAt.Format(time.RFC3339), latest.TagName)
+	return &releasedAt, nil
+}
+
+// latestPastRelease returns the release with the most recent release date which is not upcoming, e.g. prereleases of
+// gitlab_prerelease_upcoming are skipped
+func (repo *GitLabRepository) latestPastRelease() (*gitlab.Release, error) {
+	now := time.Now()
+	listOptions := repo.listOptions()
+	opts := (*gitlab.ListReleasesOptions)(&listOptions)
+	for {
+		releases, resp, err := repo.api.ListReleases(repo.projectID, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the latest release: %w", err)
+		}
+		for _, release := range releases {
+			if release.ReleasedAt != nil && !release.ReleasedAt.After(now) {
+				return release, nil
+			}
+		}
+		if resp.NextPage == 0 {
+			return nil, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
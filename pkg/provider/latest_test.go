@@ -0,0 +1,71 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-semantic-release/semantic-release/v2/pkg/provider"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReleaseLatest(t *testing.T) {
+	latestReleasedAt := time.Date(2022, 6, 1, 10, 0, 0, 0, time.UTC)
+	upcoming := time.Now().AddDate(0, 0, 30).Truncate(time.Second)
+	for _, tc := range []struct {
+		name       string
+		latest     bool
+		current    []time.Time
+		releasedAt *time.Time
+	}{
+		{"keep latest", false, []time.Time{latestReleasedAt}, timePtr(latestReleasedAt.Add(-time.Second))},
+		{"mark latest", true, []time.Time{latestReleasedAt}, nil},
+		// the stable release must not become an upcoming release itself
+		{"mark latest after upcoming prerelease", true, []time.Time{upcoming, latestReleasedAt}, nil},
+		{"keep latest despite upcoming prerelease", false, []time.Time{upcoming, latestReleasedAt}, timePtr(latestReleasedAt.Add(-time.Second))},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			repo, ts := getNewGitlabTestRepo(t)
+			defer ts.Close()
+			repo.releaseLatest = &tc.latest
+
+			var releasedAt *time.Time
+			ts.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				path := fmt.Sprintf("/api/v4/projects/%d/releases", GITLAB_PROJECT_ID)
+				if r.URL.Path == path && r.Method == "GET" {
+					releases := make([]map[string]string, 0, len(tc.current))
+					for i, current := range tc.current {
+						releases = append(releases, map[string]string{"tag_name": fmt.Sprintf("v1.%d.0", len(tc.current)-i), "released_at": current.Format(time.RFC3339)})
+					}
+					require.NoError(t, json.NewEncoder(w).Encode(releases))
+					return
+				}
+				if r.URL.Path == path && r.Method == "POST" {
+					var data struct {
+						ReleasedAt *time.Time `json:"released_at"`
+					}
+					require.NoError(t, json.NewDecoder(r.Body).Decode(&data))
+					releasedAt = data.ReleasedAt
+					w.WriteHeader(http.StatusCreated)
+					fmt.Fprint(w, `{"tag_name": "v2.0.0"}`)
+					return
+				}
+				GitlabHandler(w, r)
+			})
+
+			err := repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "2.0.0", SHA: "deadbeef"})
+			require.NoError(t, err)
+			if tc.releasedAt == nil {
+				require.Nil(t, releasedAt)
+			} else {
+				require.True(t, tc.releasedAt.Equal(*releasedAt), "released at %s", releasedAt)
+			}
+		})
+	}
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
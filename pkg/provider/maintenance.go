@@ -0,0 +1,135 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+)
+
+const (
+	maintenanceInitialBackoff = 5 * time.Second
+	maintenanceMaxBackoff     = time.Minute
+)
+
+// isReadOnlyMessage reports whether the message of a 503 response was sent by an instance in maintenance mode or by a
+// read-only Geo secondary
+func isReadOnlyMessage(message string) bool {
+	message = strings.ToLower(message)
+	return strings.Contains(message, "read-only instance") || strings.Contains(message, "maintenance")
+}
+
+// maintenanceTransport waits with backoff while the instance rejects writes, the deadline is shared by all requests
+// so a release never waits longer than the configured timeout
+type maintenanceTransport struct {
+	next    http.RoundTripper
+	timeout time.Duration
+	logger  *log.Logger
+	sleep   func(time.Duration)
+
+	mu       sync.Mutex
+	deadline time.Time
+}
+
+func (t *maintenanceTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	backoff := maintenanceInitialBackoff
+	for {
+		resp, err := t.next.RoundTrip(req)
+		if err != nil || !isReadOnlyResponse(resp) {
+			t.mu.Lock()
+			t.deadline = time.Time{}
+			t.mu.Unlock()
+			return resp, err
+		}
+
+		t.mu.Lock()
+		if t.deadline.IsZero() {
+			t.deadline = time.Now().Add(t.timeout)
+		}
+		remaining := time.Until(t.deadline)
+		t.mu.Unlock()
+		if remaining <= 0 {
+			return resp, err
+		}
+		if backoff > remaining {
+			backoff = remaining
+		}
+
+		// a body that cannot be replayed is not sent again, the client gets the read-only response
+		retry, ok := replayRequest(req)
+		if !ok {
+			return resp, err
+		}
+		req = retry
+
+		resp.Body.Close()
+		t.logger.Printf("the GitLab instance is read-only, retrying %s %s in %s", req.Method, endpointName(req.URL.EscapedPath()), backoff)
+		t.sleep(backoff)
+		if backoff *= 2; backoff > maintenanceMaxBackoff {
+			backoff = maintenanceMaxBackoff
+		}
+	}
+}
+
+// replayRequest returns a copy of the request with a fresh body, uploads are not buffered in memory so requests
+// without GetBody cannot be replayed
+func replayRequest(req *http.Request) (*http.Request, bool) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return req, true
+	}
+	if req.GetBody == nil {
+		return nil, false
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, false
+	}
+	retry := req.Clone(req.Context())
+	retry.Body = body
+	return retry, true
+}
+
+// replayableBody sets GetBody of a go-gitlab request, go-retryablehttp keeps the body to rewind it between its own
+// attempts but does not expose it to the transport
+func replayableBody(req *retryablehttp.Request) error {
+	req.GetBody = func() (io.ReadCloser, error) {
+		body, err := req.BodyBytes()
+		if err != nil {
+			return nil, err
+		}
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	return nil
+}
+
+// retryCheck retries like go-gitlab does, except for read-only responses which are not going to change within the
+// short retry window, waiting for them is up to the maintenanceTransport
+func retryCheck(ctx context.Context, resp *http.Response, err error) (bool, error) {
+	if ctx.Err() != nil {
+		return false, ctx.Err()
+	}
+	if err != nil {
+		return false, err
+	}
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 && !isReadOnlyResponse(resp) {
+		return true, nil
+	}
+	return false, nil
+}
+
+// isReadOnlyResponse checks the message of 503 responses, the body stays readable for the client
+func isReadOnlyResponse(resp *http.Response) bool {
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		return false
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return err == nil && isReadOnlyMessage(string(body))
+}
@@ -0,0 +1,141 @@
+package provider
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xanzy/go-gitlab"
+)
+
+const readOnlyMessage = `{"message":"You cannot perform write operations on a read-only instance"}`
+
+func TestIsReadOnlyMessage(t *testing.T) {
+	require.True(t, isReadOnlyMessage(readOnlyMessage))
+	require.True(t, isReadOnlyMessage("GitLab is undergoing maintenance"))
+	require.False(t, isReadOnlyMessage("502 Bad Gateway"))
+}
+
+func TestMaintenanceTransportWaits(t *testing.T) {
+	var bodies []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		if len(bodies) < 3 {
+			http.Error(w, readOnlyMessage, http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer ts.Close()
+
+	var logs bytes.Buffer
+	var sleeps []time.Duration
+	client := &http.Client{Transport: &maintenanceTransport{
+		next:    http.DefaultTransport,
+		timeout: time.Hour,
+		logger:  log.New(&logs, "", 0),
+		sleep:   func(d time.Duration) { sleeps = append(sleeps, d) },
+	}}
+
+	resp, err := client.Post(ts.URL+"/api/v4/projects/1/repository/tags", "application/json", strings.NewReader(`{"tag_name":"v2.0.0"}`))
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	require.Equal(t, []time.Duration{5 * time.Second, 10 * time.Second}, sleeps)
+	// the body is sent again with every attempt
+	require.Equal(t, []string{`{"tag_name":"v2.0.0"}`, `{"tag_name":"v2.0.0"}`, `{"tag_name":"v2.0.0"}`}, bodies)
+	require.Contains(t, logs.String(), "the GitLab instance is read-only, retrying POST projects/:id/repository/tags in 5s\n")
+}
+
+func TestMaintenanceTransportDeadline(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, readOnlyMessage, http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	client := &http.Client{Transport: &maintenanceTransport{
+		next:    http.DefaultTransport,
+		timeout: 50 * time.Millisecond,
+		logger:  log.New(io.Discard, "", 0),
+		sleep:   time.Sleep,
+	}}
+
+	resp, err := client.Post(ts.URL, "application/json", strings.NewReader("{}"))
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	require.Contains(t, string(body), "read-only instance")
+}
+
+func TestMaintenanceTransportBodyWithoutGetBody(t *testing.T) {
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		http.Error(w, readOnlyMessage, http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	var sleeps []time.Duration
+	client := &http.Client{Transport: &maintenanceTransport{
+		next:    http.DefaultTransport,
+		timeout: time.Hour,
+		logger:  log.New(io.Discard, "", 0),
+		sleep:   func(d time.Duration) { sleeps = append(sleeps, d) },
+	}}
+
+	// a streamed upload has no GetBody and is not read into memory to be replayed
+	req, err := http.NewRequest(http.MethodPost, ts.URL, io.MultiReader(strings.NewReader("{}")))
+	require.NoError(t, err)
+	require.Nil(t, req.GetBody)
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	require.Equal(t, 1, requests)
+	require.Empty(t, sleeps)
+}
+
+func TestMaintenanceTransportReplaysGitlabRequests(t *testing.T) {
+	var bodies []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// go-gitlab requests the base URL once to configure its rate limiter
+		if r.Method != http.MethodPost {
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		if len(bodies) < 2 {
+			http.Error(w, readOnlyMessage, http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		io.WriteString(w, `{"name":"v2.0.0"}`) //nolint:errcheck
+	}))
+	defer ts.Close()
+
+	transport := &maintenanceTransport{
+		next:    http.DefaultTransport,
+		timeout: time.Hour,
+		logger:  log.New(io.Discard, "", 0),
+		sleep:   func(time.Duration) {},
+	}
+	client, err := gitlab.NewClient("token", gitlab.WithBaseURL(ts.URL), gitlab.WithHTTPClient(&http.Client{Transport: transport}), gitlab.WithCustomRetry(retryCheck))
+	require.NoError(t, err)
+
+	api := &gitlabClient{client: client}
+	tag, _, err := api.CreateTag("1", &gitlab.CreateTagOptions{TagName: gitlab.String("v2.0.0"), Ref: gitlab.String("main")})
+	require.NoError(t, err)
+	require.Equal(t, "v2.0.0", tag.Name)
+	require.Len(t, bodies, 2)
+	require.Equal(t, bodies[0], bodies[1])
+	require.Contains(t, bodies[1], `"tag_name":"v2.0.0"`)
+}
@@ -0,0 +1,169 @@
+package provider
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/go-semantic-release/semantic-release/v2/pkg/provider"
+	"github.com/go-semantic-release/semantic-release/v2/pkg/semrel"
+	"github.com/xanzy/go-gitlab"
+)
+
+// parseBranchPatternsConfig parses a comma separated list of branch patterns using the syntax of path.Match
+func parseBranchPatternsConfig(config map[string]string, key string) ([]string, error) {
+	patterns := make([]string, 0)
+	for _, pattern := range parseListConfig(config, key) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("failed to set property %s: invalid branch pattern %q: %w", key, pattern, err)
+		}
+		patterns = append(patterns, pattern)
+	}
+	return patterns, nil
+}
+
+// nextMaintenanceVersion returns the next version of the maintenance line or an empty string if nothing is released.
+// Features bump the minor version if the line allows it, e.g. on 1.x but not on 1.2.x.
+func nextMaintenanceVersion(latest *semver.Version, line *semver.Constraints, change *commitChange) (string, error) {
+	if change.breaking {
+		// breaking changes cannot be released on a maintenance branch
+		return "", fmt.Errorf("breaking changes cannot be released on a maintenance branch")
+	}
+	if len(change.features) > 0 {
+		if next := latest.IncMinor(); line.Check(&next) {
+			return next.String(), nil
+		}
+	}
+	if len(change.features) > 0 || len(change.fixes) > 0 {
+		next := latest.IncPatch()
+		return next.String(), nil
+	}
+	return "", nil
+}
+
+// listMaintenanceBranches lists the branches matching gitlab_maintenance_branches which are named after a version
+// line, the released branch itself is excluded
+func (repo *GitLabRepository) listMaintenanceBranches() ([]*gitlab.Branch, error) {
+	branches := make([]*gitlab.Branch, 0)
+	opts := &gitlab.ListBranchesOptions{ListOptions: repo.listOptions()}
+	for {
+		page, resp, err := repo.api.ListBranches(repo.projectID, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list branches: %w", err)
+		}
+		for _, branch := range page {
+			if branch.Name == repo.branch || maintenanceRange(branch.Name) == nil {
+				continue
+			}
+			for _, pattern := range repo.maintenanceBranches {
+				if matched, _ := path.Match(pattern, branch.Name); matched {
+					branches = append(branches, branch)
+					break
+				}
+			}
+		}
+		if resp.NextPage == 0 {
+			return branches, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// ReleaseMaintenanceBranches creates the next release of every maintenance branch matching
+// gitlab_maintenance_branches, e.g. 1.4.3 on release/1.x and 2.0.5 on release/2.0.x. Branches without changes since
+// their latest release are skipped, a failing branch does not stop the release of the others. The versions are
+// computed from the conventional commits like NextRelease, so only gitlab-release calls it. semantic-release
+// releases maintenance branches with its configured commit analyzer in runs on those branches instead. The branches
+// are released once per run.
+func (repo *GitLabRepository) ReleaseMaintenanceBranches() error {
+	if len(repo.maintenanceBranches) == 0 || repo.maintenanceReleased {
+		return nil
+	}
+	repo.maintenanceReleased = true
+	branches, err := repo.listMaintenanceBranches()
+	if err != nil {
+		return err
+	}
+
+	failed := make([]string, 0)
+	for _, branch := range branches {
+		if err := repo.releaseMaintenanceBranch(branch); err != nil {
+			repo.logger.Printf("WARNING: failed to release maintenance branch %s: %s", branch.Name, err)
+			failed = append(failed, branch.Name)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to release maintenance branches %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// maintenanceTarget returns a repository releasing the maintenance branch on the channel of its version line. Like the
+// targets of gitlab_fan_out_projects it only takes over the settings of the tag, the release and the commit listing,
+// the maintenance version is not published to other projects, registries or services.
+func (repo *GitLabRepository) maintenanceTarget(branch, line string) *GitLabRepository {
+	target := repo.newTargetRepository(repo.projectID, branch)
+	target.channel = line
+	target.channelRange = maintenanceRange(branch)
+	target.releaseLatest = new(bool)
+	target.allowUpdate = repo.allowUpdate
+	target.updateMode = repo.updateMode
+	target.tagMetadata = repo.tagMetadata
+	target.tagSigningKey, target.tagSigningFormat = repo.tagSigningKey, repo.tagSigningFormat
+	target.runGit, target.token = repo.runGit, repo.token
+	target.rollbackOnFailure = repo.rollbackOnFailure
+	target.dryRun = repo.dryRun
+	target.messagePattern, target.messageReplacement = repo.messagePattern, repo.messageReplacement
+	target.scopes = repo.scopes
+	target.commitStrategy = repo.commitStrategy
+	target.commitTitlesOnly = repo.commitTitlesOnly
+	target.releaseOrder = repo.releaseOrder
+	target.circuitBreaker, target.circuitBreakerRetry = repo.circuitBreaker, repo.circuitBreakerRetry
+	target.tracerProvider = repo.tracerProvider
+	target.serverVersion, target.versionDetected = repo.serverVersion, repo.versionDetected
+	target.project = repo.project
+	return target
+}
+
+// releaseMaintenanceBranch releases the branch like a run on the branch with a maintenance channel would
+func (repo *GitLabRepository) releaseMaintenanceBranch(branch *gitlab.Branch) error {
+	line := maintenanceBranchPattern.FindStringSubmatch(branch.Name)[1]
+	target := repo.maintenanceTarget(branch.Name, line)
+
+	releases, err := target.GetReleases("")
+	if err != nil {
+		return err
+	}
+	latest, err := semrel.GetLatestReleaseFromReleases(releases, "")
+	if err != nil {
+		return err
+	}
+	if latest.SHA == "" {
+		repo.logger.Printf("skipping maintenance branch %s, it has no release of %s yet", branch.Name, line)
+		return nil
+	}
+
+	head := branch.Commit.ID
+	commits, err := target.GetCommits(latest.SHA, head)
+	if err != nil {
+		return err
+	}
+	change := analyzeCommits(commits)
+	version, err := nextMaintenanceVersion(semver.MustParse(latest.Version), target.channelRange, change)
+	if err != nil {
+		return err
+	}
+	if version == "" {
+		repo.logger.Printf("no changes to release on maintenance branch %s since %s", branch.Name, latest.Version)
+		return nil
+	}
+
+	repo.logger.Printf("releasing %s on maintenance branch %s", version, branch.Name)
+	return target.CreateRelease(&provider.CreateReleaseConfig{
+		Changelog:  change.changelog(),
+		NewVersion: version,
+		Branch:     branch.Name,
+		SHA:        head,
+	})
+}
@@ -0,0 +1,253 @@
+package provider
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/go-semantic-release/semantic-release/v2/pkg/provider"
+	"github.com/go-semantic-release/semantic-release/v2/pkg/semrel"
+	"github.com/stretchr/testify/require"
+	"github.com/xanzy/go-gitlab"
+)
+
+func TestNextMaintenanceVersion(t *testing.T) {
+	latest := semver.MustParse("1.2.3")
+	lineX, lineMinor := maintenanceRange("1.x"), maintenanceRange("release/1.2.x")
+
+	features := analyzeCommits([]*semrel.RawCommit{
+		{SHA: "abcdef0123", RawMessage: "feat(api): new endpoint\n\nbody"},
+		{SHA: "0123abcdef", RawMessage: "fix: bug"},
+		{SHA: "cafebabe", RawMessage: "chore: update dependencies"},
+	})
+	require.Equal(t, "#### Feature\n\n* new endpoint (abcdef01)\n\n#### Bug Fixes\n\n* bug (0123abcd)\n\n", features.changelog())
+	version, err := nextMaintenanceVersion(latest, lineX, features)
+	require.NoError(t, err)
+	require.Equal(t, "1.3.0", version)
+	version, err = nextMaintenanceVersion(latest, lineMinor, features)
+	require.NoError(t, err)
+	require.Equal(t, "1.2.4", version)
+
+	version, err = nextMaintenanceVersion(latest, lineX, analyzeCommits([]*semrel.RawCommit{{SHA: "cafebabe", RawMessage: "docs: readme"}}))
+	require.NoError(t, err)
+	require.Equal(t, "", version)
+
+	_, err = nextMaintenanceVersion(latest, lineX, analyzeCommits([]*semrel.RawCommit{{SHA: "cafebabe", RawMessage: "fix!: drop the flag"}}))
+	require.EqualError(t, err, "breaking changes cannot be released on a maintenance branch")
+}
+
+func TestGitlabReleaseMaintenanceBranches(t *testing.T) {
+	prefix := fmt.Sprintf("/api/v4/projects/%d/", GITLAB_PROJECT_ID)
+	created := make([]string, 0)
+	releases := make(map[string]*gitlab.CreateReleaseOptions)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		//nolint:errcheck
+		switch {
+		case r.Method == "GET" && r.URL.Path == prefix+"repository/branches":
+			json.NewEncoder(w).Encode([]*gitlab.Branch{
+				{Name: "main", Commit: &gitlab.Commit{ID: "deadbeef"}},
+				{Name: "release/1.x", Commit: &gitlab.Commit{ID: "head1"}},
+				{Name: "release/1.4.x", Commit: &gitlab.Commit{ID: "head14"}},
+				{Name: "release/3.x", Commit: &gitlab.Commit{ID: "head3"}},
+				{Name: "feature/1.x", Commit: &gitlab.Commit{ID: "feature"}},
+			})
+		case r.Method == "GET" && r.URL.Path == prefix+"repository/tags":
+			json.NewEncoder(w).Encode([]*gitlab.Tag{
+				createGitlabTag("v2.0.0", "deadbeef"),
+				createGitlabTag("v1.4.0", "release14"),
+				createGitlabTag("v1.5.0", "release15"),
+			})
+		case r.Method == "GET" && r.URL.Path == prefix+"repository/commits":
+			commits := map[string][]*gitlab.Commit{
+				"release15...head1":  {createGitlabCommit("head1", "feat: backported feature"), createGitlabCommit("fix1", "fix: backported fix")},
+				"release14...head14": {createGitlabCommit("head14", "docs: nothing to release")},
+			}[r.URL.Query().Get("ref_name")]
+			json.NewEncoder(w).Encode(commits)
+		case r.Method == "POST" && r.URL.Path == prefix+"releases":
+			var opts gitlab.CreateReleaseOptions
+			json.NewDecoder(r.Body).Decode(&opts)
+			created = append(created, *opts.TagName)
+			releases[*opts.TagName] = &opts
+			fmt.Fprint(w, "{}")
+		case r.Method == "GET" && r.URL.Path == prefix+"releases":
+			json.NewEncoder(w).Encode([]*gitlab.Release{})
+		default:
+			GitlabHandler(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	var logs bytes.Buffer
+	repo := &GitLabRepository{logger: log.New(&logs, "", 0)}
+	err := repo.Init(map[string]string{
+		"gitlab_baseurl":              ts.URL,
+		"token":                       "token",
+		"gitlab_projectid":            strconv.Itoa(GITLAB_PROJECT_ID),
+		"gitlab_branch":               "main",
+		"gitlab_maintenance_branches": "release/*",
+	})
+	require.NoError(t, err)
+
+	err = repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "2.0.0", SHA: "deadbeef", Branch: "main"})
+	require.NoError(t, err)
+	// semantic-release runs never release the maintenance branches
+	require.Equal(t, []string{"v2.0.0"}, created)
+
+	require.NoError(t, repo.ReleaseMaintenanceBranches())
+	require.Equal(t, []string{"v2.0.0", "v1.6.0"}, created)
+	require.Equal(t, "v1.6.0 (1.x)", *releases["v1.6.0"].Name)
+	require.Equal(t, "head1", *releases["v1.6.0"].Ref)
+	require.Contains(t, *releases["v1.6.0"].Description, "* backported feature (head1)")
+	require.Contains(t, logs.String(), "no changes to release on maintenance branch release/1.4.x since 1.4.0")
+	require.Contains(t, logs.String(), "skipping maintenance branch release/3.x, it has no release of 3.x yet")
+	require.NotContains(t, logs.String(), "feature/1.x")
+}
+
+func TestGitlabReleaseMaintenanceBranchesFailureKeepsRelease(t *testing.T) {
+	prefix := fmt.Sprintf("/api/v4/projects/%d/", GITLAB_PROJECT_ID)
+	var deleted []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		//nolint:errcheck
+		switch {
+		case r.Method == "DELETE":
+			deleted = append(deleted, r.URL.Path)
+			w.WriteHeader(http.StatusNoContent)
+		case r.Method == "GET" && r.URL.Path == prefix+"repository/branches":
+			json.NewEncoder(w).Encode([]*gitlab.Branch{{Name: "release/1.x", Commit: &gitlab.Commit{ID: "head1"}}})
+		case r.Method == "GET" && r.URL.Path == prefix+"repository/tags":
+			json.NewEncoder(w).Encode([]*gitlab.Tag{createGitlabTag("v1.5.0", "release15")})
+		case r.Method == "GET" && r.URL.Path == prefix+"repository/commits":
+			json.NewEncoder(w).Encode([]*gitlab.Commit{createGitlabCommit("head1", "fix!: breaking backport")})
+		case r.Method == "POST" && r.URL.Path == prefix+"releases":
+			fmt.Fprint(w, "{}")
+		default:
+			GitlabHandler(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	var logs bytes.Buffer
+	repo := &GitLabRepository{logger: log.New(&logs, "", 0)}
+	err := repo.Init(map[string]string{
+		"gitlab_baseurl":              ts.URL,
+		"token":                       "token",
+		"gitlab_projectid":            strconv.Itoa(GITLAB_PROJECT_ID),
+		"gitlab_branch":               "main",
+		"gitlab_maintenance_branches": "release/*",
+		"gitlab_rollback_on_failure":  "true",
+	})
+	require.NoError(t, err)
+
+	err = repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "2.0.0", SHA: "deadbeef", Branch: "main"})
+	require.NoError(t, err)
+	err = repo.ReleaseMaintenanceBranches()
+	require.EqualError(t, err, "failed to release maintenance branches release/1.x")
+	// the release of the branch was published and is not rolled back
+	require.Empty(t, deleted)
+	require.Contains(t, logs.String(), "WARNING: failed to release maintenance branch release/1.x: breaking changes cannot be released on a maintenance branch")
+}
+
+func TestGitlabReleaseMaintenanceBranchesWithoutRelease(t *testing.T) {
+	prefix := fmt.Sprintf("/api/v4/projects/%d/", GITLAB_PROJECT_ID)
+	created := make([]string, 0)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		//nolint:errcheck
+		switch {
+		case r.Method == "GET" && r.URL.Path == prefix+"repository/branches":
+			json.NewEncoder(w).Encode([]*gitlab.Branch{
+				{Name: "main", Commit: &gitlab.Commit{ID: "head"}},
+				{Name: "release/1.x", Commit: &gitlab.Commit{ID: "head1"}},
+			})
+		case r.Method == "GET" && r.URL.Path == prefix+"repository/tags":
+			json.NewEncoder(w).Encode([]*gitlab.Tag{
+				createGitlabTag("v2.0.0", "deadbeef"),
+				createGitlabTag("v1.5.0", "release15"),
+			})
+		case r.Method == "GET" && r.URL.Path == prefix+"repository/commits":
+			commits := map[string][]*gitlab.Commit{
+				"deadbeef...head":   {createGitlabCommit("head", "docs: nothing to release")},
+				"release15...head1": {createGitlabCommit("head1", "fix: backported fix")},
+			}[r.URL.Query().Get("ref_name")]
+			json.NewEncoder(w).Encode(commits)
+		case r.Method == "POST" && r.URL.Path == prefix+"releases":
+			var opts gitlab.CreateReleaseOptions
+			json.NewDecoder(r.Body).Decode(&opts)
+			created = append(created, *opts.TagName)
+			fmt.Fprint(w, "{}")
+		case r.Method == "GET" && r.URL.Path == prefix+"releases":
+			json.NewEncoder(w).Encode([]*gitlab.Release{})
+		default:
+			GitlabHandler(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	repo := &GitLabRepository{}
+	err := repo.Init(map[string]string{
+		"gitlab_baseurl":              ts.URL,
+		"token":                       "token",
+		"gitlab_projectid":            strconv.Itoa(GITLAB_PROJECT_ID),
+		"gitlab_branch":               "main",
+		"gitlab_maintenance_branches": "release/*",
+	})
+	require.NoError(t, err)
+
+	// listing the commits never releases, semantic-release also lists them in dry runs
+	_, err = repo.GetReleases("")
+	require.NoError(t, err)
+	_, err = repo.GetCommits("deadbeef", "head")
+	require.NoError(t, err)
+	require.Empty(t, created)
+
+	require.NoError(t, repo.ReleaseMaintenanceBranches())
+	require.Equal(t, []string{"v1.5.1"}, created)
+	// the state of the run of the branch is kept
+	require.Equal(t, "v2.0.0", repo.releaseTags["deadbeef"])
+
+	// the maintenance branches are released once per run
+	require.NoError(t, repo.ReleaseMaintenanceBranches())
+	require.Equal(t, []string{"v1.5.1"}, created)
+}
+
+func TestMaintenanceTargetDoesNotPublishElsewhere(t *testing.T) {
+	repo := &GitLabRepository{}
+	err := repo.Init(map[string]string{
+		"token":                       "token",
+		"gitlab_projectid":            strconv.Itoa(GITLAB_PROJECT_ID),
+		"gitlab_branch":               "main",
+		"gitlab_maintenance_branches": "release/*",
+		"gitlab_tag_prefix":           "app/",
+		"gitlab_dry_run":              "true",
+		"gitlab_fan_out_projects":     "group/other",
+		"gitlab_mirrors":              "group/mirror",
+		"gitlab_group_id":             "group",
+		"gitlab_container_image":      "registry.example.com/group/app",
+		"gitlab_environment":          "production",
+		"gitlab_version_files":        "package.json",
+		"gitlab_notify_url":           "https://hooks.example.com/release",
+		"gitlab_release_summary_file": "release.json",
+	})
+	require.NoError(t, err)
+
+	target := repo.maintenanceTarget("release/1.x", "1.x")
+	require.Equal(t, "release/1.x", target.branch)
+	require.Equal(t, "1.x", target.channel)
+	require.Equal(t, "app/", target.tagPrefix)
+	require.True(t, target.dryRun)
+	require.False(t, *target.releaseLatest)
+	require.Empty(t, target.fanOutProjects)
+	require.Empty(t, target.mirrors)
+	require.Empty(t, target.groupID)
+	require.Empty(t, target.containerImage)
+	require.Empty(t, target.environment)
+	require.Empty(t, target.versionFiles)
+	require.Empty(t, target.notifyURL)
+	require.Empty(t, target.releaseSummaryFile)
+	require.Empty(t, target.maintenanceBranches)
+}
@@ -0,0 +1,168 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/go-semantic-release/semantic-release/v2/pkg/provider"
+	"github.com/go-semantic-release/semantic-release/v2/pkg/semrel"
+	"github.com/xanzy/go-gitlab"
+)
+
+const releaseManifestFile = "release-manifest.json"
+
+// releaseManifest lists everything included in the release for deployment and audit tooling
+type releaseManifest struct {
+	ProjectID     string                 `json:"project_id"`
+	ProjectPath   string                 `json:"project_path"`
+	Version       string                 `json:"version"`
+	Tag           string                 `json:"tag"`
+	SHA           string                 `json:"sha"`
+	Commits       []manifestCommit       `json:"commits"`
+	MergeRequests []manifestMergeRequest `json:"merge_requests"`
+	Issues        []manifestIssue        `json:"issues"`
+	Authors       []manifestAuthor       `json:"authors"`
+}
+
+type manifestCommit struct {
+	SHA         string `json:"sha"`
+	Title       string `json:"title"`
+	URL         string `json:"url"`
+	AuthorName  string `json:"author_name,omitempty"`
+	AuthorEmail string `json:"author_email,omitempty"`
+}
+
+type manifestMergeRequest struct {
+	IID    int    `json:"iid"`
+	Title  string `json:"title"`
+	URL    string `json:"url"`
+	Author string `json:"author,omitempty"`
+}
+
+type manifestIssue struct {
+	IID int    `json:"iid"`
+	URL string `json:"url"`
+}
+
+type manifestAuthor struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// annotateCommitAuthor keeps the author of the commit for the release manifest
+func annotateCommitAuthor(raw *semrel.RawCommit, commit *gitlab.Commit) {
+	if raw.Annotations == nil {
+		raw.Annotations = make(map[string]string)
+	}
+	raw.Annotations["gitlab_author_name"] = commit.AuthorName
+	raw.Annotations["gitlab_author_email"] = commit.AuthorEmail
+}
+
+// newReleaseManifest collects the commits of the last GetCommits call with their merge requests, closed issues and
+// authors
+func (repo *GitLabRepository) newReleaseManifest(tag string, release *provider.CreateReleaseConfig) (*releaseManifest, error) {
+	project, err := repo.getProject()
+	if err != nil {
+		return nil, err
+	}
+	manifest := &releaseManifest{
+		ProjectID:     repo.projectID,
+		ProjectPath:   project.PathWithNamespace,
+		Version:       release.NewVersion,
+		Tag:           tag,
+		SHA:           release.SHA,
+		Commits:       make([]manifestCommit, 0, len(repo.commits)),
+		MergeRequests: make([]manifestMergeRequest, 0),
+		Issues:        make([]manifestIssue, 0),
+		Authors:       make([]manifestAuthor, 0),
+	}
+
+	authors := make(map[manifestAuthor]bool)
+	for _, commit := range repo.commits {
+		c := manifestCommit{
+			SHA:         commit.SHA,
+			Title:       strings.SplitN(commit.RawMessage, "\n", 2)[0],
+			URL:         fmt.Sprintf("%s/-/commit/%s", project.WebURL, commit.SHA),
+			AuthorName:  commit.Annotations["gitlab_author_name"],
+			AuthorEmail: commit.Annotations["gitlab_author_email"],
+		}
+		manifest.Commits = append(manifest.Commits, c)
+		if c.AuthorName != "" || c.AuthorEmail != "" {
+			authors[manifestAuthor{Name: c.AuthorName, Email: c.AuthorEmail}] = true
+		}
+	}
+	for author := range authors {
+		manifest.Authors = append(manifest.Authors, author)
+	}
+	sort.Slice(manifest.Authors, func(i, j int) bool {
+		a, b := manifest.Authors[i], manifest.Authors[j]
+		return a.Name < b.Name || a.Name == b.Name && a.Email < b.Email
+	})
+
+	mergeRequests, err := repo.mergeRequestsForCommits(repo.commitSHAs())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the released merge requests: %w", err)
+	}
+	for _, mr := range mergeRequests {
+		m := manifestMergeRequest{IID: mr.IID, Title: mr.Title, URL: mr.WebURL}
+		if mr.Author != nil {
+			m.Author = mr.Author.Username
+		}
+		manifest.MergeRequests = append(manifest.MergeRequests, m)
+	}
+
+	iids, err := repo.releasedIssues()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the released issues: %w", err)
+	}
+	for _, iid := range iids {
+		manifest.Issues = append(manifest.Issues, manifestIssue{IID: iid, URL: fmt.Sprintf("%s/-/issues/%d", project.WebURL, iid)})
+	}
+	return manifest, nil
+}
+
+// publishReleaseManifest uploads the manifest to the generic package of the assets and links it from the release
+func (repo *GitLabRepository) publishReleaseManifest(tag string, release *provider.CreateReleaseConfig) error {
+	manifest, err := repo.newReleaseManifest(tag, release)
+	if err != nil {
+		return err
+	}
+	content, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	path := fmt.Sprintf(
+		"projects/%s/packages/generic/%s/%s/%s",
+		url.PathEscape(repo.projectID),
+		url.PathEscape(repo.assetsPackage),
+		url.PathEscape(release.NewVersion),
+		releaseManifestFile,
+	)
+	req, err := repo.api.NewRequest(http.MethodPut, path, nil, nil)
+	if err != nil {
+		return err
+	}
+	if err := req.SetBody(append(content, '\n')); err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if _, err := repo.api.Do(req, nil); err != nil {
+		return fmt.Errorf("failed to upload the release manifest: %w", err)
+	}
+	repo.logger.Printf(
+		"uploaded the release manifest with %d commits, %d merge requests and %d issues",
+		len(manifest.Commits), len(manifest.MergeRequests), len(manifest.Issues),
+	)
+
+	repo.releaseLinks = append(repo.releaseLinks, &gitlab.ReleaseAssetLinkOptions{
+		Name:     gitlab.String(releaseManifestFile),
+		URL:      gitlab.String(repo.apiURL(path)),
+		LinkType: repo.packageLinkType(),
+	})
+	return nil
+}
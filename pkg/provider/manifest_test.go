@@ -0,0 +1,97 @@
+package provider
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/go-semantic-release/semantic-release/v2/pkg/provider"
+	"github.com/go-semantic-release/semantic-release/v2/pkg/semrel"
+	"github.com/stretchr/testify/require"
+	"github.com/xanzy/go-gitlab"
+)
+
+func TestAnnotateCommitAuthor(t *testing.T) {
+	raw := &semrel.RawCommit{SHA: "abc"}
+	annotateCommitAuthor(raw, &gitlab.Commit{AuthorName: "Jane Doe", AuthorEmail: "jane@example.com"})
+	require.Equal(t, map[string]string{"gitlab_author_name": "Jane Doe", "gitlab_author_email": "jane@example.com"}, raw.Annotations)
+}
+
+func TestGitlabReleaseManifest(t *testing.T) {
+	var manifest releaseManifest
+	var links []*gitlab.ReleaseAssetLinkOptions
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		//nolint:errcheck
+		switch {
+		case r.Method == "GET" && r.URL.Path == fmt.Sprintf("/api/v4/projects/%d", GITLAB_PROJECT_ID):
+			project := GITLAB_PROJECT
+			project.PathWithNamespace, project.WebURL = "group/app", "https://gitlab.example.com/group/app"
+			json.NewEncoder(w).Encode(project)
+		case r.Method == "GET" && r.URL.Path == fmt.Sprintf("/api/v4/projects/%d/repository/commits/aaa/merge_requests", GITLAB_PROJECT_ID):
+			json.NewEncoder(w).Encode([]*gitlab.MergeRequest{{
+				IID: 7, Title: "Add login", State: "merged", WebURL: "https://gitlab.example.com/group/app/-/merge_requests/7",
+				Author: &gitlab.BasicUser{Username: "jane"},
+			}})
+		case r.Method == "GET" && r.URL.Path == fmt.Sprintf("/api/v4/projects/%d/repository/commits/bbb/merge_requests", GITLAB_PROJECT_ID):
+			fmt.Fprint(w, "[]")
+		case r.Method == "GET" && r.URL.Path == fmt.Sprintf("/api/v4/projects/%d/merge_requests/7/closes_issues", GITLAB_PROJECT_ID):
+			json.NewEncoder(w).Encode([]*gitlab.Issue{{IID: 3, ProjectID: GITLAB_PROJECT_ID}})
+		case r.Method == "PUT" && r.URL.Path == fmt.Sprintf("/api/v4/projects/%d/packages/generic/app/2.0.0/release-manifest.json", GITLAB_PROJECT_ID):
+			json.NewDecoder(r.Body).Decode(&manifest)
+			fmt.Fprint(w, "{}")
+		case r.Method == "POST" && r.URL.Path == fmt.Sprintf("/api/v4/projects/%d/releases", GITLAB_PROJECT_ID):
+			var opts gitlab.CreateReleaseOptions
+			json.NewDecoder(r.Body).Decode(&opts)
+			links = opts.Assets.Links
+			fmt.Fprint(w, "{}")
+		default:
+			GitlabHandler(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	var logs bytes.Buffer
+	repo := &GitLabRepository{logger: log.New(&logs, "", 0)}
+	err := repo.Init(map[string]string{
+		"gitlab_baseurl":          ts.URL,
+		"token":                   "token",
+		"gitlab_projectid":        strconv.Itoa(GITLAB_PROJECT_ID),
+		"gitlab_assets_package":   "app",
+		"gitlab_release_manifest": "true",
+	})
+	require.NoError(t, err)
+
+	repo.commits = []*semrel.RawCommit{
+		{SHA: "aaa", RawMessage: "feat: add login\n\nCloses #5", Annotations: map[string]string{"gitlab_author_name": "Jane Doe", "gitlab_author_email": "jane@example.com"}},
+		{SHA: "bbb", RawMessage: "fix: typo", Annotations: map[string]string{"gitlab_author_name": "Jane Doe", "gitlab_author_email": "jane@example.com"}},
+	}
+	err = repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "2.0.0", SHA: "deadbeef"})
+	require.NoError(t, err)
+
+	require.Equal(t, releaseManifest{
+		ProjectID:   strconv.Itoa(GITLAB_PROJECT_ID),
+		ProjectPath: "group/app",
+		Version:     "2.0.0",
+		Tag:         "v2.0.0",
+		SHA:         "deadbeef",
+		Commits: []manifestCommit{
+			{SHA: "aaa", Title: "feat: add login", URL: "https://gitlab.example.com/group/app/-/commit/aaa", AuthorName: "Jane Doe", AuthorEmail: "jane@example.com"},
+			{SHA: "bbb", Title: "fix: typo", URL: "https://gitlab.example.com/group/app/-/commit/bbb", AuthorName: "Jane Doe", AuthorEmail: "jane@example.com"},
+		},
+		MergeRequests: []manifestMergeRequest{{IID: 7, Title: "Add login", URL: "https://gitlab.example.com/group/app/-/merge_requests/7", Author: "jane"}},
+		Issues: []manifestIssue{
+			{IID: 5, URL: "https://gitlab.example.com/group/app/-/issues/5"},
+			{IID: 3, URL: "https://gitlab.example.com/group/app/-/issues/3"},
+		},
+		Authors: []manifestAuthor{{Name: "Jane Doe", Email: "jane@example.com"}},
+	}, manifest)
+	require.Contains(t, logs.String(), "uploaded the release manifest with 2 commits, 1 merge requests and 2 issues")
+	require.Len(t, links, 1)
+	require.Equal(t, releaseManifestFile, *links[0].Name)
+	require.Equal(t, fmt.Sprintf("%s/api/v4/projects/%d/packages/generic/app/2.0.0/release-manifest.json", ts.URL, GITLAB_PROJECT_ID), *links[0].URL)
+}
@@ -0,0 +1,80 @@
+package provider
+
+import (
+	"github.com/xanzy/go-gitlab"
+)
+
+const defaultMergeRequestComment = "🎉 This MR was released in {{if .ReleaseURL}}[{{.Tag}}]({{.ReleaseURL}}){{else}}{{.Tag}}{{end}}"
+
+// mergeRequestsForCommits returns the merged merge requests which contain any of the commits
+func (repo *GitLabRepository) mergeRequestsForCommits(shas []string) ([]*gitlab.MergeRequest, error) {
+	results := make([][]*gitlab.MergeRequest, len(shas))
+	if repo.commitMergeRequests != nil {
+		// already fetched by GetCommits with gitlab_graphql
+		for i, sha := range shas {
+			results[i] = repo.commitMergeRequests[sha]
+		}
+		return deduplicateMergeRequests(results), nil
+	}
+
+	err := repo.forEach(len(shas), func(i int) error {
+		mrs, _, err := repo.api.ListMergeRequestsByCommit(repo.projectID, shas[i])
+		results[i] = mrs
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return deduplicateMergeRequests(results), nil
+}
+
+// deduplicateMergeRequests flattens the merge requests of the commits and drops unmerged ones
+func deduplicateMergeRequests(results [][]*gitlab.MergeRequest) []*gitlab.MergeRequest {
+	seen := make(map[int]bool)
+	mergeRequests := make([]*gitlab.MergeRequest, 0)
+	for _, mrs := range results {
+		for _, mr := range mrs {
+			if mr.State != "merged" || seen[mr.IID] {
+				continue
+			}
+			seen[mr.IID] = true
+			mergeRequests = append(mergeRequests, mr)
+		}
+	}
+
+	return mergeRequests
+}
+
+// commentMergeRequests posts a note on every merge request released by this run, failures are only logged
+func (repo *GitLabRepository) commentMergeRequests(data *templateData) error {
+	body, err := renderTemplate(repo.mergeRequestComment, data)
+	if err != nil {
+		return err
+	}
+
+	mergeRequests, err := repo.mergeRequestsForCommits(repo.commitSHAs())
+	if err != nil {
+		repo.logger.Printf("WARNING: failed to get the released merge requests: %s", err)
+		return nil
+	}
+
+	return repo.forEach(len(mergeRequests), func(i int) error {
+		mr := mergeRequests[i]
+		_, _, err := repo.api.CreateMergeRequestNote(repo.projectID, mr.IID, &gitlab.CreateMergeRequestNoteOptions{
+			Body: &body,
+		})
+		if err != nil {
+			repo.logger.Printf("WARNING: failed to comment on merge request !%d: %s", mr.IID, err)
+		}
+		return nil
+	})
+}
+
+// commitSHAs returns the SHAs of the commits returned by the last GetCommits call
+func (repo *GitLabRepository) commitSHAs() []string {
+	shas := make([]string, 0, len(repo.commits))
+	for _, commit := range repo.commits {
+		shas = append(shas, commit.SHA)
+	}
+	return shas
+}
@@ -0,0 +1,64 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/go-semantic-release/semantic-release/v2/pkg/provider"
+	"github.com/stretchr/testify/require"
+	"github.com/xanzy/go-gitlab"
+)
+
+func TestGitlabCommentMergeRequests(t *testing.T) {
+	comments := make(map[string]string)
+	//nolint:errcheck
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		prefix := fmt.Sprintf("/api/v4/projects/%d/", GITLAB_PROJECT_ID)
+		path := strings.TrimPrefix(r.URL.Path, prefix)
+		switch {
+		case r.Method == "GET" && strings.HasPrefix(path, "repository/commits/") && strings.HasSuffix(path, "/merge_requests"):
+			mrs := []*gitlab.MergeRequest{{IID: 1, State: "merged"}, {IID: 2, State: "opened"}}
+			if strings.Contains(path, "dcba") {
+				mrs = append(mrs, &gitlab.MergeRequest{IID: 3, State: "merged"})
+			}
+			json.NewEncoder(w).Encode(mrs)
+		case r.Method == "POST" && strings.HasPrefix(path, "merge_requests/"):
+			var data map[string]string
+			json.NewDecoder(r.Body).Decode(&data)
+			comments[path] = data["body"]
+			fmt.Fprint(w, "{}")
+		case r.Method == "GET" && r.URL.Path == strings.TrimSuffix(prefix, "/"):
+			project := GITLAB_PROJECT
+			project.WebURL = "https://gitlab.com/group/project"
+			json.NewEncoder(w).Encode(project)
+		default:
+			GitlabHandler(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	repo := &GitLabRepository{}
+	err := repo.Init(map[string]string{
+		"gitlab_baseurl":                ts.URL,
+		"token":                         "gitlab-examples-ci",
+		"gitlab_projectid":              strconv.Itoa(GITLAB_PROJECT_ID),
+		"gitlab_comment_merge_requests": "true",
+	})
+	require.NoError(t, err)
+
+	_, err = repo.GetCommits("", "")
+	require.NoError(t, err)
+	err = repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "2.0.0", SHA: "deadbeef"})
+	require.NoError(t, err)
+
+	comment := "🎉 This MR was released in [v2.0.0](https://gitlab.com/group/project/-/releases/v2.0.0)"
+	require.Equal(t, map[string]string{
+		"merge_requests/1/notes": comment,
+		"merge_requests/3/notes": comment,
+	}, comments)
+}
@@ -0,0 +1,81 @@
+package provider
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+// mergeTrainCar is a merge request on a merge train, go-gitlab does not support the merge trains API yet
+type mergeTrainCar struct {
+	ID           int    `json:"id"`
+	TargetBranch string `json:"target_branch"`
+	Status       string `json:"status"`
+	MergeRequest struct {
+		IID int `json:"iid"`
+	} `json:"merge_request"`
+}
+
+// activeMergeTrainCars lists the merge requests on the active merge train of the branch
+func (repo *GitLabRepository) activeMergeTrainCars(branch string) ([]string, error) {
+	path := fmt.Sprintf("projects/%s/merge_trains", url.PathEscape(repo.projectID))
+	opts := &struct {
+		gitlab.ListOptions
+		Scope string `url:"scope"`
+	}{ListOptions: gitlab.ListOptions{PerPage: maxPerPage}, Scope: "active"}
+	req, err := repo.api.NewRequest(http.MethodGet, path, opts, nil)
+	if err != nil {
+		return nil, err
+	}
+	var cars []*mergeTrainCar
+	if _, err := repo.api.Do(req, &cars); err != nil {
+		return nil, fmt.Errorf("failed to list the merge trains: %w", err)
+	}
+
+	active := make([]string, 0)
+	for _, car := range cars {
+		if car.TargetBranch == branch {
+			active = append(active, fmt.Sprintf("!%d (%s)", car.MergeRequest.IID, car.Status))
+		}
+	}
+	return active, nil
+}
+
+// awaitMergeTrain blocks until the merge train of the branch is empty, the merge requests on it would otherwise
+// supersede the released commit seconds later. The commit must still be the head of the branch afterwards.
+func (repo *GitLabRepository) awaitMergeTrain(branch, sha string) error {
+	project, err := repo.getProject()
+	if err != nil {
+		return err
+	}
+	if !project.MergeTrainsEnabled {
+		repo.debugf("merge trains are not enabled in project %s", repo.projectID)
+		return nil
+	}
+
+	deadline := time.Now().Add(repo.pipelineTimeout)
+	for {
+		active, err := repo.activeMergeTrainCars(branch)
+		if err != nil {
+			return err
+		}
+		if len(active) == 0 {
+			break
+		}
+		if time.Now().Add(repo.pipelinePollInterval).After(deadline) {
+			return fmt.Errorf("timed out after %s waiting for the merge train of %s: %s", repo.pipelineTimeout, branch, strings.Join(active, ", "))
+		}
+		repo.logger.Printf("waiting for the merge train of %s: %s", branch, strings.Join(active, ", "))
+		time.Sleep(repo.pipelinePollInterval)
+	}
+
+	if repo.ref != "" {
+		// the release does not point at the head of the branch
+		return nil
+	}
+	return repo.verifyBranchHead(branch, sha)
+}
@@ -0,0 +1,64 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xanzy/go-gitlab"
+)
+
+func TestGitlabAwaitMergeTrain(t *testing.T) {
+	prefix := fmt.Sprintf("/api/v4/projects/%d", GITLAB_PROJECT_ID)
+	polls, head := 0, "deadbeef"
+	trains := [][]*mergeTrainCar{
+		{{ID: 1, TargetBranch: "main", Status: "fresh"}, {ID: 2, TargetBranch: "develop", Status: "fresh"}},
+		{{ID: 2, TargetBranch: "develop", Status: "fresh"}},
+	}
+	trains[0][0].MergeRequest.IID = 7
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		//nolint:errcheck
+		switch r.URL.Path {
+		case prefix:
+			project := GITLAB_PROJECT
+			project.MergeTrainsEnabled = true
+			json.NewEncoder(w).Encode(project)
+		case prefix + "/merge_trains":
+			require.Equal(t, "active", r.URL.Query().Get("scope"))
+			json.NewEncoder(w).Encode(trains[polls])
+			if polls < len(trains)-1 {
+				polls++
+			}
+		case prefix + "/repository/branches/main":
+			json.NewEncoder(w).Encode(gitlab.Branch{Name: "main", Commit: &gitlab.Commit{ID: head}})
+		default:
+			GitlabHandler(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	repo := &GitLabRepository{pipelinePollInterval: time.Millisecond}
+	err := repo.Init(map[string]string{
+		"gitlab_baseurl":              ts.URL,
+		"token":                       "token",
+		"gitlab_projectid":            strconv.Itoa(GITLAB_PROJECT_ID),
+		"gitlab_wait_for_merge_train": "true",
+	})
+	require.NoError(t, err)
+	require.NoError(t, repo.awaitMergeTrain("main", "deadbeef"))
+	require.Equal(t, 1, polls)
+
+	// the merge train merged a commit after the release was analyzed
+	head = "cafebabe"
+	require.EqualError(t, repo.awaitMergeTrain("main", "deadbeef"), "branch main moved from deadbeef to cafebabe while the release was prepared, rerun the release to include the new commits")
+
+	polls = 0
+	trains = trains[:1]
+	repo.pipelineTimeout = 5 * time.Millisecond
+	require.EqualError(t, repo.awaitMergeTrain("main", "deadbeef"), "timed out after 5ms waiting for the merge train of main: !7 (fresh)")
+}
@@ -0,0 +1,183 @@
+package provider
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// idSegments are the collections whose next path segment identifies a resource, they are collapsed so requests are
+// counted per endpoint
+var idSegments = map[string]bool{
+	"projects":       true,
+	"groups":         true,
+	"users":          true,
+	"branches":       true,
+	"tags":           true,
+	"commits":        true,
+	"files":          true,
+	"releases":       true,
+	"links":          true,
+	"merge_requests": true,
+	"issues":         true,
+	"notes":          true,
+	"pipelines":      true,
+	"deployments":    true,
+	"packages":       true,
+	"wikis":          true,
+}
+
+// EndpointMetrics is the usage of a single API endpoint
+type EndpointMetrics struct {
+	Requests int
+	Pages    int
+	Retries  int
+	Duration time.Duration
+}
+
+// APIMetrics is the usage of the GitLab API since Init
+type APIMetrics struct {
+	Endpoints map[string]*EndpointMetrics
+	// RateLimitRemaining is the remaining rate limit of the last response, -1 if the instance does not report it
+	RateLimitRemaining int
+}
+
+// Total returns the usage summed over all endpoints
+func (m *APIMetrics) Total() EndpointMetrics {
+	var total EndpointMetrics
+	for _, e := range m.Endpoints {
+		total.Requests += e.Requests
+		total.Pages += e.Pages
+		total.Retries += e.Retries
+		total.Duration += e.Duration
+	}
+	return total
+}
+
+// metricsTransport counts the requests per endpoint, a retry is recognized by the same request being sent again
+// after a failure
+type metricsTransport struct {
+	next http.RoundTripper
+
+	mu      sync.Mutex
+	failed  map[string]bool
+	metrics APIMetrics
+}
+
+func newMetricsTransport(next http.RoundTripper) *metricsTransport {
+	return &metricsTransport{
+		next:    next,
+		failed:  make(map[string]bool),
+		metrics: APIMetrics{Endpoints: make(map[string]*EndpointMetrics), RateLimitRemaining: -1},
+	}
+}
+
+func (t *metricsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	duration := time.Since(start)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	endpoint := req.Method + " " + endpointName(req.URL.EscapedPath())
+	e := t.metrics.Endpoints[endpoint]
+	if e == nil {
+		e = &EndpointMetrics{}
+		t.metrics.Endpoints[endpoint] = e
+	}
+	e.Requests++
+	e.Duration += duration
+	key := req.Method + " " + req.URL.String()
+	// requests run concurrently, so every failed request is remembered until it is sent again
+	if t.failed[key] {
+		e.Retries++
+		delete(t.failed, key)
+	}
+	if err != nil || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+		t.failed[key] = true
+	}
+	if resp != nil {
+		if resp.Header.Get("X-Page") != "" {
+			e.Pages++
+		}
+		if remaining, err := strconv.Atoi(resp.Header.Get("RateLimit-Remaining")); err == nil {
+			t.metrics.RateLimitRemaining = remaining
+		}
+	}
+	return resp, err
+}
+
+// snapshot returns a copy of the metrics which is not changed by later requests
+func (t *metricsTransport) snapshot() *APIMetrics {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	m := &APIMetrics{Endpoints: make(map[string]*EndpointMetrics, len(t.metrics.Endpoints)), RateLimitRemaining: t.metrics.RateLimitRemaining}
+	for name, e := range t.metrics.Endpoints {
+		copied := *e
+		m.Endpoints[name] = &copied
+	}
+	return m
+}
+
+// endpointName returns the escaped path relative to the API with resource identifiers replaced by :id
+func endpointName(path string) string {
+	if i := strings.Index(path, "/api/v4/"); i >= 0 {
+		path = path[i+len("/api/v4/"):]
+	}
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return "/"
+	}
+	segments := strings.Split(path, "/")
+	for i := 1; i < len(segments); i++ {
+		if idSegments[segments[i-1]] {
+			segments[i] = ":id"
+			i++
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// Metrics returns the API usage of the provider since Init
+func (repo *GitLabRepository) Metrics() *APIMetrics {
+	if repo.metrics == nil {
+		return &APIMetrics{Endpoints: make(map[string]*EndpointMetrics), RateLimitRemaining: -1}
+	}
+	return repo.metrics.snapshot()
+}
+
+// logMetricsSummary logs the API usage of the run, most expensive endpoints first
+func (repo *GitLabRepository) logMetricsSummary() {
+	m := repo.Metrics()
+	total := m.Total()
+	rateLimit := "unknown"
+	if m.RateLimitRemaining >= 0 {
+		rateLimit = strconv.Itoa(m.RateLimitRemaining)
+	}
+	repo.logger.Printf("API usage: %d requests, %d pages, %d retries in %s, rate limit remaining: %s",
+		total.Requests, total.Pages, total.Retries, total.Duration.Round(time.Millisecond), rateLimit)
+
+	names := make([]string, 0, len(m.Endpoints))
+	for name := range m.Endpoints {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		a, b := m.Endpoints[names[i]], m.Endpoints[names[j]]
+		if a.Requests != b.Requests {
+			return a.Requests > b.Requests
+		}
+		return names[i] < names[j]
+	})
+	for _, name := range names {
+		e := m.Endpoints[name]
+		line := fmt.Sprintf("  %s: %d requests in %s", name, e.Requests, e.Duration.Round(time.Millisecond))
+		if e.Retries > 0 {
+			line += fmt.Sprintf(", %d retries", e.Retries)
+		}
+		repo.logger.Print(line)
+	}
+}
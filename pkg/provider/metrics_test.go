@@ -0,0 +1,89 @@
+package provider
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/go-semantic-release/semantic-release/v2/pkg/provider"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEndpointName(t *testing.T) {
+	require.Equal(t, "projects/:id/repository/commits", endpointName("/api/v4/projects/12324322/repository/commits"))
+	require.Equal(t, "projects/:id/repository/tags/:id", endpointName("/api/v4/projects/group%2Fproject/repository/tags/v1.0.0"))
+	require.Equal(t, "projects/:id/releases/:id/assets/links", endpointName("/gitlab/api/v4/projects/1/releases/v1.0.0/assets/links"))
+	require.Equal(t, "user", endpointName("/api/v4/user"))
+	require.Equal(t, "/", endpointName("/api/v4/"))
+}
+
+func TestMetrics(t *testing.T) {
+	var requests, commitRequests int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path == "/api/v4/projects/group/app/repository/commits" {
+			// the first request fails and is retried
+			if commitRequests++; commitRequests == 1 {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			w.Header().Set("X-Page", "1")
+		}
+		w.Header().Set("RateLimit-Remaining", strconv.Itoa(2000-requests))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[]`))
+	}))
+	defer ts.Close()
+
+	var logs bytes.Buffer
+	repo := &GitLabRepository{logger: log.New(&logs, "", 0)}
+	err := repo.Init(map[string]string{
+		"gitlab_baseurl":         ts.URL,
+		"token":                  "token",
+		"gitlab_projectid":       "group/app",
+		"gitlab_metrics_summary": "true",
+	})
+	require.NoError(t, err)
+
+	_, err = repo.GetCommits("v1.0.0", "main")
+	require.NoError(t, err)
+
+	m := repo.Metrics()
+	commits := m.Endpoints["GET projects/:id/repository/commits"]
+	require.NotNil(t, commits)
+	require.Equal(t, 2, commits.Requests)
+	require.Equal(t, 1, commits.Retries)
+	require.Equal(t, 1, commits.Pages)
+	require.Equal(t, 1997, m.RateLimitRemaining)
+	total := m.Total()
+	require.Equal(t, 1, total.Retries)
+	require.Equal(t, 3, total.Requests)
+
+	repo.logMetricsSummary()
+	require.Contains(t, logs.String(), "API usage: 3 requests, 1 pages, 1 retries in ")
+	require.Contains(t, logs.String(), "rate limit remaining: 1997\n")
+	require.Contains(t, logs.String(), "  GET projects/:id/repository/commits: 2 requests in ")
+	require.Contains(t, logs.String(), ", 1 retries\n")
+}
+
+func TestMetricsBeforeInit(t *testing.T) {
+	m := (&GitLabRepository{}).Metrics()
+	require.Empty(t, m.Endpoints)
+	require.Equal(t, -1, m.RateLimitRemaining)
+}
+
+func TestMetricsSummaryAfterRelease(t *testing.T) {
+	repo, ts := getNewGitlabTestRepo(t)
+	defer ts.Close()
+	var logs bytes.Buffer
+	repo.logger = log.New(&logs, "", 0)
+	repo.metricsSummary = true
+
+	err := repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "2.0.0", SHA: "deadbeef"})
+	require.NoError(t, err)
+	require.Contains(t, logs.String(), "API usage: ")
+	require.Contains(t, logs.String(), "  POST projects/:id/releases: 1 requests in ")
+}
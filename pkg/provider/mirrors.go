@@ -0,0 +1,125 @@
+package provider
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/go-semantic-release/semantic-release/v2/pkg/provider"
+	"github.com/xanzy/go-gitlab"
+)
+
+// newClient creates a client for an instance, the headers are only sent to this instance
+func (repo *GitLabRepository) newClient(baseURL, token string, headers http.Header) (*gitlab.Client, error) {
+	return gitlab.NewClient(token, repo.clientOptions(baseURL, headers)...)
+}
+
+func (repo *GitLabRepository) clientOptions(baseURL string, headers http.Header) []gitlab.ClientOptionFunc {
+	transport := repo.transport()
+	if len(headers) > 0 {
+		transport = &headerTransport{next: transport, headers: headers}
+	}
+	options := []gitlab.ClientOptionFunc{
+		gitlab.WithHTTPClient(&http.Client{Transport: transport}),
+		gitlab.WithCustomRetry(retryCheck),
+	}
+	if baseURL != "" {
+		options = append(options, gitlab.WithBaseURL(baseURL))
+	}
+	return options
+}
+
+// isInstanceURL reports whether the URL belongs to the configured GitLab instance
+func (repo *GitLabRepository) isInstanceURL(u *url.URL) bool {
+	base := repo.api.BaseURL()
+	return u.Scheme == base.Scheme && u.Host == base.Host
+}
+
+// mirrorToken returns the token of a mirror at baseURL. Mirrors on another instance have to name the environment
+// variable of their token, neither the token of the instance nor a predefined CI_ variable is sent to another host.
+func (repo *GitLabRepository) mirrorToken(projectID, baseURL, tokenVariable string) (string, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil || u.Host == "" {
+		return "", fmt.Errorf("invalid base URL %q of mirror %s", baseURL, projectID)
+	}
+	sameInstance := repo.isInstanceURL(u)
+	if tokenVariable == "" {
+		if !sameInstance {
+			return "", fmt.Errorf("mirror %s on %s requires the environment variable of its token, e.g. %s@%s#MIRROR_TOKEN", projectID, u.Host, projectID, baseURL)
+		}
+		return repo.token, nil
+	}
+	if !sameInstance && strings.HasPrefix(tokenVariable, "CI_") {
+		return "", fmt.Errorf("predefined variable %s of mirror %s is not sent to %s", tokenVariable, projectID, u.Host)
+	}
+	token := os.Getenv(tokenVariable)
+	if token == "" {
+		return "", fmt.Errorf("environment variable %s of mirror %s is empty", tokenVariable, projectID)
+	}
+	if !sameInstance && token == repo.token {
+		return "", fmt.Errorf("environment variable %s of mirror %s contains the token of the instance, it is not sent to %s", tokenVariable, projectID, u.Host)
+	}
+	return token, nil
+}
+
+// parseMirrorsConfig parses a comma separated list of project[@baseurl[#TOKEN_VARIABLE]] entries, mirrors on another
+// instance read their token from the given environment variable
+func (repo *GitLabRepository) parseMirrorsConfig(config map[string]string, key string) ([]*GitLabRepository, error) {
+	mirrors := make([]*GitLabRepository, 0)
+	for _, entry := range parseListConfig(config, key) {
+		projectID, instance, _ := strings.Cut(entry, "@")
+		baseURL, tokenVariable, _ := strings.Cut(instance, "#")
+		if projectID == "" {
+			return nil, fmt.Errorf("failed to set property %s: missing project in %q", key, entry)
+		}
+
+		// the tag may also have been pushed by the repository mirroring
+		mirror := repo.newTargetRepository(projectID, "")
+		if baseURL != "" {
+			token, err := repo.mirrorToken(projectID, baseURL, tokenVariable)
+			if err != nil {
+				return nil, fmt.Errorf("failed to set property %s: %w", key, err)
+			}
+			client, err := repo.newClient(baseURL, token, nil)
+			if err != nil {
+				return nil, fmt.Errorf("failed to set property %s: %w", key, err)
+			}
+			mirror.readClient = nil
+			mirror.api = &gitlabClient{client: client}
+		}
+		mirrors = append(mirrors, mirror)
+	}
+	return mirrors, nil
+}
+
+// releaseToMirrors creates the same tag and release in all mirrors, the released commit has to be mirrored already
+func (repo *GitLabRepository) releaseToMirrors(tag string, release *provider.CreateReleaseConfig) error {
+	var problems []string
+	for _, mirror := range repo.mirrors {
+		if err := repo.copyRelease(mirror, tag, release); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %s", mirror.projectID, err))
+			continue
+		}
+		repo.logger.Printf("released %s to mirror %s", tag, mirror.projectID)
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("failed to release %s to mirrors:\n  - %s", tag, strings.Join(problems, "\n  - "))
+	}
+	return nil
+}
+
+// copyRelease creates the tag and the release of this project in the target project
+func (repo *GitLabRepository) copyRelease(target *GitLabRepository, tag string, release *provider.CreateReleaseConfig) error {
+	target.releaseLinks = repo.releaseLinks
+	target.descriptionTag, target.description = repo.descriptionTag, repo.description
+	if err := target.createTag(tag, release); err != nil {
+		return err
+	}
+	if repo.tagOnly {
+		return nil
+	}
+	return target.publishRelease(tag, release, true)
+}
@@ -0,0 +1,131 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/go-semantic-release/semantic-release/v2/pkg/provider"
+	"github.com/stretchr/testify/require"
+	"github.com/xanzy/go-gitlab"
+)
+
+// mirrorHandler records the tags and releases created in the mirror project
+func mirrorHandler(t *testing.T, project, token string, created map[string]string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// rate limit detection of the client
+		if r.URL.Path == "/api/v4/" {
+			return
+		}
+		require.Equal(t, token, r.Header.Get("PRIVATE-TOKEN"))
+		switch {
+		case r.Method == "POST" && r.URL.Path == fmt.Sprintf("/api/v4/projects/%s/repository/tags", project):
+			var opts gitlab.CreateTagOptions
+			json.NewDecoder(r.Body).Decode(&opts) //nolint:errcheck
+			created[project+" tag"] = *opts.TagName + "@" + *opts.Ref
+			fmt.Fprint(w, "{}")
+		case r.Method == "POST" && r.URL.Path == fmt.Sprintf("/api/v4/projects/%s/releases", project):
+			var opts gitlab.CreateReleaseOptions
+			json.NewDecoder(r.Body).Decode(&opts) //nolint:errcheck
+			require.Nil(t, opts.Ref)
+			created[project+" release"] = *opts.TagName
+			fmt.Fprint(w, "{}")
+		default:
+			http.Error(w, `{"message":"404 Project Not Found"}`, http.StatusNotFound)
+		}
+	}
+}
+
+func TestGitlabReleaseToMirrors(t *testing.T) {
+	created := map[string]string{}
+	sameInstance := mirrorHandler(t, "mirror/project", "gitlab-examples-ci", created)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v4/projects/mirror/project/repository/tags" || r.URL.Path == "/api/v4/projects/mirror/project/releases" {
+			sameInstance(w, r)
+			return
+		}
+		GitlabHandler(w, r)
+	}))
+	defer ts.Close()
+	other := httptest.NewServer(mirrorHandler(t, "public/project", "other-token", created))
+	defer other.Close()
+
+	t.Setenv("OTHER_GITLAB_TOKEN", "other-token")
+	repo := &GitLabRepository{}
+	err := repo.Init(map[string]string{
+		"gitlab_baseurl":   ts.URL,
+		"token":            "gitlab-examples-ci",
+		"gitlab_projectid": strconv.Itoa(GITLAB_PROJECT_ID),
+		"gitlab_mirrors":   fmt.Sprintf("mirror/project, public/project@%s#OTHER_GITLAB_TOKEN", other.URL),
+	})
+	require.NoError(t, err)
+
+	err = repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "2.0.0", SHA: "deadbeef"})
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{
+		"mirror/project tag":     "v2.0.0@deadbeef",
+		"mirror/project release": "v2.0.0",
+		"public/project tag":     "v2.0.0@deadbeef",
+		"public/project release": "v2.0.0",
+	}, created)
+
+	err = repo.Init(map[string]string{
+		"gitlab_baseurl":   ts.URL,
+		"token":            "gitlab-examples-ci",
+		"gitlab_projectid": strconv.Itoa(GITLAB_PROJECT_ID),
+		"gitlab_mirrors":   fmt.Sprintf("public/project@%s#MISSING_GITLAB_TOKEN", other.URL),
+	})
+	require.EqualError(t, err, "failed to set property gitlab_mirrors: environment variable MISSING_GITLAB_TOKEN of mirror public/project is empty")
+
+	// the token of the instance is never sent to another instance
+	otherHost := strings.TrimPrefix(other.URL, "http://")
+	t.Setenv("LEAKED_GITLAB_TOKEN", "gitlab-examples-ci")
+	for entry, expected := range map[string]string{
+		"public/project@" + other.URL:                          fmt.Sprintf("mirror public/project on %s requires the environment variable of its token, e.g. public/project@%s#MIRROR_TOKEN", otherHost, other.URL),
+		"public/project@" + other.URL + "#CI_JOB_TOKEN":        "predefined variable CI_JOB_TOKEN of mirror public/project is not sent to " + otherHost,
+		"public/project@" + other.URL + "#LEAKED_GITLAB_TOKEN": "environment variable LEAKED_GITLAB_TOKEN of mirror public/project contains the token of the instance, it is not sent to " + otherHost,
+	} {
+		err = repo.Init(map[string]string{
+			"gitlab_baseurl":   ts.URL,
+			"token":            "gitlab-examples-ci",
+			"gitlab_projectid": strconv.Itoa(GITLAB_PROJECT_ID),
+			"gitlab_mirrors":   entry,
+		})
+		require.EqualError(t, err, "failed to set property gitlab_mirrors: "+expected)
+	}
+
+	// a mirror on the same instance may use its token
+	err = repo.Init(map[string]string{
+		"gitlab_baseurl":   ts.URL,
+		"token":            "gitlab-examples-ci",
+		"gitlab_projectid": strconv.Itoa(GITLAB_PROJECT_ID),
+		"gitlab_mirrors":   "mirror/project@" + ts.URL,
+	})
+	require.NoError(t, err)
+}
+
+func TestGitlabReleaseToMirrorsFailure(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(GitlabHandler))
+	defer ts.Close()
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"message":"404 Project Not Found"}`, http.StatusNotFound)
+	}))
+	defer other.Close()
+
+	t.Setenv("OTHER_GITLAB_TOKEN", "other-token")
+	repo := &GitLabRepository{}
+	err := repo.Init(map[string]string{
+		"gitlab_baseurl":   ts.URL,
+		"token":            "gitlab-examples-ci",
+		"gitlab_projectid": strconv.Itoa(GITLAB_PROJECT_ID),
+		"gitlab_mirrors":   "missing/project@" + other.URL + "#OTHER_GITLAB_TOKEN",
+	})
+	require.NoError(t, err)
+
+	err = repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "2.0.0", SHA: "deadbeef"})
+	require.ErrorContains(t, err, "failed to release v2.0.0 to mirrors:\n  - missing/project: failed to create tag v2.0.0: POST "+other.URL)
+}
@@ -0,0 +1,124 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/go-semantic-release/semantic-release/v2/pkg/provider"
+	"github.com/go-semantic-release/semantic-release/v2/pkg/semrel"
+)
+
+// conventionalCommitRe matches the header of a conventional commit, e.g. fix(api)!: message
+var conventionalCommitRe = regexp.MustCompile(`^(\w+)(?:\([^)]*\))?(!)?:\s*(.+)`)
+
+// commitChange is the change of the commits since the latest release. It is used if the provider computes versions
+// without semantic-release, e.g. for the maintenance branches or the gitlab-release command.
+type commitChange struct {
+	breaking bool
+	features []string
+	fixes    []string
+}
+
+// analyzeCommits sorts the commits like the default commit analyzer, only the conventional commit types feat, fix
+// and perf are released
+func analyzeCommits(commits []*semrel.RawCommit) *commitChange {
+	change := &commitChange{}
+	for _, commit := range commits {
+		m := conventionalCommitRe.FindStringSubmatch(commit.RawMessage)
+		if m == nil {
+			continue
+		}
+		if m[2] == "!" || strings.Contains(commit.RawMessage, "BREAKING CHANGE") {
+			change.breaking = true
+		}
+		title := strings.SplitN(m[3], "\n", 2)[0]
+		entry := fmt.Sprintf("* %s (%s)", title, shortSHA(commit.SHA))
+		switch strings.ToLower(m[1]) {
+		case "feat":
+			change.features = append(change.features, entry)
+		case "fix", "perf":
+			change.fixes = append(change.fixes, entry)
+		}
+	}
+	return change
+}
+
+// changelog returns the release notes in the format of the default changelog generator
+func (c *commitChange) changelog() string {
+	var sb strings.Builder
+	for _, section := range []struct {
+		heading string
+		entries []string
+	}{{"Feature", c.features}, {"Bug Fixes", c.fixes}} {
+		if len(section.entries) > 0 {
+			fmt.Fprintf(&sb, "#### %s\n\n%s\n\n", section.heading, strings.Join(section.entries, "\n"))
+		}
+	}
+	return sb.String()
+}
+
+func (c *commitChange) empty() bool {
+	return !c.breaking && len(c.features) == 0 && len(c.fixes) == 0
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 8 {
+		return sha[:8]
+	}
+	return sha
+}
+
+// nextVersion returns the next version like semantic-release or an empty string if nothing is released. The first
+// release is 1.0.0 and breaking changes of an initial development version bump the minor version.
+func nextVersion(latest *semver.Version, change *commitChange) string {
+	var next semver.Version
+	switch {
+	case change.empty():
+		return ""
+	case latest.Equal(semver.MustParse("0.0.0")):
+		next = *semver.MustParse("1.0.0")
+	case change.breaking && latest.Major() > 0:
+		next = latest.IncMajor()
+	case change.breaking || len(change.features) > 0:
+		next = latest.IncMinor()
+	default:
+		next = latest.IncPatch()
+	}
+	return next.String()
+}
+
+// NextRelease computes the next release of the branch from its commits since the latest release without
+// semantic-release, the version is empty if there is nothing to release. Only conventional commits are analyzed.
+func (repo *GitLabRepository) NextRelease() (*provider.CreateReleaseConfig, error) {
+	if repo.branch == "" {
+		return nil, errors.New("the next release requires gitlab_branch or CI_COMMIT_BRANCH")
+	}
+	head, err := repo.getBranchHead(repo.branch)
+	if err != nil {
+		return nil, err
+	}
+
+	releases, err := repo.GetReleases("")
+	if err != nil {
+		return nil, err
+	}
+	latest, err := semrel.GetLatestReleaseFromReleases(releases, "")
+	if err != nil {
+		return nil, err
+	}
+	commits, err := repo.GetCommits(latest.SHA, head)
+	if err != nil {
+		return nil, err
+	}
+
+	change := analyzeCommits(commits)
+	return &provider.CreateReleaseConfig{
+		Changelog:  change.changelog(),
+		NewVersion: nextVersion(semver.MustParse(latest.Version), change),
+		Branch:     repo.branch,
+		SHA:        head,
+	}, nil
+}
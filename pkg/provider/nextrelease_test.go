@@ -0,0 +1,64 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/go-semantic-release/provider-gitlab/pkg/gitlabtest"
+	"github.com/go-semantic-release/semantic-release/v2/pkg/semrel"
+	"github.com/stretchr/testify/require"
+	"github.com/xanzy/go-gitlab"
+)
+
+func TestNextVersion(t *testing.T) {
+	fix := analyzeCommits([]*semrel.RawCommit{{SHA: "c1", RawMessage: "fix: bug"}})
+	feature := analyzeCommits([]*semrel.RawCommit{{SHA: "c1", RawMessage: "feat(api): endpoint"}, {SHA: "c2", RawMessage: "fix: bug"}})
+	breaking := analyzeCommits([]*semrel.RawCommit{{SHA: "c1", RawMessage: "refactor: drop the flag\n\nBREAKING CHANGE: the flag is gone"}})
+	chore := analyzeCommits([]*semrel.RawCommit{{SHA: "c1", RawMessage: "chore: update dependencies"}, {SHA: "c2", RawMessage: "no conventional commit"}})
+
+	for _, tc := range []struct {
+		latest string
+		change *commitChange
+		next   string
+	}{
+		{"1.2.3", fix, "1.2.4"},
+		{"1.2.3", feature, "1.3.0"},
+		{"1.2.3", breaking, "2.0.0"},
+		{"0.2.3", breaking, "0.3.0"},
+		{"0.0.0", fix, "1.0.0"},
+		{"1.2.3", chore, ""},
+	} {
+		require.Equal(t, tc.next, nextVersion(semver.MustParse(tc.latest), tc.change), tc.latest)
+	}
+}
+
+func TestGitlabNextRelease(t *testing.T) {
+	server := gitlabtest.NewServer()
+	defer server.Close()
+	project := server.AddProject(gitlab.Project{PathWithNamespace: "group/app", DefaultBranch: "main"})
+	server.AddCommit(project, "c1", "feat: initial")
+	server.AddTag(project, "v1.0.0", "c1")
+
+	repo := &GitLabRepository{}
+	err := repo.Init(map[string]string{
+		"gitlab_baseurl":   server.URL,
+		"token":            "token",
+		"gitlab_projectid": "group/app",
+		"gitlab_branch":    "main",
+	})
+	require.NoError(t, err)
+
+	release, err := repo.NextRelease()
+	require.NoError(t, err)
+	require.Equal(t, "", release.NewVersion)
+	require.Equal(t, "c1", release.SHA)
+
+	server.AddCommit(project, "c2", "fix: change")
+	server.AddCommit(project, "c3", "docs: readme")
+	release, err = repo.NextRelease()
+	require.NoError(t, err)
+	require.Equal(t, "1.0.1", release.NewVersion)
+	require.Equal(t, "c3", release.SHA)
+	require.Equal(t, "main", release.Branch)
+	require.Equal(t, "#### Bug Fixes\n\n* change (c2)\n\n", release.Changelog)
+}
@@ -0,0 +1,116 @@
+package provider
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const notifySignatureHeader = "X-Semantic-Release-Signature"
+
+type releaseNotification struct {
+	ProjectID   string `json:"project_id"`
+	Version     string `json:"version"`
+	Tag         string `json:"tag"`
+	SHA         string `json:"sha"`
+	Branch      string `json:"branch,omitempty"`
+	Prerelease  bool   `json:"prerelease"`
+	ReleaseURL  string `json:"release_url,omitempty"`
+	EvidenceSHA string `json:"evidence_sha,omitempty"`
+	Changelog   string `json:"changelog"`
+}
+
+// parseHeadersConfig parses a comma separated list of Name=Value pairs
+func parseHeadersConfig(config map[string]string, key string) (map[string]string, error) {
+	headers := make(map[string]string)
+	for _, pair := range parseListConfig(config, key) {
+		name, value, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("failed to set property %s: invalid header %q, expected Name=Value", key, pair)
+		}
+		headers[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+	return headers, nil
+}
+
+// parseListConfig splits a comma separated option into its trimmed, non-empty values, \, is a comma within a value
+func parseListConfig(config map[string]string, key string) []string {
+	values := make([]string, 0)
+	for _, value := range splitList(config[key]) {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// splitList splits the list at the commas which are not escaped with a backslash
+func splitList(list string) []string {
+	var values []string
+	var value strings.Builder
+	for i := 0; i < len(list); i++ {
+		switch {
+		case list[i] == '\\' && i+1 < len(list) && list[i+1] == ',':
+			value.WriteByte(',')
+			i++
+		case list[i] == ',':
+			values = append(values, value.String())
+			value.Reset()
+		default:
+			value.WriteByte(list[i])
+		}
+	}
+	return append(values, value.String())
+}
+
+// notify posts the release details to the configured URL, failures are only logged
+func (repo *GitLabRepository) notify(data *templateData) {
+	body, err := json.Marshal(&releaseNotification{
+		ProjectID:   repo.projectID,
+		Version:     data.Version,
+		Tag:         data.Tag,
+		SHA:         data.SHA,
+		Branch:      data.Branch,
+		Prerelease:  data.Prerelease,
+		ReleaseURL:  data.ReleaseURL,
+		EvidenceSHA: data.EvidenceSHA,
+		Changelog:   data.Changelog,
+	})
+	if err != nil {
+		repo.logger.Printf("WARNING: failed to encode the release notification: %s", err)
+		return
+	}
+
+	req, err := http.NewRequest(http.MethodPost, repo.notifyURL, bytes.NewReader(body))
+	if err != nil {
+		repo.logger.Printf("WARNING: failed to create the release notification: %s", err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range repo.notifyHeaders {
+		req.Header.Set(name, value)
+	}
+	if repo.notifySecret != "" {
+		mac := hmac.New(sha256.New, []byte(repo.notifySecret))
+		mac.Write(body)
+		req.Header.Set(notifySignatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		repo.logger.Printf("WARNING: failed to send the release notification: %s", err)
+		return
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		repo.logger.Printf("WARNING: the release notification was rejected with status %s", resp.Status)
+	}
+}
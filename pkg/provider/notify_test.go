@@ -0,0 +1,66 @@
+package provider
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/go-semantic-release/semantic-release/v2/pkg/provider"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseHeadersConfig(t *testing.T) {
+	headers, err := parseHeadersConfig(map[string]string{"headers": "X-Team = platform, Authorization=Bearer abc"}, "headers")
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"X-Team": "platform", "Authorization": "Bearer abc"}, headers)
+
+	_, err = parseHeadersConfig(map[string]string{"headers": "X-Team"}, "headers")
+	require.EqualError(t, err, `failed to set property headers: invalid header "X-Team", expected Name=Value`)
+}
+
+func TestGitlabNotify(t *testing.T) {
+	var notification releaseNotification
+	var signature, team string
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &notification) //nolint:errcheck
+		mac := hmac.New(sha256.New, []byte("secret"))
+		mac.Write(body)
+		require.Equal(t, "sha256="+hex.EncodeToString(mac.Sum(nil)), r.Header.Get(notifySignatureHeader))
+		signature = r.Header.Get(notifySignatureHeader)
+		team = r.Header.Get("X-Team")
+	}))
+	defer hook.Close()
+
+	ts := httptest.NewServer(http.HandlerFunc(GitlabHandler))
+	defer ts.Close()
+
+	repo := &GitLabRepository{}
+	err := repo.Init(map[string]string{
+		"gitlab_baseurl":        ts.URL,
+		"token":                 "gitlab-examples-ci",
+		"gitlab_projectid":      strconv.Itoa(GITLAB_PROJECT_ID),
+		"gitlab_notify_url":     hook.URL,
+		"gitlab_notify_secret":  "secret",
+		"gitlab_notify_headers": "X-Team=platform",
+	})
+	require.NoError(t, err)
+
+	err = repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "2.0.0", SHA: "deadbeef", Changelog: "* feat"})
+	require.NoError(t, err)
+	require.NotEmpty(t, signature)
+	require.Equal(t, "platform", team)
+	require.Equal(t, releaseNotification{
+		ProjectID: strconv.Itoa(GITLAB_PROJECT_ID),
+		Version:   "2.0.0",
+		Tag:       "v2.0.0",
+		SHA:       "deadbeef",
+		Changelog: "* feat",
+	}, notification)
+}
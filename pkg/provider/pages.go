@@ -0,0 +1,151 @@
+package provider
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+const defaultPagesPath = "public"
+
+var pagesTemplate = template.Must(template.New("pages").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>
+body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Helvetica, Arial, sans-serif; line-height: 1.5; max-width: 50rem; margin: 0 auto; padding: 2rem 1rem; color: #1f1e24; }
+a { color: #1f75cb; }
+h2 { border-bottom: 1px solid #dcdcde; padding-bottom: .25rem; }
+.released { color: #737278; font-size: .875rem; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+{{.Releases}}
+</body>
+</html>
+`))
+
+// pagesMarkdown joins the release notes of all releases, newest first, into one document
+func pagesMarkdown(releases []*gitlab.Release) string {
+	var sb strings.Builder
+	for _, release := range releases {
+		name := release.Name
+		if name == "" {
+			name = release.TagName
+		}
+		fmt.Fprintf(&sb, "## %s\n\n", name)
+		if release.ReleasedAt != nil {
+			fmt.Fprintf(&sb, "<p class=\"released\">Released on %s</p>\n\n", release.ReleasedAt.Format("2006-01-02"))
+		}
+		sb.WriteString(strings.TrimSpace(release.Description))
+		sb.WriteString("\n\n")
+	}
+	return sb.String()
+}
+
+// listAllReleases returns all releases of the project, newest first
+func (repo *GitLabRepository) listAllReleases() ([]*gitlab.Release, error) {
+	opts := &gitlab.ListReleasesOptions{PerPage: maxPerPage}
+	releases := make([]*gitlab.Release, 0)
+	for {
+		page, resp, err := repo.api.ListReleases(repo.projectID, opts)
+		if err != nil {
+			return nil, err
+		}
+		releases = append(releases, page...)
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	return releases, nil
+}
+
+// renderPagesSite renders the release notes of all releases to the index page of the changelog site, the markdown
+// is rendered by GitLab so references and emojis look like on the releases page
+func (repo *GitLabRepository) renderPagesSite() ([]byte, error) {
+	project, err := repo.getProject()
+	if err != nil {
+		return nil, err
+	}
+	releases, err := repo.listAllReleases()
+	if err != nil {
+		return nil, err
+	}
+	markdown, _, err := repo.api.RenderMarkdown(&gitlab.RenderOptions{
+		Text:                    gitlab.String(pagesMarkdown(releases)),
+		GitlabFlavouredMarkdown: gitlab.Bool(true),
+		Project:                 gitlab.String(project.PathWithNamespace),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	title := project.Name + " changelog"
+	if project.Name == "" {
+		title = "Changelog"
+	}
+	var site bytes.Buffer
+	err = pagesTemplate.Execute(&site, struct {
+		Title    string
+		Releases template.HTML
+	}{
+		Title: title,
+		// GitLab sanitizes the rendered HTML
+		Releases: template.HTML(markdown.HTML), //nolint:gosec
+	})
+	return site.Bytes(), err
+}
+
+// publishPagesSite commits the changelog site to gitlab_pages_branch, the pipeline of the commit deploys it with the
+// pages job of the branch. A missing branch is started from the released branch. Failures are only logged.
+func (repo *GitLabRepository) publishPagesSite(data *templateData) {
+	site, err := repo.renderPagesSite()
+	if err != nil {
+		repo.logger.Printf("WARNING: failed to render the changelog site: %s", err)
+		return
+	}
+
+	branch := repo.pagesBranch
+	ref := branch
+	var startBranch *string
+	_, resp, err := repo.api.GetBranch(repo.projectID, branch)
+	switch {
+	case resp != nil && resp.StatusCode == http.StatusNotFound:
+		ref = defaultString(repo.branch, data.Branch)
+		startBranch = &ref
+	case err != nil:
+		repo.logger.Printf("WARNING: failed to get branch %s: %s", branch, err)
+		return
+	}
+
+	file := path.Join(repo.pagesPath, "index.html")
+	action := gitlab.FileCreate
+	if _, _, err := repo.api.GetFileMetaData(repo.projectID, file, &gitlab.GetFileMetaDataOptions{Ref: &ref}); err == nil {
+		action = gitlab.FileUpdate
+	}
+
+	_, _, err = repo.api.CreateCommit(repo.projectID, &gitlab.CreateCommitOptions{
+		Branch:        &branch,
+		StartBranch:   startBranch,
+		CommitMessage: gitlab.String(repo.commitMessage(commitKindPages, fmt.Sprintf("docs: publish the changelog of %s", data.Tag))),
+		Actions: []*gitlab.CommitActionOptions{{
+			Action:   gitlab.FileAction(action),
+			FilePath: &file,
+			Content:  gitlab.String(string(site)),
+		}},
+	})
+	if err != nil {
+		repo.logger.Printf("WARNING: failed to publish the changelog site to branch %s: %s", branch, err)
+		return
+	}
+	repo.logger.Printf("published the changelog site to %s in branch %s", file, branch)
+}
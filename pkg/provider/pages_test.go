@@ -0,0 +1,96 @@
+package provider
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/go-semantic-release/semantic-release/v2/pkg/provider"
+	"github.com/stretchr/testify/require"
+	"github.com/xanzy/go-gitlab"
+)
+
+func TestPagesMarkdown(t *testing.T) {
+	released := time.Date(2022, 1, 2, 0, 0, 0, 0, time.UTC)
+	markdown := pagesMarkdown([]*gitlab.Release{
+		{TagName: "v2.0.0", Description: "* feat: new\n", ReleasedAt: &released},
+		{TagName: "v1.0.0", Name: "First release", Description: "initial"},
+	})
+	require.Equal(t, "## v2.0.0\n\n<p class=\"released\">Released on 2022-01-02</p>\n\n* feat: new\n\n## First release\n\ninitial\n\n", markdown)
+}
+
+func TestGitlabPublishPagesSite(t *testing.T) {
+	for _, branchExists := range []bool{true, false} {
+		t.Run(fmt.Sprintf("branch exists %v", branchExists), func(t *testing.T) {
+			var rendered gitlab.RenderOptions
+			var commit gitlab.CreateCommitOptions
+			project := GITLAB_PROJECT
+			project.Name, project.PathWithNamespace = "project", "group/project"
+			prefix := fmt.Sprintf("/api/v4/projects/%d/", GITLAB_PROJECT_ID)
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case r.Method == "GET" && r.URL.Path == prefix[:len(prefix)-1]:
+					json.NewEncoder(w).Encode(project) //nolint:errcheck
+				case r.Method == "GET" && r.URL.Path == prefix+"releases":
+					json.NewEncoder(w).Encode([]*gitlab.Release{{TagName: "v2.0.0", Description: "* feat: new"}}) //nolint:errcheck
+				case r.Method == "POST" && r.URL.Path == "/api/v4/markdown":
+					json.NewDecoder(r.Body).Decode(&rendered) //nolint:errcheck
+					fmt.Fprint(w, `{"html": "<h2>v2.0.0</h2><ul><li>feat: new</li></ul>"}`)
+				case r.Method == "GET" && r.URL.Path == prefix+"repository/branches/pages":
+					if !branchExists {
+						http.Error(w, `{"message": "404 Branch Not Found"}`, http.StatusNotFound)
+						return
+					}
+					fmt.Fprint(w, `{"name": "pages"}`)
+				case r.Method == "HEAD" && r.URL.Path == prefix+"repository/files/site/index.html":
+					if !branchExists {
+						w.WriteHeader(http.StatusNotFound)
+					}
+				case r.Method == "POST" && r.URL.Path == prefix+"repository/commits":
+					json.NewDecoder(r.Body).Decode(&commit) //nolint:errcheck
+					fmt.Fprint(w, `{"id": "cafebabe"}`)
+				default:
+					GitlabHandler(w, r)
+				}
+			}))
+			defer ts.Close()
+
+			var logs bytes.Buffer
+			repo := &GitLabRepository{logger: log.New(&logs, "", 0)}
+			err := repo.Init(map[string]string{
+				"gitlab_baseurl":      ts.URL,
+				"token":               "token",
+				"gitlab_projectid":    strconv.Itoa(GITLAB_PROJECT_ID),
+				"gitlab_branch":       "main",
+				"gitlab_pages_branch": "pages",
+				"gitlab_pages_path":   "site",
+			})
+			require.NoError(t, err)
+
+			err = repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "2.0.0", SHA: "deadbeef"})
+			require.NoError(t, err)
+			require.Contains(t, logs.String(), "published the changelog site to site/index.html in branch pages")
+
+			require.Equal(t, "group/project", *rendered.Project)
+			require.Equal(t, "## v2.0.0\n\n* feat: new\n\n", *rendered.Text)
+			require.Equal(t, "pages", *commit.Branch)
+			require.Len(t, commit.Actions, 1)
+			require.Equal(t, "site/index.html", *commit.Actions[0].FilePath)
+			require.Contains(t, *commit.Actions[0].Content, "<title>project changelog</title>")
+			require.Contains(t, *commit.Actions[0].Content, "<h2>v2.0.0</h2><ul><li>feat: new</li></ul>")
+			if branchExists {
+				require.Nil(t, commit.StartBranch)
+				require.Equal(t, gitlab.FileUpdate, *commit.Actions[0].Action)
+			} else {
+				require.Equal(t, "main", *commit.StartBranch)
+				require.Equal(t, gitlab.FileCreate, *commit.Actions[0].Action)
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package provider
+
+import (
+	"fmt"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+// GitLab returns at most 100 items per page
+const maxPerPage = 100
+
+func parsePerPageConfig(config map[string]string, key string) (int, error) {
+	perPage, err := parseIntConfig(config, key)
+	if err != nil {
+		return 0, err
+	}
+	if perPage == 0 {
+		return maxPerPage, nil
+	}
+	if perPage > maxPerPage {
+		return 0, fmt.Errorf("failed to set property %s: GitLab returns at most %d items per page", key, maxPerPage)
+	}
+	return perPage, nil
+}
+
+// listOptions returns the options of the first page of the commit and tag listings
+func (repo *GitLabRepository) listOptions() gitlab.ListOptions {
+	perPage := repo.perPage
+	if perPage == 0 {
+		perPage = maxPerPage
+	}
+	return gitlab.ListOptions{Page: 1, PerPage: perPage}
+}
+
+// pageLimitReached reports whether a listing has to stop after the page because of gitlab_max_pages
+func (repo *GitLabRepository) pageLimitReached(page int, what string) bool {
+	if repo.maxPages == 0 || page < repo.maxPages {
+		return false
+	}
+	repo.logger.Printf("WARNING: stopped listing %s after %d pages, increase gitlab_max_pages if the result is incomplete", what, page)
+	return true
+}
@@ -0,0 +1,83 @@
+package provider
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPageLimits(t *testing.T) {
+	var perPages []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != fmt.Sprintf("/api/v4/projects/%d/repository/commits", GITLAB_PROJECT_ID) {
+			GitlabHandler(w, r)
+			return
+		}
+		// a repository with endless history
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		perPages = append(perPages, r.URL.Query().Get("per_page"))
+		w.Header().Set("X-Page", strconv.Itoa(page))
+		w.Header().Set("X-Next-Page", strconv.Itoa(page+1))
+		fmt.Fprintf(w, `[{"id": "commit%d", "message": "fix: page %d"}]`, page, page)
+	}))
+	defer ts.Close()
+
+	var logs bytes.Buffer
+	repo := &GitLabRepository{logger: log.New(&logs, "", 0)}
+	err := repo.Init(map[string]string{
+		"gitlab_baseurl":   ts.URL,
+		"token":            "token",
+		"gitlab_projectid": strconv.Itoa(GITLAB_PROJECT_ID),
+		"gitlab_per_page":  "20",
+		"gitlab_max_pages": "3",
+	})
+	require.NoError(t, err)
+
+	commits, err := repo.GetCommits("", "master")
+	require.NoError(t, err)
+	require.Len(t, commits, 3)
+	require.Equal(t, []string{"20", "20", "20"}, perPages)
+	require.Contains(t, logs.String(), "WARNING: stopped listing commits after 3 pages")
+}
+
+func TestInvalidPerPage(t *testing.T) {
+	repo := &GitLabRepository{}
+	err := repo.Init(map[string]string{"token": "token", "gitlab_projectid": "1", "gitlab_per_page": "500"})
+	require.EqualError(t, err, "failed to set property gitlab_per_page: GitLab returns at most 100 items per page")
+}
+
+func TestGetReleasesWithoutTotalPages(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != fmt.Sprintf("/api/v4/projects/%d/repository/tags", GITLAB_PROJECT_ID) {
+			GitlabHandler(w, r)
+			return
+		}
+		// gitlab.com omits X-Total and X-Total-Pages for more than 10000 items
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		w.Header().Set("X-Page", strconv.Itoa(page))
+		if page < 3 {
+			w.Header().Set("X-Next-Page", strconv.Itoa(page+1))
+		}
+		fmt.Fprintf(w, `[{"name": "v1.%d.0", "commit": {"id": "commit%d"}}]`, page, page)
+	}))
+	defer ts.Close()
+
+	repo := &GitLabRepository{}
+	err := repo.Init(map[string]string{
+		"gitlab_baseurl":   ts.URL,
+		"token":            "token",
+		"gitlab_projectid": strconv.Itoa(GITLAB_PROJECT_ID),
+	})
+	require.NoError(t, err)
+
+	releases, err := repo.GetReleases("")
+	require.NoError(t, err)
+	require.Len(t, releases, 3)
+	require.Equal(t, "1.3.0", releases[2].Version)
+}
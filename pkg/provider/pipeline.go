@@ -0,0 +1,69 @@
+package provider
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+const (
+	defaultPipelineTimeout      = 30 * time.Minute
+	defaultPipelinePollInterval = 10 * time.Second
+)
+
+// waitForPipelines blocks until all pipelines of the commit succeeded. The pipeline running the release itself is ignored.
+func (repo *GitLabRepository) waitForPipelines(sha string) error {
+	ownPipeline, _ := strconv.Atoi(os.Getenv("CI_PIPELINE_ID"))
+	deadline := time.Now().Add(repo.pipelineTimeout)
+
+	for {
+		pipelines, _, err := repo.api.ListProjectPipelines(repo.projectID, &gitlab.ListProjectPipelinesOptions{
+			ListOptions: gitlab.ListOptions{PerPage: 100},
+			SHA:         &sha,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to list pipelines of %s: %w", sha, err)
+		}
+
+		var pending []string
+		for _, p := range pipelines {
+			if p.ID == ownPipeline {
+				continue
+			}
+			switch p.Status {
+			case "success", "skipped":
+			case "failed", "canceled":
+				return fmt.Errorf("pipeline %d of %s has status %s: %s", p.ID, sha, p.Status, p.WebURL)
+			default:
+				pending = append(pending, fmt.Sprintf("%d (%s)", p.ID, p.Status))
+			}
+		}
+
+		if len(pending) == 0 {
+			return nil
+		}
+		if time.Now().Add(repo.pipelinePollInterval).After(deadline) {
+			return fmt.Errorf("timed out after %s waiting for pipelines of %s: %s", repo.pipelineTimeout, sha, strings.Join(pending, ", "))
+		}
+
+		repo.logger.Printf("waiting for pipelines of %s: %s", sha, strings.Join(pending, ", "))
+		time.Sleep(repo.pipelinePollInterval)
+	}
+}
+
+func parseDurationConfig(config map[string]string, key string, defaultValue time.Duration) (time.Duration, error) {
+	value := config[key]
+	if value == "" {
+		return defaultValue, nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("failed to set property %s: %w", key, err)
+	}
+	return d, nil
+}
@@ -0,0 +1,63 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xanzy/go-gitlab"
+)
+
+func TestGitlabWaitForPipelines(t *testing.T) {
+	t.Setenv("CI_PIPELINE_ID", "3")
+
+	polls := 0
+	statuses := map[int][]string{
+		1: {"running", "success"},
+		2: {"pending", "running", "running", "skipped"},
+		3: {"running"},
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == fmt.Sprintf("/api/v4/projects/%d/pipelines", GITLAB_PROJECT_ID) {
+			require.Equal(t, "deadbeef", r.URL.Query().Get("sha"))
+			pipelines := make([]*gitlab.PipelineInfo, 0)
+			for id := 1; id <= 3; id++ {
+				status := statuses[id][len(statuses[id])-1]
+				if polls < len(statuses[id]) {
+					status = statuses[id][polls]
+				}
+				pipelines = append(pipelines, &gitlab.PipelineInfo{ID: id, Status: status})
+			}
+			polls++
+			json.NewEncoder(w).Encode(pipelines) //nolint:errcheck
+			return
+		}
+		GitlabHandler(w, r)
+	}))
+	defer ts.Close()
+
+	repo := &GitLabRepository{pipelinePollInterval: time.Millisecond}
+	err := repo.Init(map[string]string{
+		"gitlab_baseurl":           ts.URL,
+		"token":                    "gitlab-examples-ci",
+		"gitlab_projectid":         strconv.Itoa(GITLAB_PROJECT_ID),
+		"gitlab_wait_for_pipeline": "true",
+	})
+	require.NoError(t, err)
+	require.NoError(t, repo.waitForPipelines("deadbeef"))
+	require.Equal(t, 4, polls)
+
+	polls = 0
+	statuses[2] = []string{"running", "failed"}
+	require.EqualError(t, repo.waitForPipelines("deadbeef"), "pipeline 2 of deadbeef has status failed: ")
+
+	polls = 0
+	statuses[2] = []string{"running"}
+	repo.pipelineTimeout = 5 * time.Millisecond
+	require.EqualError(t, repo.waitForPipelines("deadbeef"), "timed out after 5ms waiting for pipelines of deadbeef: 2 (running)")
+}
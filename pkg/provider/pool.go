@@ -0,0 +1,48 @@
+package provider
+
+import (
+	"sync"
+)
+
+// forEach calls fn for the indexes 0 to n-1 with at most gitlab_concurrency calls running at the same time, callers
+// store results by index to keep their order. All calls are made, the error of the lowest index is returned.
+func (repo *GitLabRepository) forEach(n int, fn func(i int) error) error {
+	workers := repo.concurrency
+	if workers > n {
+		workers = n
+	}
+	if workers <= 1 {
+		var first error
+		for i := 0; i < n; i++ {
+			if err := fn(i); err != nil && first == nil {
+				first = err
+			}
+		}
+		return first
+	}
+
+	errs := make([]error, n)
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				errs[i] = fn(i)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
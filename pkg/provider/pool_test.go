@@ -0,0 +1,112 @@
+package provider
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-semantic-release/provider-gitlab/pkg/gitlabtest"
+	"github.com/stretchr/testify/require"
+	"github.com/xanzy/go-gitlab"
+)
+
+func TestForEach(t *testing.T) {
+	repo := &GitLabRepository{concurrency: 3}
+
+	var mu sync.Mutex
+	running, maxRunning := 0, 0
+	results := make([]int, 10)
+	err := repo.forEach(len(results), func(i int) error {
+		mu.Lock()
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		mu.Unlock()
+
+		time.Sleep(5 * time.Millisecond)
+		results[i] = i * i
+
+		mu.Lock()
+		running--
+		mu.Unlock()
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 3, maxRunning)
+	require.Equal(t, []int{0, 1, 4, 9, 16, 25, 36, 49, 64, 81}, results)
+}
+
+func TestForEachError(t *testing.T) {
+	for _, concurrency := range []int{0, 4} {
+		repo := &GitLabRepository{concurrency: concurrency}
+		var mu sync.Mutex
+		calls := 0
+		err := repo.forEach(5, func(i int) error {
+			mu.Lock()
+			calls++
+			mu.Unlock()
+			if i >= 2 {
+				return fmt.Errorf("failed %d", i)
+			}
+			return nil
+		})
+		// sequential and concurrent calls do not stop at the first error
+		require.EqualError(t, err, "failed 2")
+		require.Equal(t, 5, calls)
+	}
+
+	// the serial path makes every call and returns the error of the lowest index
+	var order []int
+	err := (&GitLabRepository{concurrency: 1}).forEach(4, func(i int) error {
+		order = append(order, i)
+		if i == 1 || i == 3 {
+			return fmt.Errorf("failed %d", i)
+		}
+		return nil
+	})
+	require.EqualError(t, err, "failed 1")
+	require.Equal(t, []int{0, 1, 2, 3}, order)
+	require.NoError(t, (&GitLabRepository{concurrency: 2}).forEach(0, func(int) error { return errors.New("not called") }))
+}
+
+func TestGitlabGetCommitsPagesInParallel(t *testing.T) {
+	server := gitlabtest.NewServer()
+	defer server.Close()
+	project := server.AddProject(gitlab.Project{PathWithNamespace: "group/app"})
+	for i := 1; i <= 12; i++ {
+		server.AddCommit(project, fmt.Sprintf("c%d", i), fmt.Sprintf("fix: change %d", i))
+	}
+
+	config := map[string]string{
+		"gitlab_baseurl":     server.URL,
+		"token":              "token",
+		"gitlab_projectid":   "group/app",
+		"gitlab_branch":      "main",
+		"gitlab_per_page":    "5",
+		"gitlab_concurrency": "3",
+	}
+	repo := &GitLabRepository{}
+	require.NoError(t, repo.Init(config))
+
+	commits, err := repo.GetCommits("", "main")
+	require.NoError(t, err)
+	require.Len(t, commits, 12)
+	// the pages are joined in order, newest commit first
+	for i, commit := range commits {
+		require.Equal(t, fmt.Sprintf("c%d", 12-i), commit.SHA)
+	}
+
+	var logs bytes.Buffer
+	config["gitlab_max_pages"] = "2"
+	repo = &GitLabRepository{logger: log.New(&logs, "", 0)}
+	require.NoError(t, repo.Init(config))
+	commits, err = repo.GetCommits("", "main")
+	require.NoError(t, err)
+	require.Len(t, commits, 10)
+	require.Contains(t, logs.String(), "WARNING: stopped listing commits after 2 pages")
+}
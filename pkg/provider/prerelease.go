@@ -0,0 +1,68 @@
+package provider
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/go-semantic-release/semantic-release/v2/pkg/provider"
+)
+
+const defaultPrereleaseUpcoming = 30 * 24 * time.Hour
+
+// parseChannelBuild returns the version without the prerelease and the build number of a channel prerelease, e.g.
+// 1.5.0 and 2 for 1.5.0-beta.2 in the beta channel
+func parseChannelBuild(version *semver.Version, channel string) (string, uint64, bool) {
+	if !strings.HasPrefix(version.Prerelease(), channel+".") {
+		return "", 0, false
+	}
+	build, err := strconv.ParseUint(strings.TrimPrefix(version.Prerelease(), channel+"."), 10, 64)
+	if err != nil {
+		return "", 0, false
+	}
+	return fmt.Sprintf("%d.%d.%d", version.Major(), version.Minor(), version.Patch()), build, true
+}
+
+// trackChannelRelease remembers the latest build of each version in the prerelease channel and reports whether the
+// tag is considered by GetReleases, prereleases of other channels are ignored
+func (repo *GitLabRepository) trackChannelRelease(version *semver.Version) bool {
+	if repo.prereleaseChannel == "" || version.Prerelease() == "" {
+		return true
+	}
+	base, build, ok := parseChannelBuild(version, repo.prereleaseChannel)
+	if !ok {
+		return false
+	}
+	if build > repo.channelBuilds[base] {
+		repo.channelBuilds[base] = build
+	}
+	return true
+}
+
+// withChannelVersion appends the channel and the next build number to the version, e.g. 1.5.0 becomes 1.5.0-beta.3
+// if 1.5.0-beta.2 was the latest release of the beta channel
+func (repo *GitLabRepository) withChannelVersion(release *provider.CreateReleaseConfig) *provider.CreateReleaseConfig {
+	if repo.prereleaseChannel == "" {
+		return release
+	}
+	version, err := semver.NewVersion(release.NewVersion)
+	if err != nil || version.Prerelease() != "" {
+		return release
+	}
+	build := repo.channelBuilds[release.NewVersion] + 1
+	return &provider.CreateReleaseConfig{
+		Changelog:  release.Changelog,
+		NewVersion: fmt.Sprintf("%s-%s.%d", release.NewVersion, repo.prereleaseChannel, build),
+		Prerelease: true,
+		Branch:     release.Branch,
+		SHA:        release.SHA,
+	}
+}
+
+// upcomingReleasedAt dates prereleases in the future, GitLab shows them as upcoming releases
+func (repo *GitLabRepository) upcomingReleasedAt() *time.Time {
+	releasedAt := time.Now().Add(repo.prereleaseUpcoming).Truncate(time.Second)
+	return &releasedAt
+}
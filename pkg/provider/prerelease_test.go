@@ -0,0 +1,80 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/go-semantic-release/semantic-release/v2/pkg/provider"
+	"github.com/go-semantic-release/semantic-release/v2/pkg/semrel"
+	"github.com/stretchr/testify/require"
+	"github.com/xanzy/go-gitlab"
+)
+
+func TestPrereleaseChannel(t *testing.T) {
+	var release struct {
+		TagName    string     `json:"tag_name"`
+		ReleasedAt *time.Time `json:"released_at"`
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == fmt.Sprintf("/api/v4/projects/%d/repository/tags", GITLAB_PROJECT_ID):
+			json.NewEncoder(w).Encode([]*gitlab.Tag{ //nolint:errcheck
+				createGitlabTag("v1.5.0-beta.2", "beta2"),
+				createGitlabTag("v1.5.0-beta.1", "beta1"),
+				createGitlabTag("v1.5.0-alpha.7", "alpha7"),
+				createGitlabTag("v1.4.0", "stable"),
+			})
+		case r.Method == "POST" && r.URL.Path == fmt.Sprintf("/api/v4/projects/%d/releases", GITLAB_PROJECT_ID):
+			json.NewDecoder(r.Body).Decode(&release) //nolint:errcheck
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(gitlab.Release{TagName: release.TagName}) //nolint:errcheck
+		default:
+			GitlabHandler(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	repo := &GitLabRepository{}
+	err := repo.Init(map[string]string{
+		"gitlab_baseurl":             ts.URL,
+		"token":                      "gitlab-examples-ci",
+		"gitlab_projectid":           strconv.Itoa(GITLAB_PROJECT_ID),
+		"gitlab_branch":              "beta",
+		"gitlab_prerelease_channels": "beta=beta,next=next",
+		"gitlab_prerelease_upcoming": "24h",
+	})
+	require.NoError(t, err)
+
+	releases, err := repo.GetReleases("")
+	require.NoError(t, err)
+	require.Equal(t, []*semrel.Release{
+		{SHA: "beta2", Version: "1.5.0-beta.2"},
+		{SHA: "beta1", Version: "1.5.0-beta.1"},
+		{SHA: "stable", Version: "1.4.0"},
+	}, releases)
+
+	err = repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "1.5.0", SHA: "deadbeef"})
+	require.NoError(t, err)
+	require.Equal(t, "v1.5.0-beta.3", release.TagName)
+	require.WithinDuration(t, time.Now().Add(24*time.Hour), *release.ReleasedAt, time.Minute)
+
+	// versions without a build in the channel start at 1
+	err = repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "1.6.0", SHA: "deadbeef"})
+	require.NoError(t, err)
+	require.Equal(t, "v1.6.0-beta.1", release.TagName)
+}
+
+func TestInvalidPrereleaseChannel(t *testing.T) {
+	err := (&GitLabRepository{}).Init(map[string]string{
+		"token":                      "token",
+		"gitlab_projectid":           "1",
+		"gitlab_branch":              "beta",
+		"gitlab_prerelease_channels": "beta=be_ta",
+	})
+	require.EqualError(t, err, `failed to set property gitlab_prerelease_channels: channel "be_ta" is not a valid prerelease identifier`)
+}
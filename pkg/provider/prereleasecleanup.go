@@ -0,0 +1,89 @@
+package provider
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/xanzy/go-gitlab"
+)
+
+const (
+	// prereleaseCleanupReleases deletes the releases of the prereleases but keeps their tags as an archive
+	prereleaseCleanupReleases = "releases"
+	// prereleaseCleanupTags deletes the releases and the tags of the prereleases
+	prereleaseCleanupTags = "tags"
+)
+
+// prereleaseTag is a tag of a prerelease of the released version
+type prereleaseTag struct {
+	name    string
+	version *semver.Version
+}
+
+// listPrereleaseTags lists the tags of the prereleases of the stable version, e.g. v1.5.0-rc.1 and v1.5.0-beta.2 for
+// 1.5.0, newest first
+func (repo *GitLabRepository) listPrereleaseTags(version *semver.Version) ([]*prereleaseTag, error) {
+	opts := &gitlab.ListTagsOptions{
+		ListOptions: repo.listOptions(),
+		Search:      gitlab.String("^" + repo.tagName(version.String()+"-")),
+	}
+	prereleases := make([]*prereleaseTag, 0)
+	for {
+		tags, resp, err := repo.api.ListTags(repo.projectID, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list tags: %w", err)
+		}
+		for _, tag := range tags {
+			tagVersion, err := repo.tagVersion(tag.Name)
+			if err != nil || tagVersion.Prerelease() == "" {
+				continue
+			}
+			if tagVersion.Major() == version.Major() && tagVersion.Minor() == version.Minor() && tagVersion.Patch() == version.Patch() {
+				prereleases = append(prereleases, &prereleaseTag{name: tag.Name, version: tagVersion})
+			}
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	sort.SliceStable(prereleases, func(i, j int) bool {
+		return prereleases[i].version.GreaterThan(prereleases[j].version)
+	})
+	return prereleases, nil
+}
+
+// cleanupPrereleases deletes the prereleases of the stable version except for the newest gitlab_prerelease_cleanup_keep
+// ones, failures are only logged
+func (repo *GitLabRepository) cleanupPrereleases(version string) {
+	stable, err := semver.NewVersion(version)
+	if err != nil || stable.Prerelease() != "" {
+		return
+	}
+	prereleases, err := repo.listPrereleaseTags(stable)
+	if err != nil {
+		repo.logger.Printf("WARNING: failed to clean up the prereleases of %s: %s", version, err)
+		return
+	}
+	if len(prereleases) <= repo.prereleaseCleanupKeep {
+		return
+	}
+
+	for _, prerelease := range prereleases[repo.prereleaseCleanupKeep:] {
+		if err := repo.deleteRelease(prerelease.name); err != nil {
+			repo.logger.Printf("WARNING: %s", err)
+			continue
+		}
+		if repo.prereleaseCleanup != prereleaseCleanupTags {
+			repo.logger.Printf("deleted release %s, the tag is kept", prerelease.name)
+			continue
+		}
+		if err := repo.deleteTag(prerelease.name); err != nil {
+			repo.logger.Printf("WARNING: %s", err)
+			continue
+		}
+		repo.logger.Printf("deleted prerelease %s", prerelease.name)
+	}
+}
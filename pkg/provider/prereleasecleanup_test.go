@@ -0,0 +1,88 @@
+package provider
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/go-semantic-release/semantic-release/v2/pkg/provider"
+	"github.com/stretchr/testify/require"
+	"github.com/xanzy/go-gitlab"
+)
+
+func TestGitlabCleanupPrereleases(t *testing.T) {
+	var deleted []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tagsPath := fmt.Sprintf("/api/v4/projects/%d/repository/tags", GITLAB_PROJECT_ID)
+		releasesPath := fmt.Sprintf("/api/v4/projects/%d/releases", GITLAB_PROJECT_ID)
+		switch {
+		case r.Method == "GET" && r.URL.Path == tagsPath && r.URL.Query().Get("search") != "":
+			require.Equal(t, "^v2.0.0-", r.URL.Query().Get("search"))
+			json.NewEncoder(w).Encode([]*gitlab.Tag{ //nolint:errcheck
+				{Name: "v2.0.0-beta.1"},
+				{Name: "v2.0.0-rc.1"},
+				{Name: "v2.0.0-beta.2"},
+				{Name: "v2.0.0-rc.2"},
+				{Name: "v2.0.0-nightly"},
+			})
+		case r.Method == "DELETE" && strings.HasPrefix(r.URL.Path, releasesPath+"/"):
+			tag, _ := url.PathUnescape(strings.TrimPrefix(r.URL.Path, releasesPath+"/"))
+			deleted = append(deleted, "release "+tag)
+			fmt.Fprint(w, "{}")
+		case r.Method == "DELETE" && strings.HasPrefix(r.URL.Path, tagsPath+"/"):
+			tag, _ := url.PathUnescape(strings.TrimPrefix(r.URL.Path, tagsPath+"/"))
+			deleted = append(deleted, "tag "+tag)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			GitlabHandler(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	var logs bytes.Buffer
+	repo := &GitLabRepository{logger: log.New(&logs, "", 0)}
+	err := repo.Init(map[string]string{
+		"gitlab_baseurl":                 ts.URL,
+		"token":                          "token",
+		"gitlab_projectid":               strconv.Itoa(GITLAB_PROJECT_ID),
+		"gitlab_prerelease_cleanup":      "tags",
+		"gitlab_prerelease_cleanup_keep": "1",
+	})
+	require.NoError(t, err)
+
+	err = repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "2.0.0", SHA: "deadbeef"})
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"release v2.0.0-rc.1", "tag v2.0.0-rc.1",
+		"release v2.0.0-nightly", "tag v2.0.0-nightly",
+		"release v2.0.0-beta.2", "tag v2.0.0-beta.2",
+		"release v2.0.0-beta.1", "tag v2.0.0-beta.1",
+	}, deleted)
+	require.Contains(t, logs.String(), "deleted prerelease v2.0.0-rc.1")
+
+	deleted = nil
+	repo.prereleaseCleanup, repo.prereleaseCleanupKeep = prereleaseCleanupReleases, 0
+	repo.cleanupPrereleases("2.0.0")
+	require.Equal(t, []string{
+		"release v2.0.0-rc.2", "release v2.0.0-rc.1", "release v2.0.0-nightly", "release v2.0.0-beta.2", "release v2.0.0-beta.1",
+	}, deleted)
+	require.Contains(t, logs.String(), "deleted release v2.0.0-beta.1, the tag is kept")
+
+	deleted = nil
+	repo.cleanupPrereleases("2.0.0-rc.3")
+	require.Empty(t, deleted)
+
+	err = repo.Init(map[string]string{
+		"token":                     "token",
+		"gitlab_projectid":          strconv.Itoa(GITLAB_PROJECT_ID),
+		"gitlab_prerelease_cleanup": "archive",
+	})
+	require.EqualError(t, err, `failed to set property gitlab_prerelease_cleanup: unknown mode "archive", expected releases or tags`)
+}
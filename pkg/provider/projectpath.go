@@ -0,0 +1,75 @@
+package provider
+
+import (
+	"net/url"
+	"os/exec"
+	"strings"
+)
+
+// resolveProjectFromGitRemote sets gitlab_projectid to the path of the project the origin remote points to if neither
+// the option nor its environment variables are set. Remotes of other hosts than the instance are ignored, so a clone
+// from a different forge never resolves to an unrelated project.
+func resolveProjectFromGitRemote(config map[string]string) map[string]string {
+	if lookupConfig(config, "gitlab_projectid") != "" {
+		return config
+	}
+	out, err := exec.Command("git", "remote", "get-url", "origin").Output()
+	if err != nil {
+		return config
+	}
+	path := parseProjectPath(strings.TrimSpace(string(out)), lookupConfig(config, "gitlab_baseurl"))
+	if path == "" {
+		return config
+	}
+
+	resolved := make(map[string]string, len(config)+1)
+	for key, value := range config {
+		resolved[key] = value
+	}
+	resolved["gitlab_projectid"] = path
+	return resolved
+}
+
+// parseProjectPath returns the project path of a HTTP(S), SSH or scp-like git remote URL if it belongs to the instance
+func parseProjectPath(remote, baseURL string) string {
+	host, basePath := "gitlab.com", ""
+	if baseURL != "" {
+		u, err := url.Parse(baseURL)
+		if err != nil {
+			return ""
+		}
+		host, basePath = u.Hostname(), strings.Trim(u.Path, "/")
+	}
+
+	var remoteHost, path string
+	if strings.Contains(remote, "://") {
+		u, err := url.Parse(remote)
+		if err != nil {
+			return ""
+		}
+		remoteHost, path = u.Hostname(), u.Path
+	} else {
+		// scp-like syntax, e.g. git@gitlab.com:group/project.git
+		hostPart, p, ok := strings.Cut(remote, ":")
+		if !ok {
+			return ""
+		}
+		if _, h, found := strings.Cut(hostPart, "@"); found {
+			hostPart = h
+		}
+		remoteHost, path = hostPart, p
+	}
+	if !strings.EqualFold(remoteHost, host) {
+		return ""
+	}
+
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	// instances served from a relative URL root include it in HTTP remotes only
+	if basePath != "" {
+		path = strings.TrimPrefix(strings.TrimPrefix(path, basePath+"/"), "/")
+	}
+	if !strings.Contains(path, "/") {
+		return ""
+	}
+	return path
+}
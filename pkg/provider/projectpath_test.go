@@ -0,0 +1,31 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseProjectPath(t *testing.T) {
+	for _, tc := range []struct {
+		remote, baseURL, path string
+	}{
+		{"https://gitlab.com/group/project.git", "", "group/project"},
+		{"git@gitlab.com:group/sub/project.git", "", "group/sub/project"},
+		{"ssh://git@gitlab.example.com:2222/group/project.git", "https://gitlab.example.com", "group/project"},
+		{"https://example.com/gitlab/group/project.git", "https://example.com/gitlab/", "group/project"},
+		{"https://github.com/group/project.git", "", ""},
+		{"git@gitlab.com:project.git", "", ""},
+		{"/srv/git/project.git", "", ""},
+	} {
+		require.Equal(t, tc.path, parseProjectPath(tc.remote, tc.baseURL), tc.remote)
+	}
+}
+
+func TestProjectPathEnvFallback(t *testing.T) {
+	t.Setenv("CI_PROJECT_ID", "")
+	t.Setenv("CI_PROJECT_PATH", "group/project")
+
+	require.Equal(t, "group/project", lookupConfig(map[string]string{}, "gitlab_projectid"))
+	require.Equal(t, map[string]string{}, resolveProjectFromGitRemote(map[string]string{}))
+}
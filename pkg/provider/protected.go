@@ -0,0 +1,153 @@
+package provider
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+// checkTagProtection returns a descriptive error if a protected tag rule does not allow the token user to create the tag.
+// The check is skipped if the project has no protected tags endpoint, other errors are returned.
+func (repo *GitLabRepository) checkTagProtection(tag string) error {
+	rules, resp, err := repo.listProtectedTags()
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to list the protected tags: %w", err)
+	}
+
+	var matching []*gitlab.ProtectedTag
+	for _, rule := range rules {
+		if matchWildcard(rule.Name, tag) {
+			matching = append(matching, rule)
+		}
+	}
+	if len(matching) == 0 {
+		return nil
+	}
+
+	user, _, err := repo.api.CurrentUser()
+	if err != nil {
+		return fmt.Errorf("failed to get the current user: %w", err)
+	}
+	if user.IsAdmin {
+		return nil
+	}
+
+	level := gitlab.NoPermissions
+	member, resp, err := repo.api.GetInheritedProjectMember(repo.projectID, user.ID)
+	switch {
+	case resp != nil && resp.StatusCode == http.StatusNotFound:
+		// not a member of the project
+	case err != nil:
+		return fmt.Errorf("failed to get the membership of user %s: %w", user.Username, err)
+	default:
+		level = member.AccessLevel
+	}
+
+	for _, rule := range matching {
+		if !canCreateProtectedTag(rule, level) {
+			return fmt.Errorf("tag %s is protected by the rule %s which allows %s to create it, but user %s has the %s role",
+				tag, rule.Name, describeTagAccessLevels(rule), user.Username, accessLevelName(level))
+		}
+	}
+	return nil
+}
+
+// listProtectedTags returns the protected tag rules of the project
+func (repo *GitLabRepository) listProtectedTags() ([]*gitlab.ProtectedTag, *gitlab.Response, error) {
+	rules := make([]*gitlab.ProtectedTag, 0)
+	listOptions := repo.listOptions()
+	opts := (*gitlab.ListProtectedTagsOptions)(&listOptions)
+	for {
+		page, resp, err := repo.api.ListProtectedTags(repo.projectID, opts)
+		if err != nil {
+			return nil, resp, err
+		}
+		rules = append(rules, page...)
+		if resp.NextPage == 0 {
+			return rules, resp, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+func canCreateProtectedTag(rule *gitlab.ProtectedTag, level gitlab.AccessLevelValue) bool {
+	for _, access := range rule.CreateAccessLevels {
+		// access granted to specific users or groups cannot be verified
+		if access.AccessLevel == gitlab.NoPermissions && access.AccessLevelDescription != "No one" {
+			return true
+		}
+		if access.AccessLevel != gitlab.NoPermissions && level >= access.AccessLevel {
+			return true
+		}
+	}
+	return false
+}
+
+func describeTagAccessLevels(rule *gitlab.ProtectedTag) string {
+	descriptions := make([]string, 0, len(rule.CreateAccessLevels))
+	for _, access := range rule.CreateAccessLevels {
+		descriptions = append(descriptions, strings.ToLower(access.AccessLevelDescription))
+	}
+	if len(descriptions) == 0 {
+		return "no one"
+	}
+	return strings.Join(descriptions, ", ")
+}
+
+// matchWildcard matches a name against a GitLab protection pattern like v* or release-*-stable
+func matchWildcard(pattern, name string) bool {
+	re := "^" + strings.ReplaceAll(regexp.QuoteMeta(pattern), `\*`, ".*") + "$"
+	return regexp.MustCompile(re).MatchString(name)
+}
+
+// parseTagAccessConfig parses the role allowed to create protected tags, maintainer by default
+func parseTagAccessConfig(config map[string]string, key string) (gitlab.AccessLevelValue, error) {
+	switch strings.ToLower(config[key]) {
+	case "", "maintainer", "maintainers":
+		return gitlab.MaintainerPermissions, nil
+	case "developer", "developers":
+		return gitlab.DeveloperPermissions, nil
+	}
+	return gitlab.NoPermissions, fmt.Errorf("failed to set property %s: unknown role %q, expected maintainer or developer", key, config[key])
+}
+
+// ensureTagProtection creates the protected tag rule gitlab_protected_tag if the project does not have it yet, e.g. on
+// the first release of a newly onboarded project. An existing rule is never changed, it is only reported if it allows
+// other roles than gitlab_protected_tag_access.
+func (repo *GitLabRepository) ensureTagProtection(tag string) error {
+	if !matchWildcard(repo.protectedTag, tag) {
+		repo.logger.Printf("WARNING: the protected tag rule %s does not match the release tag %s", repo.protectedTag, tag)
+	}
+
+	rules, _, err := repo.listProtectedTags()
+	if err != nil {
+		return fmt.Errorf("failed to list the protected tags, maintainer role is required for gitlab_protected_tag: %w", err)
+	}
+	for _, rule := range rules {
+		if rule.Name != repo.protectedTag {
+			continue
+		}
+		if len(rule.CreateAccessLevels) != 1 || rule.CreateAccessLevels[0].AccessLevel != repo.protectedTagAccess {
+			repo.logger.Printf("WARNING: the protected tag rule %s allows %s to create tags instead of %ss",
+				rule.Name, describeTagAccessLevels(rule), accessLevelName(repo.protectedTagAccess))
+		}
+		repo.debugf("tags matching %s are protected", rule.Name)
+		return nil
+	}
+
+	_, _, err = repo.api.ProtectRepositoryTags(repo.projectID, &gitlab.ProtectRepositoryTagsOptions{
+		Name:              gitlab.String(repo.protectedTag),
+		CreateAccessLevel: gitlab.AccessLevel(repo.protectedTagAccess),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to protect tags matching %s: %w", repo.protectedTag, err)
+	}
+	repo.logger.Printf("protected tags matching %s, only %ss may create them", repo.protectedTag, accessLevelName(repo.protectedTagAccess))
+	return nil
+}
@@ -0,0 +1,164 @@
+package provider
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/go-semantic-release/semantic-release/v2/pkg/provider"
+	"github.com/stretchr/testify/require"
+	"github.com/xanzy/go-gitlab"
+)
+
+func TestMatchWildcard(t *testing.T) {
+	require.True(t, matchWildcard("v*", "v1.0.0"))
+	require.True(t, matchWildcard("release-*-stable", "release-1.2-stable"))
+	require.True(t, matchWildcard("v1.0.0", "v1.0.0"))
+	require.False(t, matchWildcard("v*", "1.0.0"))
+	require.False(t, matchWildcard("v1.?", "v1.0"))
+}
+
+func TestGitlabCheckTagProtection(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(GitlabHandler))
+	defer ts.Close()
+
+	repo := &GitLabRepository{}
+	err := repo.Init(map[string]string{
+		"gitlab_baseurl":   ts.URL,
+		"token":            "gitlab-examples-ci",
+		"gitlab_projectid": strconv.Itoa(GITLAB_PROJECT_ID),
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, repo.checkTagProtection("v1.0.0"))
+	require.NoError(t, repo.checkTagProtection("1.0.0"))
+	require.EqualError(t, repo.checkTagProtection("stable-1.0.0"),
+		"tag stable-1.0.0 is protected by the rule stable-* which allows no one to create it, but user release-bot has the maintainer role")
+
+	require.False(t, canCreateProtectedTag(GITLAB_PROTECTED_TAGS[0], gitlab.DeveloperPermissions))
+	status := http.StatusForbidden
+	ts.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == fmt.Sprintf("/api/v4/projects/%d/protected_tags", GITLAB_PROJECT_ID) {
+			http.Error(w, fmt.Sprintf(`{"message":"%d"}`, status), status)
+			return
+		}
+		GitlabHandler(w, r)
+	})
+	err = repo.checkTagProtection("stable-1.0.0")
+	require.ErrorContains(t, err, "failed to list the protected tags")
+
+	status = http.StatusNotFound
+	require.NoError(t, repo.checkTagProtection("stable-1.0.0"))
+}
+
+func TestGitlabCreateReleaseTagProtection(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost && r.URL.Path == fmt.Sprintf("/api/v4/projects/%d/releases", GITLAB_PROJECT_ID) {
+			fmt.Fprint(w, "{}")
+			return
+		}
+		GitlabHandler(w, r)
+	}))
+	defer ts.Close()
+
+	config := map[string]string{
+		"gitlab_baseurl":    ts.URL,
+		"token":             "gitlab-examples-ci",
+		"gitlab_projectid":  strconv.Itoa(GITLAB_PROJECT_ID),
+		"gitlab_tag_prefix": "stable-",
+	}
+	release := &provider.CreateReleaseConfig{NewVersion: "1.0.0", SHA: "deadbeef"}
+
+	// the rules are only checked when the access is verified
+	repo := &GitLabRepository{}
+	require.NoError(t, repo.Init(config))
+	require.NoError(t, repo.CreateRelease(release))
+
+	config["gitlab_verify_access"] = "true"
+	repo = &GitLabRepository{}
+	require.NoError(t, repo.Init(config))
+	require.ErrorContains(t, repo.CreateRelease(release), "tag stable-v1.0.0 is protected by the rule stable-*")
+}
+
+func TestGitlabCheckTagProtectionPaginated(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == fmt.Sprintf("/api/v4/projects/%d/protected_tags", GITLAB_PROJECT_ID) {
+			require.Equal(t, "100", r.URL.Query().Get("per_page"))
+			if r.URL.Query().Get("page") != "2" {
+				w.Header().Set("X-Next-Page", "2")
+				json.NewEncoder(w).Encode([]*gitlab.ProtectedTag{{Name: "other-*"}}) //nolint:errcheck
+				return
+			}
+			json.NewEncoder(w).Encode(GITLAB_PROTECTED_TAGS) //nolint:errcheck
+			return
+		}
+		GitlabHandler(w, r)
+	}))
+	defer ts.Close()
+
+	repo := &GitLabRepository{}
+	err := repo.Init(map[string]string{
+		"gitlab_baseurl":   ts.URL,
+		"token":            "gitlab-examples-ci",
+		"gitlab_projectid": strconv.Itoa(GITLAB_PROJECT_ID),
+	})
+	require.NoError(t, err)
+
+	// the matching rule is on the second page
+	require.EqualError(t, repo.checkTagProtection("stable-1.0.0"),
+		"tag stable-1.0.0 is protected by the rule stable-* which allows no one to create it, but user release-bot has the maintainer role")
+}
+
+func TestGitlabEnsureTagProtection(t *testing.T) {
+	var created *gitlab.ProtectRepositoryTagsOptions
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "POST" && r.URL.Path == fmt.Sprintf("/api/v4/projects/%d/protected_tags", GITLAB_PROJECT_ID) {
+			created = &gitlab.ProtectRepositoryTagsOptions{}
+			json.NewDecoder(r.Body).Decode(created) //nolint:errcheck
+			fmt.Fprint(w, "{}")
+			return
+		}
+		GitlabHandler(w, r)
+	}))
+	defer ts.Close()
+
+	var logs bytes.Buffer
+	repo := &GitLabRepository{logger: log.New(&logs, "", 0)}
+	err := repo.Init(map[string]string{
+		"gitlab_baseurl":       ts.URL,
+		"token":                "gitlab-examples-ci",
+		"gitlab_projectid":     strconv.Itoa(GITLAB_PROJECT_ID),
+		"gitlab_protected_tag": "v*",
+	})
+	require.NoError(t, err)
+	require.Equal(t, gitlab.MaintainerPermissions, repo.protectedTagAccess)
+
+	// the rule already exists with the expected role
+	require.NoError(t, repo.ensureTagProtection("v1.0.0"))
+	require.Nil(t, created)
+	require.Empty(t, logs.String())
+
+	repo.protectedTagAccess = gitlab.DeveloperPermissions
+	require.NoError(t, repo.ensureTagProtection("v1.0.0"))
+	require.Nil(t, created)
+	require.Contains(t, logs.String(), "WARNING: the protected tag rule v* allows maintainers to create tags instead of developers")
+
+	logs.Reset()
+	repo.protectedTag = "release-*"
+	require.NoError(t, repo.ensureTagProtection("release-1.0.0"))
+	require.Equal(t, "release-*", *created.Name)
+	require.Equal(t, gitlab.DeveloperPermissions, *created.CreateAccessLevel)
+	require.Equal(t, "protected tags matching release-*, only developers may create them\n", logs.String())
+
+	logs.Reset()
+	require.NoError(t, repo.ensureTagProtection("v1.0.0"))
+	require.Contains(t, logs.String(), "WARNING: the protected tag rule release-* does not match the release tag v1.0.0")
+
+	_, err = parseTagAccessConfig(map[string]string{"gitlab_protected_tag_access": "owner"}, "gitlab_protected_tag_access")
+	require.EqualError(t, err, `failed to set property gitlab_protected_tag_access: unknown role "owner", expected maintainer or developer`)
+}
@@ -0,0 +1,74 @@
+package provider
+
+import (
+	"errors"
+	"net/http"
+	"os"
+
+	"github.com/go-semantic-release/semantic-release/v2/pkg/semrel"
+	"github.com/xanzy/go-gitlab"
+)
+
+const (
+	readTokenTypePrivate = "private"
+	readTokenTypeJob     = "job"
+)
+
+// newReadClient creates the client for read requests if a Geo secondary or a separate read token is configured. With
+// a low-privilege read token the release token is only used for requests changing the project. Job tokens default to
+// CI_JOB_TOKEN.
+func (repo *GitLabRepository) newReadClient(config map[string]string, baseURL, token string, headers http.Header) (*gitlab.Client, error) {
+	readToken := lookupConfig(config, "gitlab_read_token")
+	tokenType := defaultString(config["gitlab_read_token_type"], readTokenTypePrivate)
+	if tokenType == readTokenTypeJob && readToken == "" {
+		if readToken = os.Getenv("CI_JOB_TOKEN"); readToken == "" {
+			return nil, errors.New("a job token requires gitlab_read_token or CI_JOB_TOKEN")
+		}
+	}
+	if config["gitlab_read_baseurl"] == "" && readToken == "" {
+		return nil, nil
+	}
+
+	if readToken == "" {
+		readToken = token
+	} else {
+		// impersonation requires the admin token
+		headers = nil
+	}
+	options := repo.clientOptions(defaultString(config["gitlab_read_baseurl"], baseURL), headers)
+	if tokenType == readTokenTypeJob {
+		return gitlab.NewJobClient(readToken, options...)
+	}
+	return gitlab.NewClient(readToken, options...)
+}
+
+// reader returns the client for heavy read requests, which uses the read token and the Geo secondary if configured.
+// Tags and releases are always created on the primary, a tag missing on a lagging secondary therefore ends in a
+// conflict instead of a duplicate release.
+func (repo *GitLabRepository) reader() Client {
+	if repo.readClient != nil {
+		return &gitlabClient{client: repo.readClient}
+	}
+	return repo.api
+}
+
+// listCommitsFromReader lists the commits on the secondary and falls back to the primary if the secondary has not
+// replicated the released commit yet
+func (repo *GitLabRepository) listCommitsFromReader(fromSha, toSha string) ([]*semrel.RawCommit, error) {
+	if repo.readClient == nil {
+		commits, _, err := repo.listCommitRange(repo.api, fromSha, toSha)
+		return commits, err
+	}
+
+	commits, head, err := repo.listCommitRange(&gitlabClient{client: repo.readClient}, fromSha, toSha)
+	if err == nil && (toSha == "" || head == toSha) {
+		return commits, nil
+	}
+	if err != nil {
+		repo.logger.Printf("failed to list commits on the read replica, falling back to the primary: %s", err)
+	} else {
+		repo.logger.Printf("the read replica has not replicated %s yet, falling back to the primary", toSha)
+	}
+	commits, _, err = repo.listCommitRange(repo.api, fromSha, toSha)
+	return commits, err
+}
@@ -0,0 +1,124 @@
+package provider
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/go-semantic-release/provider-gitlab/pkg/gitlabtest"
+	"github.com/go-semantic-release/semantic-release/v2/pkg/provider"
+	"github.com/stretchr/testify/require"
+)
+
+// newGeoTestRepo reads from a secondary which has replicated the history of the primary up to abcd
+func newGeoTestRepo(t *testing.T) (*GitLabRepository, *gitlabtest.Server, *gitlabtest.Server, *bytes.Buffer) {
+	newServer := func() (*gitlabtest.Server, *gitlabtest.Project) {
+		server, project := newFakeServer(t)
+		addGitlabCommits(server, project)
+		server.AddTag(project, "v1.0.0", "efcd")
+		return server, project
+	}
+	primary, primaryProject := newServer()
+	secondary, _ := newServer()
+	primary.AddCommit(primaryProject, "feedface", "fix: not replicated yet")
+
+	var logs bytes.Buffer
+	repo := initFakeRepo(t, &GitLabRepository{logger: log.New(&logs, "", 0)}, primary, map[string]string{
+		"gitlab_read_baseurl": secondary.URL,
+	})
+	return repo, primary, secondary, &logs
+}
+
+func TestReadBaseURL(t *testing.T) {
+	repo, primary, secondary, _ := newGeoTestRepo(t)
+	commitsRequest := "GET /api/v4/projects/group/app/repository/commits"
+	tagsRequest := "GET /api/v4/projects/group/app/repository/tags"
+
+	commits, err := repo.GetCommits("", GITLAB_COMMITS[0].ID)
+	require.NoError(t, err)
+	require.Len(t, commits, len(GITLAB_COMMITS))
+	_, err = repo.GetReleases("")
+	require.NoError(t, err)
+
+	require.Len(t, secondary.Queries(commitsRequest), 1)
+	require.Len(t, secondary.Queries(tagsRequest), 1)
+	require.Empty(t, primary.Queries(commitsRequest))
+	require.Empty(t, primary.Queries(tagsRequest))
+}
+
+func TestReadBaseURLFallback(t *testing.T) {
+	repo, primary, secondary, logs := newGeoTestRepo(t)
+	commitsRequest := "GET /api/v4/projects/group/app/repository/commits"
+
+	// the secondary does not know the commit yet
+	commits, err := repo.GetCommits("", "feedface")
+	require.NoError(t, err)
+	require.Len(t, commits, len(GITLAB_COMMITS)+1)
+	require.Len(t, secondary.Queries(commitsRequest), 1)
+	require.Len(t, primary.Queries(commitsRequest), 1)
+	require.Contains(t, logs.String(), "the read replica has not replicated feedface yet, falling back to the primary")
+}
+
+func TestReadToken(t *testing.T) {
+	tokens := make(map[string]string)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tokens[r.Method+" "+r.URL.Path] = r.Header.Get("PRIVATE-TOKEN")
+		GitlabHandler(w, r)
+	}))
+	defer ts.Close()
+
+	repo := &GitLabRepository{}
+	err := repo.Init(map[string]string{
+		"gitlab_baseurl":    ts.URL,
+		"token":             "write-token",
+		"gitlab_read_token": "read-token",
+		"gitlab_projectid":  strconv.Itoa(GITLAB_PROJECT_ID),
+	})
+	require.NoError(t, err)
+
+	_, err = repo.GetInfo()
+	require.NoError(t, err)
+	_, err = repo.GetCommits("", GITLAB_COMMITS[0].ID)
+	require.NoError(t, err)
+	_, err = repo.GetReleases("")
+	require.NoError(t, err)
+	err = repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "2.0.0", SHA: "deadbeef"})
+	require.NoError(t, err)
+
+	require.Equal(t, "read-token", tokens["GET /api/v4/projects/12324322"])
+	require.Equal(t, "read-token", tokens["GET /api/v4/projects/12324322/repository/commits"])
+	require.Equal(t, "read-token", tokens["GET /api/v4/projects/12324322/repository/tags"])
+	require.Equal(t, "write-token", tokens["POST /api/v4/projects/12324322/releases"])
+}
+
+func TestReadJobToken(t *testing.T) {
+	t.Setenv("CI_JOB_TOKEN", "job-token")
+	var jobTokens []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v4/projects/12324322/repository/tags" {
+			jobTokens = append(jobTokens, r.Header.Get("JOB-TOKEN"))
+			r.Header.Set("PRIVATE-TOKEN", "accepted")
+		}
+		GitlabHandler(w, r)
+	}))
+	defer ts.Close()
+
+	config := map[string]string{
+		"gitlab_baseurl":         ts.URL,
+		"token":                  "write-token",
+		"gitlab_read_token_type": "job",
+		"gitlab_projectid":       strconv.Itoa(GITLAB_PROJECT_ID),
+	}
+	repo := &GitLabRepository{}
+	require.NoError(t, repo.Init(config))
+	_, err := repo.GetReleases("")
+	require.NoError(t, err)
+	require.Equal(t, []string{"job-token"}, jobTokens)
+
+	t.Setenv("CI_JOB_TOKEN", "")
+	err = (&GitLabRepository{}).Init(config)
+	require.EqualError(t, err, "failed to create read client: a job token requires gitlab_read_token or CI_JOB_TOKEN")
+}
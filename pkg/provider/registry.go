@@ -0,0 +1,242 @@
+package provider
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"text/template"
+	"time"
+)
+
+const (
+	defaultContainerSourceTag = "{{.SHA}}"
+	defaultContainerTags      = "{{.Tag}},{{if not .Prerelease}}{{.Major}}.{{.Minor}}{{end}},{{if and .Latest (not .Prerelease)}}latest{{end}}"
+)
+
+var manifestMediaTypes = []string{
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}
+
+// parseTemplateListConfig parses a comma separated list of templates
+func parseTemplateListConfig(config map[string]string, key, defaultValue string) ([]*template.Template, error) {
+	value := config[key]
+	if value == "" {
+		value = defaultValue
+	}
+	templates := make([]*template.Template, 0)
+	for _, v := range parseListConfig(map[string]string{key: value}, key) {
+		tmpl, err := template.New(key).Funcs(templateFuncs).Parse(v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to set property %s: %w", key, err)
+		}
+		templates = append(templates, tmpl)
+	}
+	return templates, nil
+}
+
+// registryClient talks to the Docker Registry HTTP API of the GitLab container registry
+type registryClient struct {
+	baseURL    string
+	repository string
+	token      string
+	client     *http.Client
+}
+
+// newRegistryClient splits the image into registry and repository and requests a pull and push token for it
+func (repo *GitLabRepository) newRegistryClient(image string) (*registryClient, error) {
+	scheme := "https"
+	if s, rest, ok := strings.Cut(image, "://"); ok {
+		scheme, image = s, rest
+	}
+	host, repository, ok := strings.Cut(image, "/")
+	if !ok || repository == "" {
+		return nil, fmt.Errorf("invalid image %s, expected registry/repository", image)
+	}
+
+	rc := &registryClient{
+		baseURL:    scheme + "://" + host,
+		repository: repository,
+		client:     &http.Client{Timeout: 30 * time.Second, Transport: repo.transport()},
+	}
+
+	// the registry announces the token service of the GitLab instance in its challenge
+	resp, err := rc.client.Get(rc.baseURL + "/v2/")
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		return rc, nil
+	}
+	challenge := parseAuthChallenge(resp.Header.Get("WWW-Authenticate"))
+	if challenge["realm"] == "" {
+		return nil, fmt.Errorf("registry %s did not announce a token service", host)
+	}
+
+	if err := repo.checkTokenRealm(host, challenge["realm"]); err != nil {
+		return nil, err
+	}
+
+	query := url.Values{}
+	query.Set("service", challenge["service"])
+	query.Set("scope", fmt.Sprintf("repository:%s:pull,push", repository))
+	req, err := http.NewRequest(http.MethodGet, challenge["realm"]+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	user, err := repo.registryUsername()
+	if err != nil {
+		return nil, err
+	}
+	req.SetBasicAuth(user, repo.token)
+
+	resp, err = rc.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get a registry token for %s: %s", repository, resp.Status)
+	}
+	var token struct {
+		Token string `json:"token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return nil, fmt.Errorf("failed to decode the registry token: %w", err)
+	}
+	rc.token = token.Token
+	return rc, nil
+}
+
+// checkTokenRealm makes sure the token service announced by the registry belongs to the GitLab instance, any
+// registry could otherwise collect the token by announcing its own service
+func (repo *GitLabRepository) checkTokenRealm(registry, realm string) error {
+	u, err := url.Parse(realm)
+	if err != nil || u.Host == "" {
+		return fmt.Errorf("registry %s announced the invalid token service %q", registry, realm)
+	}
+	if repo.isInstanceURL(u) {
+		return nil
+	}
+	if project, err := repo.getProject(); err == nil && project.ContainerRegistryImagePrefix != "" {
+		registryHost, _, _ := strings.Cut(project.ContainerRegistryImagePrefix, "/")
+		if u.Scheme == "https" && u.Host == registryHost {
+			return nil
+		}
+	}
+	return fmt.Errorf("registry %s announced the token service %s which is neither the GitLab instance nor its registry, the token is not sent to it",
+		registry, u.Scheme+"://"+u.Host)
+}
+
+// registryUsername returns the configured user or the owner of the token
+func (repo *GitLabRepository) registryUsername() (string, error) {
+	if repo.registryUser != "" {
+		return repo.registryUser, nil
+	}
+	// the current user is the impersonated one, but the registry authenticates the owner of the token
+	if repo.sudo != "" {
+		return "", errors.New("gitlab_container_registry_user is required if gitlab_sudo is set")
+	}
+	user, _, err := repo.api.CurrentUser()
+	if err != nil {
+		return "", fmt.Errorf("failed to get the registry user: %w", err)
+	}
+	return user.Username, nil
+}
+
+// parseAuthChallenge returns the parameters of a Bearer WWW-Authenticate header
+func parseAuthChallenge(header string) map[string]string {
+	params := make(map[string]string)
+	_, header, ok := strings.Cut(header, " ")
+	if !ok {
+		return params
+	}
+	for _, param := range strings.Split(header, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+		if ok {
+			params[name] = strings.Trim(value, `"`)
+		}
+	}
+	return params
+}
+
+func (rc *registryClient) do(method, reference, contentType string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequest(method, fmt.Sprintf("%s/v2/%s/manifests/%s", rc.baseURL, rc.repository, reference), body)
+	if err != nil {
+		return nil, err
+	}
+	if rc.token != "" {
+		req.Header.Set("Authorization", "Bearer "+rc.token)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	req.Header.Set("Accept", strings.Join(manifestMediaTypes, ", "))
+	return rc.client.Do(req)
+}
+
+// tag puts the manifest of the source tag under all target tags, the image layers are not touched
+func (rc *registryClient) tag(source string, targets []string) error {
+	resp, err := rc.do(http.MethodGet, source, "", nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to get manifest %s:%s: %s", rc.repository, source, resp.Status)
+	}
+	manifest, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	contentType := resp.Header.Get("Content-Type")
+
+	for _, target := range targets {
+		resp, err := rc.do(http.MethodPut, target, contentType, bytes.NewReader(manifest))
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= 300 {
+			return fmt.Errorf("failed to tag %s:%s as %s: %s", rc.repository, source, target, resp.Status)
+		}
+	}
+	return nil
+}
+
+// tagContainerImage tags the image built for the released commit with the release version
+func (repo *GitLabRepository) tagContainerImage(data *templateData) error {
+	source, err := renderTemplate(repo.containerSourceTag, data)
+	if err != nil {
+		return err
+	}
+	targets := make([]string, 0, len(repo.containerTags))
+	for _, tmpl := range repo.containerTags {
+		target, err := renderTemplate(tmpl, data)
+		if err != nil {
+			return err
+		}
+		// templates may skip tags, e.g. latest for prereleases
+		if target = strings.TrimSpace(target); target != "" {
+			targets = append(targets, target)
+		}
+	}
+
+	rc, err := repo.newRegistryClient(repo.containerImage)
+	if err != nil {
+		return fmt.Errorf("failed to connect to the container registry: %w", err)
+	}
+	if err := rc.tag(source, targets); err != nil {
+		return err
+	}
+	repo.logger.Printf("tagged container image %s:%s as %s", repo.containerImage, source, strings.Join(targets, ", "))
+	return nil
+}
@@ -0,0 +1,144 @@
+package provider
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/go-semantic-release/semantic-release/v2/pkg/provider"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAuthChallenge(t *testing.T) {
+	require.Equal(t, map[string]string{
+		"realm":   "https://gitlab.com/jwt/auth",
+		"service": "container_registry",
+	}, parseAuthChallenge(`Bearer realm="https://gitlab.com/jwt/auth",service="container_registry"`))
+	require.Empty(t, parseAuthChallenge(""))
+}
+
+func TestGitlabTagContainerImage(t *testing.T) {
+	const manifest = `{"schemaVersion":2}`
+	const manifestType = "application/vnd.oci.image.manifest.v1+json"
+	tagged := map[string]string{}
+
+	// the token service is part of the GitLab instance
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/jwt/auth" {
+			user, password, _ := r.BasicAuth()
+			require.Equal(t, GITLAB_USER.Username, user)
+			require.Equal(t, "gitlab-examples-ci", password)
+			require.Equal(t, "repository:group/project:pull,push", r.URL.Query().Get("scope"))
+			fmt.Fprint(w, `{"token":"registry-token"}`)
+			return
+		}
+		if r.URL.Path == fmt.Sprintf("/api/v4/projects/%d/releases", GITLAB_PROJECT_ID) {
+			// releases of other versions than the fixtures
+			if r.Method == "GET" {
+				fmt.Fprint(w, "[]")
+			} else {
+				fmt.Fprint(w, "{}")
+			}
+			return
+		}
+		GitlabHandler(w, r)
+	}))
+	defer ts.Close()
+
+	registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/v2/":
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/jwt/auth",service="container_registry"`, ts.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+		case strings.HasPrefix(r.URL.Path, "/v2/group/project/manifests/"):
+			require.Equal(t, "Bearer registry-token", r.Header.Get("Authorization"))
+			reference := strings.TrimPrefix(r.URL.Path, "/v2/group/project/manifests/")
+			if r.Method == "GET" && reference == "deadbeef" {
+				w.Header().Set("Content-Type", manifestType)
+				fmt.Fprint(w, manifest)
+				return
+			}
+			if r.Method == "PUT" {
+				require.Equal(t, manifestType, r.Header.Get("Content-Type"))
+				body, _ := io.ReadAll(r.Body)
+				tagged[reference] = string(body)
+				w.WriteHeader(http.StatusCreated)
+				return
+			}
+			http.NotFound(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer registry.Close()
+
+	repo := &GitLabRepository{}
+	err := repo.Init(map[string]string{
+		"gitlab_baseurl":         ts.URL,
+		"token":                  "gitlab-examples-ci",
+		"gitlab_projectid":       strconv.Itoa(GITLAB_PROJECT_ID),
+		"gitlab_container_image": registry.URL + "/group/project",
+	})
+	require.NoError(t, err)
+
+	err = repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "2.0.0", SHA: "deadbeef"})
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"v2.0.0": manifest, "2.0": manifest, "latest": manifest}, tagged)
+
+	// prereleases only get their own tag
+	tagged = map[string]string{}
+	err = repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "2.0.0", SHA: "deadbeef", Prerelease: true})
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"v2.0.0": manifest}, tagged)
+
+	// releases of maintenance branches keep latest on the latest release
+	tagged = map[string]string{}
+	repo.channel = "1.x"
+	err = repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "1.4.3", SHA: "deadbeef"})
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"v1.4.3": manifest, "1.4": manifest}, tagged)
+
+	tagged = map[string]string{}
+	repo.channel, repo.releaseLatest = "", new(bool)
+	err = repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "1.4.4", SHA: "deadbeef"})
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"v1.4.4": manifest, "1.4": manifest}, tagged)
+
+	err = repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "2.0.0", SHA: "cafebabe"})
+	require.EqualError(t, err, "failed to get manifest group/project:cafebabe: 404 Not Found")
+}
+
+func TestGitlabTagContainerImageForeignRealm(t *testing.T) {
+	collected := false
+	attacker := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		collected = true
+		fmt.Fprint(w, `{"token":"registry-token"}`)
+	}))
+	defer attacker.Close()
+
+	registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/jwt/auth",service="container_registry"`, attacker.URL))
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer registry.Close()
+
+	ts := httptest.NewServer(http.HandlerFunc(GitlabHandler))
+	defer ts.Close()
+
+	repo := &GitLabRepository{}
+	err := repo.Init(map[string]string{
+		"gitlab_baseurl":         ts.URL,
+		"token":                  "gitlab-examples-ci",
+		"gitlab_projectid":       strconv.Itoa(GITLAB_PROJECT_ID),
+		"gitlab_container_image": registry.URL + "/group/project",
+	})
+	require.NoError(t, err)
+
+	err = repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "2.0.0", SHA: "deadbeef"})
+	require.ErrorContains(t, err, fmt.Sprintf("announced the token service %s which is neither the GitLab instance nor its registry", attacker.URL))
+	require.False(t, collected)
+}
@@ -0,0 +1,298 @@
+package provider
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/go-semantic-release/semantic-release/v2/pkg/provider"
+	"github.com/xanzy/go-gitlab"
+)
+
+// publishRelease creates the release object, the tag is created by the releases API unless it already exists
+func (repo *GitLabRepository) publishRelease(tag string, release *provider.CreateReleaseConfig, tagExists bool) error {
+	description, err := repo.releaseDescription(tag, release.Changelog)
+	if err != nil {
+		return err
+	}
+
+	opts := &gitlab.CreateReleaseOptions{
+		TagName:     &tag,
+		Description: &description,
+	}
+	if len(repo.releaseLinks) > 0 {
+		opts.Assets = &gitlab.ReleaseAssetsOptions{Links: repo.releaseLinks}
+	}
+
+	if repo.isMaintenanceChannel() {
+		name := fmt.Sprintf("%s (%s)", tag, repo.channel)
+		opts.Name = &name
+	}
+
+	if repo.prereleaseChannel != "" && release.Prerelease {
+		opts.ReleasedAt = repo.upcomingReleasedAt()
+	} else {
+		releasedAt, err := repo.releasedAt(tag)
+		if err != nil {
+			return err
+		}
+		opts.ReleasedAt = releasedAt
+	}
+
+	if repo.useExistingTag {
+		if err := repo.verifyExistingTag(tag, release.SHA); err != nil {
+			return err
+		}
+	} else if !tagExists {
+		opts.Ref = &release.SHA
+	}
+
+	// Gitlab does not have any notion of pre-releases
+	_, resp, err := repo.api.CreateRelease(repo.projectID, opts)
+
+	// the release already exists, e.g. when a previously failed job is retried
+	if err != nil && repo.allowUpdate && resp != nil && resp.StatusCode == http.StatusConflict {
+		return repo.updateRelease(tag, description)
+	}
+	if err != nil {
+		return err
+	}
+
+	repo.createdRelease = tag
+	return nil
+}
+
+const (
+	updateModeReplace = "replace"
+	updateModeAppend  = "append"
+	updateModePrepend = "prepend"
+)
+
+// releaseDivider separates the changelog from the existing description of an updated release
+const releaseDivider = "\n\n---\n\n"
+
+func isValidUpdateMode(mode string) bool {
+	switch mode {
+	case "", updateModeReplace, updateModeAppend, updateModePrepend:
+		return true
+	}
+	return false
+}
+
+// updatedDescription combines the existing description with the changelog as configured by gitlab_update_mode, so
+// notes added manually by release managers are kept. A changelog which is already part of the description, e.g.
+// when a job is retried, is not added again.
+func updatedDescription(existing, description, mode string) string {
+	if mode == "" || mode == updateModeReplace || existing == "" {
+		return description
+	}
+	if strings.Contains(existing, description) {
+		return existing
+	}
+	if mode == updateModePrepend {
+		return description + releaseDivider + existing
+	}
+	return existing + releaseDivider + description
+}
+
+func (repo *GitLabRepository) updateRelease(tag, description string) error {
+	existing, _, err := repo.api.GetRelease(repo.projectID, tag)
+	if err != nil {
+		return fmt.Errorf("failed to get existing release %s: %w", tag, err)
+	}
+	description = updatedDescription(existing.Description, description, repo.updateMode)
+
+	_, _, err = repo.api.UpdateRelease(repo.projectID, tag, &gitlab.UpdateReleaseOptions{
+		Name:        &existing.Name,
+		Description: &description,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update existing release %s: %w", tag, err)
+	}
+
+	return repo.syncReleaseLinks(tag)
+}
+
+// syncReleaseLinks creates the asset links of the release which are missing on the existing release and updates the
+// ones pointing elsewhere, links added manually to the release are kept
+func (repo *GitLabRepository) syncReleaseLinks(tag string) error {
+	if len(repo.releaseLinks) == 0 {
+		return nil
+	}
+	existing, err := repo.listReleaseLinks(tag)
+	if err != nil {
+		return err
+	}
+
+	for _, link := range repo.releaseLinks {
+		current := existing[*link.Name]
+		if current == nil {
+			_, _, err := repo.api.CreateReleaseLink(repo.projectID, tag, &gitlab.CreateReleaseLinkOptions{
+				Name:     link.Name,
+				URL:      link.URL,
+				FilePath: link.FilePath,
+				LinkType: link.LinkType,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to add link %s to release %s: %w", *link.Name, tag, err)
+			}
+			continue
+		}
+		if current.URL == *link.URL && (link.LinkType == nil || current.LinkType == *link.LinkType) {
+			continue
+		}
+		_, _, err := repo.api.UpdateReleaseLink(repo.projectID, tag, current.ID, &gitlab.UpdateReleaseLinkOptions{
+			URL:      link.URL,
+			FilePath: link.FilePath,
+			LinkType: link.LinkType,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to update link %s of release %s: %w", *link.Name, tag, err)
+		}
+	}
+	return nil
+}
+
+// listReleaseLinks returns the asset links of the release by name
+func (repo *GitLabRepository) listReleaseLinks(tag string) (map[string]*gitlab.ReleaseLink, error) {
+	links := make(map[string]*gitlab.ReleaseLink)
+	listOptions := repo.listOptions()
+	opts := (*gitlab.ListReleaseLinksOptions)(&listOptions)
+	for {
+		page, resp, err := repo.api.ListReleaseLinks(repo.projectID, tag, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list the links of release %s: %w", tag, err)
+		}
+		for _, link := range page {
+			links[link.Name] = link
+		}
+		if resp.NextPage == 0 {
+			return links, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// afterRelease runs the optional steps once the tag and the release exist
+func (repo *GitLabRepository) afterRelease(tag string, release *provider.CreateReleaseConfig) error {
+	data := repo.releaseTemplateData(tag, release)
+	if !repo.tagOnly {
+		data.ReleaseURL = repo.releaseURL(tag)
+	}
+
+	if repo.evidenceMode != "" && !repo.tagOnly {
+		if err := repo.verifyReleaseEvidence(tag, data); err != nil {
+			return err
+		}
+	}
+
+	if len(repo.mirrors) > 0 {
+		if err := repo.releaseToMirrors(tag, release); err != nil {
+			return err
+		}
+	}
+
+	if len(repo.fanOutProjects) > 0 {
+		if err := repo.releaseToProjects(tag, release, repo.fanOutProjects); err != nil {
+			return err
+		}
+	}
+
+	if repo.groupID != "" {
+		targets, err := repo.groupProjects()
+		if err != nil {
+			return err
+		}
+		if err := repo.releaseToProjects(tag, release, targets); err != nil {
+			return err
+		}
+	}
+
+	if repo.containerImage != "" {
+		if err := repo.tagContainerImage(data); err != nil {
+			return err
+		}
+	}
+
+	if repo.environment != "" {
+		if err := repo.createDeployment(tag, release.SHA); err != nil {
+			return err
+		}
+	}
+
+	if repo.changelogFile != "" {
+		if err := repo.commitGitLabChangelog(release); err != nil {
+			return err
+		}
+	}
+
+	if repo.releaseBranch != nil || repo.backMergeBranch != "" {
+		if err := repo.releaseBranches(data); err != nil {
+			return err
+		}
+	}
+
+	if repo.mergeRequestComment != nil {
+		if err := repo.commentMergeRequests(data); err != nil {
+			return err
+		}
+	}
+
+	if repo.issueComment != nil {
+		if err := repo.updateReleasedIssues(data); err != nil {
+			return err
+		}
+	}
+
+	if repo.wikiPage != nil {
+		repo.publishWikiPage(data)
+	}
+
+	if repo.pagesBranch != "" && !repo.tagOnly {
+		repo.publishPagesSite(data)
+	}
+
+	if repo.announcementIssue != "" {
+		repo.announceRelease(data)
+	}
+
+	if repo.packageRetention > 0 {
+		repo.pruneOutdatedPackages()
+	}
+
+	if repo.prereleaseCleanup != "" {
+		repo.cleanupPrereleases(release.NewVersion)
+	}
+
+	if repo.notifyURL != "" {
+		repo.notify(data)
+	}
+
+	if repo.auditStreamURL != "" {
+		if err := repo.sendAuditEvent(data); err != nil {
+			// security teams may rather fail the job than miss a release in their audit trail
+			if repo.auditRequired {
+				return err
+			}
+			repo.logger.Printf("WARNING: %s", err)
+		}
+	}
+
+	if repo.releaseSummaryFile != "" {
+		if err := repo.writeReleaseSummary(data); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// releaseURL returns the web URL of the release or an empty string if the project cannot be fetched
+func (repo *GitLabRepository) releaseURL(tag string) string {
+	project, err := repo.getProject()
+	if err != nil || project.WebURL == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s/-/releases/%s", project.WebURL, url.PathEscape(tag))
+}
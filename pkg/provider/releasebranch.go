@@ -0,0 +1,81 @@
+package provider
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+// createReleaseBranch creates the configured release branch at the released commit unless it already exists,
+// e.g. because a patch release of the same minor version was published before
+func (repo *GitLabRepository) createReleaseBranch(data *templateData) (string, error) {
+	branch, err := renderTemplate(repo.releaseBranch, data)
+	if err != nil {
+		return "", err
+	}
+
+	_, resp, err := repo.api.GetBranch(repo.projectID, branch)
+	if err == nil {
+		repo.logger.Printf("release branch %s already exists", branch)
+		return branch, nil
+	}
+	if resp == nil || resp.StatusCode != http.StatusNotFound {
+		return "", fmt.Errorf("failed to get release branch %s: %w", branch, err)
+	}
+
+	_, _, err = repo.api.CreateBranch(repo.projectID, &gitlab.CreateBranchOptions{
+		Branch: &branch,
+		Ref:    &data.SHA,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to create release branch %s: %w", branch, err)
+	}
+	repo.logger.Printf("created release branch %s", branch)
+	return branch, nil
+}
+
+// openBackMergeRequest opens a merge request which merges the released changes back into the configured branch
+func (repo *GitLabRepository) openBackMergeRequest(source string, data *templateData) error {
+	_, resp, err := repo.api.CreateMergeRequest(repo.projectID, &gitlab.CreateMergeRequestOptions{
+		Title:        gitlab.String(fmt.Sprintf("Back-merge %s into %s", data.Tag, repo.backMergeBranch)),
+		Description:  gitlab.String(fmt.Sprintf("Merges the changes of release %s from %s back into %s.", data.Tag, source, repo.backMergeBranch)),
+		SourceBranch: &source,
+		TargetBranch: &repo.backMergeBranch,
+	})
+	// a merge request between both branches is already open
+	if resp != nil && resp.StatusCode == http.StatusConflict {
+		repo.logger.Printf("a merge request from %s into %s is already open", source, repo.backMergeBranch)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open back-merge request from %s into %s: %w", source, repo.backMergeBranch, err)
+	}
+	repo.logger.Printf("opened back-merge request from %s into %s", source, repo.backMergeBranch)
+	return nil
+}
+
+// releaseBranches creates the release branch and opens the back-merge request from it or from the released branch
+func (repo *GitLabRepository) releaseBranches(data *templateData) error {
+	source := repo.branch
+	if source == "" {
+		source = data.Branch
+	}
+
+	if repo.releaseBranch != nil {
+		branch, err := repo.createReleaseBranch(data)
+		if err != nil {
+			return err
+		}
+		source = branch
+	}
+
+	if repo.backMergeBranch == "" {
+		return nil
+	}
+	if source == "" || source == repo.backMergeBranch {
+		repo.logger.Printf("skipping back-merge into %s, the released branch is unknown or the same", repo.backMergeBranch)
+		return nil
+	}
+	return repo.openBackMergeRequest(source, data)
+}
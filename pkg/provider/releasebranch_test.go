@@ -0,0 +1,70 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/go-semantic-release/semantic-release/v2/pkg/provider"
+	"github.com/stretchr/testify/require"
+	"github.com/xanzy/go-gitlab"
+)
+
+func TestGitlabReleaseBranch(t *testing.T) {
+	branches := map[string]bool{}
+	var created *gitlab.CreateBranchOptions
+	var mergeRequest *gitlab.CreateMergeRequestOptions
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		branchesPath := fmt.Sprintf("/api/v4/projects/%d/repository/branches", GITLAB_PROJECT_ID)
+		switch {
+		case r.Method == "GET" && len(r.URL.Path) > len(branchesPath) && r.URL.Path[:len(branchesPath)] == branchesPath:
+			if !branches[r.URL.Path[len(branchesPath)+1:]] {
+				http.Error(w, `{"message":"404 Branch Not Found"}`, http.StatusNotFound)
+				return
+			}
+			fmt.Fprint(w, "{}")
+		case r.Method == "POST" && r.URL.Path == branchesPath:
+			json.NewDecoder(r.Body).Decode(&created) //nolint:errcheck
+			branches[*created.Branch] = true
+			fmt.Fprint(w, "{}")
+		case r.Method == "POST" && r.URL.Path == fmt.Sprintf("/api/v4/projects/%d/merge_requests", GITLAB_PROJECT_ID):
+			if mergeRequest != nil {
+				http.Error(w, `{"message":["Another open merge request already exists for this source branch"]}`, http.StatusConflict)
+				return
+			}
+			json.NewDecoder(r.Body).Decode(&mergeRequest) //nolint:errcheck
+			fmt.Fprint(w, "{}")
+		default:
+			GitlabHandler(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	repo := &GitLabRepository{}
+	err := repo.Init(map[string]string{
+		"gitlab_baseurl":           ts.URL,
+		"token":                    "gitlab-examples-ci",
+		"gitlab_branch":            "main",
+		"gitlab_projectid":         strconv.Itoa(GITLAB_PROJECT_ID),
+		"gitlab_release_branch":    "release/{{.Major}}.{{.Minor}}",
+		"gitlab_back_merge_branch": "develop",
+	})
+	require.NoError(t, err)
+
+	err = repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "2.0.0", SHA: "deadbeef"})
+	require.NoError(t, err)
+	require.Equal(t, "release/2.0", *created.Branch)
+	require.Equal(t, "deadbeef", *created.Ref)
+	require.Equal(t, "release/2.0", *mergeRequest.SourceBranch)
+	require.Equal(t, "develop", *mergeRequest.TargetBranch)
+	require.Equal(t, "Back-merge v2.0.0 into develop", *mergeRequest.Title)
+
+	// a retried release finds the branch and the open merge request
+	created = nil
+	err = repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "2.0.0", SHA: "deadbeef"})
+	require.NoError(t, err)
+	require.Nil(t, created)
+}
@@ -0,0 +1,58 @@
+package provider
+
+import (
+	"github.com/Masterminds/semver/v3"
+	"github.com/xanzy/go-gitlab"
+)
+
+// gitlab_release_order lists the tags newest first and stops once the remaining pages cannot hold a newer release
+const (
+	releaseOrderVersion = "version"
+	releaseOrderUpdated = "updated"
+)
+
+// releaseListing tracks the latest stable release of an ordered tag listing
+type releaseListing struct {
+	order       string
+	latest      *semver.Version
+	pageLatest  *semver.Version
+	pageHasNext bool
+}
+
+func (repo *GitLabRepository) newReleaseListing(opts *gitlab.ListTagsOptions) *releaseListing {
+	if repo.releaseOrder == "" {
+		return nil
+	}
+	opts.OrderBy = gitlab.String(repo.releaseOrder)
+	opts.Sort = gitlab.String("desc")
+	return &releaseListing{order: repo.releaseOrder}
+}
+
+func (l *releaseListing) startPage() {
+	l.pageLatest = l.latest
+	l.pageHasNext = false
+}
+
+// add records a listed release, prereleases never end the listing as semantic-release looks for the latest stable
+// release and the prereleases above it
+func (l *releaseListing) add(version *semver.Version) {
+	if l.pageLatest == nil || version.GreaterThan(l.pageLatest) {
+		l.pageHasNext = true
+	}
+	if version.Prerelease() == "" && (l.latest == nil || version.GreaterThan(l.latest)) {
+		l.latest = version
+	}
+}
+
+// done reports whether the remaining pages only hold releases below the latest stable release. Ordered by version
+// this is the case as soon as a stable release was listed, ordered by update time once a whole page held no newer
+// release, e.g. because maintenance releases of older versions were tagged last.
+func (l *releaseListing) done() bool {
+	if l == nil || l.latest == nil {
+		return false
+	}
+	if l.order == releaseOrderVersion {
+		return true
+	}
+	return l.pageLatest != nil && !l.pageHasNext
+}
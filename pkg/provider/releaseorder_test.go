@@ -0,0 +1,74 @@
+package provider
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/go-semantic-release/provider-gitlab/pkg/gitlabtest"
+	"github.com/go-semantic-release/semantic-release/v2/pkg/semrel"
+	"github.com/stretchr/testify/require"
+)
+
+// newReleaseOrderTestRepo serves the tags in the given order, two per page
+func newReleaseOrderTestRepo(t *testing.T, order string, tags []string) (*GitLabRepository, *gitlabtest.Server) {
+	server, project := newFakeServer(t)
+	for _, name := range tags {
+		server.AddTag(project, name, "sha-"+name)
+	}
+	repo := initFakeRepo(t, &GitLabRepository{}, server, map[string]string{
+		"gitlab_per_page":      "2",
+		"gitlab_release_order": order,
+	})
+	return repo, server
+}
+
+// requestedTagPages returns the order, sort and page of each tag listing
+func requestedTagPages(server *gitlabtest.Server) []string {
+	var requested []string
+	for _, query := range server.Queries("GET /api/v4/projects/group/app/repository/tags") {
+		requested = append(requested, fmt.Sprintf("%s %s page %s", query.Get("order_by"), query.Get("sort"), query.Get("page")))
+	}
+	return requested
+}
+
+func releaseVersions(releases []*semrel.Release) []string {
+	versions := make([]string, 0, len(releases))
+	for _, release := range releases {
+		versions = append(versions, release.Version)
+	}
+	return versions
+}
+
+func TestReleaseOrderVersion(t *testing.T) {
+	repo, server := newReleaseOrderTestRepo(t, releaseOrderVersion, []string{"v3.0.0-beta.2", "v3.0.0-beta.1", "v2.1.0", "v2.0.0", "v1.0.0"})
+
+	releases, err := repo.GetReleases("")
+	require.NoError(t, err)
+	require.Equal(t, []string{"3.0.0-beta.2", "3.0.0-beta.1", "2.1.0", "2.0.0"}, releaseVersions(releases))
+	require.Equal(t, []string{"version desc page 1", "version desc page 2"}, requestedTagPages(server))
+}
+
+func TestReleaseOrderUpdated(t *testing.T) {
+	// a maintenance release of 1.x was tagged after 2.1.0
+	repo, server := newReleaseOrderTestRepo(t, releaseOrderUpdated, []string{"v1.4.1", "v2.1.0", "v2.0.1", "v1.4.0", "v2.0.0", "v1.3.0", "v1.0.0"})
+
+	releases, err := repo.GetReleases("")
+	require.NoError(t, err)
+	require.Equal(t, []string{"1.4.1", "2.1.0", "2.0.1", "1.4.0"}, releaseVersions(releases))
+	require.Equal(t, []string{"updated desc page 1", "updated desc page 2"}, requestedTagPages(server))
+}
+
+func TestReleaseOrderDefault(t *testing.T) {
+	repo, server := newReleaseOrderTestRepo(t, "", []string{"v2.0.0", "v1.0.0", "v0.1.0"})
+
+	releases, err := repo.GetReleases("")
+	require.NoError(t, err)
+	require.Len(t, releases, 3)
+	require.Equal(t, []string{"  page 1", "  page 2"}, requestedTagPages(server))
+}
+
+func TestInvalidReleaseOrder(t *testing.T) {
+	repo := &GitLabRepository{}
+	err := repo.Init(map[string]string{"token": "token", "gitlab_projectid": "1", "gitlab_release_order": "name"})
+	require.EqualError(t, err, `failed to set property gitlab_release_order: unknown order "name", expected version or updated`)
+}
@@ -0,0 +1,84 @@
+package provider
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+var fullSHARe = regexp.MustCompile(`^[0-9a-f]{40}([0-9a-f]{24})?$`)
+
+// validateReleaseSHA makes sure the commit exists in the project and is on the branch before it is tagged, GitLab only
+// answers the tag creation with a generic 400 otherwise
+func (repo *GitLabRepository) validateReleaseSHA(branch, sha string) error {
+	if sha == "" {
+		return fmt.Errorf("the release has no commit SHA")
+	}
+
+	_, resp, err := repo.api.GetCommit(repo.projectID, sha)
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("commit %s does not exist in project %s: %s", sha, repo.projectID, repo.missingCommitReason(sha))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get commit %s: %w", sha, err)
+	}
+
+	if branch == "" || repo.ref != "" {
+		// the release of gitlab_ref does not have to be on the branch
+		return nil
+	}
+	branches, err := repo.commitBranches(sha)
+	if err != nil {
+		return err
+	}
+	if containsString(branches, branch) {
+		return nil
+	}
+
+	if len(branches) > 0 {
+		return fmt.Errorf("commit %s is not on branch %s but on %s", sha, branch, strings.Join(branches, ", "))
+	}
+	if iid := os.Getenv("CI_MERGE_REQUEST_IID"); iid != "" {
+		// merged results pipelines run for a merge commit which only exists in refs/merge-requests/:iid/merge
+		return fmt.Errorf("commit %s is not on branch %s, it is the merged result of a detached pipeline of merge request !%s, release from a branch pipeline instead",
+			sha, branch, iid)
+	}
+	return fmt.Errorf("commit %s is not on branch %s or any other branch", sha, branch)
+}
+
+// missingCommitReason guesses why the commit of the release cannot be found in the project
+func (repo *GitLabRepository) missingCommitReason(sha string) string {
+	if ciProject := os.Getenv("CI_PROJECT_ID"); ciProject != "" && ciProject != repo.projectID && os.Getenv("CI_PROJECT_PATH") != repo.projectID {
+		return fmt.Sprintf("the pipeline runs in project %s, check gitlab_projectid", os.Getenv("CI_PROJECT_PATH"))
+	}
+	if !fullSHARe.MatchString(sha) {
+		return "it is not a full commit SHA"
+	}
+	if ciSHA := os.Getenv("CI_COMMIT_SHA"); ciSHA != "" && ciSHA != sha {
+		return fmt.Sprintf("the pipeline runs for %s, the commit may only exist in the clone of the job, e.g. after a shallow fetch or a local commit", ciSHA)
+	}
+	return "it was not pushed or was removed by a force push"
+}
+
+// commitBranches lists the branches containing the commit
+func (repo *GitLabRepository) commitBranches(sha string) ([]string, error) {
+	branches := make([]string, 0)
+	opts := &gitlab.GetCommitRefsOptions{ListOptions: repo.listOptions(), Type: gitlab.String("branch")}
+	for {
+		refs, resp, err := repo.api.GetCommitRefs(repo.projectID, sha, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the branches of commit %s: %w", sha, err)
+		}
+		for _, ref := range refs {
+			branches = append(branches, ref.Name)
+		}
+		if resp.NextPage == 0 {
+			return branches, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
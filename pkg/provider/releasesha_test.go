@@ -0,0 +1,85 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/go-semantic-release/semantic-release/v2/pkg/provider"
+	"github.com/stretchr/testify/require"
+	"github.com/xanzy/go-gitlab"
+)
+
+func TestGitlabValidateReleaseSHA(t *testing.T) {
+	const (
+		onMain    = "1111111111111111111111111111111111111111"
+		onFeature = "2222222222222222222222222222222222222222"
+		detached  = "3333333333333333333333333333333333333333"
+		missing   = "4444444444444444444444444444444444444444"
+	)
+	branches := map[string][]string{onMain: {"main", "release/1.x"}, onFeature: {"feature"}, detached: {}}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		commitsPath := fmt.Sprintf("/api/v4/projects/%d/repository/commits/", GITLAB_PROJECT_ID)
+		if r.Method != "GET" || !strings.HasPrefix(r.URL.Path, commitsPath) {
+			GitlabHandler(w, r)
+			return
+		}
+		sha := strings.TrimPrefix(r.URL.Path, commitsPath)
+		refs := strings.HasSuffix(sha, "/refs")
+		sha = strings.TrimSuffix(sha, "/refs")
+		names, ok := branches[sha]
+		if !ok {
+			w.Header().Set("Content-Type", "application/json")
+			http.Error(w, `{"message":"404 Commit Not Found"}`, http.StatusNotFound)
+			return
+		}
+		if !refs {
+			json.NewEncoder(w).Encode(createGitlabCommit(sha, "feat: release")) //nolint:errcheck
+			return
+		}
+		require.Equal(t, "branch", r.URL.Query().Get("type"))
+		commitRefs := make([]*gitlab.CommitRef, 0)
+		for _, name := range names {
+			commitRefs = append(commitRefs, &gitlab.CommitRef{Type: "branch", Name: name})
+		}
+		json.NewEncoder(w).Encode(commitRefs) //nolint:errcheck
+	}))
+	defer ts.Close()
+
+	repo := &GitLabRepository{}
+	err := repo.Init(map[string]string{
+		"gitlab_baseurl":            ts.URL,
+		"token":                     "token",
+		"gitlab_projectid":          strconv.Itoa(GITLAB_PROJECT_ID),
+		"gitlab_branch":             "main",
+		"gitlab_verify_release_sha": "true",
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, repo.validateReleaseSHA("main", onMain))
+	require.NoError(t, repo.validateReleaseSHA("", onFeature))
+	require.EqualError(t, repo.validateReleaseSHA("main", onFeature), fmt.Sprintf("commit %s is not on branch main but on feature", onFeature))
+	require.EqualError(t, repo.validateReleaseSHA("main", detached), fmt.Sprintf("commit %s is not on branch main or any other branch", detached))
+	require.EqualError(t, repo.validateReleaseSHA("main", missing), fmt.Sprintf("commit %s does not exist in project %d: it was not pushed or was removed by a force push", missing, GITLAB_PROJECT_ID))
+	require.EqualError(t, repo.validateReleaseSHA("main", "deadbeef"), fmt.Sprintf("commit deadbeef does not exist in project %d: it is not a full commit SHA", GITLAB_PROJECT_ID))
+	require.EqualError(t, repo.validateReleaseSHA("main", ""), "the release has no commit SHA")
+
+	t.Setenv("CI_COMMIT_SHA", onMain)
+	require.EqualError(t, repo.validateReleaseSHA("main", missing), fmt.Sprintf("commit %s does not exist in project %d: the pipeline runs for %s, the commit may only exist in the clone of the job, e.g. after a shallow fetch or a local commit", missing, GITLAB_PROJECT_ID, onMain))
+
+	t.Setenv("CI_PROJECT_ID", "999")
+	t.Setenv("CI_PROJECT_PATH", "group/other")
+	require.EqualError(t, repo.validateReleaseSHA("main", missing), fmt.Sprintf("commit %s does not exist in project %d: the pipeline runs in project group/other, check gitlab_projectid", missing, GITLAB_PROJECT_ID))
+
+	t.Setenv("CI_MERGE_REQUEST_IID", "12")
+	require.EqualError(t, repo.validateReleaseSHA("main", detached), fmt.Sprintf("commit %s is not on branch main, it is the merged result of a detached pipeline of merge request !12, release from a branch pipeline instead", detached))
+
+	// the release is rejected before anything is created
+	err = repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "2.0.0", SHA: onFeature})
+	require.ErrorContains(t, err, fmt.Sprintf("commit %s is not on branch main but on feature", onFeature))
+}
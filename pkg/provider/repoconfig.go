@@ -0,0 +1,70 @@
+package provider
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+// projectOptions are the options the project itself can set, e.g. a committer in the repository config file. They
+// only change the messages and the release notes, every other option may send the token to another host, release
+// other projects, weaken the protection of the tags or read and write files of the job.
+var projectOptions = map[string]bool{
+	"gitlab_changelog_mode":             true,
+	"gitlab_update_mode":                true,
+	"gitlab_tag_message":                true,
+	"gitlab_tag_metadata":               true,
+	"gitlab_commit_message_pattern":     true,
+	"gitlab_commit_message_replacement": true,
+	"gitlab_commit_titles_only":         true,
+	"gitlab_ignore_authors":             true,
+	"gitlab_scopes":                     true,
+	"gitlab_asset_links":                true,
+	"gitlab_merge_request_comment":      true,
+	"gitlab_issue_label":                true,
+	"gitlab_issue_comment":              true,
+	"gitlab_announcement_comment":       true,
+	"gitlab_version_files_message":      true,
+}
+
+// loadRepositoryConfigFile fetches gitlab_repository_config_file from the repository at the analyzed commit and merges
+// its options into the config like gitlab_config_file, so the options can be versioned with the code. A missing
+// file is not an error, projects may add it later.
+func (repo *GitLabRepository) loadRepositoryConfigFile(config map[string]string) (map[string]string, error) {
+	path := config["gitlab_repository_config_file"]
+	ref := config["gitlab_ref"]
+	if ref == "" {
+		ref = os.Getenv("CI_COMMIT_SHA")
+	}
+	if ref == "" {
+		ref = defaultString(repo.branch, "HEAD")
+	}
+
+	content, resp, err := repo.api.GetRawFile(repo.projectID, path, &gitlab.GetRawFileOptions{Ref: &ref})
+	if err != nil && resp != nil && resp.StatusCode == http.StatusNotFound {
+		repo.debugf("the repository has no config file %s at %s", path, ref)
+		return config, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get config file %s at %s: %w", path, ref, wrapAPIError(err))
+	}
+	options, err := parseConfigFile(content, path)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(options))
+	for key := range options {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if !projectOptions[key] {
+			return nil, fmt.Errorf("option %s cannot be set in config file %s, only the options of the messages and the release notes can", key, path)
+		}
+	}
+	repo.debugf("loaded config file %s at %s", path, ref)
+	return mergeConfigFile(config, options), nil
+}
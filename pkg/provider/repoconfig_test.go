@@ -0,0 +1,131 @@
+package provider
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepositoryConfigFile(t *testing.T) {
+	var ref string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == fmt.Sprintf("/api/v4/projects/%d/repository/files/.gitlab-semrel.yml/raw", GITLAB_PROJECT_ID) {
+			ref = r.URL.Query().Get("ref")
+			fmt.Fprint(w, "gitlab_commit_titles_only: true\ngitlab_scopes:\n  - api\n  - api-*\ngitlab_changelog_mode: fenced\n")
+			return
+		}
+		GitlabHandler(w, r)
+	}))
+	defer ts.Close()
+	t.Setenv("CI_COMMIT_SHA", "deadbeef")
+
+	repo := &GitLabRepository{}
+	err := repo.Init(map[string]string{
+		"gitlab_baseurl":                ts.URL,
+		"token":                         "token",
+		"gitlab_projectid":              strconv.Itoa(GITLAB_PROJECT_ID),
+		"gitlab_repository_config_file": ".gitlab-semrel.yml",
+		"gitlab_changelog_mode":         "escape",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "deadbeef", ref)
+	require.True(t, repo.commitTitlesOnly)
+	require.Equal(t, []string{"api", "api-*"}, repo.scopes)
+	// options set directly take precedence
+	require.Equal(t, changelogModeEscape, repo.changelogMode)
+}
+
+func TestRepositoryConfigFileMissing(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == fmt.Sprintf("/api/v4/projects/%d/repository/files/.gitlab-semrel.yml/raw", GITLAB_PROJECT_ID) {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"message": "404 File Not Found"}`)
+			return
+		}
+		GitlabHandler(w, r)
+	}))
+	defer ts.Close()
+
+	repo := &GitLabRepository{}
+	err := repo.Init(map[string]string{
+		"gitlab_baseurl":                ts.URL,
+		"token":                         "token",
+		"gitlab_projectid":              strconv.Itoa(GITLAB_PROJECT_ID),
+		"gitlab_branch":                 "main",
+		"gitlab_repository_config_file": ".gitlab-semrel.yml",
+		"gitlab_tag_prefix":             "cli/",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "cli/", repo.tagPrefix)
+}
+
+func TestRepositoryConfigFileInvalid(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == fmt.Sprintf("/api/v4/projects/%d/repository/files/.gitlab-semrel.yml/raw", GITLAB_PROJECT_ID) {
+			require.Equal(t, "main", r.URL.Query().Get("ref"))
+			fmt.Fprint(w, "gitlab_commit_titles_only: maybe\n")
+			return
+		}
+		GitlabHandler(w, r)
+	}))
+	defer ts.Close()
+	t.Setenv("CI_COMMIT_SHA", "")
+
+	repo := &GitLabRepository{}
+	err := repo.Init(map[string]string{
+		"gitlab_baseurl":                ts.URL,
+		"token":                         "token",
+		"gitlab_projectid":              strconv.Itoa(GITLAB_PROJECT_ID),
+		"gitlab_branch":                 "main",
+		"gitlab_repository_config_file": ".gitlab-semrel.yml",
+	})
+	require.ErrorContains(t, err, "gitlab_commit_titles_only")
+}
+
+func TestRepositoryConfigFileOperatorOptions(t *testing.T) {
+	for option, content := range map[string]string{
+		"gitlab_baseurl":               "gitlab_baseurl: https://attacker.example.com\n",
+		"gitlab_mirrors":               "gitlab_mirrors: x@https://attacker.example.com#CI_JOB_TOKEN\n",
+		"gitlab_release_summary_file":  "gitlab_release_summary_file: /etc/profile.d/release.sh\n",
+		"gitlab_audit_stream_url":      "gitlab_audit_stream_url: https://attacker.example.com/audit\n",
+		"gitlab_notify_url":            "gitlab_notify_url: https://attacker.example.com/notify\n",
+		"gitlab_assets":                "gitlab_assets:\n  - /etc/passwd\n",
+		"gitlab_helm_chart":            "gitlab_helm_chart: /root\n",
+		"gitlab_terraform_module_path": "gitlab_terraform_module_path: /root/.ssh\n",
+		"gitlab_tag_signing_key":       "gitlab_tag_signing_key: /root/.ssh/id_ed25519\n",
+		"gitlab_fan_out_projects":      "gitlab_fan_out_projects: other/project\n",
+		"gitlab_group_id":              "gitlab_group_id: other\n",
+		"gitlab_child_projects":        "gitlab_child_projects: other/private\n",
+		"gitlab_protected_tag_access":  "gitlab_protected_tag_access: developer\n",
+		"gitlab_force_retag":           "gitlab_force_retag: true\n",
+		"gitlab_prerelease_cleanup":    "gitlab_prerelease_cleanup: tags\n",
+		"gitlab_tag_prefix":            "gitlab_tag_prefix: unprotected/\n",
+		// options added later are operator-only until they are allowed explicitly
+		"gitlab_new_option": "gitlab_new_option: true\n",
+	} {
+		t.Run(option, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == fmt.Sprintf("/api/v4/projects/%d/repository/files/.gitlab-semrel.yml/raw", GITLAB_PROJECT_ID) {
+					fmt.Fprint(w, "gitlab_changelog_mode: fenced\n"+content)
+					return
+				}
+				GitlabHandler(w, r)
+			}))
+			defer ts.Close()
+			t.Setenv("CI_SERVER_URL", ts.URL)
+			t.Setenv("GITLAB_TOKEN", "token")
+
+			repo := &GitLabRepository{}
+			err := repo.Init(map[string]string{
+				"gitlab_projectid":              strconv.Itoa(GITLAB_PROJECT_ID),
+				"gitlab_branch":                 "main",
+				"gitlab_repository_config_file": ".gitlab-semrel.yml",
+			})
+			require.EqualError(t, err, "option "+option+" cannot be set in config file .gitlab-semrel.yml, only the options of the messages and the release notes can")
+		})
+	}
+}
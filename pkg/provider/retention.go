@@ -0,0 +1,85 @@
+package provider
+
+import (
+	"sort"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/xanzy/go-gitlab"
+)
+
+// listPackages returns all packages of the project, optionally filtered by name
+func (repo *GitLabRepository) listPackages() ([]*gitlab.Package, error) {
+	opts := &gitlab.ListProjectPackagesOptions{
+		ListOptions: gitlab.ListOptions{
+			Page:    1,
+			PerPage: 100,
+		},
+	}
+	if repo.packageRetentionName != "" {
+		opts.PackageName = &repo.packageRetentionName
+	}
+
+	packages := make([]*gitlab.Package, 0)
+	for {
+		page, resp, err := repo.api.ListProjectPackages(repo.projectID, opts)
+		if err != nil {
+			return nil, err
+		}
+		packages = append(packages, page...)
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	return packages, nil
+}
+
+// outdatedPrereleases returns the prerelease and snapshot versions of each package except for the newest ones,
+// stable versions are never returned
+func outdatedPrereleases(packages []*gitlab.Package, keep int) []*gitlab.Package {
+	type prerelease struct {
+		pkg     *gitlab.Package
+		version *semver.Version
+	}
+
+	byPackage := make(map[string][]prerelease)
+	for _, pkg := range packages {
+		version, err := semver.NewVersion(pkg.Version)
+		if err != nil || version.Prerelease() == "" {
+			continue
+		}
+		key := pkg.PackageType + "/" + pkg.Name
+		byPackage[key] = append(byPackage[key], prerelease{pkg, version})
+	}
+
+	outdated := make([]*gitlab.Package, 0)
+	for _, prereleases := range byPackage {
+		sort.SliceStable(prereleases, func(i, j int) bool {
+			return prereleases[i].version.GreaterThan(prereleases[j].version)
+		})
+		for i := keep; i < len(prereleases); i++ {
+			outdated = append(outdated, prereleases[i].pkg)
+		}
+	}
+	sort.Slice(outdated, func(i, j int) bool {
+		return outdated[i].ID < outdated[j].ID
+	})
+	return outdated
+}
+
+// pruneOutdatedPackages deletes old prerelease versions from the package registry, failures are only logged
+func (repo *GitLabRepository) pruneOutdatedPackages() {
+	packages, err := repo.listPackages()
+	if err != nil {
+		repo.logger.Printf("WARNING: failed to list packages: %s", err)
+		return
+	}
+
+	for _, pkg := range outdatedPrereleases(packages, repo.packageRetention) {
+		if _, err := repo.api.DeleteProjectPackage(repo.projectID, pkg.ID); err != nil {
+			repo.logger.Printf("WARNING: failed to delete package %s %s: %s", pkg.Name, pkg.Version, err)
+			continue
+		}
+		repo.logger.Printf("deleted outdated package %s %s", pkg.Name, pkg.Version)
+	}
+}
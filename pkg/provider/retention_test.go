@@ -0,0 +1,80 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/go-semantic-release/semantic-release/v2/pkg/provider"
+	"github.com/stretchr/testify/require"
+	"github.com/xanzy/go-gitlab"
+)
+
+func TestOutdatedPrereleases(t *testing.T) {
+	packages := []*gitlab.Package{
+		{ID: 1, Name: "app", PackageType: "npm", Version: "1.0.0-beta.1"},
+		{ID: 2, Name: "app", PackageType: "npm", Version: "1.0.0"},
+		{ID: 3, Name: "app", PackageType: "npm", Version: "1.1.0-beta.2"},
+		{ID: 4, Name: "app", PackageType: "npm", Version: "1.1.0-beta.10"},
+		{ID: 5, Name: "app", PackageType: "maven", Version: "1.0-SNAPSHOT"},
+		{ID: 6, Name: "lib", PackageType: "npm", Version: "0.1.0-rc.1"},
+		{ID: 7, Name: "app", PackageType: "generic", Version: "nightly"},
+	}
+
+	ids := func(packages []*gitlab.Package) []int {
+		ids := make([]int, 0)
+		for _, pkg := range packages {
+			ids = append(ids, pkg.ID)
+		}
+		return ids
+	}
+	require.Equal(t, []int{1, 3}, ids(outdatedPrereleases(packages, 1)))
+	require.Equal(t, []int{1}, ids(outdatedPrereleases(packages, 2)))
+	require.Empty(t, outdatedPrereleases(packages, 3))
+}
+
+func TestGitlabPruneOutdatedPackages(t *testing.T) {
+	var deleted []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		packagesPath := fmt.Sprintf("/api/v4/projects/%d/packages", GITLAB_PROJECT_ID)
+		switch {
+		case r.Method == "GET" && r.URL.Path == packagesPath:
+			require.Equal(t, "app", r.URL.Query().Get("package_name"))
+			json.NewEncoder(w).Encode([]*gitlab.Package{ //nolint:errcheck
+				{ID: 1, Name: "app", PackageType: "npm", Version: "2.0.0-beta.1"},
+				{ID: 2, Name: "app", PackageType: "npm", Version: "2.0.0-beta.2"},
+				{ID: 3, Name: "app", PackageType: "npm", Version: "2.0.0"},
+			})
+		case r.Method == "DELETE":
+			deleted = append(deleted, r.URL.Path[len(packagesPath)+1:])
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			GitlabHandler(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	repo := &GitLabRepository{}
+	err := repo.Init(map[string]string{
+		"gitlab_baseurl":                ts.URL,
+		"token":                         "gitlab-examples-ci",
+		"gitlab_projectid":              strconv.Itoa(GITLAB_PROJECT_ID),
+		"gitlab_package_retention":      "1",
+		"gitlab_package_retention_name": "app",
+	})
+	require.NoError(t, err)
+
+	err = repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "2.0.0", SHA: "deadbeef"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"1"}, deleted)
+
+	err = repo.Init(map[string]string{
+		"token":                    "gitlab-examples-ci",
+		"gitlab_projectid":         strconv.Itoa(GITLAB_PROJECT_ID),
+		"gitlab_package_retention": "-1",
+	})
+	require.EqualError(t, err, "failed to set property gitlab_package_retention: must not be negative")
+}
@@ -0,0 +1,38 @@
+package provider
+
+// DeleteRelease deletes the release and the tag of the given version, e.g. if a later pipeline stage failed.
+func (repo *GitLabRepository) DeleteRelease(version string) error {
+	tag := repo.tagName(version)
+	if repo.dryRun {
+		repo.logger.Printf("dry run: would delete release and tag %s", tag)
+		return nil
+	}
+
+	if err := repo.deleteRelease(tag); err != nil {
+		return wrapAPIError(err)
+	}
+	return wrapAPIError(repo.deleteTag(tag))
+}
+
+// Rollback deletes the release and the tag created by the last CreateRelease call.
+// Tags and releases which already existed before are never deleted. A tag implicitly created by the
+// releases API is not tracked, gitlab_rollback_on_failure makes sure the tag is always created explicitly.
+func (repo *GitLabRepository) Rollback() error {
+	if repo.createdRelease != "" {
+		if err := repo.deleteRelease(repo.createdRelease); err != nil {
+			return err
+		}
+		repo.logger.Printf("rolled back release %s", repo.createdRelease)
+		repo.createdRelease = ""
+	}
+
+	if repo.createdTag != "" {
+		if err := repo.deleteTag(repo.createdTag); err != nil {
+			return err
+		}
+		repo.logger.Printf("rolled back tag %s", repo.createdTag)
+		repo.createdTag = ""
+	}
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package provider
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/go-semantic-release/semantic-release/v2/pkg/provider"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGitlabRollbackOnFailure(t *testing.T) {
+	var deleted []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "DELETE":
+			deleted = append(deleted, strings.TrimPrefix(r.URL.Path, fmt.Sprintf("/api/v4/projects/%d/", GITLAB_PROJECT_ID)))
+			fmt.Fprint(w, "{}")
+			return
+		case r.Method == "POST" && strings.HasSuffix(r.URL.Path, "/releases"):
+			http.Error(w, `{"message":"invalid release"}`, http.StatusBadRequest)
+			return
+		}
+		GitlabHandler(w, r)
+	}))
+	defer ts.Close()
+
+	repo := &GitLabRepository{}
+	err := repo.Init(map[string]string{
+		"gitlab_baseurl":             ts.URL,
+		"token":                      "gitlab-examples-ci",
+		"gitlab_projectid":           strconv.Itoa(GITLAB_PROJECT_ID),
+		"gitlab_rollback_on_failure": "true",
+	})
+	require.NoError(t, err)
+
+	err = repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "2.0.0", SHA: "deadbeef"})
+	require.Error(t, err)
+	require.Equal(t, []string{"repository/tags/v2.0.0"}, deleted)
+
+	deleted = nil
+	require.NoError(t, repo.DeleteRelease("1.0.0"))
+	require.Equal(t, []string{"releases/v1.0.0", "repository/tags/v1.0.0"}, deleted)
+}
+
+func TestGitlabRollbackKeepsPublishedRelease(t *testing.T) {
+	var deleted []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "DELETE":
+			deleted = append(deleted, r.URL.Path)
+			fmt.Fprint(w, "{}")
+			return
+		case r.Method == "POST" && strings.HasSuffix(r.URL.Path, "/deployments"):
+			http.Error(w, `{"message":"invalid environment"}`, http.StatusBadRequest)
+			return
+		}
+		GitlabHandler(w, r)
+	}))
+	defer ts.Close()
+
+	repo := &GitLabRepository{}
+	err := repo.Init(map[string]string{
+		"gitlab_baseurl":             ts.URL,
+		"token":                      "gitlab-examples-ci",
+		"gitlab_projectid":           strconv.Itoa(GITLAB_PROJECT_ID),
+		"gitlab_rollback_on_failure": "true",
+		"gitlab_environment":         "production",
+	})
+	require.NoError(t, err)
+
+	err = repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "2.0.0", SHA: "deadbeef"})
+	require.Error(t, err)
+	// the deployment failed after the release was published
+	require.Empty(t, deleted)
+}
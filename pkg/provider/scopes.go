@@ -0,0 +1,56 @@
+package provider
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/go-semantic-release/semantic-release/v2/pkg/semrel"
+)
+
+// matches the scope of a conventional commit header like feat(api): or fix(api,web)!:
+var commitScopeRe = regexp.MustCompile(`^\w+\(([^)]*)\)!?:`)
+
+// parseScopesConfig parses a comma separated list of scope patterns using the syntax of path.Match
+func parseScopesConfig(config map[string]string, key string) ([]string, error) {
+	scopes := make([]string, 0)
+	for _, scope := range parseListConfig(config, key) {
+		if _, err := path.Match(scope, ""); err != nil {
+			return nil, fmt.Errorf("failed to set property %s: invalid scope pattern %q: %w", key, scope, err)
+		}
+		scopes = append(scopes, scope)
+	}
+	return scopes, nil
+}
+
+// hasScope reports whether one of the scopes of the commit header matches gitlab_scopes, commits without a scope
+// belong to no package
+func (repo *GitLabRepository) hasScope(message string) bool {
+	m := commitScopeRe.FindStringSubmatch(message)
+	if m == nil {
+		return false
+	}
+	for _, scope := range strings.Split(m[1], ",") {
+		scope = strings.TrimSpace(scope)
+		for _, pattern := range repo.scopes {
+			if matched, _ := path.Match(pattern, scope); matched {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// filterScopes returns the commits whose conventional commit scope matches gitlab_scopes
+func (repo *GitLabRepository) filterScopes(commits []*semrel.RawCommit) []*semrel.RawCommit {
+	filtered := make([]*semrel.RawCommit, 0, len(commits))
+	for _, commit := range commits {
+		if repo.hasScope(commit.RawMessage) {
+			filtered = append(filtered, commit)
+			continue
+		}
+		repo.debugf("ignoring commit %s which is out of gitlab_scopes", commit.SHA)
+	}
+	return filtered
+}
@@ -0,0 +1,48 @@
+package provider
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGitlabHasScope(t *testing.T) {
+	repo := &GitLabRepository{scopes: []string{"api", "web-*"}}
+	for message, expected := range map[string]bool{
+		"feat(api): add endpoint":          true,
+		"fix(web-admin)!: drop IE":         true,
+		"chore(cli, api): bump":            true,
+		"feat(cli): add flag":              false,
+		"feat: no scope":                   false,
+		"Merge branch 'feat(api)'":         false,
+		"docs(apis): typo\n\nfeat(api): x": false,
+	} {
+		require.Equal(t, expected, repo.hasScope(message), message)
+	}
+}
+
+func TestGitlabScopes(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(GitlabHandler))
+	defer ts.Close()
+
+	repo := &GitLabRepository{}
+	err := repo.Init(map[string]string{
+		"gitlab_baseurl":   ts.URL,
+		"token":            "token",
+		"gitlab_projectid": strconv.Itoa(GITLAB_PROJECT_ID),
+		"gitlab_scopes":    "app",
+	})
+	require.NoError(t, err)
+
+	commits, err := repo.GetCommits("", "")
+	require.NoError(t, err)
+	require.Len(t, commits, 1)
+	require.Equal(t, "feat(app): new feature", commits[0].RawMessage)
+	require.Len(t, repo.commits, 1)
+
+	err = (&GitLabRepository{}).Init(map[string]string{"token": "token", "gitlab_projectid": "1", "gitlab_scopes": "[api"})
+	require.EqualError(t, err, `failed to set property gitlab_scopes: invalid scope pattern "[api": syntax error in pattern`)
+}
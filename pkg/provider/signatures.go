@@ -0,0 +1,56 @@
+package provider
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-semantic-release/semantic-release/v2/pkg/semrel"
+)
+
+const signatureUnsigned = "unsigned"
+
+// annotateCommitSignatures sets the gitlab_signature annotation to the verification status of the GPG, SSH or X.509
+// signature of each commit, e.g. verified, unverified or unsigned. If signed commits are required every commit has
+// to be verified.
+func (repo *GitLabRepository) annotateCommitSignatures(commits []*semrel.RawCommit) error {
+	statuses := make([]string, len(commits))
+	err := repo.forEach(len(commits), func(i int) error {
+		status, err := repo.commitSignatureStatus(commits[i].SHA)
+		if err != nil {
+			return fmt.Errorf("failed to get the signature of commit %s: %w", commits[i].SHA, err)
+		}
+		statuses[i] = status
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	var problems []string
+	for i, commit := range commits {
+		status := statuses[i]
+		if commit.Annotations == nil {
+			commit.Annotations = make(map[string]string)
+		}
+		commit.Annotations["gitlab_signature"] = status
+		if repo.requireSignedCommits && status != "verified" {
+			problems = append(problems, fmt.Sprintf("%s: %s", commit.SHA, status))
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("gitlab_require_signed_commits is set but commits are not verified:\n  - %s", strings.Join(problems, "\n  - "))
+	}
+	return nil
+}
+
+func (repo *GitLabRepository) commitSignatureStatus(sha string) (string, error) {
+	signature, resp, err := repo.reader().GetGPGSignature(repo.projectID, sha)
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return signatureUnsigned, nil
+	}
+	if err != nil {
+		return "", wrapAPIError(err)
+	}
+	return signature.VerificationStatus, nil
+}
@@ -0,0 +1,40 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xanzy/go-gitlab"
+)
+
+// newSignaturesTestRepo signs abcd and dcba with verified keys and cdba with an unverified key, efcd is unsigned
+func newSignaturesTestRepo(t *testing.T, config map[string]string) *GitLabRepository {
+	server, project := newFakeServer(t)
+	addGitlabCommits(server, project)
+	verified := &gitlab.GPGSignature{VerificationStatus: "verified"}
+	server.SignCommit(project, "abcd", verified)
+	server.SignCommit(project, "dcba", verified)
+	server.SignCommit(project, "cdba", &gitlab.GPGSignature{VerificationStatus: "unverified"})
+	return initFakeRepo(t, &GitLabRepository{}, server, config)
+}
+
+func TestCommitSignatures(t *testing.T) {
+	repo := newSignaturesTestRepo(t, map[string]string{"gitlab_commit_signatures": "true", "gitlab_concurrency": "4"})
+
+	commits, err := repo.GetCommits("", "main")
+	require.NoError(t, err)
+	statuses := make([]string, 0, len(commits))
+	for _, commit := range commits {
+		statuses = append(statuses, commit.Annotations["gitlab_signature"])
+	}
+	require.Equal(t, []string{"verified", "verified", "unverified", "unsigned"}, statuses)
+}
+
+func TestRequireSignedCommits(t *testing.T) {
+	repo := newSignaturesTestRepo(t, map[string]string{"gitlab_require_signed_commits": "true"})
+
+	_, err := repo.GetCommits("", "main")
+	require.EqualError(t, err, "gitlab_require_signed_commits is set but commits are not verified:\n"+
+		"  - cdba: unverified\n"+
+		"  - efcd: unsigned")
+}
@@ -0,0 +1,51 @@
+package provider
+
+import (
+	"fmt"
+	"strings"
+)
+
+// commits created by the provider, which can be excluded from CI with gitlab_skip_ci
+const (
+	commitKindVersionFiles = "version_files"
+	commitKindChangelog    = "changelog"
+	commitKindPages        = "pages"
+)
+
+var commitKinds = []string{commitKindVersionFiles, commitKindChangelog, commitKindPages}
+
+// skipCIMarker prevents the commit from starting a pipeline. The commits are created with the API, push options are
+// therefore not available.
+const skipCIMarker = "[skip ci]"
+
+// parseSkipCIConfig parses a comma separated list of commit kinds, all kinds are enabled by true
+func parseSkipCIConfig(config map[string]string, key string) (map[string]bool, error) {
+	kinds := make(map[string]bool)
+	for _, kind := range parseListConfig(config, key) {
+		switch {
+		case kind == "false":
+		case kind == "true":
+			for _, k := range commitKinds {
+				kinds[k] = true
+			}
+		case containsString(commitKinds, kind):
+			kinds[kind] = true
+		default:
+			return nil, fmt.Errorf("failed to set property %s: unknown commit %q, expected %s", key, kind, strings.Join(commitKinds, ", "))
+		}
+	}
+	return kinds, nil
+}
+
+// commitMessage appends the skip CI marker to the message if gitlab_skip_ci includes the kind of the commit. The tag
+// of a release on top of the version files commit points at that commit, its pipeline is skipped as well.
+func (repo *GitLabRepository) commitMessage(kind, message string) string {
+	if !repo.skipCI[kind] {
+		return message
+	}
+	lower := strings.ToLower(message)
+	if strings.Contains(lower, skipCIMarker) || strings.Contains(lower, "[ci skip]") {
+		return message
+	}
+	return strings.TrimRight(message, "\n") + "\n\n" + skipCIMarker
+}
@@ -0,0 +1,65 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/go-semantic-release/semantic-release/v2/pkg/provider"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSkipCIConfig(t *testing.T) {
+	kinds, err := parseSkipCIConfig(map[string]string{"gitlab_skip_ci": "changelog, pages"}, "gitlab_skip_ci")
+	require.NoError(t, err)
+	require.Equal(t, map[string]bool{commitKindChangelog: true, commitKindPages: true}, kinds)
+
+	kinds, err = parseSkipCIConfig(map[string]string{"gitlab_skip_ci": "true"}, "gitlab_skip_ci")
+	require.NoError(t, err)
+	require.Equal(t, map[string]bool{commitKindVersionFiles: true, commitKindChangelog: true, commitKindPages: true}, kinds)
+
+	kinds, err = parseSkipCIConfig(map[string]string{"gitlab_skip_ci": "false"}, "gitlab_skip_ci")
+	require.NoError(t, err)
+	require.Empty(t, kinds)
+
+	_, err = parseSkipCIConfig(map[string]string{"gitlab_skip_ci": "wiki"}, "gitlab_skip_ci")
+	require.EqualError(t, err, `failed to set property gitlab_skip_ci: unknown commit "wiki", expected version_files, changelog, pages`)
+}
+
+func TestCommitMessageSkipCI(t *testing.T) {
+	repo := &GitLabRepository{skipCI: map[string]bool{commitKindVersionFiles: true}}
+	require.Equal(t, "chore(release): 2.0.0\n\n[skip ci]", repo.commitMessage(commitKindVersionFiles, "chore(release): 2.0.0\n"))
+	require.Equal(t, "chore(release): 2.0.0 [CI SKIP]", repo.commitMessage(commitKindVersionFiles, "chore(release): 2.0.0 [CI SKIP]"))
+	require.Equal(t, "docs: publish", repo.commitMessage(commitKindPages, "docs: publish"))
+}
+
+func TestGitlabSkipCIChangelog(t *testing.T) {
+	var committed map[string]string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "POST" && r.URL.Path == fmt.Sprintf("/api/v4/projects/%d/repository/changelog", GITLAB_PROJECT_ID) {
+			json.NewDecoder(r.Body).Decode(&committed) //nolint:errcheck
+			fmt.Fprint(w, "{}")
+			return
+		}
+		GitlabHandler(w, r)
+	}))
+	defer ts.Close()
+
+	repo := &GitLabRepository{}
+	err := repo.Init(map[string]string{
+		"gitlab_baseurl":        ts.URL,
+		"token":                 "token",
+		"gitlab_projectid":      strconv.Itoa(GITLAB_PROJECT_ID),
+		"gitlab_branch":         "main",
+		"gitlab_changelog_file": "CHANGELOG.md",
+		"gitlab_skip_ci":        "changelog",
+	})
+	require.NoError(t, err)
+
+	err = repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "2.0.0", SHA: "deadbeef"})
+	require.NoError(t, err)
+	require.Equal(t, "Add changelog for version 2.0.0\n\n[skip ci]", committed["message"])
+}
@@ -0,0 +1,73 @@
+package provider
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/go-semantic-release/semantic-release/v2/pkg/provider"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSudo(t *testing.T) {
+	var sudoUsers []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v4/" {
+			sudoUsers = append(sudoUsers, r.Header.Get("Sudo"))
+		}
+		GitlabHandler(w, r)
+	}))
+	defer ts.Close()
+
+	repo := &GitLabRepository{}
+	err := repo.Init(map[string]string{
+		"gitlab_baseurl":   ts.URL,
+		"token":            "admin-token",
+		"gitlab_projectid": strconv.Itoa(GITLAB_PROJECT_ID),
+		"gitlab_sudo":      "release-bot",
+	})
+	require.NoError(t, err)
+
+	err = repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "2.0.0", SHA: "deadbeef"})
+	require.NoError(t, err)
+	require.NotEmpty(t, sudoUsers)
+	for _, user := range sudoUsers {
+		require.Equal(t, "release-bot", user)
+	}
+}
+
+func TestSudoNotSentToMirrors(t *testing.T) {
+	var mirrorSudo []string
+	mirror := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mirrorSudo = append(mirrorSudo, r.Header.Get("Sudo"))
+		GitlabHandler(w, r)
+	}))
+	defer mirror.Close()
+	ts := httptest.NewServer(http.HandlerFunc(GitlabHandler))
+	defer ts.Close()
+
+	t.Setenv("MIRROR_TOKEN", "mirror-token")
+	repo := &GitLabRepository{}
+	err := repo.Init(map[string]string{
+		"gitlab_baseurl":   ts.URL,
+		"token":            "admin-token",
+		"gitlab_projectid": strconv.Itoa(GITLAB_PROJECT_ID),
+		"gitlab_sudo":      "release-bot",
+		"gitlab_mirrors":   strconv.Itoa(GITLAB_PROJECT_ID) + "@" + mirror.URL + "#MIRROR_TOKEN",
+	})
+	require.NoError(t, err)
+
+	err = repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "2.0.0", SHA: "deadbeef"})
+	require.NoError(t, err)
+	require.NotEmpty(t, mirrorSudo)
+	for _, user := range mirrorSudo {
+		require.Empty(t, user)
+	}
+}
+
+func TestSudoRequiresRegistryUser(t *testing.T) {
+	repo := &GitLabRepository{sudo: "release-bot"}
+	_, err := repo.registryUsername()
+	require.EqualError(t, err, "gitlab_container_registry_user is required if gitlab_sudo is set")
+}
@@ -0,0 +1,90 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+)
+
+// releaseSummary is written to gitlab_release_summary_file for later jobs of the pipeline
+type releaseSummary struct {
+	ProjectID   string        `json:"project_id"`
+	Version     string        `json:"version"`
+	Tag         string        `json:"tag"`
+	SHA         string        `json:"sha"`
+	Prerelease  bool          `json:"prerelease"`
+	ReleaseURL  string        `json:"release_url,omitempty"`
+	EvidenceSHA string        `json:"evidence_sha,omitempty"`
+	Assets      []summaryLink `json:"assets"`
+	Milestones  []summaryLink `json:"milestones"`
+}
+
+type summaryLink struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+// writeReleaseSummary writes the details of the release as JSON, the assets and milestones are read from the
+// release as GitLab reports them so links added by other steps are included
+func (repo *GitLabRepository) writeReleaseSummary(data *templateData) error {
+	summary := &releaseSummary{
+		ProjectID:   repo.projectID,
+		Version:     data.Version,
+		Tag:         data.Tag,
+		SHA:         data.SHA,
+		Prerelease:  data.Prerelease,
+		ReleaseURL:  data.ReleaseURL,
+		EvidenceSHA: data.EvidenceSHA,
+		Assets:      make([]summaryLink, 0),
+		Milestones:  make([]summaryLink, 0),
+	}
+
+	if !repo.tagOnly {
+		path := fmt.Sprintf("projects/%s/releases/%s", url.PathEscape(repo.projectID), url.PathEscape(data.Tag))
+		req, err := repo.api.NewRequest(http.MethodGet, path, nil, nil)
+		if err != nil {
+			return err
+		}
+		var release struct {
+			Assets struct {
+				Links []struct {
+					Name           string `json:"name"`
+					URL            string `json:"url"`
+					DirectAssetURL string `json:"direct_asset_url"`
+				} `json:"links"`
+				Sources []struct {
+					Format string `json:"format"`
+					URL    string `json:"url"`
+				} `json:"sources"`
+			} `json:"assets"`
+			Milestones []struct {
+				Title  string `json:"title"`
+				WebURL string `json:"web_url"`
+			} `json:"milestones"`
+		}
+		if _, err := repo.api.Do(req, &release); err != nil {
+			return fmt.Errorf("failed to get release %s: %w", data.Tag, err)
+		}
+
+		for _, link := range release.Assets.Links {
+			summary.Assets = append(summary.Assets, summaryLink{Name: link.Name, URL: defaultString(link.DirectAssetURL, link.URL)})
+		}
+		for _, source := range release.Assets.Sources {
+			summary.Assets = append(summary.Assets, summaryLink{Name: "source code (" + source.Format + ")", URL: source.URL})
+		}
+		for _, milestone := range release.Milestones {
+			summary.Milestones = append(summary.Milestones, summaryLink{Name: milestone.Title, URL: milestone.WebURL})
+		}
+	}
+
+	content, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(repo.releaseSummaryFile, append(content, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write release summary: %w", err)
+	}
+	return nil
+}
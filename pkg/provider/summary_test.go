@@ -0,0 +1,64 @@
+package provider
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-semantic-release/semantic-release/v2/pkg/provider"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReleaseSummary(t *testing.T) {
+	repo, ts := getNewGitlabTestRepo(t)
+	defer ts.Close()
+	ts.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" && r.URL.Path == fmt.Sprintf("/api/v4/projects/%d/releases/v2.0.0", GITLAB_PROJECT_ID) {
+			fmt.Fprint(w, `{
+				"tag_name": "v2.0.0",
+				"assets": {
+					"links": [{"name": "app.tgz", "url": "https://gitlab.com/link", "direct_asset_url": "https://gitlab.com/direct"}],
+					"sources": [{"format": "zip", "url": "https://gitlab.com/archive.zip"}]
+				},
+				"milestones": [{"title": "2.0", "web_url": "https://gitlab.com/group/project/-/milestones/2"}]
+			}`)
+			return
+		}
+		GitlabHandler(w, r)
+	})
+	repo.releaseSummaryFile = filepath.Join(t.TempDir(), "release.json")
+
+	err := repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "2.0.0", SHA: "deadbeef"})
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(repo.releaseSummaryFile)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"project_id": "12324322",
+		"version": "2.0.0",
+		"tag": "v2.0.0",
+		"sha": "deadbeef",
+		"prerelease": false,
+		"assets": [
+			{"name": "app.tgz", "url": "https://gitlab.com/direct"},
+			{"name": "source code (zip)", "url": "https://gitlab.com/archive.zip"}
+		],
+		"milestones": [{"name": "2.0", "url": "https://gitlab.com/group/project/-/milestones/2"}]
+	}`, string(content))
+}
+
+func TestReleaseSummaryTagOnly(t *testing.T) {
+	repo, ts := getNewGitlabTestRepo(t)
+	defer ts.Close()
+	repo.tagOnly = true
+	repo.releaseSummaryFile = filepath.Join(t.TempDir(), "release.json")
+
+	err := repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "2.0.0", SHA: "deadbeef"})
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(repo.releaseSummaryFile)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"project_id": "12324322", "version": "2.0.0", "tag": "v2.0.0", "sha": "deadbeef", "prerelease": false, "assets": [], "milestones": []}`, string(content))
+}
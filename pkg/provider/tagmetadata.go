@@ -0,0 +1,118 @@
+package provider
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/go-semantic-release/semantic-release/v2/pkg/provider"
+)
+
+// trailers of the annotated tag message written with gitlab_tag_metadata
+const (
+	tagTrailerChannel         = "Release-Channel"
+	tagTrailerPipeline        = "Release-Pipeline"
+	tagTrailerBranch          = "Release-Branch"
+	tagTrailerPreviousVersion = "Release-Previous-Version"
+)
+
+// tagMetadata is the provenance of a release, written to the tag so it is available without the releases API
+type tagMetadata struct {
+	Channel         string
+	PipelineID      string
+	Branch          string
+	PreviousVersion string
+}
+
+// newTagMetadata returns the metadata of the release, the previous version is the latest version found by the last
+// GetReleases call
+func (repo *GitLabRepository) newTagMetadata(release *provider.CreateReleaseConfig) *tagMetadata {
+	channel := repo.channel
+	if channel == "" {
+		channel = repo.prereleaseChannel
+	}
+	return &tagMetadata{
+		Channel:         channel,
+		PipelineID:      os.Getenv("CI_PIPELINE_ID"),
+		Branch:          defaultString(repo.branch, release.Branch),
+		PreviousVersion: repo.previousVersion,
+	}
+}
+
+// trailers formats the metadata as git trailers, empty values are omitted
+func (m *tagMetadata) trailers() string {
+	lines := make([]string, 0, 4)
+	for _, trailer := range []struct{ key, value string }{
+		{tagTrailerChannel, m.Channel},
+		{tagTrailerPipeline, m.PipelineID},
+		{tagTrailerBranch, m.Branch},
+		{tagTrailerPreviousVersion, m.PreviousVersion},
+	} {
+		if trailer.value != "" {
+			lines = append(lines, fmt.Sprintf("%s: %s", trailer.key, trailer.value))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// withTagMetadata appends the trailers to the tag message separated by a blank line
+func withTagMetadata(message string, metadata *tagMetadata) string {
+	trailers := metadata.trailers()
+	if trailers == "" {
+		return message
+	}
+	return strings.TrimRight(message, "\n") + "\n\n" + trailers
+}
+
+// parseTagMetadata parses the trailers of the last paragraph of a tag message, nil is returned for tags without
+// metadata, e.g. lightweight tags or tags created before gitlab_tag_metadata was enabled
+func parseTagMetadata(message string) *tagMetadata {
+	paragraphs := strings.Split(strings.TrimSpace(message), "\n\n")
+	var metadata tagMetadata
+	found := false
+	for _, line := range strings.Split(paragraphs[len(paragraphs)-1], "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch strings.TrimSpace(key) {
+		case tagTrailerChannel:
+			metadata.Channel = value
+		case tagTrailerPipeline:
+			metadata.PipelineID = value
+		case tagTrailerBranch:
+			metadata.Branch = value
+		case tagTrailerPreviousVersion:
+			metadata.PreviousVersion = value
+		default:
+			continue
+		}
+		found = true
+	}
+	if !found {
+		return nil
+	}
+	return &metadata
+}
+
+// otherChannelTag reports whether the metadata of the tag records a release of another channel than the configured
+// one, such tags are not considered by GetReleases
+func (repo *GitLabRepository) otherChannelTag(tag string, metadata *tagMetadata) bool {
+	if metadata == nil || metadata.Channel == "" || repo.channel == "" || metadata.Channel == repo.channel {
+		return false
+	}
+	repo.debugf("ignoring tag %s of channel %s", tag, metadata.Channel)
+	return true
+}
+
+// trackPreviousVersion remembers the latest version found by GetReleases
+func (repo *GitLabRepository) trackPreviousVersion(version *semver.Version) {
+	if repo.previousVersion != "" {
+		if previous, err := semver.NewVersion(repo.previousVersion); err == nil && !version.GreaterThan(previous) {
+			return
+		}
+	}
+	repo.previousVersion = version.String()
+}
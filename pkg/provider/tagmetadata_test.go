@@ -0,0 +1,79 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/go-semantic-release/semantic-release/v2/pkg/provider"
+	"github.com/go-semantic-release/semantic-release/v2/pkg/semrel"
+	"github.com/stretchr/testify/require"
+	"github.com/xanzy/go-gitlab"
+)
+
+func TestTagMetadataTrailers(t *testing.T) {
+	metadata := &tagMetadata{Channel: "beta", PipelineID: "42", Branch: "next", PreviousVersion: "1.0.0"}
+	message := withTagMetadata("Release v1.1.0\n", metadata)
+	require.Equal(t, "Release v1.1.0\n\nRelease-Channel: beta\nRelease-Pipeline: 42\nRelease-Branch: next\nRelease-Previous-Version: 1.0.0", message)
+	require.Equal(t, metadata, parseTagMetadata(message))
+
+	require.Equal(t, "v1.1.0\n\nRelease-Branch: main", withTagMetadata("v1.1.0", &tagMetadata{Branch: "main"}))
+	require.Equal(t, "v1.1.0", withTagMetadata("v1.1.0", &tagMetadata{}))
+	require.Nil(t, parseTagMetadata(""))
+	require.Nil(t, parseTagMetadata("Release v1.1.0\n\nSigned-off-by: someone"))
+	// trailers of an earlier paragraph are part of the message
+	require.Nil(t, parseTagMetadata("Release-Channel: beta\n\nnotes"))
+}
+
+func TestGitlabTagMetadata(t *testing.T) {
+	t.Setenv("CI_PIPELINE_ID", "42")
+	tags := []*gitlab.Tag{
+		createGitlabTag("v1.5.0", "deadbeef"),
+		createGitlabTag("v1.2.0", "beefdead"),
+		createGitlabTag("v1.0.0", "cafebabe"),
+	}
+	tags[0].Message = withTagMetadata("v1.5.0", &tagMetadata{Channel: "next", Branch: "next"})
+	tags[1].Message = withTagMetadata("v1.2.0", &tagMetadata{Channel: "latest", Branch: "main", PreviousVersion: "1.0.0"})
+
+	var tagMessage string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != fmt.Sprintf("/api/v4/projects/%d/repository/tags", GITLAB_PROJECT_ID) {
+			GitlabHandler(w, r)
+			return
+		}
+		if r.Method == http.MethodGet {
+			json.NewEncoder(w).Encode(tags) //nolint:errcheck
+			return
+		}
+		var data gitlab.CreateTagOptions
+		json.NewDecoder(r.Body).Decode(&data) //nolint:errcheck
+		tagMessage = *data.Message
+		json.NewEncoder(w).Encode(createGitlabTag(*data.TagName, *data.Ref)) //nolint:errcheck
+	}))
+	defer ts.Close()
+
+	repo := &GitLabRepository{}
+	err := repo.Init(map[string]string{
+		"gitlab_baseurl":         ts.URL,
+		"token":                  "gitlab-examples-ci",
+		"gitlab_projectid":       strconv.Itoa(GITLAB_PROJECT_ID),
+		"gitlab_branch":          "main",
+		"gitlab_branch_channels": "main=latest,next=next",
+		"gitlab_tag_metadata":    "true",
+	})
+	require.NoError(t, err)
+
+	releases, err := repo.GetReleases("")
+	require.NoError(t, err)
+	require.Equal(t, []*semrel.Release{
+		{SHA: "beefdead", Version: "1.2.0"},
+		{SHA: "cafebabe", Version: "1.0.0"},
+	}, releases)
+
+	err = repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "2.0.0", SHA: "deadbeef"})
+	require.NoError(t, err)
+	require.Equal(t, "v2.0.0\n\nRelease-Channel: latest\nRelease-Pipeline: 42\nRelease-Branch: main\nRelease-Previous-Version: 1.2.0", tagMessage)
+}
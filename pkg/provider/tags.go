@@ -0,0 +1,126 @@
+package provider
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/go-semantic-release/semantic-release/v2/pkg/provider"
+	"github.com/xanzy/go-gitlab"
+)
+
+func (repo *GitLabRepository) createTag(tag string, release *provider.CreateReleaseConfig) error {
+	opts := &gitlab.CreateTagOptions{
+		TagName: &tag,
+		Ref:     &release.SHA,
+	}
+
+	if repo.tagMessage != nil {
+		message, err := renderTemplate(repo.tagMessage, newTemplateData(tag, release))
+		if err != nil {
+			return err
+		}
+		opts.Message = &message
+	}
+	if repo.tagMetadata {
+		// the metadata makes the tag an annotated tag, the message defaults to the name of the tag
+		message := tag
+		if opts.Message != nil {
+			message = *opts.Message
+		}
+		message = withTagMetadata(message, repo.newTagMetadata(release))
+		opts.Message = &message
+	}
+
+	var err error
+	if repo.tagSigningKey != "" {
+		// signed tags are annotated, the message defaults to the name of the tag
+		message := tag
+		if opts.Message != nil {
+			message = *opts.Message
+		}
+		err = repo.pushSignedTag(tag, release.SHA, message)
+	} else {
+		_, _, err = repo.api.CreateTag(repo.projectID, opts)
+	}
+	if err != nil && repo.allowUpdate {
+		// the tag may have been created by a previous attempt
+		if verifyErr := repo.verifyExistingTag(tag, release.SHA); verifyErr == nil {
+			return nil
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create tag %s: %w", tag, err)
+	}
+
+	repo.createdTag = tag
+	return nil
+}
+
+// verifyExistingTag makes sure the tag was already created and points at the release commit
+func (repo *GitLabRepository) verifyExistingTag(tag, sha string) error {
+	existing, resp, err := repo.api.GetTag(repo.projectID, tag)
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("tag %s does not exist", tag)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get tag %s: %w", tag, err)
+	}
+
+	if existing.Commit == nil || existing.Commit.ID != sha {
+		actual := ""
+		if existing.Commit != nil {
+			actual = existing.Commit.ID
+		}
+		return fmt.Errorf("tag %s points at %s instead of %s", tag, actual, sha)
+	}
+
+	return nil
+}
+
+// removeMisplacedTag deletes the tag and its release if the tag does not point at the release commit
+func (repo *GitLabRepository) removeMisplacedTag(tag, sha string) error {
+	existing, resp, err := repo.api.GetTag(repo.projectID, tag)
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get tag %s: %w", tag, err)
+	}
+	if existing.Commit != nil && existing.Commit.ID == sha {
+		return nil
+	}
+
+	actual := ""
+	if existing.Commit != nil {
+		actual = existing.Commit.ID
+	}
+	repo.logger.Printf("WARNING: tag %s points at %s instead of %s, deleting and recreating it (gitlab_force_retag)", tag, actual, sha)
+
+	if err := repo.deleteRelease(tag); err != nil {
+		return err
+	}
+	if err := repo.deleteTag(tag); err != nil {
+		return err
+	}
+
+	repo.logger.Printf("WARNING: deleted tag %s (previously at %s)", tag, actual)
+	return nil
+}
+
+// deleteTag deletes the tag, a missing tag is not considered an error
+func (repo *GitLabRepository) deleteTag(tag string) error {
+	resp, err := repo.api.DeleteTag(repo.projectID, tag)
+	if err != nil && (resp == nil || resp.StatusCode != http.StatusNotFound) {
+		return fmt.Errorf("failed to delete tag %s: %w", tag, err)
+	}
+	return nil
+}
+
+// deleteRelease deletes the release but keeps its tag, a missing release is not considered an error
+func (repo *GitLabRepository) deleteRelease(tag string) error {
+	_, resp, err := repo.api.DeleteRelease(repo.projectID, tag)
+	if err != nil && (resp == nil || resp.StatusCode != http.StatusNotFound) {
+		return fmt.Errorf("failed to delete release %s: %w", tag, err)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package provider
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// signedTagFormats are the values of git's gpg.format
+var signedTagFormats = []string{"openpgp", "ssh", "x509"}
+
+// pushSignedTag signs the tag with the local git installation and pushes it, the API can only create unsigned tags.
+// The release commit is fetched first if the clone does not contain it, e.g. because it was created through the API.
+func (repo *GitLabRepository) pushSignedTag(tag, sha, message string) error {
+	project, err := repo.getProject()
+	if err != nil {
+		return fmt.Errorf("failed to get project: %w", err)
+	}
+	remote := project.HTTPURLToRepo
+	auth := repo.gitAuthEnv(remote)
+
+	if _, err := repo.git(nil, "cat-file", "-e", sha+"^{commit}"); err != nil {
+		if _, err := repo.git(auth, "fetch", "--no-tags", remote, sha); err != nil {
+			return err
+		}
+	}
+
+	args := []string{"-c", "user.signingkey=" + repo.tagSigningKey}
+	if repo.tagSigningFormat != "" {
+		args = append(args, "-c", "gpg.format="+repo.tagSigningFormat)
+	}
+	// a tag left behind by a failed attempt is replaced
+	args = append(args, "tag", "--force", "--sign", "--message", message, tag, sha)
+	if _, err := repo.git(nil, args...); err != nil {
+		return err
+	}
+	_, err = repo.git(auth, "push", remote, "refs/tags/"+tag)
+	return err
+}
+
+// gitAuthEnv configures the token as authorization header of the remote through the environment, unlike the
+// command line or the remote URL the environment of git does not show up in the process list. Configuration passed
+// by the job the same way is kept.
+func (repo *GitLabRepository) gitAuthEnv(remote string) []string {
+	count, _ := strconv.Atoi(os.Getenv("GIT_CONFIG_COUNT"))
+	return []string{
+		fmt.Sprintf("GIT_CONFIG_COUNT=%d", count+1),
+		fmt.Sprintf("GIT_CONFIG_KEY_%d=http.%s.extraHeader", count, remote),
+		fmt.Sprintf("GIT_CONFIG_VALUE_%d=Authorization: Basic %s", count, repo.gitCredentials()),
+	}
+}
+
+// gitCredentials returns the credentials of the authorization header of git
+func (repo *GitLabRepository) gitCredentials() string {
+	return base64.StdEncoding.EncodeToString([]byte("oauth2:" + repo.token))
+}
+
+// git runs git in the working directory with the additional environment, the token is removed from the output
+func (repo *GitLabRepository) git(env []string, args ...string) ([]byte, error) {
+	run := repo.runGit
+	if run == nil {
+		run = func(env []string, args ...string) ([]byte, error) {
+			cmd := exec.Command("git", args...)
+			cmd.Env = append(os.Environ(), env...)
+			return cmd.CombinedOutput()
+		}
+	}
+	out, err := run(env, args...)
+	if err != nil {
+		output := strings.TrimSpace(string(out))
+		if repo.token != "" {
+			output = strings.ReplaceAll(output, repo.token, "REDACTED")
+			output = strings.ReplaceAll(output, repo.gitCredentials(), "REDACTED")
+		}
+		return nil, fmt.Errorf("git %s failed: %s", gitSubcommand(args), output)
+	}
+	return out, nil
+}
+
+// gitSubcommand returns the first argument which is not a -c option
+func gitSubcommand(args []string) string {
+	for i := 0; i < len(args); i++ {
+		if args[i] == "-c" {
+			i++
+			continue
+		}
+		return args[i]
+	}
+	return ""
+}
@@ -0,0 +1,85 @@
+package provider
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/go-semantic-release/semantic-release/v2/pkg/provider"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignedTag(t *testing.T) {
+	repo, ts := getNewGitlabTestRepo(t)
+	defer ts.Close()
+	repo.tagSigningKey = "ABCDEF"
+	repo.tagSigningFormat = "ssh"
+
+	var apiTags int
+	ts.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "POST" && r.URL.Path == fmt.Sprintf("/api/v4/projects/%d/repository/tags", GITLAB_PROJECT_ID) {
+			apiTags++
+		}
+		GitlabHandler(w, r)
+	})
+
+	t.Setenv("GIT_CONFIG_COUNT", "1")
+	var calls []string
+	var fetchEnv, pushEnv []string
+	repo.runGit = func(env []string, args ...string) ([]byte, error) {
+		calls = append(calls, strings.Join(args, " "))
+		switch args[0] {
+		case "fetch":
+			fetchEnv = env
+		case "push":
+			pushEnv = env
+		}
+		if args[0] == "cat-file" {
+			// the release commit is not part of the clone
+			return []byte("fatal: Not a valid object name deadbeef^{commit}"), errors.New("exit status 128")
+		}
+		return nil, nil
+	}
+
+	err := repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "2.0.0", SHA: "deadbeef"})
+	require.NoError(t, err)
+	require.Zero(t, apiTags)
+	require.Equal(t, []string{
+		"cat-file -e deadbeef^{commit}",
+		"fetch --no-tags https://gitlab.com/group/project.git deadbeef",
+		"-c user.signingkey=ABCDEF -c gpg.format=ssh tag --force --sign --message v2.0.0 v2.0.0 deadbeef",
+		"push https://gitlab.com/group/project.git refs/tags/v2.0.0",
+	}, calls)
+	// the token is only passed in the environment, after the configuration of the job
+	auth := []string{
+		"GIT_CONFIG_COUNT=2",
+		"GIT_CONFIG_KEY_1=http.https://gitlab.com/group/project.git.extraHeader",
+		"GIT_CONFIG_VALUE_1=Authorization: Basic " + base64.StdEncoding.EncodeToString([]byte("oauth2:gitlab-examples-ci")),
+	}
+	require.Equal(t, auth, fetchEnv)
+	require.Equal(t, auth, pushEnv)
+}
+
+func TestSignedTagGitError(t *testing.T) {
+	repo, ts := getNewGitlabTestRepo(t)
+	defer ts.Close()
+	repo.tagSigningKey = "ABCDEF"
+	repo.runGit = func(env []string, args ...string) ([]byte, error) {
+		if args[0] == "push" {
+			credentials := base64.StdEncoding.EncodeToString([]byte("oauth2:gitlab-examples-ci"))
+			return []byte("remote: denied for gitlab-examples-ci\nAuthorization: Basic " + credentials + "\n"), errors.New("exit status 1")
+		}
+		return nil, nil
+	}
+
+	err := repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "2.0.0", SHA: "deadbeef"})
+	require.EqualError(t, err, "failed to create tag v2.0.0: git push failed: remote: denied for REDACTED\nAuthorization: Basic REDACTED")
+}
+
+func TestInvalidTagSigningFormat(t *testing.T) {
+	err := (&GitLabRepository{}).Init(map[string]string{"token": "token", "gitlab_projectid": "1", "gitlab_tag_signing_format": "gpg"})
+	require.EqualError(t, err, `failed to set property gitlab_tag_signing_format: unknown format "gpg", expected openpgp, ssh, x509`)
+}
@@ -0,0 +1,126 @@
+package provider
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/go-semantic-release/semantic-release/v2/pkg/provider"
+)
+
+// templateData is available in all configurable templates
+type templateData struct {
+	Version   string
+	Major     uint64
+	Minor     uint64
+	Patch     uint64
+	Tag       string
+	SHA       string
+	Branch    string
+	Changelog string
+	// Summary counts the entries of the changelog sections on one line, e.g. for short tag messages
+	Summary    string
+	Prerelease bool
+	// Latest is false for releases of maintenance channels and with gitlab_release_latest disabled
+	Latest     bool
+	ReleaseURL string
+	// EvidenceSHA is set if gitlab_release_evidence is enabled and the evidence was collected
+	EvidenceSHA string
+	// ProjectPath and ProjectURL are empty if the project cannot be fetched
+	ProjectPath string
+	ProjectURL  string
+}
+
+// templateFuncs are available in all configurable templates, env returns a CI/CD variable, e.g. {{env "CI_JOB_ID"}}
+var templateFuncs = template.FuncMap{
+	"env": templateEnv,
+}
+
+// credentialVariableRe matches the predefined variables holding credentials, e.g. CI_JOB_TOKEN or CI_REGISTRY_PASSWORD.
+// CI_REPOSITORY_URL contains the job token as well.
+var credentialVariableRe = regexp.MustCompile(`TOKEN|PASSWORD|SECRET|JWT|KEY|CERT|AUTH|^CI_REPOSITORY_URL$`)
+
+// templateEnv returns a predefined CI/CD variable, other variables may hold credentials which would be published in
+// the tags and releases
+func templateEnv(name string) (string, error) {
+	if !strings.HasPrefix(name, "CI_") || credentialVariableRe.MatchString(name) {
+		return "", fmt.Errorf("variable %s is not available in templates, only CI_ variables without credentials are", name)
+	}
+	return os.Getenv(name), nil
+}
+
+func newTemplateData(tag string, release *provider.CreateReleaseConfig) *templateData {
+	data := &templateData{
+		Version:    release.NewVersion,
+		Tag:        tag,
+		SHA:        release.SHA,
+		Branch:     release.Branch,
+		Changelog:  release.Changelog,
+		Summary:    summarizeSections(release.Changelog),
+		Prerelease: release.Prerelease,
+	}
+	if version, err := semver.NewVersion(release.NewVersion); err == nil {
+		data.Major, data.Minor, data.Patch = version.Major(), version.Minor(), version.Patch()
+	}
+	return data
+}
+
+// releaseTemplateData returns the template data including the project, which is fetched once
+func (repo *GitLabRepository) releaseTemplateData(tag string, release *provider.CreateReleaseConfig) *templateData {
+	data := newTemplateData(tag, release)
+	data.Latest = !repo.isMaintenanceChannel() && (repo.releaseLatest == nil || *repo.releaseLatest)
+	if project, err := repo.getProject(); err == nil {
+		data.ProjectPath, data.ProjectURL = project.PathWithNamespace, project.WebURL
+	}
+	return data
+}
+
+// summarizeSections returns the headings of the changelog with the number of their entries, e.g.
+// "Feature (2), Bug Fixes (1)", entries are the top-level list items
+func summarizeSections(changelog string) string {
+	sections := make([]string, 0)
+	heading, entries := "", 0
+	flush := func() {
+		if heading != "" && entries > 0 {
+			sections = append(sections, fmt.Sprintf("%s (%d)", heading, entries))
+		}
+	}
+	for _, line := range strings.Split(changelog, "\n") {
+		switch {
+		case strings.HasPrefix(line, "#"):
+			flush()
+			heading, entries = strings.TrimSpace(strings.TrimLeft(line, "#")), 0
+		case strings.HasPrefix(line, "* ") || strings.HasPrefix(line, "- "):
+			entries++
+		}
+	}
+	flush()
+	return strings.Join(sections, ", ")
+}
+
+func parseTemplateConfig(config map[string]string, key, defaultValue string) (*template.Template, error) {
+	value := config[key]
+	if value == "" {
+		value = defaultValue
+	}
+	if value == "" {
+		return nil, nil
+	}
+
+	tmpl, err := template.New(key).Funcs(templateFuncs).Parse(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set property %s: %w", key, err)
+	}
+	return tmpl, nil
+}
+
+func renderTemplate(tmpl *template.Template, data *templateData) (string, error) {
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("failed to render %s: %w", tmpl.Name(), err)
+	}
+	return sb.String(), nil
+}
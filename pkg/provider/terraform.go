@@ -0,0 +1,67 @@
+package provider
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+// parseTerraformModuleConfig parses the module-name/module-system pair of the Terraform module registry
+func parseTerraformModuleConfig(config map[string]string, key string) (string, string, error) {
+	value := config[key]
+	if value == "" {
+		return "", "", nil
+	}
+	name, system, ok := strings.Cut(value, "/")
+	if !ok || name == "" || system == "" || strings.Contains(system, "/") {
+		return "", "", fmt.Errorf("failed to set property %s: invalid module %q, expected module-name/module-system", key, value)
+	}
+	return name, system, nil
+}
+
+// publishTerraformModule uploads the module archive with the new version to the Terraform module registry of the
+// project and links it from the release
+func (repo *GitLabRepository) publishTerraformModule(version string) error {
+	var archive []byte
+	var err error
+	if strings.HasSuffix(repo.terraformModulePath, ".tgz") || strings.HasSuffix(repo.terraformModulePath, ".tar.gz") {
+		archive, err = os.ReadFile(repo.terraformModulePath)
+	} else {
+		archive, err = tarGzDir(repo.terraformModulePath, "", nil)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to package terraform module %s: %w", repo.terraformModulePath, err)
+	}
+
+	module := fmt.Sprintf("%s/%s", repo.terraformModuleName, repo.terraformModuleSystem)
+	path := fmt.Sprintf(
+		"projects/%s/packages/terraform/modules/%s/%s/%s/file",
+		url.PathEscape(repo.projectID),
+		url.PathEscape(repo.terraformModuleName),
+		url.PathEscape(repo.terraformModuleSystem),
+		url.PathEscape(version),
+	)
+	req, err := repo.api.NewRequest(http.MethodPut, path, nil, nil)
+	if err != nil {
+		return err
+	}
+	if err := req.SetBody(archive); err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	if _, err := repo.api.Do(req, nil); err != nil {
+		return fmt.Errorf("failed to publish terraform module %s: %w", module, err)
+	}
+	repo.logger.Printf("published terraform module %s %s", module, version)
+
+	repo.releaseLinks = append(repo.releaseLinks, &gitlab.ReleaseAssetLinkOptions{
+		Name:     gitlab.String(fmt.Sprintf("Terraform module %s %s", module, version)),
+		URL:      gitlab.String(repo.apiURL(path)),
+		LinkType: repo.packageLinkType(),
+	})
+	return nil
+}
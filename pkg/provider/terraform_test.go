@@ -0,0 +1,74 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/go-semantic-release/semantic-release/v2/pkg/provider"
+	"github.com/stretchr/testify/require"
+	"github.com/xanzy/go-gitlab"
+)
+
+func TestParseTerraformModuleConfig(t *testing.T) {
+	name, system, err := parseTerraformModuleConfig(map[string]string{"gitlab_terraform_module": "vpc/aws"}, "gitlab_terraform_module")
+	require.NoError(t, err)
+	require.Equal(t, "vpc", name)
+	require.Equal(t, "aws", system)
+
+	for _, value := range []string{"vpc", "vpc/", "/aws", "vpc/aws/extra"} {
+		_, _, err = parseTerraformModuleConfig(map[string]string{"gitlab_terraform_module": value}, "gitlab_terraform_module")
+		require.EqualError(t, err, fmt.Sprintf("failed to set property gitlab_terraform_module: invalid module %q, expected module-name/module-system", value))
+	}
+}
+
+func TestGitlabPublishTerraformModule(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.tf"), []byte("resource \"aws_vpc\" \"this\" {}\n"), 0o644))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".terraform"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".terraform", "state"), []byte("{}"), 0o644))
+
+	var uploaded map[string]string
+	var links []*gitlab.ReleaseAssetLinkOptions
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "PUT" && r.URL.Path == fmt.Sprintf("/api/v4/projects/%d/packages/terraform/modules/vpc/aws/2.0.0/file", GITLAB_PROJECT_ID):
+			content, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			uploaded = readTestArchive(t, content)
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"message":"201 Created"}`)
+		case r.Method == "POST" && r.URL.Path == fmt.Sprintf("/api/v4/projects/%d/releases", GITLAB_PROJECT_ID):
+			var opts gitlab.CreateReleaseOptions
+			json.NewDecoder(r.Body).Decode(&opts) //nolint:errcheck
+			links = opts.Assets.Links
+			fmt.Fprint(w, "{}")
+		default:
+			GitlabHandler(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	repo := &GitLabRepository{}
+	err := repo.Init(map[string]string{
+		"gitlab_baseurl":               ts.URL,
+		"token":                        "gitlab-examples-ci",
+		"gitlab_projectid":             strconv.Itoa(GITLAB_PROJECT_ID),
+		"gitlab_terraform_module":      "vpc/aws",
+		"gitlab_terraform_module_path": dir,
+	})
+	require.NoError(t, err)
+
+	err = repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "2.0.0", SHA: "deadbeef"})
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"main.tf": "resource \"aws_vpc\" \"this\" {}\n"}, uploaded)
+	require.Len(t, links, 1)
+	require.Equal(t, "Terraform module vpc/aws 2.0.0", *links[0].Name)
+	require.Equal(t, fmt.Sprintf("%s/api/v4/projects/%d/packages/terraform/modules/vpc/aws/2.0.0/file", ts.URL, GITLAB_PROJECT_ID), *links[0].URL)
+}
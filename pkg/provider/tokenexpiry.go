@@ -0,0 +1,37 @@
+package provider
+
+import (
+	"fmt"
+	"time"
+)
+
+// checkTokenExpiry warns, or fails with gitlab_token_expiry_fail, if the access token expires within the configured
+// window. Job tokens and instances without the token endpoint are skipped.
+func (repo *GitLabRepository) checkTokenExpiry() error {
+	token, _, err := repo.getTokenInfo()
+	if err != nil {
+		repo.debugf("failed to get the token metadata, skipping the expiry check: %s", err)
+		return nil
+	}
+	if token.ExpiresAt == nil {
+		return nil
+	}
+
+	// tokens expire at midnight UTC of the expiry date
+	expiresAt := time.Time(*token.ExpiresAt)
+	remaining := time.Until(expiresAt)
+	if remaining > repo.tokenExpiryWindow {
+		return nil
+	}
+
+	message := fmt.Sprintf("the token %q expires on %s", token.Name, expiresAt.Format("2006-01-02"))
+	if remaining <= 0 {
+		message = fmt.Sprintf("the token %q expired on %s", token.Name, expiresAt.Format("2006-01-02"))
+	}
+	message += ", rotate it before scheduled releases fail"
+	if repo.tokenExpiryFail {
+		return fmt.Errorf("gitlab_token_expiry_window: %s", message)
+	}
+	repo.logger.Printf("WARNING: %s", message)
+	return nil
+}
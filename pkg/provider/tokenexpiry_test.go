@@ -0,0 +1,73 @@
+package provider
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGitlabTokenExpiry(t *testing.T) {
+	expiresAt := time.Now().UTC().AddDate(0, 0, 3).Format("2006-01-02")
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v4/personal_access_tokens/self" {
+			fmt.Fprintf(w, `{"name": "release", "scopes": ["api"], "active": true, "expires_at": %q}`, expiresAt)
+			return
+		}
+		GitlabHandler(w, r)
+	}))
+	defer ts.Close()
+
+	initRepo := func(window string, fail bool) (*bytes.Buffer, error) {
+		var logs bytes.Buffer
+		repo := &GitLabRepository{logger: log.New(&logs, "", 0)}
+		return &logs, repo.Init(map[string]string{
+			"gitlab_baseurl":             ts.URL,
+			"token":                      "token",
+			"gitlab_projectid":           strconv.Itoa(GITLAB_PROJECT_ID),
+			"gitlab_token_expiry_window": window,
+			"gitlab_token_expiry_fail":   strconv.FormatBool(fail),
+		})
+	}
+
+	logs, err := initRepo("168h", false)
+	require.NoError(t, err)
+	require.Equal(t, fmt.Sprintf("WARNING: the token \"release\" expires on %s, rotate it before scheduled releases fail\n", expiresAt), logs.String())
+
+	_, err = initRepo("168h", true)
+	require.EqualError(t, err, fmt.Sprintf("gitlab_token_expiry_window: the token \"release\" expires on %s, rotate it before scheduled releases fail", expiresAt))
+
+	logs, err = initRepo("24h", true)
+	require.NoError(t, err)
+	require.Empty(t, logs.String())
+}
+
+func TestGitlabTokenExpiryUnknown(t *testing.T) {
+	// job tokens cannot read their metadata
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v4/personal_access_tokens/self" {
+			http.Error(w, `{"message": "401 Unauthorized"}`, http.StatusUnauthorized)
+			return
+		}
+		GitlabHandler(w, r)
+	}))
+	defer ts.Close()
+
+	var logs bytes.Buffer
+	repo := &GitLabRepository{logger: log.New(&logs, "", 0)}
+	err := repo.Init(map[string]string{
+		"gitlab_baseurl":             ts.URL,
+		"token":                      "token",
+		"gitlab_projectid":           strconv.Itoa(GITLAB_PROJECT_ID),
+		"gitlab_token_expiry_window": "168h",
+		"gitlab_token_expiry_fail":   "true",
+	})
+	require.NoError(t, err)
+	require.Empty(t, logs.String())
+}
@@ -0,0 +1,118 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/go-semantic-release/provider-gitlab"
+
+// newTracerProvider configures the exporter with the standard OTEL_* environment variables, tracing is disabled
+// unless OTEL_TRACES_EXPORTER or an OTLP endpoint is set
+func newTracerProvider() (*sdktrace.TracerProvider, error) {
+	exporterName := os.Getenv("OTEL_TRACES_EXPORTER")
+	if exporterName == "" && (os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" || os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") != "") {
+		exporterName = "otlp"
+	}
+
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch exporterName {
+	case "", "none":
+		return nil, nil
+	case "otlp":
+		// the endpoint, headers and timeout are read from the environment by the exporter
+		exporter, err = otlptracehttp.New(context.Background())
+	case "console":
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stderr))
+	default:
+		return nil, fmt.Errorf("unsupported OTEL_TRACES_EXPORTER %q, expected otlp, console or none", exporterName)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the %s trace exporter: %w", exporterName, err)
+	}
+
+	// OTEL_SERVICE_NAME and OTEL_RESOURCE_ATTRIBUTES take precedence over the defaults
+	res, err := resource.New(context.Background(),
+		resource.WithTelemetrySDK(),
+		resource.WithAttributes(attribute.String("service.name", "provider-gitlab")),
+		resource.WithFromEnv(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the trace resource: %w", err)
+	}
+	return sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter), sdktrace.WithResource(res)), nil
+}
+
+func (repo *GitLabRepository) tracer() trace.Tracer {
+	if repo.tracerProvider == nil {
+		return trace.NewNoopTracerProvider().Tracer(tracerName)
+	}
+	return repo.tracerProvider.Tracer(tracerName)
+}
+
+// startSpan starts the span of a provider call, API requests made until it ends are recorded as its children. The
+// trace of the pipeline is continued if TRACEPARENT is set.
+func (repo *GitLabRepository) startSpan(name string, attrs ...attribute.KeyValue) trace.Span {
+	parent := propagation.TraceContext{}.Extract(context.Background(), propagation.MapCarrier{
+		"traceparent": os.Getenv("TRACEPARENT"),
+		"tracestate":  os.Getenv("TRACESTATE"),
+	})
+	ctx, span := repo.tracer().Start(parent, name, trace.WithAttributes(attrs...))
+	repo.spanCtx = ctx
+	return span
+}
+
+// endSpan records the error of the call and flushes the spans, the plugin process may be stopped after any call
+func (repo *GitLabRepository) endSpan(span trace.Span, err *error) {
+	if *err != nil {
+		span.RecordError(*err)
+		span.SetStatus(codes.Error, (*err).Error())
+	}
+	span.End()
+	repo.spanCtx = nil
+	if repo.tracerProvider != nil {
+		_ = repo.tracerProvider.ForceFlush(context.Background())
+	}
+}
+
+// tracingTransport records a client span for every request as a child of the current provider call
+type tracingTransport struct {
+	next http.RoundTripper
+	repo *GitLabRepository
+}
+
+func (t *tracingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	parent := t.repo.spanCtx
+	if parent == nil {
+		parent = context.Background()
+	}
+	_, span := t.repo.tracer().Start(parent, "HTTP "+req.Method, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+		attribute.String("http.method", req.Method),
+		attribute.String("http.url", redactURL(req.URL)),
+	))
+	defer span.End()
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return resp, err
+	}
+	span.SetAttributes(attribute.Int("http.status_code", resp.StatusCode))
+	if resp.StatusCode >= 400 {
+		span.SetStatus(codes.Error, resp.Status)
+	}
+	return resp, err
+}
@@ -0,0 +1,72 @@
+package provider
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/go-semantic-release/provider-gitlab/pkg/gitlabtest"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestNewTracerProvider(t *testing.T) {
+	t.Setenv("OTEL_TRACES_EXPORTER", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "")
+	tp, err := newTracerProvider()
+	require.NoError(t, err)
+	require.Nil(t, tp)
+
+	t.Setenv("OTEL_TRACES_EXPORTER", "console")
+	tp, err = newTracerProvider()
+	require.NoError(t, err)
+	require.NotNil(t, tp)
+
+	t.Setenv("OTEL_TRACES_EXPORTER", "jaeger")
+	_, err = newTracerProvider()
+	require.EqualError(t, err, `unsupported OTEL_TRACES_EXPORTER "jaeger", expected otlp, console or none`)
+}
+
+func newTracedTestRepo(t *testing.T) (*GitLabRepository, *gitlabtest.Server, *tracetest.InMemoryExporter) {
+	server, project := newFakeServer(t)
+	addGitlabCommits(server, project)
+	exporter := tracetest.NewInMemoryExporter()
+	repo := initFakeRepo(t, &GitLabRepository{tracerProvider: sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))}, server, nil)
+	return repo, server, exporter
+}
+
+func TestTracingSpans(t *testing.T) {
+	repo, _, exporter := newTracedTestRepo(t)
+	exporter.Reset()
+
+	_, err := repo.GetCommits("", "")
+	require.NoError(t, err)
+
+	spans := exporter.GetSpans()
+	call := spans[len(spans)-1]
+	require.Equal(t, "GetCommits", call.Name)
+	for _, request := range spans[:len(spans)-1] {
+		require.Equal(t, "HTTP GET", request.Name)
+		require.Equal(t, call.SpanContext.SpanID(), request.Parent.SpanID())
+		require.Equal(t, call.SpanContext.TraceID(), request.SpanContext.TraceID())
+	}
+	require.Contains(t, spans[len(spans)-2].Attributes, attribute.Int("http.status_code", http.StatusOK))
+}
+
+func TestTracingRecordsErrors(t *testing.T) {
+	repo, server, exporter := newTracedTestRepo(t)
+	server.Fail("GET /api/v4/projects/group/app/repository/tags", http.StatusNotFound, 1)
+	exporter.Reset()
+
+	_, err := repo.GetReleases("")
+	require.Error(t, err)
+
+	spans := exporter.GetSpans()
+	call := spans[len(spans)-1]
+	require.Equal(t, "GetReleases", call.Name)
+	require.Equal(t, codes.Error, call.Status.Code)
+	require.Equal(t, codes.Error, spans[len(spans)-2].Status.Code)
+}
@@ -0,0 +1,130 @@
+package provider
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	logLevelInfo  = "info"
+	logLevelDebug = "debug"
+)
+
+// redactedQueryParams are query parameters which may carry credentials
+var redactedQueryParams = []string{"private_token", "job_token", "access_token", "token"}
+
+// credentialHeaders are set by the client from the token and cannot be configured as request headers
+var credentialHeaders = []string{"Private-Token", "Job-Token", "Authorization"}
+
+// parseRequestHeadersConfig parses the extra headers sent with every request, the user agent takes precedence over a
+// User-Agent header
+func parseRequestHeadersConfig(config map[string]string, headersKey, userAgentKey string) (http.Header, error) {
+	pairs, err := parseHeadersConfig(config, headersKey)
+	if err != nil {
+		return nil, err
+	}
+	headers := make(http.Header, len(pairs)+1)
+	for name, value := range pairs {
+		for _, credential := range credentialHeaders {
+			if strings.EqualFold(name, credential) {
+				return nil, fmt.Errorf("failed to set property %s: the %s header is set from the token", headersKey, name)
+			}
+		}
+		headers.Set(name, value)
+	}
+	if userAgent := config[userAgentKey]; userAgent != "" {
+		headers.Set("User-Agent", userAgent)
+	}
+	return headers, nil
+}
+
+// transport returns the transport used for all requests to GitLab, metrics, tracing and debug logging wrap the
+// default transport
+func (repo *GitLabRepository) transport() http.RoundTripper {
+	transport := http.DefaultTransport
+	if repo.metrics != nil {
+		transport = repo.metrics
+	}
+	if repo.tracerProvider != nil {
+		transport = &tracingTransport{next: transport, repo: repo}
+	}
+	if repo.logLevel == logLevelDebug {
+		transport = &debugTransport{next: transport, logger: repo.logger}
+	}
+	if len(repo.requestHeaders) > 0 {
+		transport = &headerTransport{next: transport, headers: repo.requestHeaders}
+	}
+	return transport
+}
+
+// headerTransport adds the configured headers to every request
+type headerTransport struct {
+	next    http.RoundTripper
+	headers http.Header
+}
+
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// a round tripper must not modify the request of the caller
+	req = req.Clone(req.Context())
+	for name, values := range t.headers {
+		req.Header[name] = values
+	}
+	return t.next.RoundTrip(req)
+}
+
+// debugf logs the message if the log level is debug
+func (repo *GitLabRepository) debugf(format string, args ...interface{}) {
+	if repo.logLevel == logLevelDebug {
+		repo.logger.Printf("DEBUG: "+format, args...)
+	}
+}
+
+// debugTransport logs method, path, status, duration and pagination of every request, headers are never logged as
+// they contain the token
+type debugTransport struct {
+	next   http.RoundTripper
+	logger *log.Logger
+}
+
+func (t *debugTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	duration := time.Since(start).Round(time.Millisecond)
+	target := redactURL(req.URL)
+
+	if err != nil {
+		t.logger.Printf("DEBUG: %s %s failed after %s: %s", req.Method, target, duration, err)
+		return resp, err
+	}
+
+	line := fmt.Sprintf("DEBUG: %s %s %d (%s)", req.Method, target, resp.StatusCode, duration)
+	if page := resp.Header.Get("X-Page"); page != "" {
+		line += ", page " + page
+		if total := resp.Header.Get("X-Total-Pages"); total != "" {
+			line += " of " + total
+		}
+		if next := resp.Header.Get("X-Next-Page"); next == "" {
+			line += ", last page"
+		}
+	}
+	t.logger.Print(line)
+	return resp, err
+}
+
+// redactURL returns the URL without user info and credential query parameters
+func redactURL(u *url.URL) string {
+	redacted := *u
+	redacted.User = nil
+	query := redacted.Query()
+	for _, param := range redactedQueryParams {
+		if query.Has(param) {
+			query.Set(param, "REDACTED")
+		}
+	}
+	redacted.RawQuery = query.Encode()
+	return redacted.String()
+}
@@ -0,0 +1,280 @@
+package provider
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/go-semantic-release/semantic-release/v2/pkg/provider"
+	"github.com/xanzy/go-gitlab"
+)
+
+const defaultVersionFilesMessage = "chore(release): {{.Version}}"
+
+// versionFile is a file whose version is replaced on release, the replacement depends on the file type:
+//   - .json files: the string at the JSON path selector (default: version)
+//   - .yaml/.yml and .toml files: the first top-level key matching the selector (default: version)
+//   - all other files: the first capture group of every match of the regex selector or the whole file without a selector
+type versionFile struct {
+	path      string
+	selector  string
+	re        *regexp.Regexp
+	firstOnly bool
+}
+
+// parseVersionFilesConfig parses a comma-separated list of path[:selector] entries
+func parseVersionFilesConfig(config map[string]string, key string) ([]*versionFile, error) {
+	files := make([]*versionFile, 0)
+	for _, entry := range parseListConfig(config, key) {
+		filePath, selector, _ := strings.Cut(entry, ":")
+		if strings.TrimSpace(filePath) == "" {
+			return nil, fmt.Errorf("failed to set property %s: missing path in %q", key, entry)
+		}
+		file, err := newVersionFile(strings.TrimSpace(filePath), strings.TrimSpace(selector))
+		if err != nil {
+			return nil, fmt.Errorf("failed to set property %s: %w", key, err)
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+func newVersionFile(filePath, selector string) (*versionFile, error) {
+	file := &versionFile{path: filePath, selector: selector}
+
+	var err error
+	switch path.Ext(file.path) {
+	case ".json":
+		if file.selector == "" {
+			file.selector = "version"
+		}
+	case ".yaml", ".yml":
+		file.firstOnly = true
+		file.re, err = regexp.Compile(`(?m)^` + regexp.QuoteMeta(defaultString(file.selector, "version")) + `:[ \t]*["']?([^"'\s#]+)`)
+	case ".toml":
+		file.firstOnly = true
+		file.re, err = regexp.Compile(`(?m)^` + regexp.QuoteMeta(defaultString(file.selector, "version")) + `[ \t]*=[ \t]*["']([^"']*)["']`)
+	default:
+		if file.selector != "" {
+			file.re, err = regexp.Compile(file.selector)
+			if err == nil && file.re.NumSubexp() == 0 {
+				err = errors.New("the regex needs a capture group")
+			}
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid selector for %s: %w", file.path, err)
+	}
+	return file, nil
+}
+
+func defaultString(value, defaultValue string) string {
+	if value == "" {
+		return defaultValue
+	}
+	return value
+}
+
+// bump returns the content of the file with the version replaced
+func (f *versionFile) bump(content []byte, version string) ([]byte, error) {
+	switch {
+	case path.Ext(f.path) == ".json":
+		return replaceJSONString(content, strings.Split(strings.TrimPrefix(f.selector, "$."), "."), version)
+	case f.re == nil:
+		return []byte(version + "\n"), nil
+	}
+
+	matches := f.re.FindAllSubmatchIndex(content, -1)
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("no version found in %s", f.path)
+	}
+	// keys of structured files are only replaced once, the same key in a nested table must not be touched
+	if f.firstOnly {
+		matches = matches[:1]
+	}
+
+	var buf bytes.Buffer
+	last := 0
+	for _, m := range matches {
+		buf.Write(content[last:m[2]])
+		buf.WriteString(version)
+		last = m[3]
+	}
+	buf.Write(content[last:])
+	return buf.Bytes(), nil
+}
+
+type jsonFrame struct {
+	object  bool
+	wantKey bool
+	key     string
+	index   int
+}
+
+// replaceJSONString replaces the string at the given path without changing the formatting of the document
+func replaceJSONString(content []byte, keys []string, value string) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(content))
+	stack := make([]*jsonFrame, 0)
+
+	matchesPath := func() bool {
+		if len(stack) != len(keys) {
+			return false
+		}
+		for i, frame := range stack {
+			key := frame.key
+			if !frame.object {
+				key = strconv.Itoa(frame.index)
+			}
+			if key != keys[i] {
+				return false
+			}
+		}
+		return true
+	}
+	valueDone := func() {
+		if len(stack) == 0 {
+			return
+		}
+		if top := stack[len(stack)-1]; top.object {
+			top.wantKey = true
+		} else {
+			top.index++
+		}
+	}
+
+	for {
+		start := dec.InputOffset()
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%s not found", strings.Join(keys, "."))
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if delim, ok := tok.(json.Delim); ok && (delim == '}' || delim == ']') {
+			stack = stack[:len(stack)-1]
+			valueDone()
+			continue
+		}
+		if n := len(stack); n > 0 && stack[n-1].wantKey {
+			stack[n-1].key, stack[n-1].wantKey = tok.(string), false
+			continue
+		}
+
+		if matchesPath() {
+			if _, ok := tok.(string); !ok {
+				return nil, fmt.Errorf("%s is not a string", strings.Join(keys, "."))
+			}
+			end := dec.InputOffset()
+			// the raw token is preceded by whitespace and separators only
+			quote := start + int64(bytes.IndexByte(content[start:end], '"'))
+			encoded, err := json.Marshal(value)
+			if err != nil {
+				return nil, err
+			}
+
+			result := make([]byte, 0, len(content)+len(encoded))
+			result = append(result, content[:quote]...)
+			result = append(result, encoded...)
+			return append(result, content[end:]...), nil
+		}
+
+		if delim, ok := tok.(json.Delim); ok {
+			stack = append(stack, &jsonFrame{object: delim == '{', wantKey: delim == '{'})
+			continue
+		}
+		valueDone()
+	}
+}
+
+// bumpVersionFiles commits the new version to all configured version files and returns the SHA of the new commit
+func (repo *GitLabRepository) bumpVersionFiles(tag string, release *provider.CreateReleaseConfig) (string, error) {
+	branch := repo.branch
+	if branch == "" {
+		branch = release.Branch
+	}
+	if branch == "" {
+		return "", errors.New("a branch is required to update the version files")
+	}
+
+	actions, err := repo.versionFileActions(release)
+	if err != nil {
+		return "", err
+	}
+	if len(actions) == 0 {
+		repo.logger.Printf("version files already contain version %s", release.NewVersion)
+		return release.SHA, nil
+	}
+
+	message, err := renderTemplate(repo.versionFilesMessage, newTemplateData(tag, release))
+	if err != nil {
+		return "", err
+	}
+
+	// the commit is added on top of the branch, which therefore must not contain any unreleased commits
+	head, err := repo.getBranchHead(branch)
+	if err != nil {
+		return "", err
+	}
+	if head != release.SHA {
+		return "", fmt.Errorf("branch %s moved from %s to %s, the version files can only be updated on top of the released commit", branch, release.SHA, head)
+	}
+
+	commit, err := repo.commitFiles(branch, repo.commitMessage(commitKindVersionFiles, message), actions)
+	if err != nil {
+		return "", fmt.Errorf("failed to update version files: %w", err)
+	}
+	repo.logger.Printf("updated version files in commit %s", commit.ID)
+	return commit.ID, nil
+}
+
+// versionFileActions returns the update actions of all version files which do not contain the new version yet
+func (repo *GitLabRepository) versionFileActions(release *provider.CreateReleaseConfig) ([]*gitlab.CommitActionOptions, error) {
+	actions := make([]*gitlab.CommitActionOptions, 0, len(repo.versionFiles))
+	for _, file := range repo.versionFiles {
+		content, _, err := repo.api.GetRawFile(repo.projectID, file.path, &gitlab.GetRawFileOptions{Ref: gitlab.String(release.SHA)})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get version file %s: %w", file.path, err)
+		}
+		bumped, err := file.bump(content, release.NewVersion)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update version file %s: %w", file.path, err)
+		}
+		if bytes.Equal(content, bumped) {
+			continue
+		}
+		actions = append(actions, &gitlab.CommitActionOptions{
+			Action:   gitlab.FileAction(gitlab.FileUpdate),
+			FilePath: gitlab.String(file.path),
+			Content:  gitlab.String(string(bumped)),
+		})
+	}
+	return actions, nil
+}
+
+func (repo *GitLabRepository) commitFiles(branch, message string, actions []*gitlab.CommitActionOptions) (*gitlab.Commit, error) {
+	commit, _, err := repo.api.CreateCommit(repo.projectID, &gitlab.CreateCommitOptions{
+		Branch:        gitlab.String(branch),
+		CommitMessage: gitlab.String(message),
+		Actions:       actions,
+	})
+	return commit, err
+}
+
+// withSHA returns a copy of the release config pointing at another commit
+func withSHA(release *provider.CreateReleaseConfig, sha string) *provider.CreateReleaseConfig {
+	return &provider.CreateReleaseConfig{
+		Changelog:  release.Changelog,
+		NewVersion: release.NewVersion,
+		Prerelease: release.Prerelease,
+		Branch:     release.Branch,
+		SHA:        sha,
+	}
+}
@@ -0,0 +1,111 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/go-semantic-release/semantic-release/v2/pkg/provider"
+	"github.com/stretchr/testify/require"
+	"github.com/xanzy/go-gitlab"
+)
+
+func TestVersionFileBump(t *testing.T) {
+	testCases := []struct {
+		entry    string
+		content  string
+		expected string
+	}{
+		{"VERSION", "1.0.0\n", "2.0.0\n"},
+		{"package.json", "{\n  \"name\": \"app\",\n  \"version\": \"1.0.0\"\n}\n", "{\n  \"name\": \"app\",\n  \"version\": \"2.0.0\"\n}\n"},
+		{"package.json", `{"deps": {"version": "9"}, "version": "1.0.0"}`, `{"deps": {"version": "9"}, "version": "2.0.0"}`},
+		{"app.json:$.meta.versions.1", `{"meta": {"versions": ["0.1.0", "1.0.0"]}}`, `{"meta": {"versions": ["0.1.0", "2.0.0"]}}`},
+		{"Chart.yaml", "name: app\nversion: 1.0.0 # chart\nappVersion: \"1.0.0\"\n", "name: app\nversion: 2.0.0 # chart\nappVersion: \"1.0.0\"\n"},
+		{"Chart.yaml:appVersion", "version: 0.1.0\nappVersion: \"1.0.0\"\n", "version: 0.1.0\nappVersion: \"2.0.0\"\n"},
+		{"pyproject.toml", "[tool.poetry]\nversion = \"1.0.0\"\n\n[other]\nversion = \"3\"\n", "[tool.poetry]\nversion = \"2.0.0\"\n\n[other]\nversion = \"3\"\n"},
+		{"README.md:app@v(\\d+\\.\\d+\\.\\d+)", "use app@v1.0.0 or app@v1.0.0", "use app@v2.0.0 or app@v2.0.0"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.entry, func(t *testing.T) {
+			files, err := parseVersionFilesConfig(map[string]string{"gitlab_version_files": tc.entry}, "gitlab_version_files")
+			require.NoError(t, err)
+			require.Len(t, files, 1)
+
+			bumped, err := files[0].bump([]byte(tc.content), "2.0.0")
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, string(bumped))
+		})
+	}
+}
+
+func TestVersionFileBumpErrors(t *testing.T) {
+	_, err := parseVersionFilesConfig(map[string]string{"gitlab_version_files": "README.md:v1"}, "gitlab_version_files")
+	require.EqualError(t, err, "failed to set property gitlab_version_files: invalid selector for README.md: the regex needs a capture group")
+
+	files, err := parseVersionFilesConfig(map[string]string{"gitlab_version_files": "package.json, Chart.yaml"}, "gitlab_version_files")
+	require.NoError(t, err)
+
+	_, err = files[0].bump([]byte(`{"name": "app"}`), "2.0.0")
+	require.EqualError(t, err, "version not found")
+	_, err = files[0].bump([]byte(`{"version": 1}`), "2.0.0")
+	require.EqualError(t, err, "version is not a string")
+	_, err = files[1].bump([]byte("name: app\n"), "2.0.0")
+	require.EqualError(t, err, "no version found in Chart.yaml")
+}
+
+func TestGitlabBumpVersionFiles(t *testing.T) {
+	head := "deadbeef"
+	var commit map[string]interface{}
+	var releaseRef string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == fmt.Sprintf("/api/v4/projects/%d/repository/files/VERSION/raw", GITLAB_PROJECT_ID):
+			require.Equal(t, "deadbeef", r.URL.Query().Get("ref"))
+			fmt.Fprint(w, "1.0.0\n")
+		case r.Method == "GET" && r.URL.Path == fmt.Sprintf("/api/v4/projects/%d/repository/files/package.json/raw", GITLAB_PROJECT_ID):
+			fmt.Fprint(w, `{"version": "2.0.0"}`)
+		case r.Method == "GET" && r.URL.Path == fmt.Sprintf("/api/v4/projects/%d/repository/branches/master", GITLAB_PROJECT_ID):
+			json.NewEncoder(w).Encode(gitlab.Branch{Name: "master", Commit: &gitlab.Commit{ID: head}}) //nolint:errcheck
+		case r.Method == "POST" && r.URL.Path == fmt.Sprintf("/api/v4/projects/%d/repository/commits", GITLAB_PROJECT_ID):
+			json.NewDecoder(r.Body).Decode(&commit) //nolint:errcheck
+			fmt.Fprint(w, `{"id": "cafebabe"}`)
+		case r.Method == "POST" && r.URL.Path == fmt.Sprintf("/api/v4/projects/%d/releases", GITLAB_PROJECT_ID):
+			var opts gitlab.CreateReleaseOptions
+			json.NewDecoder(r.Body).Decode(&opts) //nolint:errcheck
+			releaseRef = *opts.Ref
+			fmt.Fprint(w, "{}")
+		default:
+			GitlabHandler(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	repo := &GitLabRepository{}
+	err := repo.Init(map[string]string{
+		"gitlab_baseurl":       ts.URL,
+		"token":                "gitlab-examples-ci",
+		"gitlab_branch":        "master",
+		"gitlab_projectid":     strconv.Itoa(GITLAB_PROJECT_ID),
+		"gitlab_version_files": "VERSION,package.json",
+	})
+	require.NoError(t, err)
+
+	err = repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "2.0.0", SHA: "deadbeef"})
+	require.NoError(t, err)
+	require.Equal(t, "cafebabe", releaseRef)
+	require.Equal(t, map[string]interface{}{
+		"branch":         "master",
+		"commit_message": "chore(release): 2.0.0",
+		"actions": []interface{}{
+			map[string]interface{}{"action": "update", "file_path": "VERSION", "content": "2.0.0\n"},
+		},
+	}, commit)
+
+	head = "ffff"
+	err = repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "2.0.0", SHA: "deadbeef"})
+	require.EqualError(t, err, "branch master moved from deadbeef to ffff, the version files can only be updated on top of the released commit")
+}
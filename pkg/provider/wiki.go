@@ -0,0 +1,86 @@
+package provider
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+// wikiSlug returns the slug GitLab derives from the title of a wiki page
+func wikiSlug(title string) string {
+	return strings.ReplaceAll(title, " ", "-")
+}
+
+// upsertWikiPage creates the wiki page or replaces its content
+func (repo *GitLabRepository) upsertWikiPage(title, content string) error {
+	slug := wikiSlug(title)
+	_, resp, err := repo.api.GetWikiPage(repo.projectID, slug, nil)
+	switch {
+	case err == nil:
+		_, _, err = repo.api.EditWikiPage(repo.projectID, slug, &gitlab.EditWikiPageOptions{
+			Title:   &title,
+			Content: &content,
+		})
+	case resp != nil && resp.StatusCode == http.StatusNotFound:
+		_, _, err = repo.api.CreateWikiPage(repo.projectID, &gitlab.CreateWikiPageOptions{
+			Title:   &title,
+			Content: &content,
+		})
+	}
+	return err
+}
+
+// addWikiIndexEntry adds the entry on top of the first list of the page unless the page already links the release
+func addWikiIndexEntry(content, slug, tag string) string {
+	link := fmt.Sprintf("](/%s)", slug)
+	if strings.Contains(content, link) {
+		return content
+	}
+	entry := fmt.Sprintf("- [%s%s", tag, link)
+
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, "- ") {
+			lines = append(lines[:i], append([]string{entry}, lines[i:]...)...)
+			return strings.Join(lines, "\n")
+		}
+	}
+	return strings.TrimRight(content, "\n") + "\n\n" + entry + "\n"
+}
+
+// publishWikiPage writes the release notes to the wiki and lists them on the index page, failures are only logged
+func (repo *GitLabRepository) publishWikiPage(data *templateData) {
+	title, err := renderTemplate(repo.wikiPage, data)
+	if err != nil {
+		repo.logger.Printf("WARNING: %s", err)
+		return
+	}
+
+	content := fmt.Sprintf("# %s\n\n%s\n", data.Tag, strings.TrimRight(formatChangelog(data.Changelog, repo.changelogMode), "\n"))
+	if data.ReleaseURL != "" {
+		content += fmt.Sprintf("\n[Release %s](%s)\n", data.Tag, data.ReleaseURL)
+	}
+	if err := repo.upsertWikiPage(title, content); err != nil {
+		repo.logger.Printf("WARNING: failed to write wiki page %s: %s", title, err)
+		return
+	}
+	repo.logger.Printf("wrote release notes to wiki page %s", title)
+
+	if repo.wikiIndex == "" {
+		return
+	}
+	index := "# " + repo.wikiIndex + "\n"
+	page, resp, err := repo.api.GetWikiPage(repo.projectID, wikiSlug(repo.wikiIndex), nil)
+	switch {
+	case err == nil:
+		index = page.Content
+	case resp == nil || resp.StatusCode != http.StatusNotFound:
+		repo.logger.Printf("WARNING: failed to get wiki page %s: %s", repo.wikiIndex, err)
+		return
+	}
+	if err := repo.upsertWikiPage(repo.wikiIndex, addWikiIndexEntry(index, wikiSlug(title), data.Tag)); err != nil {
+		repo.logger.Printf("WARNING: failed to write wiki page %s: %s", repo.wikiIndex, err)
+	}
+}
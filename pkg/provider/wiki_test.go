@@ -0,0 +1,74 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/go-semantic-release/semantic-release/v2/pkg/provider"
+	"github.com/stretchr/testify/require"
+	"github.com/xanzy/go-gitlab"
+)
+
+func TestAddWikiIndexEntry(t *testing.T) {
+	require.Equal(t, "# Releases\n\n- [v2.0.0](/Releases/v2.0.0)\n", addWikiIndexEntry("# Releases\n", "Releases/v2.0.0", "v2.0.0"))
+	require.Equal(t, "# Releases\n\n- [v2.0.0](/Releases/v2.0.0)\n- [v1.0.0](/Releases/v1.0.0)\n", addWikiIndexEntry("# Releases\n\n- [v1.0.0](/Releases/v1.0.0)\n", "Releases/v2.0.0", "v2.0.0"))
+	require.Equal(t, "- [v2.0.0](/Releases/v2.0.0)\n", addWikiIndexEntry("- [v2.0.0](/Releases/v2.0.0)\n", "Releases/v2.0.0", "v2.0.0"))
+}
+
+func TestGitlabPublishWikiPage(t *testing.T) {
+	pages := map[string]string{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		wikisPath := fmt.Sprintf("/api/v4/projects/%d/wikis", GITLAB_PROJECT_ID)
+		if !strings.HasPrefix(r.URL.Path, wikisPath) {
+			GitlabHandler(w, r)
+			return
+		}
+
+		slug := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, wikisPath), "/")
+		var opts gitlab.CreateWikiPageOptions
+		switch r.Method {
+		case "GET":
+			content, ok := pages[slug]
+			if !ok {
+				http.Error(w, `{"message":"404 Wiki Page Not Found"}`, http.StatusNotFound)
+				return
+			}
+			json.NewEncoder(w).Encode(gitlab.Wiki{Slug: slug, Content: content}) //nolint:errcheck
+			return
+		case "POST":
+			json.NewDecoder(r.Body).Decode(&opts) //nolint:errcheck
+			slug = wikiSlug(*opts.Title)
+		case "PUT":
+			json.NewDecoder(r.Body).Decode(&opts) //nolint:errcheck
+		}
+		pages[slug] = *opts.Content
+		fmt.Fprint(w, "{}")
+	}))
+	defer ts.Close()
+
+	repo := &GitLabRepository{}
+	err := repo.Init(map[string]string{
+		"gitlab_baseurl":    ts.URL,
+		"token":             "gitlab-examples-ci",
+		"gitlab_projectid":  strconv.Itoa(GITLAB_PROJECT_ID),
+		"gitlab_wiki_page":  "Releases/{{.Tag}}",
+		"gitlab_wiki_index": "Releases",
+	})
+	require.NoError(t, err)
+
+	err = repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "2.0.0", SHA: "deadbeef", Changelog: "* feat\n"})
+	require.NoError(t, err)
+	require.Equal(t, "# v2.0.0\n\n* feat\n", pages["Releases/v2.0.0"])
+	require.Equal(t, "# Releases\n\n- [v2.0.0](/Releases/v2.0.0)\n", pages["Releases"])
+
+	// a rerun replaces the page and does not duplicate the index entry
+	err = repo.CreateRelease(&provider.CreateReleaseConfig{NewVersion: "2.0.0", SHA: "deadbeef", Changelog: "* fix\n"})
+	require.NoError(t, err)
+	require.Equal(t, "# v2.0.0\n\n* fix\n", pages["Releases/v2.0.0"])
+	require.Equal(t, "# Releases\n\n- [v2.0.0](/Releases/v2.0.0)\n", pages["Releases"])
+}